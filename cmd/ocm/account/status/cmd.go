@@ -80,7 +80,7 @@ func run(cmd *cobra.Command, argv []string) error {
 	currAccount := response.Body()
 	currOrg := currAccount.Organization()
 	fmt.Printf("User %s on %s in org '%s' %s (external_id: %s) ",
-		currAccount.Username(), cfg.URL, currOrg.Name(), currOrg.ID(), currOrg.ExternalID())
+		currAccount.Username(), connection.URL(), currOrg.Name(), currOrg.ID(), currOrg.ExternalID())
 
 	// Display roles currently assigned to the user
 	roleSlice, err := acc_util.GetRolesFromUsers([]*amv1.Account{currAccount}, connection)
@@ -0,0 +1,34 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/cmd/ocm/auth/status"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "auth COMMAND",
+	Short: "Inspect authentication",
+	Long:  "Inspect the credentials used to authenticate to the API.",
+	Args:  cobra.NoArgs,
+}
+
+func init() {
+	Cmd.AddCommand(status.Cmd)
+}
@@ -0,0 +1,219 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/properties"
+	"github.com/openshift-online/ocm-cli/pkg/region"
+)
+
+var args struct {
+	json    bool
+	offline bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show authentication status",
+	Long: "Show the API URL and region, the authentication method, the details of the access " +
+		"and refresh tokens, where the credentials are stored, and whether the server accepts " +
+		"them. The tokens are decoded without verifying their signatures. The expiration time " +
+		"of encrypted refresh tokens can't be known, so it is reported as unknown.",
+	Example: `  # Show the authentication status
+  ocm auth status
+
+  # Show the authentication status without contacting the server, in JSON format
+  ocm auth status --offline --json`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Print the status in JSON format.",
+	)
+	flags.BoolVar(
+		&args.offline,
+		"offline",
+		false,
+		"Don't contact the server to check if it accepts the credentials.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("Can't load config file: %v", err)
+	}
+	status := config.NewAuthStatus(cfg, config.CurrentStore())
+	if overrideURL := os.Getenv(properties.URLEnvKey); overrideURL != "" {
+		status.URL = overrideURL
+	}
+
+	if name := region.Selected(); name != "" {
+		regional, err := region.Find(status.URL, name)
+		if err != nil {
+			return err
+		}
+		status.URL = regional.URL
+		status.Region = regional.Name
+	}
+
+	if status.LoggedIn && !args.offline {
+		if status.Region == "" {
+			status.Region = region.NameOf(status.URL)
+		}
+		checkServer(cfg, status)
+	}
+
+	if args.json {
+		data, err := json.Marshal(status)
+		if err != nil {
+			return err
+		}
+		err = dump.Pretty(os.Stdout, data)
+		if err != nil {
+			return err
+		}
+	} else {
+		printStatus(status)
+	}
+
+	switch {
+	case !status.LoggedIn:
+		return fmt.Errorf("Not logged in, %s, run the 'login' command", status.Reason)
+	case status.Accepted != nil && !*status.Accepted:
+		return fmt.Errorf("Server didn't accept the credentials: %s", status.Error)
+	}
+	return nil
+}
+
+// checkServer sends a request to the server to check if it accepts the credentials, and saves the
+// result in the status.
+func checkServer(cfg *config.Config, status *config.AuthStatus) {
+	accepted := false
+	status.Accepted = &accepted
+	connection, err := ocm.NewConnection().Config(cfg).Build()
+	if err != nil {
+		status.Error = err.Error()
+		return
+	}
+	defer connection.Close()
+	response, err := connection.AccountsMgmt().V1().CurrentAccount().Get().Send()
+	if err != nil {
+		status.Error = err.Error()
+		return
+	}
+	accepted = true
+	status.Account = response.Body().Username()
+}
+
+func printStatus(status *config.AuthStatus) {
+	printField("URL", status.URL)
+	printField("Region", status.Region)
+	printField("Token URL", status.TokenURL)
+	printField("Method", status.Method)
+	printField("Client ID", status.ClientID)
+	printField("User", status.User)
+	printField("Credential helper", status.CredentialHelper)
+	switch {
+	case status.Store.Keyring != "":
+		printField("Stored in", fmt.Sprintf("%s keyring '%s'", status.Store.Kind, status.Store.Keyring))
+	case status.Store.File != "":
+		printField("Stored in", fmt.Sprintf("%s file '%s'", status.Store.Kind, status.Store.File))
+	default:
+		printField("Stored in", status.Store.Kind)
+	}
+	if status.LoggedIn {
+		printField("Logged in", "yes")
+	} else {
+		printField("Logged in", fmt.Sprintf("no, %s", status.Reason))
+	}
+	if status.Accepted != nil {
+		if *status.Accepted {
+			printField("Server", "accepts the credentials")
+		} else {
+			printField("Server", fmt.Sprintf("doesn't accept the credentials: %s", status.Error))
+		}
+	}
+	printField("Account", status.Account)
+	printToken("Access token", status.AccessToken)
+	printToken("Refresh token", status.RefreshToken)
+}
+
+func printToken(name string, token *config.TokenInfo) {
+	if token == nil {
+		return
+	}
+	fmt.Printf("%s:\n", name)
+	if token.Error != "" {
+		fmt.Printf("  %-18s%s\n", "Error:", token.Error)
+		return
+	}
+	if token.Encrypted {
+		fmt.Printf("  %-18s%s\n", "Expires:", "unknown (encrypted token)")
+		return
+	}
+	printTokenField("Type", token.Type)
+	printTokenField("Issuer", token.Issuer)
+	printTokenField("Subject", token.Subject)
+	printTokenField("Username", token.Username)
+	printTokenField("Scopes", strings.Join(token.Scopes, " "))
+	if token.IssuedAt != nil {
+		printTokenField("Issued", token.IssuedAt.Local().Format(time.RFC3339))
+	}
+	switch {
+	case token.ExpiresAt == nil:
+		printTokenField("Expires", "never")
+	case token.Expired:
+		printTokenField("Expires", fmt.Sprintf("%s (expired)",
+			token.ExpiresAt.Local().Format(time.RFC3339)))
+	default:
+		printTokenField("Expires", fmt.Sprintf("%s (in %s)",
+			token.ExpiresAt.Local().Format(time.RFC3339),
+			time.Until(*token.ExpiresAt).Round(time.Second)))
+	}
+}
+
+func printField(name, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Printf("%-20s%s\n", name+":", value)
+}
+
+func printTokenField(name, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Printf("  %-18s%s\n", name+":", value)
+}
@@ -17,8 +17,13 @@ limitations under the License.
 package cluster
 
 import (
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/events"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/kubeconfig"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/login"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/logs"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/proxy"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/status"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/trustbundle"
 	"github.com/spf13/cobra"
 )
 
@@ -30,6 +35,11 @@ var Cmd = &cobra.Command{
 }
 
 func init() {
+	Cmd.AddCommand(events.Cmd)
+	Cmd.AddCommand(kubeconfig.Cmd)
 	Cmd.AddCommand(login.Cmd)
+	Cmd.AddCommand(logs.Cmd)
+	Cmd.AddCommand(proxy.Cmd)
 	Cmd.AddCommand(status.Cmd)
+	Cmd.AddCommand(trustbundle.Cmd)
 }
@@ -0,0 +1,221 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+var args struct {
+	since      string
+	severities []string
+	sources    []string
+	json       bool
+	post       bool
+	template   string
+	params     map[string]string
+	dryRun     bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "events [flags] CLUSTER",
+	Short: "Show the timeline of events of a cluster",
+	Long: "Show in chronological order the service log entries of a cluster, together with the " +
+		"creation of the cluster, its limited support reasons, the state of its upgrade " +
+		"policies and the changes of its subscription.\n" +
+		"\n" +
+		"With --post a service log entry is posted to the cluster instead. The entry is read " +
+		"from a JSON template with the 'severity', 'service_name', 'summary', 'description', " +
+		"'internal_only', 'log_type', 'event_stream_id' and 'doc_references' fields, where " +
+		"'${NAME}' placeholders are replaced with the values given with --param.",
+	Example: `  # Show the events of the last week
+  ocm cluster events mycluster --since 7d
+
+  # Show the warnings and errors in JSON format
+  ocm cluster events mycluster --severity warning,error --json
+
+  # Check and then post a service log entry from a template
+  ocm cluster events mycluster --post --template notice.json --param REASON=maintenance --dry-run
+  ocm cluster events mycluster --post --template notice.json --param REASON=maintenance`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.since,
+		"since",
+		"",
+		"Show only events after this time, given as a duration like '36h' or '7d', or as a "+
+			"date like '2024-05-01'.",
+	)
+	flags.StringSliceVar(
+		&args.severities,
+		"severity",
+		nil,
+		"Show only events with these severities: Debug, Info, Warning, Error or Fatal.",
+	)
+	flags.StringSliceVar(
+		&args.sources,
+		"source",
+		nil,
+		fmt.Sprintf("Show only events from these sources: %s, %s, %s, %s or %s.",
+			c.EventSourceCluster, c.EventSourceServiceLog, c.EventSourceLimitedSupport,
+			c.EventSourceUpgrade, c.EventSourceSubscription),
+	)
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Print the events in JSON format.",
+	)
+	flags.BoolVar(
+		&args.post,
+		"post",
+		false,
+		"Post a service log entry to the cluster from the template given with --template.",
+	)
+	flags.StringVar(
+		&args.template,
+		"template",
+		"",
+		"JSON file containing the template of the service log entry to post.",
+	)
+	flags.StringToStringVar(
+		&args.params,
+		"param",
+		nil,
+		"Value of a template placeholder, in 'NAME=VALUE' format. Can be repeated.",
+	)
+	flags.BoolVar(
+		&args.dryRun,
+		"dry-run",
+		false,
+		"Print the service log entry without posting it.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	if !args.post && (args.template != "" || len(args.params) > 0 || args.dryRun) {
+		return fmt.Errorf("Flags --template, --param and --dry-run can only be used with --post")
+	}
+	if args.post && args.template == "" {
+		return fmt.Errorf("Flag --template is required with --post")
+	}
+	var filter c.EventFilter
+	if args.since != "" {
+		since, err := c.ParseSince(args.since, time.Now())
+		if err != nil {
+			return err
+		}
+		filter.Since = since
+	}
+	filter.Severities = args.severities
+	filter.Sources = args.sources
+
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	if args.post {
+		template, err := c.LoadServiceLogTemplate(args.template, args.params)
+		if err != nil {
+			return err
+		}
+		entry, err := c.BuildServiceLog(template, cluster)
+		if err != nil {
+			return fmt.Errorf("Failed to build service log entry: %v", err)
+		}
+		if args.dryRun {
+			data, err := marshalEntry(entry)
+			if err != nil {
+				return err
+			}
+			return dump.Pretty(os.Stdout, data)
+		}
+		response, err := connection.ServiceLogs().V1().ClusterLogs().Add().Body(entry).Send()
+		if err != nil {
+			return fmt.Errorf("Failed to post service log entry to cluster '%s': %v",
+				cluster.Name(), err)
+		}
+		fmt.Printf("Posted service log entry '%s' to cluster '%s'\n", response.Body().ID(),
+			cluster.Name())
+		return nil
+	}
+
+	input, err := c.GetEventInput(connection, cluster, filter)
+	if err != nil {
+		return err
+	}
+	events := c.FilterEvents(c.BuildTimeline(input), filter)
+
+	if args.json {
+		if events == nil {
+			events = []c.Event{}
+		}
+		data, err := json.Marshal(events)
+		if err != nil {
+			return err
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+	if len(events) == 0 {
+		fmt.Fprintf(os.Stderr, "No events found for cluster '%s'\n", cluster.Name())
+		return nil
+	}
+	return c.PrintEvents(os.Stdout, events)
+}
+
+func marshalEntry(entry *slv1.LogEntry) ([]byte, error) {
+	var buffer bytes.Buffer
+	err := slv1.MarshalLogEntry(entry, &buffer)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to marshal service log entry: %v", err)
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,38 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/kubeconfig/list"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/kubeconfig/remove"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/kubeconfig/use"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "kubeconfig COMMAND",
+	Short: "Manage kubeconfig contexts",
+	Long: "List, switch and remove the kubeconfig contexts created with " +
+		"'ocm cluster login --kubeconfig'. Contexts created by other tools aren't modified.",
+	Args: cobra.MinimumNArgs(1),
+}
+
+func init() {
+	Cmd.AddCommand(list.Cmd)
+	Cmd.AddCommand(use.Cmd)
+	Cmd.AddCommand(remove.Cmd)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package list
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/openshift-online/ocm-cli/pkg/kubeconfig"
+	"github.com/spf13/cobra"
+)
+
+var args struct {
+	kubeconfig string
+}
+
+var Cmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List kubeconfig contexts created by ocm",
+	Long:    "List the kubeconfig contexts created with 'ocm cluster login --kubeconfig'.",
+	Example: "  # List the contexts of the default kubeconfig file\n" +
+		"  ocm cluster kubeconfig list",
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.kubeconfig,
+		"kubeconfig",
+		"",
+		"Kubeconfig file. Defaults to the first file of the KUBECONFIG environment "+
+			"variable or '~/.kube/config'.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	path := args.kubeconfig
+	if path == "" {
+		var err error
+		path, err = kubeconfig.DefaultPath()
+		if err != nil {
+			return err
+		}
+	}
+	config, err := kubeconfig.Load(path)
+	if err != nil {
+		return err
+	}
+	entries := config.Entries()
+	if len(entries) == 0 {
+		fmt.Fprintf(os.Stderr, "There are no contexts created by ocm in '%s'\n", path)
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "CURRENT\tNAME\tCLUSTER\tID\tSERVER\tUSER\n")
+	for _, entry := range entries {
+		current := ""
+		if entry.Current {
+			current = "*"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			current, entry.Name, entry.Extension.ClusterName, entry.Extension.ClusterID,
+			entry.Server, entry.Extension.Username)
+	}
+	return writer.Flush()
+}
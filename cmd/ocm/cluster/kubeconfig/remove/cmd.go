@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remove
+
+import (
+	"fmt"
+
+	"github.com/openshift-online/ocm-cli/pkg/kubeconfig"
+	"github.com/spf13/cobra"
+)
+
+var args struct {
+	kubeconfig string
+}
+
+var Cmd = &cobra.Command{
+	Use:     "remove CONTEXT|CLUSTER...",
+	Aliases: []string{"rm", "delete"},
+	Short:   "Remove kubeconfig contexts created by ocm",
+	Long: "Remove kubeconfig contexts created with 'ocm cluster login --kubeconfig', " +
+		"together with their cluster and user entries when no other context uses them.",
+	Example: "  # Remove the context of cluster 'mycluster'\n" +
+		"  ocm cluster kubeconfig remove mycluster",
+	Args: cobra.MinimumNArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.kubeconfig,
+		"kubeconfig",
+		"",
+		"Kubeconfig file. Defaults to the first file of the KUBECONFIG environment "+
+			"variable or '~/.kube/config'.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	path := args.kubeconfig
+	if path == "" {
+		var err error
+		path, err = kubeconfig.DefaultPath()
+		if err != nil {
+			return err
+		}
+	}
+	config, err := kubeconfig.Load(path)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, key := range argv {
+		entry, err := config.Find(key)
+		if err != nil {
+			return err
+		}
+		names = append(names, entry.Name)
+	}
+	for _, name := range names {
+		config.Remove(name)
+	}
+	err = kubeconfig.Save(path, config)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		fmt.Printf("Removed context '%s'\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package use
+
+import (
+	"fmt"
+
+	"github.com/openshift-online/ocm-cli/pkg/kubeconfig"
+	"github.com/spf13/cobra"
+)
+
+var args struct {
+	kubeconfig string
+}
+
+var Cmd = &cobra.Command{
+	Use:     "use CONTEXT|CLUSTER",
+	Aliases: []string{"switch"},
+	Short:   "Switch to a kubeconfig context created by ocm",
+	Long: "Make current the kubeconfig context created with 'ocm cluster login --kubeconfig' " +
+		"that has the given name, or that belongs to the cluster with the given name or " +
+		"identifier.",
+	Example: "  # Switch to the context of cluster 'mycluster'\n" +
+		"  ocm cluster kubeconfig use mycluster",
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.kubeconfig,
+		"kubeconfig",
+		"",
+		"Kubeconfig file. Defaults to the first file of the KUBECONFIG environment "+
+			"variable or '~/.kube/config'.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	path := args.kubeconfig
+	if path == "" {
+		var err error
+		path, err = kubeconfig.DefaultPath()
+		if err != nil {
+			return err
+		}
+	}
+	config, err := kubeconfig.Load(path)
+	if err != nil {
+		return err
+	}
+	entry, err := config.Find(argv[0])
+	if err != nil {
+		return err
+	}
+	config.Use(entry.Name)
+	err = kubeconfig.Save(path, config)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Switched to context '%s' of cluster '%s'\n", entry.Name, entry.Extension.ClusterName)
+	return nil
+}
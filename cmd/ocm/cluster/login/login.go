@@ -17,30 +17,46 @@ limitations under the License.
 package login
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
 
+	"github.com/AlecAivazis/survey/v2"
 	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/kubeconfig"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
 )
 
 var args struct {
-	user    string
-	console bool
-	token   bool
+	user       string
+	console    bool
+	token      bool
+	kubeconfig string
+	context    string
+	web        bool
+	caFile     string
+	insecure   bool
 }
 
 var Cmd = &cobra.Command{
 	Use:   "login [flags] {CLUSTER_ID|CLUSTER_NAME|CLUSTER_NAME_SEARCH}",
 	Short: "login to a cluster",
 	Long: "login to a cluster by ID or Name or cluster name search string according to the api: " +
-		"https://api.openshift.com/#/clusters/get_api_clusters_mgmt_v1_clusters",
-	Example: " ocm cluster login <id>\n ocm cluster login %test%",
-	RunE:    run,
+		"https://api.openshift.com/#/clusters/get_api_clusters_mgmt_v1_clusters\n" +
+		"\n" +
+		"By default 'oc login' is used. With --kubeconfig the token is obtained from the OAuth " +
+		"server of the cluster, with a user name and password or with the browser, and a " +
+		"context named after the cluster is written to the given kubeconfig file, so 'oc' " +
+		"isn't needed. Use 'ocm cluster kubeconfig' to manage these contexts.",
+	Example: " ocm cluster login <id>\n ocm cluster login %test%\n" +
+		" ocm cluster login <id> --kubeconfig ~/.kube/config --username admin\n" +
+		" ocm cluster login <id> --kubeconfig ~/.kube/config --web",
+	RunE: run,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			return fmt.Errorf("cluster name expected")
@@ -73,7 +89,38 @@ func init() {
 		false,
 		"Display the cluster API login token using the default browser",
 	)
-
+	flags.StringVar(
+		&args.kubeconfig,
+		"kubeconfig",
+		"",
+		"Write a context for the cluster to this kubeconfig file instead of running 'oc login'.",
+	)
+	flags.StringVar(
+		&args.context,
+		"context",
+		"",
+		"Name of the kubeconfig context. Defaults to the name of the cluster.",
+	)
+	flags.BoolVar(
+		&args.web,
+		"web",
+		false,
+		"Obtain the token with the browser instead of a user name and password. Used "+
+			"with --kubeconfig.",
+	)
+	flags.StringVar(
+		&args.caFile,
+		"certificate-authority",
+		"",
+		"File containing the certificate authorities of the API and OAuth servers, in "+
+			"addition to the system ones. Used with --kubeconfig.",
+	)
+	flags.BoolVar(
+		&args.insecure,
+		"insecure-skip-tls-verify",
+		false,
+		"Don't verify the certificates of the API and OAuth servers. Used with --kubeconfig.",
+	)
 }
 func run(cmd *cobra.Command, argv []string) error {
 	// Check that the cluster key (name, identifier or external identifier) given by the user
@@ -87,11 +134,6 @@ func run(cmd *cobra.Command, argv []string) error {
 		)
 	}
 
-	path, err := exec.LookPath("oc")
-	if err != nil {
-		return fmt.Errorf("to run this, you need install the OpenShift CLI (oc) first")
-	}
-
 	// Create the client for the OCM API:
 	connection, err := ocm.NewConnection().Build()
 	if err != nil {
@@ -134,6 +176,16 @@ func run(cmd *cobra.Command, argv []string) error {
 	if len(cluster.API().URL()) == 0 {
 		return fmt.Errorf("cannot find the api URL for cluster: %s", cluster.Name())
 	}
+
+	if args.kubeconfig != "" {
+		return loginKubeconfig(cluster)
+	}
+
+	path, err := exec.LookPath("oc")
+	if err != nil {
+		return fmt.Errorf("to run this, you need install the OpenShift CLI (oc) first, " +
+			"or use --kubeconfig")
+	}
 	ocArgs := []string{}
 	ocArgs = append(ocArgs, "login", cluster.API().URL())
 	if args.user != "" {
@@ -152,3 +204,93 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	return nil
 }
+
+// loginKubeconfig obtains a token from the OAuth server of the cluster and writes a context for
+// the cluster to the kubeconfig file.
+func loginKubeconfig(cluster *cmv1.Cluster) error {
+	if len(cluster.Console().URL()) == 0 {
+		return fmt.Errorf("cannot find the console URL for cluster: %s", cluster.Name())
+	}
+	oauthURL := c.GetClusterOauthURL(cluster)
+	client, caData, err := c.ClusterClient(args.caFile, args.insecure)
+	if err != nil {
+		return err
+	}
+
+	var token string
+	if args.web {
+		loginURL := c.TokenRequestURL(oauthURL)
+		fmt.Printf(" Login URL: %s\n", loginURL)
+		err = browser.OpenURL(loginURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Can't open the browser, open the login URL manually: %v\n", err)
+		}
+		err = survey.AskOne(&survey.Password{
+			Message: "Paste the API token displayed in the browser:",
+		}, &token, survey.WithValidator(survey.Required))
+		if err != nil {
+			return err
+		}
+		token = strings.TrimSpace(token)
+	} else {
+		username := args.user
+		if username == "" {
+			err = survey.AskOne(&survey.Input{
+				Message: "Username:",
+			}, &username, survey.WithValidator(survey.Required))
+			if err != nil {
+				return err
+			}
+		}
+		var password string
+		err = survey.AskOne(&survey.Password{
+			Message: "Password:",
+		}, &password, survey.WithValidator(survey.Required))
+		if err != nil {
+			return err
+		}
+		token, err = c.RequestToken(client, oauthURL, username, password)
+		if err != nil {
+			return fmt.Errorf("failed to login to cluster: %v", err)
+		}
+	}
+
+	username, err := c.GetCurrentUser(client, cluster.API().URL(), token)
+	if err != nil {
+		return fmt.Errorf("failed to login to cluster: %v", err)
+	}
+
+	contextName := args.context
+	if contextName == "" {
+		contextName = cluster.Name()
+	}
+	config, err := kubeconfig.Load(args.kubeconfig)
+	if err != nil {
+		return err
+	}
+	kubeCluster := kubeconfig.Cluster{
+		Server:                cluster.API().URL(),
+		InsecureSkipTLSVerify: args.insecure,
+	}
+	if caData != nil {
+		kubeCluster.CertificateAuthorityData = base64.StdEncoding.EncodeToString(caData)
+	}
+	err = config.SetContext(contextName, kubeCluster, kubeconfig.NamedUser{
+		Name: fmt.Sprintf("%s/%s", username, contextName),
+		User: kubeconfig.User{Token: token},
+	}, kubeconfig.Extension{
+		ClusterID:   cluster.ID(),
+		ClusterName: cluster.Name(),
+		Username:    username,
+	})
+	if err != nil {
+		return fmt.Errorf("%v, use --context to choose another name", err)
+	}
+	err = kubeconfig.Save(args.kubeconfig, config)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Logged in as '%s', context '%s' of '%s' is now the current context\n",
+		username, contextName, args.kubeconfig)
+	return nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	install   bool
+	uninstall bool
+	follow    bool
+	tail      int
+	interval  time.Duration
+	save      string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "logs [flags] CLUSTER",
+	Short: "Show the install or uninstall log of a cluster",
+	Long: "Show the install log of a cluster identified by its identifier or name, or the " +
+		"uninstall log when --uninstall is used.\n" +
+		"\n" +
+		"With --follow the log is polled and new lines are written as they arrive, until the " +
+		"cluster leaves the installing or uninstalling state.",
+	Example: `  # Show the install log of a cluster
+  ocm cluster logs mycluster
+
+  # Follow the install log, starting with the last 50 lines, and save it to a file
+  ocm cluster logs mycluster --follow --tail 50 --save install.log
+
+  # Show the uninstall log
+  ocm cluster logs mycluster --uninstall`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.install,
+		"install",
+		false,
+		"Show the install log. This is the default.",
+	)
+	flags.BoolVar(
+		&args.uninstall,
+		"uninstall",
+		false,
+		"Show the uninstall log.",
+	)
+	flags.BoolVarP(
+		&args.follow,
+		"follow",
+		"f",
+		false,
+		"Keep writing new lines of the log until the cluster finishes installing or "+
+			"uninstalling.",
+	)
+	flags.IntVar(
+		&args.tail,
+		"tail",
+		0,
+		"Number of lines from the end of the log to show. By default the complete log is shown.",
+	)
+	flags.DurationVar(
+		&args.interval,
+		"interval",
+		10*time.Second,
+		"Time between polls of the log when following it.",
+	)
+	flags.StringVar(
+		&args.save,
+		"save",
+		"",
+		"Also write the log to this file.",
+	)
+	Cmd.MarkFlagsMutuallyExclusive("install", "uninstall")
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	if args.tail < 0 {
+		return fmt.Errorf("Number of lines of --tail must be positive")
+	}
+	if args.interval <= 0 {
+		return fmt.Errorf("Interval must be positive")
+	}
+	logType := c.LogTypeInstall
+	if args.uninstall {
+		logType = c.LogTypeUninstall
+	}
+
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	var output io.Writer = os.Stdout
+	if args.save != "" {
+		file, err := os.Create(args.save)
+		if err != nil {
+			return fmt.Errorf("Failed to create file '%s': %v", args.save, err)
+		}
+		defer file.Close()
+		output = io.MultiWriter(os.Stdout, file)
+	}
+
+	// Stop following the log when interrupted:
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	source := c.NewLogSource(connection.ClustersMgmt().V1().Clusters(), cluster.ID(), logType)
+	err = c.NewLogStreamer(source).
+		Tail(args.tail).
+		Follow(args.follow).
+		Interval(args.interval).
+		Output(output).
+		Run(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to get %s log of cluster '%s': %v", logType, cluster.Name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/proxy/set"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/proxy/show"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/proxy/unset"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "proxy COMMAND",
+	Short: "Manage the cluster-wide proxy",
+	Long: "Show, set and remove the HTTP proxy, HTTPS proxy and no-proxy settings of the " +
+		"cluster-wide proxy. Use the 'trust-bundle' command to manage the additional trust bundle.",
+	Args: cobra.MinimumNArgs(1),
+}
+
+func init() {
+	Cmd.AddCommand(show.Cmd)
+	Cmd.AddCommand(set.Cmd)
+	Cmd.AddCommand(unset.Cmd)
+}
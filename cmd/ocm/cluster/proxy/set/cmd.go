@@ -0,0 +1,136 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package set
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	httpProxy  string
+	httpsProxy string
+	noProxy    string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "set [flags] CLUSTER",
+	Short: "Change the cluster-wide proxy settings",
+	Long: "Change the cluster-wide proxy settings of a cluster. Only the settings given in the " +
+		"command line are changed.",
+	Example: `  # Use the same proxy for HTTP and HTTPS connections
+  ocm cluster proxy set mycluster --http-proxy http://proxy.example.com:3128 \
+    --https-proxy http://proxy.example.com:3128
+
+  # Don't use the proxy for an internal domain
+  ocm cluster proxy set mycluster --no-proxy .internal.example.com`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.httpProxy,
+		"http-proxy",
+		"",
+		"A proxy URL to use for creating HTTP connections outside the cluster.",
+	)
+	flags.StringVar(
+		&args.httpsProxy,
+		"https-proxy",
+		"",
+		"A proxy URL to use for creating HTTPS connections outside the cluster.",
+	)
+	flags.StringVar(
+		&args.noProxy,
+		"no-proxy",
+		"",
+		"A comma-separated list of destination domain names, domains, IP addresses or other "+
+			"network CIDRs to exclude proxying.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	var proxy c.ClusterWideProxy
+	flags := cmd.Flags()
+	if flags.Changed("http-proxy") {
+		proxy.HTTPProxy = &args.httpProxy
+	}
+	if flags.Changed("https-proxy") {
+		proxy.HTTPSProxy = &args.httpsProxy
+	}
+	if flags.Changed("no-proxy") {
+		proxy.NoProxy = &args.noProxy
+	}
+	if proxy.HTTPProxy == nil && proxy.HTTPSProxy == nil && proxy.NoProxy == nil {
+		return fmt.Errorf("At least one of --http-proxy, --https-proxy or --no-proxy is required")
+	}
+	for _, value := range []*string{proxy.HTTPProxy, proxy.HTTPSProxy, proxy.NoProxy} {
+		if value != nil && *value == "" {
+			return fmt.Errorf("Proxy settings can't be empty, use 'ocm cluster proxy unset' to " +
+				"remove them")
+		}
+	}
+	err := c.ValidateProxy(proxy.HTTPProxy, proxy.HTTPSProxy, proxy.NoProxy)
+	if err != nil {
+		return err
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+	if !c.SupportsClusterWideProxy(cluster) {
+		return fmt.Errorf("Cluster-wide proxy is not supported on clusters using the default VPC")
+	}
+	if proxy.NoProxy != nil && proxy.HTTPProxy == nil && proxy.HTTPSProxy == nil &&
+		cluster.Proxy().HTTPProxy() == "" && cluster.Proxy().HTTPSProxy() == "" {
+		return fmt.Errorf("Can't set --no-proxy without an HTTP or HTTPS proxy")
+	}
+
+	err = c.UpdateCluster(connection.ClustersMgmt().V1().Clusters(), cluster.ID(), c.Spec{
+		ClusterWideProxy: proxy,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to update proxy of cluster '%s': %v", clusterKey, err)
+	}
+	fmt.Printf("Updated proxy settings of cluster '%s'\n", clusterKey)
+	return nil
+}
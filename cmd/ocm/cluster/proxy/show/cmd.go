@@ -0,0 +1,126 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package show
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	json bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "show [flags] CLUSTER",
+	Short: "Show the cluster-wide proxy settings",
+	Long:  "Show the cluster-wide proxy settings of a cluster.",
+	Example: `  # Show the proxy settings of cluster 'mycluster'
+  ocm cluster proxy show mycluster`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Print the settings in JSON format.",
+	)
+}
+
+type proxySettings struct {
+	HTTPProxy             string `json:"http_proxy"`
+	HTTPSProxy            string `json:"https_proxy"`
+	NoProxy               string `json:"no_proxy"`
+	AdditionalTrustBundle string `json:"additional_trust_bundle"`
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	// Describe the trust bundle instead of printing it, use the 'trust-bundle' command to see
+	// the certificates:
+	bundle := "not set"
+	switch value := cluster.AdditionalTrustBundle(); {
+	case value == c.TrustBundleRedacted:
+		bundle = "set"
+	case value != "":
+		certs, err := c.ParseTrustBundle(value)
+		if err != nil {
+			bundle = fmt.Sprintf("set, can't be parsed: %v", err)
+		} else {
+			bundle = fmt.Sprintf("%d certificates", len(certs))
+		}
+	}
+	settings := proxySettings{
+		HTTPProxy:             cluster.Proxy().HTTPProxy(),
+		HTTPSProxy:            cluster.Proxy().HTTPSProxy(),
+		NoProxy:               cluster.Proxy().NoProxy(),
+		AdditionalTrustBundle: bundle,
+	}
+
+	if args.json {
+		data, err := json.Marshal(settings)
+		if err != nil {
+			return err
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+	fmt.Printf("%-26s%s\n", "HTTP proxy:", valueOrNone(settings.HTTPProxy))
+	fmt.Printf("%-26s%s\n", "HTTPS proxy:", valueOrNone(settings.HTTPSProxy))
+	fmt.Printf("%-26s%s\n", "No proxy:", valueOrNone(settings.NoProxy))
+	fmt.Printf("%-26s%s\n", "Additional trust bundle:", settings.AdditionalTrustBundle)
+	return nil
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "not set"
+	}
+	return value
+}
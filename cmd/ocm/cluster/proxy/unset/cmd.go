@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unset
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	httpProxy  bool
+	httpsProxy bool
+	noProxy    bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "unset [flags] CLUSTER",
+	Short: "Remove cluster-wide proxy settings",
+	Long: "Remove cluster-wide proxy settings of a cluster. When no flag is given all the " +
+		"settings are removed. The additional trust bundle isn't changed.",
+	Example: `  # Stop using the proxy for HTTP connections
+  ocm cluster proxy unset mycluster --http-proxy
+
+  # Remove all the proxy settings
+  ocm cluster proxy unset mycluster`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.httpProxy,
+		"http-proxy",
+		false,
+		"Remove the HTTP proxy.",
+	)
+	flags.BoolVar(
+		&args.httpsProxy,
+		"https-proxy",
+		false,
+		"Remove the HTTPS proxy.",
+	)
+	flags.BoolVar(
+		&args.noProxy,
+		"no-proxy",
+		false,
+		"Remove the list of destinations that don't use the proxy.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+	all := !args.httpProxy && !args.httpsProxy && !args.noProxy
+	empty := ""
+	var proxy c.ClusterWideProxy
+	if all || args.httpProxy {
+		proxy.HTTPProxy = &empty
+	}
+	if all || args.httpsProxy {
+		proxy.HTTPSProxy = &empty
+	}
+	if all || args.noProxy {
+		proxy.NoProxy = &empty
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	err = c.UpdateCluster(connection.ClustersMgmt().V1().Clusters(), cluster.ID(), c.Spec{
+		ClusterWideProxy: proxy,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to update proxy of cluster '%s': %v", clusterKey, err)
+	}
+	fmt.Printf("Removed proxy settings of cluster '%s'\n", clusterKey)
+	return nil
+}
@@ -17,20 +17,87 @@ limitations under the License.
 package status
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"sync"
+	"time"
 
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/spf13/cobra"
 )
 
+// concurrency is the number of clusters whose health report is generated at the same time.
+const concurrency = 8
+
+var args struct {
+	detailed bool
+	json     bool
+	search   string
+	since    string
+}
+
 var Cmd = &cobra.Command{
-	Use:   "status [flags] CLUSTER_ID",
+	Use:   "status [flags] CLUSTER_ID...",
 	Short: "Status of a cluster",
-	Long:  "Get the status of a cluster identified by its cluster ID",
-	RunE:  run,
+	Long: "Get the status of a cluster identified by its cluster ID.\n" +
+		"\n" +
+		"With --detailed a health report is generated that checks the state of the cluster, " +
+		"provision errors, limited support reasons, compute replicas, upgrade policies, " +
+		"add-ons, the subscription and warnings in service logs, and calculates a score " +
+		"from 0 to 100. Only the service log warnings sent in the period given with --since, " +
+		"the last 7 days by default, are considered. Detailed reports can be generated for " +
+		"several clusters at once, given as arguments or selected with --search. In JSON format " +
+		"the reports are always printed as an array, even for a single cluster.",
+	Example: "  # Show the state and usage of a cluster\n" +
+		"  ocm cluster status 1234567890abcdef\n" +
+		"\n" +
+		"  # Show the health report of two clusters\n" +
+		"  ocm cluster status --detailed mycluster othercluster\n" +
+		"\n" +
+		"  # Show the health report of all the ready AWS clusters in JSON format\n" +
+		"  ocm cluster status --detailed --json --search \"state = 'ready' and cloud_provider.id = 'aws'\"",
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.detailed,
+		"detailed",
+		false,
+		"Generate a health report of the cluster.",
+	)
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Print the health report in JSON format. Implies --detailed.",
+	)
+	flags.StringVar(
+		&args.search,
+		"search",
+		"",
+		"Search criteria selecting the clusters to report, for example "+
+			"\"name like 'prod-%'\". Implies --detailed.",
+	)
+	flags.StringVar(
+		&args.since,
+		"since",
+		"7d",
+		"Only consider the service log warnings sent after this time, given as a duration like "+
+			"'36h' or '7d', or a date like '2024-05-01'.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	if args.detailed || args.json || args.search != "" {
+		return runDetailed(argv)
+	}
 
 	if len(argv) != 1 {
 		return fmt.Errorf("Expected exactly one cluster id")
@@ -96,3 +163,106 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	return nil
 }
+
+func runDetailed(argv []string) error {
+	if len(argv) == 0 && args.search == "" {
+		return fmt.Errorf("Expected at least one cluster or the --search flag")
+	}
+	for _, key := range argv {
+		if !c.IsValidClusterKey(key) {
+			return fmt.Errorf(
+				"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+					"must contain only letters, digits, dashes and underscores",
+				key,
+			)
+		}
+	}
+	since, err := c.ParseSince(args.since, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	var clusters []*cmv1.Cluster
+	for _, key := range argv {
+		cluster, err := c.GetCluster(connection, key)
+		if err != nil {
+			return fmt.Errorf("Failed to get cluster '%s': %v", key, err)
+		}
+		clusters = append(clusters, cluster)
+	}
+	if args.search != "" {
+		found, err := searchClusters(connection, args.search)
+		if err != nil {
+			return err
+		}
+		if len(found) == 0 {
+			return fmt.Errorf("There are no clusters matching '%s'", args.search)
+		}
+		clusters = append(clusters, found...)
+	}
+
+	// Generate the reports in parallel, as each one needs several requests:
+	reports := make([]*c.HealthReport, len(clusters))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency && i < len(clusters); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				input := c.GetHealthInput(connection, clusters[index], since)
+				reports[index] = c.EvaluateHealth(input)
+			}
+		}()
+	}
+	for i := range clusters {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if args.json {
+		data, err := json.Marshal(reports)
+		if err != nil {
+			return err
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+	for i, report := range reports {
+		if i > 0 {
+			fmt.Println()
+		}
+		err = c.PrintHealthReport(os.Stdout, report)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func searchClusters(connection *sdk.Connection, search string) ([]*cmv1.Cluster, error) {
+	var clusters []*cmv1.Cluster
+	size := 100
+	for page := 1; ; page++ {
+		response, err := connection.ClustersMgmt().V1().Clusters().List().
+			Search(search).
+			Page(page).
+			Size(size).
+			Send()
+		if err != nil {
+			return nil, fmt.Errorf("Can't retrieve clusters: %v", err)
+		}
+		clusters = append(clusters, response.Items().Slice()...)
+		if response.Size() < size {
+			break
+		}
+	}
+	return clusters, nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package add
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	file    string
+	current string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "add [flags] CLUSTER",
+	Short: "Add certificates to the additional trust bundle",
+	Long: "Add the certificates of a PEM file to the additional trust bundle of a cluster. " +
+		"Certificates that are already in the trust bundle are skipped.",
+	Example: `  # Add the certificate of the proxy to the trust bundle of cluster 'mycluster'
+  ocm cluster trust-bundle add mycluster --file proxy-ca.pem`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.file,
+		"file",
+		"",
+		"PEM file containing the certificates to add (required).",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("file")
+	flags.StringVar(
+		&args.current,
+		"current-bundle-file",
+		"",
+		"PEM file containing the current trust bundle, needed when the server doesn't return "+
+			"its contents.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	certs, err := c.ReadTrustBundleFile(args.file)
+	if err != nil {
+		return err
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+	if !c.SupportsClusterWideProxy(cluster) {
+		return fmt.Errorf("Additional trust bundle is not supported on clusters using the " +
+			"default VPC")
+	}
+
+	bundle, err := c.CurrentTrustBundle(cluster, args.current)
+	if err != nil {
+		return err
+	}
+	bundle, added := c.AddCertificates(bundle, certs)
+	if len(added) == 0 {
+		fmt.Printf("All the certificates are already in the trust bundle of cluster '%s'\n",
+			clusterKey)
+		return nil
+	}
+	for _, warning := range c.CertificateWarnings(added, time.Now()) {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	err = c.UpdateTrustBundle(connection.ClustersMgmt().V1().Clusters(), cluster.ID(), bundle)
+	if err != nil {
+		return fmt.Errorf("Failed to update trust bundle of cluster '%s': %v", clusterKey, err)
+	}
+	for _, cert := range added {
+		fmt.Printf("Added certificate '%s' (%s) to the trust bundle of cluster '%s'\n",
+			cert.Name(), cert.ID(), clusterKey)
+	}
+	return nil
+}
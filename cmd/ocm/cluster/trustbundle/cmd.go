@@ -0,0 +1,41 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trustbundle
+
+import (
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/trustbundle/add"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/trustbundle/remove"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/trustbundle/replace"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/trustbundle/show"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:     "trust-bundle COMMAND",
+	Aliases: []string{"trustbundle"},
+	Short:   "Manage the additional trust bundle",
+	Long: "Show, add and remove the certificates of the additional trust bundle of a cluster, " +
+		"used to trust the certificates of the cluster-wide proxy and other services.",
+	Args: cobra.MinimumNArgs(1),
+}
+
+func init() {
+	Cmd.AddCommand(show.Cmd)
+	Cmd.AddCommand(add.Cmd)
+	Cmd.AddCommand(remove.Cmd)
+	Cmd.AddCommand(replace.Cmd)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remove
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	current string
+}
+
+var Cmd = &cobra.Command{
+	Use:     "remove [flags] CLUSTER CERTIFICATE...",
+	Aliases: []string{"rm", "delete"},
+	Short:   "Remove certificates from the additional trust bundle",
+	Long: "Remove certificates from the additional trust bundle of a cluster. Certificates are " +
+		"identified by the identifier shown by the 'show' command or any other prefix of at " +
+		"least eight digits of their SHA-256 fingerprint, by their common name or by their " +
+		"complete subject. If all the certificates are removed the trust bundle is removed.",
+	Example: `  # Remove the certificate with common name 'Old Proxy CA'
+  ocm cluster trust-bundle remove mycluster "Old Proxy CA"
+
+  # Remove a certificate using the identifier shown by the 'show' command
+  ocm cluster trust-bundle remove mycluster 3f2a9c1b7d4e8f60`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.current,
+		"current-bundle-file",
+		"",
+		"PEM file containing the current trust bundle, needed when the server doesn't return "+
+			"its contents.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	bundle, err := c.CurrentTrustBundle(cluster, args.current)
+	if err != nil {
+		return err
+	}
+	bundle, removed, err := c.RemoveCertificates(bundle, argv[1:])
+	if err != nil {
+		return err
+	}
+
+	err = c.UpdateTrustBundle(connection.ClustersMgmt().V1().Clusters(), cluster.ID(), bundle)
+	if err != nil {
+		return fmt.Errorf("Failed to update trust bundle of cluster '%s': %v", clusterKey, err)
+	}
+	for _, cert := range removed {
+		fmt.Printf("Removed certificate '%s' (%s) from the trust bundle of cluster '%s'\n",
+			cert.Name(), cert.ID(), clusterKey)
+	}
+	if len(bundle) == 0 {
+		fmt.Printf("Removed the trust bundle of cluster '%s'\n", clusterKey)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replace
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	file string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "replace [flags] CLUSTER",
+	Short: "Replace the additional trust bundle",
+	Long: "Replace all the certificates of the additional trust bundle of a cluster with the " +
+		"certificates of a PEM file.",
+	Example: `  # Replace the trust bundle of cluster 'mycluster'
+  ocm cluster trust-bundle replace mycluster --file bundle.pem`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.file,
+		"file",
+		"",
+		"PEM file containing the new trust bundle (required).",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("file")
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	certs, err := c.ReadTrustBundleFile(args.file)
+	if err != nil {
+		return err
+	}
+
+	// Remove duplicated certificates:
+	certs, _ = c.AddCertificates(nil, certs)
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+	if !c.SupportsClusterWideProxy(cluster) {
+		return fmt.Errorf("Additional trust bundle is not supported on clusters using the " +
+			"default VPC")
+	}
+	for _, warning := range c.CertificateWarnings(certs, time.Now()) {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	err = c.UpdateTrustBundle(connection.ClustersMgmt().V1().Clusters(), cluster.ID(), certs)
+	if err != nil {
+		return fmt.Errorf("Failed to update trust bundle of cluster '%s': %v", clusterKey, err)
+	}
+	fmt.Printf("Replaced the trust bundle of cluster '%s' with %d certificates\n", clusterKey,
+		len(certs))
+	return nil
+}
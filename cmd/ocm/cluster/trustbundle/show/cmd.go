@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package show
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	json bool
+	pem  bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "show [flags] CLUSTER",
+	Short: "Show the certificates of the additional trust bundle",
+	Long: "Show the subject and expiration date of the certificates of the additional trust " +
+		"bundle of a cluster, and warn about the ones that are expired or will expire soon.",
+	Example: `  # Show the certificates of the trust bundle of cluster 'mycluster'
+  ocm cluster trust-bundle show mycluster
+
+  # Save the trust bundle to a file
+  ocm cluster trust-bundle show mycluster --pem > bundle.pem`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Print the certificates in JSON format.",
+	)
+	flags.BoolVar(
+		&args.pem,
+		"pem",
+		false,
+		"Print the PEM encoded certificates.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	if args.json && args.pem {
+		return fmt.Errorf("Flags --json and --pem can't be used together")
+	}
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	switch cluster.AdditionalTrustBundle() {
+	case "":
+		return fmt.Errorf("Cluster '%s' doesn't have an additional trust bundle", clusterKey)
+	case c.TrustBundleRedacted:
+		return fmt.Errorf("Cluster '%s' has an additional trust bundle, but the server doesn't "+
+			"return its contents", clusterKey)
+	}
+	certs, err := c.CurrentTrustBundle(cluster, "")
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	switch {
+	case args.json:
+		data, err := json.Marshal(certs)
+		if err != nil {
+			return err
+		}
+		err = dump.Pretty(os.Stdout, data)
+		if err != nil {
+			return err
+		}
+	case args.pem:
+		fmt.Print(c.FormatTrustBundle(certs))
+	default:
+		err = c.PrintTrustBundle(os.Stdout, certs, now)
+		if err != nil {
+			return err
+		}
+	}
+	for _, warning := range c.CertificateWarnings(certs, now) {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	return nil
+}
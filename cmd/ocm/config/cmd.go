@@ -24,6 +24,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/openshift-online/ocm-cli/cmd/ocm/config/get"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/config/migrate"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/config/set"
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/properties"
@@ -38,7 +39,7 @@ func configVarDocs() (ret string) {
 		// TODO(efried): Use JSON parser instead
 		name := strings.Split(tag.Get("json"), ",")[0]
 		doc := tag.Get("doc")
-		fieldHelps[i] = fmt.Sprintf("\t%-15s%s", name, doc)
+		fieldHelps[i] = fmt.Sprintf("\t%-19s%s", name, doc)
 	}
 	ret = strings.Join(fieldHelps, "\n")
 	return
@@ -69,8 +70,12 @@ following backends are supported for the keyring:
 - Linux: secret-service, pass
 - Windows: wincred
 
+The '%s' backend stores the configuration in a file encrypted with a passphrase, for hosts without
+an OS keyring. Use 'ocm config migrate' to move the configuration between stores.
+
 Available Keyrings on your OS: %s
-`, loc, configVarDocs(), properties.KeyringEnvKey, strings.Join(config.GetKeyrings(), ", "))
+`, loc, configVarDocs(), properties.KeyringEnvKey, config.EncryptedKeyring,
+		strings.Join(config.GetKeyrings(), ", "))
 	return
 }
 
@@ -82,5 +87,6 @@ var Cmd = &cobra.Command{
 
 func init() {
 	Cmd.AddCommand(get.Cmd)
+	Cmd.AddCommand(migrate.Cmd)
 	Cmd.AddCommand(set.Cmd)
 }
@@ -69,6 +69,8 @@ func run(cmd *cobra.Command, argv []string) error {
 		fmt.Fprintf(os.Stdout, "%s\n", cfg.ClientID)
 	case "client_secret":
 		fmt.Fprintf(os.Stdout, "%s\n", cfg.ClientSecret)
+	case "credential_helper":
+		fmt.Fprintf(os.Stdout, "%s\n", cfg.CredentialHelper)
 	case "insecure":
 		fmt.Fprintf(os.Stdout, "%v\n", cfg.Insecure)
 	case "password":
@@ -0,0 +1,120 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrate
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/properties"
+)
+
+var args struct {
+	to         string
+	keyring    string
+	keepSource bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "migrate --to=keyring|encrypted|plaintext",
+	Short: "Move the configuration to another store",
+	Long: "Move the configuration, including tokens and secrets, from the store currently " +
+		"selected by the " + properties.KeyringEnvKey + " environment variable to an OS " +
+		"keyring, to a file encrypted with a passphrase, or to the plain text configuration " +
+		"file. The configuration is read back from the new store before it is removed from " +
+		"the old one.\n" +
+		"\n" +
+		"The passphrase of the encrypted file is read from the " + config.PassphraseEnv +
+		" environment variable, from the file descriptor given in the " +
+		config.PassphraseFDEnv + " environment variable, or from the terminal.",
+	Example: `  # Move the configuration from the plain text file to an encrypted file
+  ocm config migrate --to=encrypted
+  export OCM_KEYRING=encrypted
+
+  # Move the configuration from the encrypted file to the secret service keyring
+  OCM_KEYRING=encrypted ocm config migrate --to=keyring --keyring=secret-service`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.to,
+		"to",
+		"",
+		"Store where the configuration is moved: keyring, encrypted or plaintext.",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("to")
+	flags.StringVar(
+		&args.keyring,
+		"keyring",
+		"",
+		fmt.Sprintf("OS keyring backend used with --to=keyring. Available backends: %v.",
+			config.GetKeyrings()),
+	)
+	flags.BoolVar(
+		&args.keepSource,
+		"keep-source",
+		false,
+		"Don't remove the configuration from the current store.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	to := config.Store{Kind: args.to}
+	switch args.to {
+	case config.StoreKeyring:
+		if args.keyring == "" || args.keyring == config.EncryptedKeyring {
+			return fmt.Errorf("Flag --keyring is required with --to=keyring, use one of %v",
+				config.GetKeyrings())
+		}
+		to.Keyring = args.keyring
+	case config.StoreEncrypted, config.StorePlaintext:
+		if args.keyring != "" {
+			return fmt.Errorf("Flag --keyring can only be used with --to=keyring")
+		}
+	default:
+		return fmt.Errorf("Unknown store '%s', use keyring, encrypted or plaintext", args.to)
+	}
+	from := config.CurrentStore()
+
+	// The descriptions contain the locations of the files, and the location of the plain text file
+	// may change when it is removed, so they are calculated before migrating:
+	source, target := from.String(), to.String()
+	err := config.Migrate(from, to, args.keepSource)
+	if err != nil {
+		return fmt.Errorf("Can't migrate configuration: %v", err)
+	}
+	fmt.Printf("Configuration moved from %s to %s\n", source, target)
+
+	// Tell the user how to select the new store:
+	switch to.Kind {
+	case config.StoreKeyring:
+		fmt.Fprintf(os.Stderr, "To use it set %s=%s\n", properties.KeyringEnvKey, to.Keyring)
+	case config.StoreEncrypted:
+		fmt.Fprintf(os.Stderr, "To use it set %s=%s\n", properties.KeyringEnvKey,
+			config.EncryptedKeyring)
+	default:
+		fmt.Fprintf(os.Stderr, "To use it unset %s\n", properties.KeyringEnvKey)
+	}
+	return nil
+}
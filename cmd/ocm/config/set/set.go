@@ -69,6 +69,8 @@ func run(cmd *cobra.Command, argv []string) error {
 		cfg.ClientID = value
 	case "client_secret":
 		cfg.ClientSecret = value
+	case "credential_helper":
+		cfg.CredentialHelper = value
 	case "insecure":
 		cfg.Insecure, err = strconv.ParseBool(value)
 		if err != nil {
@@ -0,0 +1,167 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/addon"
+	"github.com/openshift-online/ocm-cli/pkg/arguments"
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	asv1 "github.com/openshift-online/ocm-sdk-go/addonsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var args struct {
+	clusterKey  string
+	parameters  []string
+	valuesFile  string
+	interactive bool
+}
+
+var Cmd = &cobra.Command{
+	Use:     "addon --cluster={NAME|ID|EXTERNAL_ID} [flags] ADDON_ID",
+	Aliases: []string{"addons", "add-on", "add-ons"},
+	Short:   "Install an add-on on a cluster",
+	Long: "Install an add-on on a cluster. The values of the add-on parameters can be given " +
+		"with the --param flag, read from a values file or asked for interactively.",
+	Example: `  # Install the 'managed-odh' add-on on a cluster named 'mycluster'
+  ocm create addon --cluster=mycluster managed-odh
+
+  # Install an add-on giving the value of a parameter
+  ocm create addon --cluster=mycluster --param notification-email=me@example.com my-addon
+
+  # Install an add-on asking for the values of its parameters
+  ocm create addon --cluster=mycluster --interactive my-addon`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+
+	flags.StringVarP(
+		&args.clusterKey,
+		"cluster",
+		"c",
+		"",
+		"Name or ID or external_id of the cluster to install the add-on on (required).",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("cluster")
+
+	flags.StringArrayVar(
+		&args.parameters,
+		"param",
+		nil,
+		"Value of an add-on parameter in 'name=value' format. Can be used multiple times. "+
+			"Takes precedence over the values file.",
+	)
+	flags.StringVar(
+		&args.valuesFile,
+		"values",
+		"",
+		"YAML or JSON file containing a map of add-on parameter names to values.",
+	)
+	arguments.AddInteractiveFlag(flags, &args.interactive)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	addOnID := argv[0]
+
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := args.clusterKey
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	values, err := addon.CollectValues(args.valuesFile, args.parameters)
+	if err != nil {
+		return err
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+	if cluster.State() != cmv1.ClusterStateReady {
+		return fmt.Errorf("Cluster '%s' is not yet ready", clusterKey)
+	}
+
+	addOn, err := addon.GetAddOn(connection, addOnID)
+	if err != nil {
+		return err
+	}
+	installation, err := addon.GetAddOnInstallation(connection, cluster.ID(), addOnID)
+	if err != nil {
+		return err
+	}
+	if installation != nil {
+		return fmt.Errorf("Add-on '%s' is already installed on cluster '%s', use 'ocm edit addon' "+
+			"to change its parameters", addOnID, clusterKey)
+	}
+
+	parameters := addon.GetParameters(addOn)
+	if args.interactive {
+		err = addon.PromptValues(parameters, values, nil)
+		if err != nil {
+			return err
+		}
+	}
+	err = addon.ValidateValues(parameters, values, nil)
+	if err != nil {
+		return err
+	}
+
+	body, err := asv1.NewAddonInstallation().
+		ID(addOnID).
+		Addon(asv1.NewAddon().ID(addOnID)).
+		Parameters(addon.BuildParameters(values)).
+		Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create add-on installation for cluster '%s': %v", clusterKey, err)
+	}
+
+	_, err = connection.AddonsMgmt().V1().Clusters().
+		Cluster(cluster.ID()).
+		Addons().
+		Add().
+		Body(body).
+		Send()
+	if err != nil {
+		return fmt.Errorf("Failed to install add-on '%s' on cluster '%s': %v", addOnID, clusterKey, err)
+	}
+
+	fmt.Printf("Installing add-on '%s' on cluster '%s', run 'ocm describe addon --cluster=%s %s' "+
+		"to check its state\n", addOnID, clusterKey, clusterKey, addOnID)
+	return nil
+}
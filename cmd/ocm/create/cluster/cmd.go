@@ -17,6 +17,7 @@ limitations under the License.
 package cluster
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -27,10 +28,11 @@ import (
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
-	"github.com/openshift-online/ocm-cli/cmd/ocm/edit/ingress"
 	"github.com/openshift-online/ocm-cli/pkg/arguments"
 	"github.com/openshift-online/ocm-cli/pkg/billing"
 	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/gcp"
+	"github.com/openshift-online/ocm-cli/pkg/ingress"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	"github.com/openshift-online/ocm-cli/pkg/provider"
 	"github.com/openshift-online/ocm-cli/pkg/utils"
@@ -68,6 +70,7 @@ var args struct {
 	// flags
 	interactive bool
 	dryRun      bool
+	preflight   bool
 
 	region                string
 	version               string
@@ -163,6 +166,12 @@ func init() {
 		false,
 		"Simulate creating the cluster.",
 	)
+	fs.BoolVar(
+		&args.preflight,
+		"preflight",
+		false,
+		"Check the network and the credentials of a GCP cluster before creating it.",
+	)
 
 	arguments.AddProviderFlag(fs, &args.provider)
 	Cmd.RegisterFlagCompletionFunc("provider", arguments.MakeCompleteFunc(osdProviderOptions))
@@ -807,6 +816,11 @@ func run(cmd *cobra.Command, argv []string) error {
 		return err
 	}
 
+	err = validateNetwork(connection)
+	if err != nil {
+		return err
+	}
+
 	if args.interactive {
 		args.subscriptionType = parseSubscriptionType(args.subscriptionType)
 	}
@@ -841,6 +855,13 @@ func run(cmd *cobra.Command, argv []string) error {
 		GcpPrivateSvcConnect: args.gcpPrivateSvcConnect,
 	}
 
+	if args.preflight {
+		err = runPreflight(connection, clusterConfig)
+		if err != nil {
+			return err
+		}
+	}
+
 	cluster, err := c.CreateCluster(connection.ClustersMgmt().V1(), clusterConfig, args.dryRun)
 	if err != nil {
 		return fmt.Errorf("Failed to create cluster: %v", err)
@@ -865,10 +886,97 @@ func run(cmd *cobra.Command, argv []string) error {
 	return nil
 }
 
+// validateNetwork checks that the machine, service and pod CIDRs and the host prefix don't conflict,
+// using the defaults of the flavour for the ones that weren't given.
+func validateNetwork(connection *sdk.Connection) error {
+	plan := c.NetworkPlan(c.Spec{
+		MachineCIDR: args.machineCIDR,
+		ServiceCIDR: args.serviceCIDR,
+		PodCIDR:     args.podCIDR,
+		HostPrefix:  args.hostPrefix,
+	})
+	if plan.MachineCIDR == nil && plan.ServiceCIDR == nil && plan.PodCIDR == nil && plan.HostPrefix == 0 {
+		return nil
+	}
+	machineCIDR, podCIDR, serviceCIDR, hostPrefix := GetDefaultClusterFlavors(connection, args.flavour)
+	if plan.MachineCIDR == nil {
+		plan.MachineCIDR = machineCIDR
+	}
+	if plan.ServiceCIDR == nil {
+		plan.ServiceCIDR = serviceCIDR
+	}
+	if plan.PodCIDR == nil {
+		plan.PodCIDR = podCIDR
+	}
+	if plan.HostPrefix == 0 {
+		plan.HostPrefix = hostPrefix
+	}
+	problems := plan.Validate()
+	if len(problems) > 0 {
+		return fmt.Errorf("%v, run 'ocm network plan' to check the cluster networks", problems[0])
+	}
+	return nil
+}
+
+// runPreflight checks the configuration of a GCP cluster before creating it, and returns an error if
+// any of the checks fails.
+func runPreflight(connection *sdk.Connection, clusterConfig c.Spec) error {
+	if clusterConfig.Provider != c.ProviderGCP {
+		return fmt.Errorf("Preflight checks are only supported for provider '%s'", c.ProviderGCP)
+	}
+
+	// Check the CIDRs that the cluster will use, even if they weren't given explicitly:
+	machineCIDR, podCIDR, serviceCIDR, hostPrefix := GetDefaultClusterFlavors(connection, clusterConfig.Flavour)
+	if clusterConfig.MachineCIDR.IP == nil && machineCIDR != nil {
+		clusterConfig.MachineCIDR = *machineCIDR
+	}
+	if clusterConfig.ServiceCIDR.IP == nil && serviceCIDR != nil {
+		clusterConfig.ServiceCIDR = *serviceCIDR
+	}
+	if clusterConfig.PodCIDR.IP == nil && podCIDR != nil {
+		clusterConfig.PodCIDR = *podCIDR
+	}
+	if clusterConfig.HostPrefix == 0 {
+		clusterConfig.HostPrefix = hostPrefix
+	}
+
+	// The details of the networks are read with the GCP API, using the service account key of
+	// the cluster if there is one, or the application default credentials otherwise:
+	ctx := context.Background()
+	var network gcp.NetworkClient
+	var err error
+	switch {
+	case clusterConfig.GcpAuthentication.Type == c.AuthenticationWif:
+		network, err = gcp.NewNetworkClient(ctx, nil)
+	case clusterConfig.CCS.Enabled:
+		var credentials []byte
+		credentials, err = json.Marshal(clusterConfig.CCS.GCP)
+		if err != nil {
+			return fmt.Errorf("Failed to encode GCP credentials: %v", err)
+		}
+		network, err = gcp.NewNetworkClient(ctx, credentials)
+	default:
+		err = fmt.Errorf("the cluster doesn't use Customer Cloud Subscription")
+	}
+
+	fmt.Println("Running preflight checks...")
+	results := provider.NewGCPPreflight(connection.ClustersMgmt().V1(), clusterConfig).
+		Network(network, err).
+		Run(ctx)
+	err = provider.PrintPreflightResults(os.Stdout, results)
+	if err != nil {
+		return err
+	}
+	if provider.Failed(results) {
+		return fmt.Errorf("Preflight checks failed, the cluster wasn't created")
+	}
+	return nil
+}
+
 func buildDefaultIngressSpec() (c.DefaultIngressSpec, error) {
 	defaultIngress := c.NewDefaultIngressSpec()
 	if args.defaultIngressRouteSelectors != "" {
-		routeSelectors, err := ingress.GetRouteSelector(args.defaultIngressRouteSelectors)
+		routeSelectors, err := ingress.ParseRouteSelectors(args.defaultIngressRouteSelectors)
 		if err != nil {
 			return defaultIngress, err
 		}
@@ -876,14 +984,27 @@ func buildDefaultIngressSpec() (c.DefaultIngressSpec, error) {
 	}
 
 	if args.defaultIngressExcludedNamespaces != "" {
-		defaultIngress.ExcludedNamespaces = ingress.GetExcludedNamespaces(args.defaultIngressExcludedNamespaces)
+		excludedNamespaces, err := ingress.ParseExcludedNamespaces(args.defaultIngressExcludedNamespaces)
+		if err != nil {
+			return defaultIngress, err
+		}
+		defaultIngress.ExcludedNamespaces = excludedNamespaces
 	}
 
 	if args.defaultIngressWildcardPolicy != "" {
+		if !utils.Contains(ingress.ValidWildcardPolicies, args.defaultIngressWildcardPolicy) {
+			return defaultIngress, fmt.Errorf("Wildcard policy '%s' isn't valid, options are %s",
+				args.defaultIngressWildcardPolicy, strings.Join(ingress.ValidWildcardPolicies, ", "))
+		}
 		defaultIngress.WildcardPolicy = args.defaultIngressWildcardPolicy
 	}
 
 	if args.defaultIngressNamespaceOwnershipPolicy != "" {
+		if !utils.Contains(ingress.ValidNamespaceOwnershipPolicies, args.defaultIngressNamespaceOwnershipPolicy) {
+			return defaultIngress, fmt.Errorf("Namespace ownership policy '%s' isn't valid, options are %s",
+				args.defaultIngressNamespaceOwnershipPolicy,
+				strings.Join(ingress.ValidNamespaceOwnershipPolicies, ", "))
+		}
 		defaultIngress.NamespaceOwnershipPolicy = args.defaultIngressNamespaceOwnershipPolicy
 	}
 	return defaultIngress, nil
@@ -14,6 +14,7 @@ limitations under the License.
 package create
 
 import (
+	"github.com/openshift-online/ocm-cli/cmd/ocm/create/addon"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/create/cluster"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/create/idp"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/create/ingress"
@@ -31,6 +32,7 @@ var Cmd = &cobra.Command{
 }
 
 func init() {
+	Cmd.AddCommand(addon.Cmd)
 	Cmd.AddCommand(cluster.Cmd)
 	Cmd.AddCommand(idp.Cmd)
 	Cmd.AddCommand(ingress.Cmd)
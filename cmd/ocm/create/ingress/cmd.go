@@ -18,15 +18,22 @@ import (
 	"strings"
 
 	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	i "github.com/openshift-online/ocm-cli/pkg/ingress"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/spf13/cobra"
 )
 
 var args struct {
-	clusterKey string
-	private    bool
-	labelMatch string
+	clusterKey                string
+	private                   bool
+	routeSelector             string
+	excludedNamespaces        string
+	lbType                    string
+	wildcardPolicy            string
+	namespaceOwnershipPolicy  string
+	clusterRoutesHostname     string
+	clusterRoutesTlsSecretRef string
 }
 
 var Cmd = &cobra.Command{
@@ -39,7 +46,9 @@ var Cmd = &cobra.Command{
   # Add a public ingress to a cluster
   ocm create ingress --cluster=mycluster
   # Add an ingress with route selector label match
-  ocm create ingress -c mycluster --label-match="foo=bar,bar=baz"`,
+  ocm create ingress -c mycluster --label-match="foo=bar,bar=baz"
+  # Add an ingress using a network load balancer that allows wildcard routes
+  ocm create ingress -c mycluster --lb-type=nlb --wildcard-policy=WildcardsAllowed`,
 	Args: cobra.NoArgs,
 	RunE: run,
 }
@@ -65,12 +74,63 @@ func init() {
 	)
 
 	flags.StringVar(
-		&args.labelMatch,
+		&args.routeSelector,
 		"label-match",
 		"",
-		"Label match for ingress. Format should be a comma-separated list of 'key=value'. "+
+		"Alias to 'route-selector' flag.",
+	)
+
+	flags.StringVar(
+		&args.routeSelector,
+		"route-selector",
+		"",
+		"Route selector for ingress. Format should be a comma-separated list of 'key=value'. "+
 			"If no label is specified, all routes will be exposed on both routers.",
 	)
+
+	flags.StringVar(
+		&args.excludedNamespaces,
+		"excluded-namespaces",
+		"",
+		"Excluded namespaces for ingress. Format should be a comma-separated list 'value1, value2...'. "+
+			"If no values are specified, all namespaces will be exposed.",
+	)
+
+	flags.StringVar(
+		&args.lbType,
+		"lb-type",
+		"",
+		fmt.Sprintf("Type of Load Balancer. Options are %s.", strings.Join(i.ValidLoadBalancerTypes, ",")),
+	)
+
+	flags.StringVar(
+		&args.wildcardPolicy,
+		"wildcard-policy",
+		"",
+		fmt.Sprintf("Wildcard Policy for ingress. Options are %s", strings.Join(i.ValidWildcardPolicies, ",")),
+	)
+
+	flags.StringVar(
+		&args.namespaceOwnershipPolicy,
+		"namespace-ownership-policy",
+		"",
+		fmt.Sprintf("Namespace Ownership Policy for ingress. Options are %s",
+			strings.Join(i.ValidNamespaceOwnershipPolicies, ",")),
+	)
+
+	flags.StringVar(
+		&args.clusterRoutesHostname,
+		"cluster-routes-hostname",
+		"",
+		"Components route hostname for oauth, console, downloads.",
+	)
+
+	flags.StringVar(
+		&args.clusterRoutesTlsSecretRef,
+		"cluster-routes-tls-secret-ref",
+		"",
+		"Components route TLS secret reference for oauth, console, downloads.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -86,15 +146,13 @@ func run(cmd *cobra.Command, argv []string) error {
 		)
 	}
 
-	routeSelectors := make(map[string]string)
-	if args.labelMatch != "" {
-		for _, labelMatch := range strings.Split(args.labelMatch, ",") {
-			if !strings.Contains(labelMatch, "=") {
-				return fmt.Errorf("Expected key=value format for label-match")
-			}
-			tokens := strings.Split(labelMatch, "=")
-			routeSelectors[strings.TrimSpace(tokens[0])] = strings.TrimSpace(tokens[1])
-		}
+	routeSelectors, err := i.ParseRouteSelectors(args.routeSelector)
+	if err != nil {
+		return err
+	}
+	excludedNamespaces, err := i.ParseExcludedNamespaces(args.excludedNamespaces)
+	if err != nil {
+		return err
 	}
 
 	// Create the client for the OCM API:
@@ -127,10 +185,33 @@ func run(cmd *cobra.Command, argv []string) error {
 	if len(routeSelectors) > 0 {
 		ingressBuilder = ingressBuilder.RouteSelectors(routeSelectors)
 	}
+	if len(excludedNamespaces) > 0 {
+		ingressBuilder = ingressBuilder.ExcludedNamespaces(excludedNamespaces...)
+	}
+	if args.lbType != "" {
+		ingressBuilder = ingressBuilder.LoadBalancerType(cmv1.LoadBalancerFlavor(args.lbType))
+	}
+	if args.wildcardPolicy != "" {
+		ingressBuilder = ingressBuilder.RouteWildcardPolicy(cmv1.WildcardPolicy(args.wildcardPolicy))
+	}
+	if args.namespaceOwnershipPolicy != "" {
+		ingressBuilder = ingressBuilder.RouteNamespaceOwnershipPolicy(
+			cmv1.NamespaceOwnershipPolicy(args.namespaceOwnershipPolicy))
+	}
+	if args.clusterRoutesHostname != "" {
+		ingressBuilder = ingressBuilder.ClusterRoutesHostname(args.clusterRoutesHostname)
+	}
+	if args.clusterRoutesTlsSecretRef != "" {
+		ingressBuilder = ingressBuilder.ClusterRoutesTlsSecretRef(args.clusterRoutesTlsSecretRef)
+	}
 	ingress, err := ingressBuilder.Build()
 	if err != nil {
 		return fmt.Errorf("Failed to create ingress for cluster '%s': %v", clusterKey, err)
 	}
+	err = i.Validate(cluster, nil, ingress)
+	if err != nil {
+		return err
+	}
 
 	_, err = clusterCollection.Cluster(cluster.ID()).
 		Ingresses().
@@ -22,28 +22,44 @@ import (
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	"github.com/openshift-online/ocm-cli/pkg/provider"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift/rosa/pkg/interactive"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var args struct {
 	clusterKey                 string
+	interactive                bool
 	instanceType               string
 	replicas                   int
 	autoscaling                c.Autoscaling
 	labels                     string
 	taints                     string
 	additionalSecurityGroupIds []string
+	spotInstances              bool
+	spotMaxPrice               string
+	rootDiskSize               int
+	availabilityZone           string
+	subnet                     string
+	secureBoot                 bool
 }
 
 const (
 	additionalSecurityGroupIdsFlag = "additional-security-group-ids"
+	secureBootFlag                 = "secure-boot-for-shielded-vms"
+
+	// allOption is the interactive answer used to not restrict the machine pool to a single
+	// availability zone or subnet.
+	allOption = "all"
 )
 
 var Cmd = &cobra.Command{
 	Use:     "machinepool --cluster={NAME|ID|EXTERNAL_ID} --instance-type=TYPE --replicas=N [flags] MACHINE_POOL_ID",
 	Aliases: []string{"machinepools", "machine-pool", "machine-pools"},
 	Short:   "Add machine pool to cluster",
-	Long:    "Add a machine pool to the cluster.",
+	Long: "Add a machine pool to the cluster. Spot instances, availability zones, subnets and " +
+		"additional security groups are only supported for AWS clusters. The options are " +
+		"validated against the cloud provider and network settings of the cluster.",
 	Example: `  # Add a machine pool mp-1 with 3 replicas and m5.xlarge instance type to a cluster
   ocm create machinepool --cluster mycluster --instance-type m5.xlarge --replicas 3 mp-1
   # Add a machine pool mp-1 with autoscaling enabled and 3 to 6 replicas of m5.xlarge to a cluster
@@ -52,7 +68,12 @@ var Cmd = &cobra.Command{
   # Add a machine pool mp-1 with labels and m5.xlarge instance type to a cluster
   ocm create machinepool --cluster mycluster --instance-type m5.xlarge --replicas 3 --labels "foo=bar,bar=baz" mp-1
   # Add a machine pool mp-1 with taints and m5.xlarge instance type to a cluster
-  ocm create machinepool --cluster mycluster --instance-type m5.xlarge --replicas 3 --taints "foo=bar:NoSchedule" mp-1`,
+  ocm create machinepool --cluster mycluster --instance-type m5.xlarge --replicas 3 --taints "foo=bar:NoSchedule" mp-1
+  # Add a machine pool mp-1 of spot instances with a 500 GiB root disk in a single zone
+  ocm create machinepool --cluster mycluster --instance-type m5.xlarge --replicas 2 \
+  --spot-instances --spot-max-price 0.1 --root-disk-size 500 --availability-zone us-east-1a mp-1
+  # Add a machine pool answering questions for the options that aren't given
+  ocm create machinepool --cluster mycluster --interactive`,
 	RunE: run,
 }
 
@@ -69,15 +90,15 @@ func init() {
 	//nolint:gosec
 	Cmd.MarkFlagRequired("cluster")
 
+	arguments.AddInteractiveFlag(flags, &args.interactive)
+
 	flags.StringVar(
 		&args.instanceType,
 		"instance-type",
 		"",
-		"Instance type that should be used.",
+		"Instance type that should be used (required).",
 	)
-
-	//nolint:gosec
-	Cmd.MarkFlagRequired("instance-type")
+	arguments.SetQuestion(flags, "instance-type", "Instance type:")
 
 	flags.IntVar(
 		&args.replicas,
@@ -85,6 +106,7 @@ func init() {
 		0,
 		"Count of machines for this machine pool.",
 	)
+	arguments.SetQuestion(flags, "replicas", "Replicas:")
 
 	arguments.AddAutoscalingFlags(flags, &args.autoscaling)
 
@@ -108,11 +130,62 @@ func init() {
 		additionalSecurityGroupIdsFlag,
 		nil,
 		"The additional Security Group IDs to be added to the machine pool. "+
-			"Format should be a comma-separated list.",
+			"Format should be a comma-separated list. Only for AWS clusters that use an existing VPC.",
+	)
+
+	flags.BoolVar(
+		&args.spotInstances,
+		"spot-instances",
+		false,
+		"Use spot instances for the machine pool. Only for AWS clusters that use the customer "+
+			"cloud subscription.",
+	)
+	arguments.SetQuestion(flags, "spot-instances", "Use spot instances:")
+
+	flags.StringVar(
+		&args.spotMaxPrice,
+		"spot-max-price",
+		c.SpotOnDemand,
+		"Maximum hourly price for a spot instance. The default is to use the on-demand price.",
+	)
+	arguments.SetQuestion(flags, "spot-max-price", "Spot instance maximum price:")
+
+	flags.IntVar(
+		&args.rootDiskSize,
+		"root-disk-size",
+		0,
+		fmt.Sprintf("Size of the root disk of the nodes in GiB, between %d and %d. "+
+			"The default is the size of the cloud provider.", c.MinRootDiskSize, c.MaxRootDiskSize),
+	)
+	arguments.SetQuestion(flags, "root-disk-size", "Root disk size in GiB (0 for the default):")
+
+	flags.StringVar(
+		&args.availabilityZone,
+		"availability-zone",
+		"",
+		"Create the machine pool in this availability zone of a multi zone AWS cluster, "+
+			"instead of spreading it across all the zones.",
+	)
+
+	flags.StringVar(
+		&args.subnet,
+		"subnet",
+		"",
+		"Create the machine pool in this subnet of an AWS cluster that uses an existing VPC, "+
+			"instead of spreading it across all the subnets.",
+	)
+
+	flags.BoolVar(
+		&args.secureBoot,
+		secureBootFlag,
+		false,
+		"Secure boot for the shielded VMs of a GCP cluster. Machine pools use the setting of "+
+			"the cluster, so it must match it.",
 	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	flags := cmd.Flags()
 
 	// Check that the cluster key (name, identifier or external identifier) given by the user
 	// is reasonably safe so that there is no risk of SQL injection:
@@ -125,10 +198,74 @@ func run(cmd *cobra.Command, argv []string) error {
 		)
 	}
 
-	if len(argv) < 1 || argv[0] == "" {
+	var machinePoolID string
+	if len(argv) > 0 {
+		machinePoolID = argv[0]
+	}
+	if machinePoolID == "" && args.interactive {
+		var err error
+		machinePoolID, err = interactive.GetString(interactive.Input{
+			Question: "Machine pool ID",
+			Help:     "Identifier of the new machine pool.",
+			Required: true,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if machinePoolID == "" {
 		return fmt.Errorf("Missing machine pool ID")
 	}
-	machinePoolID := argv[0]
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Get the client for the cluster management api
+	clusterCollection := connection.ClustersMgmt().V1().Clusters()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	if cluster.State() != cmv1.ClusterStateReady {
+		return fmt.Errorf("Cluster '%s' is not yet ready", clusterKey)
+	}
+
+	machineTypeList, err := provider.GetMachineTypeOptions(connection.ClustersMgmt().V1(),
+		cluster.CloudProvider().ID(),
+		cluster.CCS().Enabled())
+	if err != nil {
+		return err
+	}
+	err = arguments.PromptOrCheckOneOf(flags, "instance-type", machineTypeList)
+	if err != nil {
+		return err
+	}
+	if args.instanceType == "" {
+		return fmt.Errorf("--instance-type is required")
+	}
+
+	err = promptSize(flags)
+	if err != nil {
+		return err
+	}
+	err = arguments.PromptString(flags, "labels")
+	if err != nil {
+		return err
+	}
+	err = arguments.PromptString(flags, "taints")
+	if err != nil {
+		return err
+	}
+	err = promptProviderOptions(flags, cluster)
+	if err != nil {
+		return err
+	}
 
 	labels := make(map[string]string)
 	if args.labels != "" {
@@ -153,9 +290,9 @@ func run(cmd *cobra.Command, argv []string) error {
 		}
 	}
 
-	isMinReplicasSet := cmd.Flags().Changed("min-replicas")
-	isMaxReplicasSet := cmd.Flags().Changed("max-replicas")
-	isReplicasSet := cmd.Flags().Changed("replicas")
+	isMinReplicasSet := flags.Changed("min-replicas")
+	isMaxReplicasSet := flags.Changed("max-replicas")
+	isReplicasSet := flags.Changed("replicas")
 
 	if args.autoscaling.Enabled {
 		if isReplicasSet {
@@ -175,32 +312,18 @@ func run(cmd *cobra.Command, argv []string) error {
 		}
 	}
 
-	// Create the client for the OCM API:
-	connection, err := ocm.NewConnection().Build()
-	if err != nil {
-		return fmt.Errorf("Failed to create OCM connection: %v", err)
-	}
-	defer connection.Close()
-
-	// Get the client for the cluster management api
-	clusterCollection := connection.ClustersMgmt().V1().Clusters()
-
-	cluster, err := c.GetCluster(connection, clusterKey)
-	if err != nil {
-		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	options := c.MachinePoolOptions{
+		SpotInstances:    args.spotInstances,
+		SpotMaxPrice:     args.spotMaxPrice,
+		RootDiskSize:     args.rootDiskSize,
+		AvailabilityZone: args.availabilityZone,
+		Subnet:           args.subnet,
+		SecurityGroupIDs: args.additionalSecurityGroupIds,
 	}
-
-	if cluster.State() != cmv1.ClusterStateReady {
-		return fmt.Errorf("Cluster '%s' is not yet ready", clusterKey)
+	if flags.Changed(secureBootFlag) {
+		options.SecureBoot = &args.secureBoot
 	}
-
-	machineTypeList, err := provider.GetMachineTypeOptions(connection.ClustersMgmt().V1(),
-		cluster.CloudProvider().ID(),
-		cluster.CCS().Enabled())
-	if err != nil {
-		return err
-	}
-	err = arguments.CheckOneOf(cmd.Flags(), "instance-type", machineTypeList)
+	err = options.Validate(cluster)
 	if err != nil {
 		return err
 	}
@@ -211,13 +334,9 @@ func run(cmd *cobra.Command, argv []string) error {
 		Labels(labels).
 		Taints(taintBuilders...)
 
-	if len(args.additionalSecurityGroupIds) != 0 {
-		for i, sg := range args.additionalSecurityGroupIds {
-			args.additionalSecurityGroupIds[i] = strings.TrimSpace(sg)
-		}
-		mpBuilder.AWS(
-			cmv1.NewAWSMachinePool().
-				AdditionalSecurityGroupIds(args.additionalSecurityGroupIds...))
+	err = options.Apply(cluster, mpBuilder)
+	if err != nil {
+		return err
 	}
 
 	if args.autoscaling.Enabled {
@@ -244,3 +363,119 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 	return nil
 }
+
+// promptSize asks for the number of replicas, or for the autoscaling limits if autoscaling is
+// enabled. Does nothing in non-interactive mode.
+func promptSize(fs *pflag.FlagSet) error {
+	err := arguments.PromptBool(fs, "enable-autoscaling")
+	if err != nil {
+		return err
+	}
+	if !args.autoscaling.Enabled {
+		return arguments.PromptInt(fs, "replicas", validateReplicas)
+	}
+	err = arguments.PromptInt(fs, "min-replicas", validateReplicas)
+	if err != nil {
+		return err
+	}
+	if args.interactive && args.autoscaling.MaxReplicas == 0 {
+		args.autoscaling.MaxReplicas = args.autoscaling.MinReplicas
+	}
+	return arguments.PromptInt(fs, "max-replicas", validateReplicas)
+}
+
+func validateReplicas() error {
+	if args.replicas < 0 || args.autoscaling.MinReplicas < 0 {
+		return fmt.Errorf("Number of replicas can't be negative")
+	}
+	if args.autoscaling.Enabled && args.autoscaling.MaxReplicas != 0 &&
+		args.autoscaling.MaxReplicas < args.autoscaling.MinReplicas {
+		return fmt.Errorf("max-replicas must be greater or equal to min-replicas")
+	}
+	return nil
+}
+
+func validateRootDiskSize() error {
+	if args.rootDiskSize != 0 &&
+		(args.rootDiskSize < c.MinRootDiskSize || args.rootDiskSize > c.MaxRootDiskSize) {
+		return fmt.Errorf("Root disk size must be between %d and %d GiB",
+			c.MinRootDiskSize, c.MaxRootDiskSize)
+	}
+	return nil
+}
+
+// promptProviderOptions asks for the options that are supported by the cloud provider and the
+// network settings of the cluster. Does nothing in non-interactive mode.
+func promptProviderOptions(fs *pflag.FlagSet, cluster *cmv1.Cluster) error {
+	if !args.interactive {
+		return nil
+	}
+	providerID := cluster.CloudProvider().ID()
+	if providerID != c.ProviderAWS && providerID != c.ProviderGCP {
+		return nil
+	}
+	err := arguments.PromptInt(fs, "root-disk-size", validateRootDiskSize)
+	if err != nil {
+		return err
+	}
+	if providerID != c.ProviderAWS {
+		return nil
+	}
+
+	if cluster.CCS().Enabled() {
+		err = arguments.PromptBool(fs, "spot-instances")
+		if err != nil {
+			return err
+		}
+		if args.spotInstances {
+			err = arguments.PromptString(fs, "spot-max-price")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	subnets := cluster.AWS().SubnetIDs()
+	switch {
+	case len(subnets) > 0 && !fs.Changed("subnet") && !fs.Changed("availability-zone"):
+		answer, err := interactive.GetOption(interactive.Input{
+			Question: "Subnet",
+			Help:     fs.Lookup("subnet").Usage,
+			Options:  append([]string{allOption}, subnets...),
+			Default:  allOption,
+		})
+		if err != nil {
+			return err
+		}
+		if answer != allOption {
+			args.subnet = answer
+		}
+	case len(subnets) == 0 && cluster.MultiAZ() && !fs.Changed("availability-zone"):
+		answer, err := interactive.GetOption(interactive.Input{
+			Question: "Availability zone",
+			Help:     fs.Lookup("availability-zone").Usage,
+			Options:  append([]string{allOption}, cluster.Nodes().AvailabilityZones()...),
+			Default:  allOption,
+		})
+		if err != nil {
+			return err
+		}
+		if answer != allOption {
+			args.availabilityZone = answer
+		}
+	}
+
+	if len(subnets) > 0 && !fs.Changed(additionalSecurityGroupIdsFlag) {
+		answer, err := interactive.GetString(interactive.Input{
+			Question: "Additional security group IDs",
+			Help:     fs.Lookup(additionalSecurityGroupIdsFlag).Usage,
+		})
+		if err != nil {
+			return err
+		}
+		if answer != "" {
+			args.additionalSecurityGroupIds = strings.Split(answer, ",")
+		}
+	}
+	return nil
+}
@@ -232,18 +232,9 @@ func run(cmd *cobra.Command, argv []string) error {
 			Version(version)
 	}
 
-	upgradePolicy, err := upgradeBuilder.Build()
+	_, err = c.AddUpgradePolicy(clusterCollection, cluster.ID(), upgradeBuilder)
 	if err != nil {
-		return fmt.Errorf("Failed to set an upgrade policy for cluster '%s': %v", clusterKey, err)
-	}
-
-	_, err = clusterCollection.Cluster(cluster.ID()).
-		UpgradePolicies().
-		Add().
-		Body(upgradePolicy).
-		Send()
-	if err != nil {
-		return fmt.Errorf("Failed to create upgrade policy for cluster: %v", err)
+		return err
 	}
 	fmt.Println("upgrade policy successfully created")
 
@@ -0,0 +1,116 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/addon"
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	clusterKey string
+}
+
+var Cmd = &cobra.Command{
+	Use:     "addon --cluster={NAME|ID|EXTERNAL_ID} [flags] ADDON_ID",
+	Aliases: []string{"addons", "add-on", "add-ons"},
+	Short:   "Uninstall an add-on from a cluster",
+	Long: "Uninstall an add-on from a cluster. Without the --cluster flag the add-on itself is " +
+		"deleted, like 'ocm delete /api/addons_mgmt/v1/addons/ADDON_ID'.",
+	Example: `  # Uninstall the add-on 'my-addon' from a cluster named 'mycluster'
+  ocm delete addon --cluster=mycluster my-addon`,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+
+	flags.StringVarP(
+		&args.clusterKey,
+		"cluster",
+		"c",
+		"",
+		"Name or ID or external_id of the cluster to uninstall the add-on from.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+
+	// Check command line arguments:
+	if len(argv) != 1 {
+		return fmt.Errorf(
+			"Expected exactly one command line parameters containing the ID " +
+				"of the add-on.",
+		)
+	}
+
+	addOnID := argv[0]
+
+	// Without a cluster this is the 'addon' resource alias of the parent command:
+	if args.clusterKey == "" {
+		parent := cmd.Parent()
+		return parent.RunE(parent, []string{"addon", addOnID})
+	}
+
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := args.clusterKey
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	installation, err := addon.GetAddOnInstallation(connection, cluster.ID(), addOnID)
+	if err != nil {
+		return err
+	}
+	if installation == nil {
+		return fmt.Errorf("Add-on '%s' isn't installed on cluster '%s'", addOnID, clusterKey)
+	}
+
+	_, err = connection.AddonsMgmt().V1().Clusters().
+		Cluster(cluster.ID()).
+		Addons().
+		Addon(addOnID).
+		Delete().
+		Send()
+	if err != nil {
+		return fmt.Errorf("Failed to uninstall add-on '%s' from cluster '%s': %v", addOnID, clusterKey, err)
+	}
+
+	fmt.Printf("Uninstalling add-on '%s' from cluster '%s'\n", addOnID, clusterKey)
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/openshift-online/ocm-cli/cmd/ocm/delete/addon"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/delete/idp"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/delete/ingress"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/delete/machinepool"
@@ -86,6 +87,7 @@ func init() {
 	fs := Cmd.Flags()
 	arguments.AddParameterFlag(fs, &args.parameter)
 	arguments.AddHeaderFlag(fs, &args.header)
+	Cmd.AddCommand(addon.Cmd)
 	Cmd.AddCommand(idp.Cmd)
 	Cmd.AddCommand(ingress.Cmd)
 	Cmd.AddCommand(machinepool.Cmd)
@@ -0,0 +1,126 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/addon"
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	asv1 "github.com/openshift-online/ocm-sdk-go/addonsmgmt/v1"
+)
+
+var args struct {
+	clusterKey string
+	json       bool
+}
+
+var Cmd = &cobra.Command{
+	Use:     "addon [flags] ADDON_ID",
+	Aliases: []string{"addons", "add-on", "add-ons"},
+	Short:   "Show details of an add-on",
+	Long: "Show the parameters and requirements of an add-on and, when a cluster is given, " +
+		"the state and parameter values of its installation on that cluster.",
+	Example: `  # Show the parameters of the add-on 'my-addon'
+  ocm describe addon my-addon
+
+  # Show the installation state of the add-on 'my-addon' on a cluster named 'mycluster'
+  ocm describe addon --cluster=mycluster my-addon`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+
+	flags.StringVarP(
+		&args.clusterKey,
+		"cluster",
+		"c",
+		"",
+		"Name or ID or external_id of the cluster to show the add-on installation of.",
+	)
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the entire JSON structure",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	addOnID := argv[0]
+
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := args.clusterKey
+	if clusterKey != "" && !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	addOn, err := addon.GetAddOn(connection, addOnID)
+	if err != nil {
+		return err
+	}
+
+	var installation *asv1.AddonInstallation
+	if clusterKey != "" {
+		cluster, err := c.GetCluster(connection, clusterKey)
+		if err != nil {
+			return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+		}
+		installation, err = addon.GetAddOnInstallation(connection, cluster.ID(), addOnID)
+		if err != nil {
+			return err
+		}
+		if installation == nil {
+			return fmt.Errorf("Add-on '%s' isn't installed on cluster '%s'", addOnID, clusterKey)
+		}
+	}
+
+	if args.json {
+		body := &bytes.Buffer{}
+		if installation != nil {
+			err = asv1.MarshalAddonInstallation(installation, body)
+		} else {
+			err = asv1.MarshalAddon(addOn, body)
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to marshal add-on '%s': %v", addOnID, err)
+		}
+		return dump.Pretty(os.Stdout, body.Bytes())
+	}
+
+	return addon.PrintAddOnDescription(os.Stdout, addOn, installation)
+}
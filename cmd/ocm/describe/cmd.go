@@ -14,8 +14,10 @@ limitations under the License.
 package describe
 
 import (
+	"github.com/openshift-online/ocm-cli/cmd/ocm/describe/addon"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/describe/cluster"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/describe/ingress"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/describe/queue"
 	"github.com/spf13/cobra"
 )
 
@@ -26,6 +28,8 @@ var Cmd = &cobra.Command{
 }
 
 func init() {
+	Cmd.AddCommand(addon.Cmd)
 	Cmd.AddCommand(cluster.Cmd)
 	Cmd.AddCommand(ingress.Cmd)
+	Cmd.AddCommand(queue.Cmd)
 }
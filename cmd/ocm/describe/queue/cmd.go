@@ -0,0 +1,101 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/job"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	jqv1 "github.com/openshift-online/ocm-sdk-go/jobqueue/v1"
+)
+
+var args struct {
+	json bool
+	jobs bool
+}
+
+var Cmd = &cobra.Command{
+	Use:     "queue [flags] QUEUE_NAME",
+	Aliases: []string{"queues", "job-queue"},
+	Short:   "Show details of a job queue",
+	Long: "Show the settings of a job queue and, with --jobs, a summary of the jobs it " +
+		"contains.",
+	Example: `  # Show the settings of the queue 'my-queue'
+  ocm describe queue my-queue
+
+  # Show also how many jobs are in progress, abandoned or retried
+  ocm describe queue my-queue --jobs`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the entire JSON structure",
+	)
+	flags.BoolVar(
+		&args.jobs,
+		"jobs",
+		false,
+		"Show a summary of the jobs of the queue.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	queue, err := job.GetQueue(connection, argv[0])
+	if err != nil {
+		return err
+	}
+
+	if args.json {
+		body := &bytes.Buffer{}
+		err = jqv1.MarshalQueue(queue, body)
+		if err != nil {
+			return fmt.Errorf("Failed to marshal job queue '%s': %v", argv[0], err)
+		}
+		return dump.Pretty(os.Stdout, body.Bytes())
+	}
+
+	var jobs []*jqv1.Job
+	if args.jobs {
+		jobs, err = job.ListJobs(connection, argv[0])
+		if err != nil {
+			return err
+		}
+		if jobs == nil {
+			jobs = []*jqv1.Job{}
+		}
+	}
+	return job.PrintQueueDescription(os.Stdout, queue, jobs)
+}
@@ -0,0 +1,202 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/addon"
+	"github.com/openshift-online/ocm-cli/pkg/arguments"
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	asv1 "github.com/openshift-online/ocm-sdk-go/addonsmgmt/v1"
+)
+
+var args struct {
+	clusterKey  string
+	parameters  []string
+	valuesFile  string
+	version     string
+	interactive bool
+}
+
+var Cmd = &cobra.Command{
+	Use:     "addon --cluster={NAME|ID|EXTERNAL_ID} [flags] ADDON_ID",
+	Aliases: []string{"addons", "add-on", "add-ons"},
+	Short:   "Edit the parameters or version of an installed add-on",
+	Long: "Change the values of the editable parameters of an add-on installed on a cluster, " +
+		"or upgrade it to another version. Parameters that aren't given keep their current values.",
+	Example: `  # Change the notification email of an add-on installed on a cluster named 'mycluster'
+  ocm edit addon --cluster=mycluster --param notification-email=ops@example.com my-addon
+
+  # Upgrade an add-on to version 1.2.0
+  ocm edit addon --cluster=mycluster --version=1.2.0 my-addon
+
+  # Edit the parameters of an add-on interactively
+  ocm edit addon --cluster=mycluster --interactive my-addon`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+
+	flags.StringVarP(
+		&args.clusterKey,
+		"cluster",
+		"c",
+		"",
+		"Name or ID or external_id of the cluster the add-on is installed on (required).",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("cluster")
+
+	flags.StringArrayVar(
+		&args.parameters,
+		"param",
+		nil,
+		"New value of an add-on parameter in 'name=value' format. Can be used multiple times. "+
+			"Takes precedence over the values file.",
+	)
+	flags.StringVar(
+		&args.valuesFile,
+		"values",
+		"",
+		"YAML or JSON file containing a map of add-on parameter names to new values.",
+	)
+	flags.StringVar(
+		&args.version,
+		"version",
+		"",
+		"Version of the add-on to upgrade to.",
+	)
+	arguments.AddInteractiveFlag(flags, &args.interactive)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	addOnID := argv[0]
+
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := args.clusterKey
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	values, err := addon.CollectValues(args.valuesFile, args.parameters)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 && args.version == "" && !args.interactive {
+		return fmt.Errorf("Nothing to change, use --param, --values, --version or --interactive")
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	addOn, err := addon.GetAddOn(connection, addOnID)
+	if err != nil {
+		return err
+	}
+	installation, err := addon.GetAddOnInstallation(connection, cluster.ID(), addOnID)
+	if err != nil {
+		return err
+	}
+	if installation == nil {
+		return fmt.Errorf("Add-on '%s' isn't installed on cluster '%s'", addOnID, clusterKey)
+	}
+
+	// Validate against the parameters of the version that will be installed after the change:
+	version := addOn.Version()
+	versionID := args.version
+	if versionID == "" {
+		versionID = installation.AddonVersion().ID()
+	}
+	if versionID != "" && versionID != version.ID() {
+		version, err = addon.GetAddOnVersion(connection, addOnID, versionID)
+		if err != nil {
+			return err
+		}
+	}
+	parameters := addon.GetVersionParameters(addOn, version)
+	existing := addon.InstallationValues(installation)
+	if args.interactive {
+		err = addon.PromptValues(parameters, values, existing)
+		if err != nil {
+			return err
+		}
+	}
+	err = addon.ValidateValues(parameters, values, existing)
+	if err != nil {
+		return err
+	}
+
+	changed := args.version != "" && args.version != installation.AddonVersion().ID()
+	merged := map[string]string{}
+	for name, value := range existing {
+		merged[name] = value
+	}
+	for name, value := range values {
+		if existing[name] != value {
+			changed = true
+		}
+		merged[name] = value
+	}
+	if !changed {
+		fmt.Printf("No changes to add-on '%s' on cluster '%s'\n", addOnID, clusterKey)
+		return nil
+	}
+
+	builder := asv1.NewAddonInstallation().
+		Parameters(addon.BuildParameters(merged))
+	if args.version != "" {
+		builder.AddonVersion(asv1.NewAddonVersion().ID(args.version))
+	}
+	body, err := builder.Build()
+	if err != nil {
+		return fmt.Errorf("Failed to update add-on installation for cluster '%s': %v", clusterKey, err)
+	}
+
+	_, err = connection.AddonsMgmt().V1().Clusters().
+		Cluster(cluster.ID()).
+		Addons().
+		Addon(addOnID).
+		Update().
+		Body(body).
+		Send()
+	if err != nil {
+		return fmt.Errorf("Failed to update add-on '%s' on cluster '%s': %v", addOnID, clusterKey, err)
+	}
+
+	fmt.Printf("Updated add-on '%s' on cluster '%s'\n", addOnID, clusterKey)
+	return nil
+}
@@ -17,7 +17,6 @@ import (
 	"fmt"
 	"os"
 	"reflect"
-	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -25,7 +24,6 @@ import (
 	c "github.com/openshift-online/ocm-cli/pkg/cluster"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	"github.com/openshift-online/ocm-cli/pkg/utils"
-	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 )
 
 var args struct {
@@ -129,11 +127,6 @@ func init() {
 	)
 }
 
-func isGCPNetworkEmpty(network *cmv1.GCPNetwork) bool {
-	return network != nil && network.VPCName() == "" &&
-		network.ControlPlaneSubnet() == "" && network.ComputeSubnet() == ""
-}
-
 func wasClusterWideProxyReceived(httpProxy, httpsProxy, noProxy, additionalTrustBundleFile *string) bool {
 	return ((httpProxy != nil && *httpProxy != "") || (httpsProxy != nil && *httpsProxy != "") ||
 		(noProxy != nil && *noProxy != "") || (additionalTrustBundleFile != nil && *additionalTrustBundleFile != ""))
@@ -193,43 +186,24 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	var httpProxy *string
 	if cmd.Flags().Changed("http-proxy") {
-		if *args.clusterWideProxy.HTTPProxy != "" {
-			err := utils.ValidateHTTPProxy(*args.clusterWideProxy.HTTPProxy)
-			if err != nil {
-				return err
-			}
-		}
 		httpProxy = args.clusterWideProxy.HTTPProxy
 	}
 
 	var httpsProxy *string
 	if cmd.Flags().Changed("https-proxy") {
-		if *args.clusterWideProxy.HTTPSProxy != "" {
-			err := utils.IsURL(*args.clusterWideProxy.HTTPSProxy)
-			if err != nil {
-				return fmt.Errorf("Invalid 'proxy.https_proxy' attribute '%s'", *args.clusterWideProxy.HTTPSProxy)
-			}
-		}
 		httpsProxy = args.clusterWideProxy.HTTPSProxy
 	}
 
 	var noProxy *string
 	if cmd.Flags().Changed("no-proxy") {
-		if *args.clusterWideProxy.NoProxy != "" {
-			noProxyValues := strings.Split(*args.clusterWideProxy.NoProxy, ",")
-			err := utils.MatchNoPorxyRE(noProxyValues)
-			if err != nil {
-				return err
-			}
-
-			duplicate, found := utils.HasDuplicates(noProxyValues)
-			if found {
-				return fmt.Errorf("no-proxy values must be unique, duplicate key '%s' found", duplicate)
-			}
-		}
 		noProxy = args.clusterWideProxy.NoProxy
 	}
 
+	err = c.ValidateProxy(httpProxy, httpsProxy, noProxy)
+	if err != nil {
+		return err
+	}
+
 	var enableDeleteProtection bool
 	if cmd.Flags().Changed("enable-delete-protection") {
 		enableDeleteProtection = args.enableDeleteProtection
@@ -252,7 +226,7 @@ func run(cmd *cobra.Command, argv []string) error {
 		additionalTrustBundleFile = &additionalTrustBundleFileValue
 	}
 
-	if len(cluster.AWS().SubnetIDs()) == 0 && isGCPNetworkEmpty(cluster.GCPNetwork()) &&
+	if !c.SupportsClusterWideProxy(cluster) &&
 		wasClusterWideProxyReceived(httpProxy, httpsProxy, noProxy, additionalTrustBundleFile) {
 		return fmt.Errorf("Cluster-wide proxy is not supported on clusters using the default VPC")
 	}
@@ -14,9 +14,12 @@ limitations under the License.
 package edit
 
 import (
+	"github.com/openshift-online/ocm-cli/cmd/ocm/edit/addon"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/edit/cluster"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/edit/idp"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/edit/ingress"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/edit/machinepool"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/edit/user"
 	"github.com/spf13/cobra"
 )
 
@@ -28,7 +31,10 @@ var Cmd = &cobra.Command{
 }
 
 func init() {
+	Cmd.AddCommand(addon.Cmd)
+	Cmd.AddCommand(idp.Cmd)
 	Cmd.AddCommand(ingress.Cmd)
 	Cmd.AddCommand(cluster.Cmd)
 	Cmd.AddCommand(machinepool.Cmd)
+	Cmd.AddCommand(user.Cmd)
 }
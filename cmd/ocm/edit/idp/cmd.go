@@ -0,0 +1,524 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package idp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var args struct {
+	clusterKey  string
+	interactive bool
+
+	clientID      string
+	clientSecret  string
+	mappingMethod string
+
+	// GitHub
+	githubHostname      string
+	githubOrganizations string
+	githubTeams         string
+
+	// Google
+	googleHostedDomain string
+
+	// LDAP
+	ldapURL          string
+	ldapBindDN       string
+	ldapBindPassword string
+	ldapIDs          string
+	ldapUsernames    string
+	ldapDisplayNames string
+	ldapEmails       string
+
+	// OpenID
+	openidIssuerURL   string
+	openidEmail       string
+	openidName        string
+	openidUsername    string
+	openidExtraScopes string
+
+	// HTPasswd
+	htpasswdFile        string
+	htpasswdAddUsers    []string
+	htpasswdRemoveUsers []string
+	htpasswdRotateUsers []string
+	htpasswdPassword    string
+}
+
+var Cmd = &cobra.Command{
+	Use:     "idp --cluster={NAME|ID|EXTERNAL_ID} [flags] IDP_NAME",
+	Aliases: []string{"idps"},
+	Short:   "Edit cluster IDPs",
+	Long: "Edit an identity provider of a cluster without deleting it, so that users stay logged " +
+		"in. Only the given settings are changed. When no setting is given, the current settings " +
+		"are prompted for interactively. For htpasswd identity providers, individual users can " +
+		"be added, removed or have their passwords rotated.",
+	Example: `  # Rotate the client secret of a GitHub identity provider named github-1
+  ocm edit idp github-1 --cluster=mycluster --client-secret=...
+
+  # Change the LDAP bind credentials
+  ocm edit idp ldap-1 --cluster=mycluster --bind-dn=cn=reader --bind-password=...
+
+  # Edit an identity provider following interactive prompts
+  ocm edit idp openid-1 --cluster=mycluster --interactive
+
+  # Add and rotate htpasswd users using the hashed passwords of a local htpasswd file
+  ocm edit idp htpasswd-1 --cluster=mycluster --from-file=users.htpasswd --add-user=alice \
+    --rotate-user=bob
+
+  # Remove an htpasswd user
+  ocm edit idp htpasswd-1 --cluster=mycluster --remove-user=carol`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.SortFlags = false
+
+	flags.StringVarP(
+		&args.clusterKey,
+		"cluster",
+		"c",
+		"",
+		"Name or ID or external_id of the cluster the IdP belongs to (required).",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("cluster")
+
+	flags.BoolVarP(
+		&args.interactive,
+		"interactive",
+		"i",
+		false,
+		"Prompt for the settings that weren't given, using the current values as defaults.",
+	)
+
+	flags.StringVar(
+		&args.mappingMethod,
+		"mapping-method",
+		"",
+		"Specifies how new identities are mapped to users when they log in",
+	)
+	flags.StringVar(
+		&args.clientID,
+		"client-id",
+		"",
+		"Client ID from the registered application.",
+	)
+	flags.StringVar(
+		&args.clientSecret,
+		"client-secret",
+		"",
+		"Client Secret from the registered application.\n",
+	)
+
+	// GitHub
+	flags.StringVar(
+		&args.githubHostname,
+		"hostname",
+		"",
+		"GitHub: Optional domain to use with a hosted instance of GitHub Enterprise.",
+	)
+	flags.StringVar(
+		&args.githubOrganizations,
+		"organizations",
+		"",
+		"GitHub: Only users that are members of at least one of the listed organizations will be allowed to log in.",
+	)
+	flags.StringVar(
+		&args.githubTeams,
+		"teams",
+		"",
+		"GitHub: Only users that are members of at least one of the listed teams will be allowed to log in. "+
+			"The format is <org>/<team>.\n",
+	)
+
+	// Google
+	flags.StringVar(
+		&args.googleHostedDomain,
+		"hosted-domain",
+		"",
+		"Google: Restrict users to a Google Apps domain. Example: http://redhat.com (scheme required)\n",
+	)
+
+	// LDAP
+	flags.StringVar(
+		&args.ldapURL,
+		"url",
+		"",
+		"LDAP: An RFC 2255 URL which specifies the LDAP search parameters to use.",
+	)
+	flags.StringVar(
+		&args.ldapBindDN,
+		"bind-dn",
+		"",
+		"LDAP: DN to bind with during the search phase.",
+	)
+	flags.StringVar(
+		&args.ldapBindPassword,
+		"bind-password",
+		"",
+		"LDAP: Password to bind with during the search phase.",
+	)
+	flags.StringVar(
+		&args.ldapIDs,
+		"id-attributes",
+		"",
+		"LDAP: The list of attributes whose values should be used as the user ID.",
+	)
+	flags.StringVar(
+		&args.ldapUsernames,
+		"username-attributes",
+		"",
+		"LDAP: The list of attributes whose values should be used as the preferred username.",
+	)
+	flags.StringVar(
+		&args.ldapDisplayNames,
+		"name-attributes",
+		"",
+		"LDAP: The list of attributes whose values should be used as the display name.",
+	)
+	flags.StringVar(
+		&args.ldapEmails,
+		"email-attributes",
+		"",
+		"LDAP: The list of attributes whose values should be used as the email address.\n",
+	)
+
+	// OpenID
+	flags.StringVar(
+		&args.openidIssuerURL,
+		"issuer-url",
+		"",
+		"OpenID: The URL that the OpenID Provider asserts as the Issuer Identifier. "+
+			"It must use the https scheme with no URL query parameters or fragment.",
+	)
+	flags.StringVar(
+		&args.openidEmail,
+		"email-claims",
+		"",
+		"OpenID: List of claims to use as the email address.",
+	)
+	flags.StringVar(
+		&args.openidName,
+		"name-claims",
+		"",
+		"OpenID: List of claims to use as the display name.",
+	)
+	flags.StringVar(
+		&args.openidUsername,
+		"username-claims",
+		"",
+		"OpenID: List of claims to use as the preferred username when provisioning a user.",
+	)
+	flags.StringVar(
+		&args.openidExtraScopes,
+		"extra-scopes",
+		"",
+		"OpenID: List of extra scopes to request when provisioning a user.\n",
+	)
+
+	// HTPasswd
+	flags.StringVar(
+		&args.htpasswdFile,
+		"from-file",
+		"",
+		"HTPasswd: Local htpasswd file containing the hashed passwords of the users to add or "+
+			"rotate. When no user is given, all the users of the file are added or rotated.",
+	)
+	flags.StringSliceVar(
+		&args.htpasswdAddUsers,
+		"add-user",
+		nil,
+		"HTPasswd: User to add. Can be used multiple times.",
+	)
+	flags.StringSliceVar(
+		&args.htpasswdRemoveUsers,
+		"remove-user",
+		nil,
+		"HTPasswd: User to remove. Can be used multiple times.",
+	)
+	flags.StringSliceVar(
+		&args.htpasswdRotateUsers,
+		"rotate-user",
+		nil,
+		"HTPasswd: User to change the password of. Can be used multiple times.",
+	)
+	flags.StringVar(
+		&args.htpasswdPassword,
+		"password",
+		"",
+		"HTPasswd: Password of the added or rotated user when not using a htpasswd file. "+
+			"A password is generated if not given.\n",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	idpName := argv[0]
+	if idpName == "" {
+		return fmt.Errorf("Identity provider name is required")
+	}
+
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := args.clusterKey
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Get the client for the cluster management api
+	clusterCollection := connection.ClustersMgmt().V1().Clusters()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	if cluster.State() != cmv1.ClusterStateReady {
+		return fmt.Errorf("Cluster '%s' is not yet ready", clusterKey)
+	}
+
+	idps, err := c.GetIdentityProviders(clusterCollection, cluster.ID())
+	if err != nil {
+		return fmt.Errorf("Failed to get identity providers for cluster '%s': %v", clusterKey, err)
+	}
+
+	var idp *cmv1.IdentityProvider
+	for _, item := range idps {
+		if item.Name() == idpName {
+			idp = item
+		}
+	}
+	if idp == nil {
+		return fmt.Errorf("Failed to get identity provider '%s' for cluster '%s'", idpName, clusterKey)
+	}
+
+	err = checkFlags(cmd, idp)
+	if err != nil {
+		return err
+	}
+
+	// Changes to htpasswd users use their own endpoints:
+	if htpasswdUsersChanged(cmd) {
+		if cmd.Flags().Changed("mapping-method") {
+			return fmt.Errorf("Flag --mapping-method can't be used together with the flags " +
+				"that change htpasswd users")
+		}
+		return editHtpasswdUsers(clusterCollection, cluster, idp)
+	}
+
+	// Settings are only prompted for when none was given:
+	interactive := args.interactive || !settingsChanged(cmd)
+
+	var idpBuilder *cmv1.IdentityProviderBuilder
+	switch idp.Type() {
+	case cmv1.IdentityProviderTypeGithub:
+		idpBuilder, err = buildGithubIdp(cmd, interactive, idp)
+	case cmv1.IdentityProviderTypeGoogle:
+		idpBuilder, err = buildGoogleIdp(cmd, interactive, idp)
+	case cmv1.IdentityProviderTypeLDAP:
+		idpBuilder, err = buildLdapIdp(cmd, interactive, idp)
+	case cmv1.IdentityProviderTypeOpenID:
+		idpBuilder, err = buildOpenidIdp(cmd, interactive, idp)
+	case cmv1.IdentityProviderTypeHtpasswd:
+		idpBuilder, err = buildHtpasswdIdp(cmd, interactive, idp)
+	default:
+		err = fmt.Errorf("Editing identity providers of type '%s' isn't supported", idp.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to edit IDP '%s' for cluster '%s': %v", idpName, clusterKey, err)
+	}
+	if idpBuilder == nil {
+		fmt.Printf("No changes to identity provider '%s' on cluster '%s'\n", idpName, clusterKey)
+		return nil
+	}
+
+	body, err := idpBuilder.Type(idp.Type()).Build()
+	if err != nil {
+		return fmt.Errorf("Failed to edit IDP '%s' for cluster '%s': %v", idpName, clusterKey, err)
+	}
+
+	_, err = clusterCollection.Cluster(cluster.ID()).
+		IdentityProviders().
+		IdentityProvider(idp.ID()).
+		Update().
+		Body(body).
+		Send()
+	if err != nil {
+		return fmt.Errorf("Failed to update identity provider '%s' on cluster '%s': %v",
+			idpName, clusterKey, err)
+	}
+
+	fmt.Printf("Updated identity provider '%s' on cluster '%s'\n", idpName, clusterKey)
+	return nil
+}
+
+// typeFlags are the flags that can be used with each type of identity provider, in addition to
+// the ones that can be used with all of them.
+var typeFlags = map[cmv1.IdentityProviderType][]string{
+	cmv1.IdentityProviderTypeGithub: {
+		"client-id", "client-secret", "hostname", "organizations", "teams",
+	},
+	cmv1.IdentityProviderTypeGoogle: {
+		"client-id", "client-secret", "hosted-domain",
+	},
+	cmv1.IdentityProviderTypeLDAP: {
+		"url", "bind-dn", "bind-password", "id-attributes", "username-attributes",
+		"name-attributes", "email-attributes",
+	},
+	cmv1.IdentityProviderTypeOpenID: {
+		"client-id", "client-secret", "issuer-url", "email-claims", "name-claims",
+		"username-claims", "extra-scopes",
+	},
+	cmv1.IdentityProviderTypeHtpasswd: {
+		"from-file", "add-user", "remove-user", "rotate-user", "password",
+	},
+}
+
+// checkFlags checks that the flags given apply to the type of the identity provider, as otherwise
+// they would be silently ignored.
+func checkFlags(cmd *cobra.Command, idp *cmv1.IdentityProvider) error {
+	allowed := map[string]bool{
+		"cluster":        true,
+		"interactive":    true,
+		"mapping-method": true,
+	}
+	for _, name := range typeFlags[idp.Type()] {
+		allowed[name] = true
+	}
+	var invalid []string
+	cmd.LocalFlags().Visit(func(flag *pflag.Flag) {
+		if !allowed[flag.Name] {
+			invalid = append(invalid, "--"+flag.Name)
+		}
+	})
+	if len(invalid) > 0 {
+		return fmt.Errorf("Identity provider '%s' is of type '%s', flags %s can't be used with it",
+			idp.Name(), idp.Type(), strings.Join(invalid, ", "))
+	}
+	return nil
+}
+
+func settingsChanged(cmd *cobra.Command) bool {
+	changed := false
+	cmd.LocalFlags().Visit(func(flag *pflag.Flag) {
+		if flag.Name != "cluster" && flag.Name != "interactive" {
+			changed = true
+		}
+	})
+	return changed
+}
+
+// setting is a value of an identity provider that can be changed with a flag or a prompt.
+type setting struct {
+	flag    string
+	message string
+	value   *string
+	current string
+	secret  bool
+}
+
+// collectSettings returns the names of the flags of the settings that need to be changed. When
+// running interactively the settings whose flags weren't given are prompted for, using the current
+// values as defaults. Secrets aren't returned by the API, so an empty answer keeps them unchanged.
+func collectSettings(cmd *cobra.Command, interactive bool, settings []setting) (map[string]bool, error) {
+	changed := map[string]bool{}
+	for _, s := range settings {
+		if cmd.Flags().Changed(s.flag) {
+			changed[s.flag] = true
+			continue
+		}
+		if !interactive {
+			continue
+		}
+		answer := ""
+		var prompt survey.Prompt
+		if s.secret {
+			prompt = &survey.Password{
+				Message: s.message + " (leave empty to keep the current value):",
+			}
+		} else {
+			prompt = &survey.Input{
+				Message: s.message + ":",
+				Default: s.current,
+			}
+		}
+		err := survey.AskOne(prompt, &answer)
+		if err != nil {
+			return nil, errors.New("Expected a valid value for " + s.flag)
+		}
+		if s.secret && answer == "" {
+			continue
+		}
+		if !s.secret && answer == s.current {
+			continue
+		}
+		*s.value = answer
+		changed[s.flag] = true
+	}
+	return changed, nil
+}
+
+// mappingMethodSetting returns the setting of the mapping method, common to all the types.
+func mappingMethodSetting(idp *cmv1.IdentityProvider) setting {
+	return setting{
+		flag:    "mapping-method",
+		message: "Mapping method",
+		value:   &args.mappingMethod,
+		current: string(idp.MappingMethod()),
+	}
+}
+
+// newIdpBuilder returns the builder for the identity provider patch, with the mapping method if
+// it changed.
+func newIdpBuilder(changed map[string]bool) *cmv1.IdentityProviderBuilder {
+	idpBuilder := cmv1.NewIdentityProvider()
+	if changed["mapping-method"] {
+		idpBuilder.MappingMethod(cmv1.IdentityProviderMappingMethod(args.mappingMethod))
+	}
+	return idpBuilder
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	return strings.Split(value, ",")
+}
@@ -0,0 +1,109 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package idp
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+)
+
+func buildGithubIdp(cmd *cobra.Command, interactive bool,
+	idp *cmv1.IdentityProvider) (*cmv1.IdentityProviderBuilder, error) {
+	current := idp.Github()
+	changed, err := collectSettings(cmd, interactive, []setting{
+		{
+			flag:    "client-id",
+			message: "Client ID provided by GitHub",
+			value:   &args.clientID,
+			current: current.ClientID(),
+		},
+		{
+			flag:    "client-secret",
+			message: "Client Secret provided by GitHub",
+			value:   &args.clientSecret,
+			secret:  true,
+		},
+		{
+			flag:    "hostname",
+			message: "Hostname of GitHub Enterprise",
+			value:   &args.githubHostname,
+			current: current.Hostname(),
+		},
+		{
+			flag:    "organizations",
+			message: "List of GitHub organizations",
+			value:   &args.githubOrganizations,
+			current: strings.Join(current.Organizations(), ","),
+		},
+		{
+			flag:    "teams",
+			message: "List of GitHub teams",
+			value:   &args.githubTeams,
+			current: strings.Join(current.Teams(), ","),
+		},
+		mappingMethodSetting(idp),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	githubIDP := cmv1.NewGithubIdentityProvider()
+	if changed["client-id"] {
+		githubIDP.ClientID(args.clientID)
+	}
+	if changed["client-secret"] {
+		githubIDP.ClientSecret(args.clientSecret)
+	}
+	if changed["hostname"] {
+		if args.githubHostname != "" {
+			_, err = url.ParseRequestURI(args.githubHostname)
+			if err != nil {
+				return nil, fmt.Errorf("Expected a valid Hostname: %v", err)
+			}
+		}
+		githubIDP.Hostname(args.githubHostname)
+	}
+
+	// GitHub allows either organizations or teams, so setting one clears the other:
+	if changed["organizations"] && changed["teams"] &&
+		args.githubOrganizations != "" && args.githubTeams != "" {
+		return nil, errors.New("GitHub IDP only allows either organizations or teams, but not both")
+	}
+	if changed["organizations"] && args.githubOrganizations != "" {
+		githubIDP.Organizations(splitList(args.githubOrganizations)...)
+		githubIDP.Teams()
+	} else if changed["teams"] && args.githubTeams != "" {
+		githubIDP.Teams(splitList(args.githubTeams)...)
+		githubIDP.Organizations()
+	} else if changed["organizations"] || changed["teams"] {
+		return nil, errors.New("Expected a GitHub organization or team name")
+	}
+
+	idpBuilder := newIdpBuilder(changed)
+	if !githubIDP.Empty() {
+		idpBuilder.Github(githubIDP)
+	}
+	return idpBuilder, nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package idp
+
+import (
+	"fmt"
+	"net/url"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+)
+
+func buildGoogleIdp(cmd *cobra.Command, interactive bool,
+	idp *cmv1.IdentityProvider) (*cmv1.IdentityProviderBuilder, error) {
+	current := idp.Google()
+	changed, err := collectSettings(cmd, interactive, []setting{
+		{
+			flag:    "client-id",
+			message: "Client ID provided by Google",
+			value:   &args.clientID,
+			current: current.ClientID(),
+		},
+		{
+			flag:    "client-secret",
+			message: "Client Secret provided by Google",
+			value:   &args.clientSecret,
+			secret:  true,
+		},
+		{
+			flag:    "hosted-domain",
+			message: "Hosted Domain to restrict users",
+			value:   &args.googleHostedDomain,
+			current: current.HostedDomain(),
+		},
+		mappingMethodSetting(idp),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	googleIDP := cmv1.NewGoogleIdentityProvider()
+	if changed["client-id"] {
+		googleIDP.ClientID(args.clientID)
+	}
+	if changed["client-secret"] {
+		googleIDP.ClientSecret(args.clientSecret)
+	}
+	if changed["hosted-domain"] {
+		hostedDomain := args.googleHostedDomain
+		if hostedDomain != "" {
+			hostedDomainParsed, err := url.ParseRequestURI(hostedDomain)
+			if err != nil {
+				return nil, fmt.Errorf("Expected a valid Hosted Domain: %v", err)
+			}
+			hostedDomain = hostedDomainParsed.Hostname()
+		}
+		googleIDP.HostedDomain(hostedDomain)
+	}
+
+	idpBuilder := newIdpBuilder(changed)
+	if !googleIDP.Empty() {
+		idpBuilder.Google(googleIDP)
+	}
+	return idpBuilder, nil
+}
@@ -0,0 +1,199 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package idp
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/AlecAivazis/survey/v2"
+	pwdgen "github.com/m1/go-generate-password/generator"
+	"github.com/spf13/cobra"
+)
+
+func buildHtpasswdIdp(cmd *cobra.Command, interactive bool,
+	idp *cmv1.IdentityProvider) (*cmv1.IdentityProviderBuilder, error) {
+	if cmd.Flags().Changed("password") {
+		return nil, errors.New("Use --add-user or --rotate-user to set the password of htpasswd users")
+	}
+	changed, err := collectSettings(cmd, interactive, []setting{
+		mappingMethodSetting(idp),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	return newIdpBuilder(changed), nil
+}
+
+func htpasswdUsersChanged(cmd *cobra.Command) bool {
+	flags := cmd.Flags()
+	return flags.Changed("from-file") || flags.Changed("add-user") ||
+		flags.Changed("remove-user") || flags.Changed("rotate-user")
+}
+
+// editHtpasswdUsers adds, removes and rotates the passwords of the users of an htpasswd identity
+// provider. Passwords are taken from the local htpasswd file if given, otherwise from the
+// password flag, a prompt or generated.
+func editHtpasswdUsers(clusterCollection *cmv1.ClustersClient, cluster *cmv1.Cluster,
+	idp *cmv1.IdentityProvider) error {
+	var hashes map[string]string
+	var err error
+	if args.htpasswdFile != "" {
+		hashes, err = c.LoadHTPasswdFile(args.htpasswdFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	users, err := c.GetHTPasswdUsers(clusterCollection, cluster.ID(), idp.ID())
+	if err != nil {
+		return err
+	}
+	existing := map[string]string{}
+	for _, user := range users {
+		existing[user.Username()] = user.ID()
+	}
+
+	addUsers := args.htpasswdAddUsers
+	rotateUsers := args.htpasswdRotateUsers
+	if hashes != nil && len(addUsers) == 0 && len(rotateUsers) == 0 {
+		names := make([]string, 0, len(hashes))
+		for name := range hashes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if _, ok := existing[name]; ok {
+				rotateUsers = append(rotateUsers, name)
+			} else {
+				addUsers = append(addUsers, name)
+			}
+		}
+	}
+
+	// Check everything before changing anything:
+	for _, name := range addUsers {
+		if _, ok := existing[name]; ok {
+			return fmt.Errorf("User '%s' already exists in identity provider '%s'", name, idp.Name())
+		}
+	}
+	for _, name := range append(rotateUsers, args.htpasswdRemoveUsers...) {
+		if _, ok := existing[name]; !ok {
+			return fmt.Errorf("User '%s' doesn't exist in identity provider '%s'", name, idp.Name())
+		}
+	}
+	if hashes != nil {
+		for _, name := range append(addUsers, rotateUsers...) {
+			if _, ok := hashes[name]; !ok {
+				return fmt.Errorf("User '%s' isn't in htpasswd file '%s'", name, args.htpasswdFile)
+			}
+		}
+	}
+	if len(existing)+len(addUsers)-len(args.htpasswdRemoveUsers) < 1 {
+		return fmt.Errorf("Identity provider '%s' must keep at least one user", idp.Name())
+	}
+
+	usersClient := clusterCollection.Cluster(cluster.ID()).
+		IdentityProviders().
+		IdentityProvider(idp.ID()).
+		HtpasswdUsers()
+
+	for _, name := range addUsers {
+		userBuilder, message, err := buildHtpasswdUser(name, hashes)
+		if err != nil {
+			return err
+		}
+		user, err := userBuilder.Username(name).Build()
+		if err != nil {
+			return fmt.Errorf("Failed to create user '%s': %v", name, err)
+		}
+		_, err = usersClient.Add().Body(user).Send()
+		if err != nil {
+			return fmt.Errorf("Failed to add user '%s' to identity provider '%s': %v", name, idp.Name(), err)
+		}
+		fmt.Printf("Added user '%s' to identity provider '%s'\n%s", name, idp.Name(), message)
+	}
+
+	for _, name := range rotateUsers {
+		userBuilder, message, err := buildHtpasswdUser(name, hashes)
+		if err != nil {
+			return err
+		}
+		user, err := userBuilder.Build()
+		if err != nil {
+			return fmt.Errorf("Failed to update user '%s': %v", name, err)
+		}
+		_, err = usersClient.HtpasswdUser(existing[name]).Update().Body(user).Send()
+		if err != nil {
+			return fmt.Errorf("Failed to rotate password of user '%s' in identity provider '%s': %v",
+				name, idp.Name(), err)
+		}
+		fmt.Printf("Rotated password of user '%s' in identity provider '%s'\n%s", name, idp.Name(), message)
+	}
+
+	for _, name := range args.htpasswdRemoveUsers {
+		_, err = usersClient.HtpasswdUser(existing[name]).Delete().Send()
+		if err != nil {
+			return fmt.Errorf("Failed to remove user '%s' from identity provider '%s': %v",
+				name, idp.Name(), err)
+		}
+		fmt.Printf("Removed user '%s' from identity provider '%s'\n", name, idp.Name())
+	}
+
+	return nil
+}
+
+// buildHtpasswdUser returns a user with the hashed password of the htpasswd file, or the password
+// given, prompted for or generated. The message contains the generated password, if any.
+func buildHtpasswdUser(name string, hashes map[string]string) (*cmv1.HTPasswdUserBuilder, string, error) {
+	userBuilder := cmv1.NewHTPasswdUser()
+	if hashes != nil {
+		return userBuilder.HashedPassword(hashes[name]), "", nil
+	}
+
+	password := args.htpasswdPassword
+	if password == "" {
+		prompt := &survey.Password{
+			Message: fmt.Sprintf("Enter password for user '%s' or leave empty to generate:", name),
+		}
+		err := survey.AskOne(prompt, &password)
+		if err != nil {
+			return nil, "", errors.New("Expected a password")
+		}
+	}
+	message := ""
+	if password == "" {
+		generator, err := pwdgen.NewWithDefault()
+		if err != nil {
+			return nil, "", errors.New("Failed to initialize password generator")
+		}
+		generatedPwd, err := generator.Generate()
+		if err != nil {
+			return nil, "", errors.New("Failed to generate a password")
+		}
+		password = *generatedPwd
+		message = "User '" + name + "' can now log in with the password '" + password + "'.\n"
+	}
+	return userBuilder.Password(password), message, nil
+}
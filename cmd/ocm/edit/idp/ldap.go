@@ -0,0 +1,130 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package idp
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+)
+
+func buildLdapIdp(cmd *cobra.Command, interactive bool,
+	idp *cmv1.IdentityProvider) (*cmv1.IdentityProviderBuilder, error) {
+	current := idp.LDAP()
+	attributes := current.Attributes()
+	changed, err := collectSettings(cmd, interactive, []setting{
+		{
+			flag:    "url",
+			message: "URL which specifies the LDAP search parameters to use",
+			value:   &args.ldapURL,
+			current: current.URL(),
+		},
+		{
+			flag:    "bind-dn",
+			message: "DN to bind with during the search phase",
+			value:   &args.ldapBindDN,
+			current: current.BindDN(),
+		},
+		{
+			flag:    "bind-password",
+			message: "Password to bind with during the search phase",
+			value:   &args.ldapBindPassword,
+			secret:  true,
+		},
+		{
+			flag:    "id-attributes",
+			message: "List of attributes whose values should be used as the user ID",
+			value:   &args.ldapIDs,
+			current: strings.Join(attributes.ID(), ","),
+		},
+		{
+			flag:    "username-attributes",
+			message: "List of attributes whose values should be used as the preferred username",
+			value:   &args.ldapUsernames,
+			current: strings.Join(attributes.PreferredUsername(), ","),
+		},
+		{
+			flag:    "name-attributes",
+			message: "List of attributes whose values should be used as the display name",
+			value:   &args.ldapDisplayNames,
+			current: strings.Join(attributes.Name(), ","),
+		},
+		{
+			flag:    "email-attributes",
+			message: "List of attributes whose values should be used as the email address",
+			value:   &args.ldapEmails,
+			current: strings.Join(attributes.Email(), ","),
+		},
+		mappingMethodSetting(idp),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	ldapIDP := cmv1.NewLDAPIdentityProvider()
+	if changed["url"] {
+		parsedLdapURL, err := url.ParseRequestURI(args.ldapURL)
+		if err != nil {
+			return nil, fmt.Errorf("Expected a valid LDAP URL: %v", err)
+		}
+		if parsedLdapURL.Scheme != "ldap" && parsedLdapURL.Scheme != "ldaps" {
+			return nil, errors.New("Expected LDAP URL to have an ldap:// or ldaps:// scheme")
+		}
+		ldapIDP.URL(args.ldapURL)
+	}
+	if changed["bind-dn"] {
+		ldapIDP.BindDN(args.ldapBindDN)
+	}
+	if changed["bind-password"] {
+		ldapIDP.BindPassword(args.ldapBindPassword)
+	}
+
+	// The attributes are sent together, keeping the ones that didn't change:
+	if changed["id-attributes"] || changed["username-attributes"] ||
+		changed["name-attributes"] || changed["email-attributes"] {
+		ldapAttributes := cmv1.NewLDAPAttributes().Copy(attributes)
+		if changed["id-attributes"] {
+			if args.ldapIDs == "" {
+				return nil, errors.New("Expected a valid comma-separated list of attributes")
+			}
+			ldapAttributes.ID(splitList(args.ldapIDs)...)
+		}
+		if changed["username-attributes"] {
+			ldapAttributes.PreferredUsername(splitList(args.ldapUsernames)...)
+		}
+		if changed["name-attributes"] {
+			ldapAttributes.Name(splitList(args.ldapDisplayNames)...)
+		}
+		if changed["email-attributes"] {
+			ldapAttributes.Email(splitList(args.ldapEmails)...)
+		}
+		ldapIDP.Attributes(ldapAttributes)
+	}
+
+	idpBuilder := newIdpBuilder(changed)
+	if !ldapIDP.Empty() {
+		idpBuilder.LDAP(ldapIDP)
+	}
+	return idpBuilder, nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package idp
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+)
+
+func buildOpenidIdp(cmd *cobra.Command, interactive bool,
+	idp *cmv1.IdentityProvider) (*cmv1.IdentityProviderBuilder, error) {
+	current := idp.OpenID()
+	claims := current.Claims()
+	changed, err := collectSettings(cmd, interactive, []setting{
+		{
+			flag:    "client-id",
+			message: "Client ID provided by the OpenID Provider",
+			value:   &args.clientID,
+			current: current.ClientID(),
+		},
+		{
+			flag:    "client-secret",
+			message: "Client Secret provided by the OpenID Provider",
+			value:   &args.clientSecret,
+			secret:  true,
+		},
+		{
+			flag:    "issuer-url",
+			message: "URL that the OpenID Provider asserts as the Issuer Identifier",
+			value:   &args.openidIssuerURL,
+			current: current.Issuer(),
+		},
+		{
+			flag:    "email-claims",
+			message: "Claim mappings to use as the email address",
+			value:   &args.openidEmail,
+			current: strings.Join(claims.Email(), ","),
+		},
+		{
+			flag:    "name-claims",
+			message: "Claim mappings to use as the display name",
+			value:   &args.openidName,
+			current: strings.Join(claims.Name(), ","),
+		},
+		{
+			flag:    "username-claims",
+			message: "Claim mappings to use as the preferred username",
+			value:   &args.openidUsername,
+			current: strings.Join(claims.PreferredUsername(), ","),
+		},
+		{
+			flag:    "extra-scopes",
+			message: "Extra scopes to request",
+			value:   &args.openidExtraScopes,
+			current: strings.Join(current.ExtraScopes(), ","),
+		},
+		mappingMethodSetting(idp),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	openIDIDP := cmv1.NewOpenIDIdentityProvider()
+	if changed["client-id"] {
+		openIDIDP.ClientID(args.clientID)
+	}
+	if changed["client-secret"] {
+		openIDIDP.ClientSecret(args.clientSecret)
+	}
+	if changed["issuer-url"] {
+		parsedIssuerURL, err := url.ParseRequestURI(args.openidIssuerURL)
+		if err != nil {
+			return nil, fmt.Errorf("Expected a valid OpenID issuer URL: %v", err)
+		}
+		if parsedIssuerURL.Scheme != "https" {
+			return nil, errors.New("Expected OpenID issuer URL to use an https:// scheme")
+		}
+		if parsedIssuerURL.RawQuery != "" {
+			return nil, errors.New("OpenID issuer URL must not have query parameters")
+		}
+		if parsedIssuerURL.Fragment != "" {
+			return nil, errors.New("OpenID issuer URL must not have a fragment")
+		}
+		openIDIDP.Issuer(args.openidIssuerURL)
+	}
+	if changed["extra-scopes"] {
+		openIDIDP.ExtraScopes(splitList(args.openidExtraScopes)...)
+	}
+
+	// The claims are sent together, keeping the ones that didn't change:
+	if changed["email-claims"] || changed["name-claims"] || changed["username-claims"] {
+		openIDClaims := cmv1.NewOpenIDClaims().Copy(claims)
+		if changed["email-claims"] {
+			openIDClaims.Email(splitList(args.openidEmail)...)
+		}
+		if changed["name-claims"] {
+			openIDClaims.Name(splitList(args.openidName)...)
+		}
+		if changed["username-claims"] {
+			openIDClaims.PreferredUsername(splitList(args.openidUsername)...)
+		}
+		claimsObject, err := openIDClaims.Build()
+		if err != nil {
+			return nil, err
+		}
+		if len(claimsObject.Email()) == 0 && len(claimsObject.Name()) == 0 &&
+			len(claimsObject.PreferredUsername()) == 0 {
+			return nil, errors.New("At least one claim is required: [email-claims name-claims username-claims]")
+		}
+		openIDIDP.Claims(openIDClaims)
+	}
+
+	idpBuilder := newIdpBuilder(changed)
+	if !openIDIDP.Empty() {
+		idpBuilder.OpenID(openIDIDP)
+	}
+	return idpBuilder, nil
+}
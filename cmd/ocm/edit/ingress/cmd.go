@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	i "github.com/openshift-online/ocm-cli/pkg/ingress"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	"github.com/openshift-online/ocm-cli/pkg/utils"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
@@ -27,11 +28,6 @@ import (
 
 var ingressKeyRE = regexp.MustCompile(`^[a-z0-9]{4,5}$`)
 
-var validLbTypes = []string{string(cmv1.LoadBalancerFlavorClassic), string(cmv1.LoadBalancerFlavorNlb)}
-var ValidWildcardPolicies = []string{string(cmv1.WildcardPolicyWildcardsDisallowed),
-	string(cmv1.WildcardPolicyWildcardsAllowed)}
-var ValidNamespaceOwnershipPolicies = []string{string(cmv1.NamespaceOwnershipPolicyStrict),
-	string(cmv1.NamespaceOwnershipPolicyInterNamespaceAllowed)}
 var expectedComponentRoutes = []string{
 	string(cmv1.ComponentRouteTypeOauth),
 	string(cmv1.ComponentRouteTypeConsole),
@@ -55,6 +51,8 @@ var args struct {
 	clusterRoutesTlsSecretRef string
 
 	componentRoutes string
+
+	fromFile string
 }
 
 const (
@@ -68,6 +66,7 @@ const (
 	clusterRoutesHostnameFlag     = "cluster-routes-hostname"
 	clusterRoutesTlsSecretRefFlag = "cluster-routes-tls-secret-ref"
 	componentRoutesFlag           = "component-routes"
+	fromFileFlag                  = "from-file"
 
 	expectedLengthOfParsedComponent = 2
 	hostnameParameter               = "hostname"
@@ -83,7 +82,9 @@ var Cmd = &cobra.Command{
 	Example: `  #  Update the router selectors for the additional ingress with ID 'a1b2'
   ocm edit ingress --label-match=foo=bar --cluster=mycluster a1b2
   #  Update the default ingress using the sub-domain identifier
-  ocm edit ingress --private=false --cluster=mycluster apps"`,
+  ocm edit ingress --private=false --cluster=mycluster apps
+  #  Update the default ingress with the settings from a YAML or JSON file
+  ocm edit ingress --from-file=ingress.yaml --cluster=mycluster apps`,
 	RunE: run,
 }
 
@@ -127,7 +128,7 @@ func init() {
 		&args.lbType,
 		lbTypeFlag,
 		"",
-		fmt.Sprintf("Type of Load Balancer. Options are %s.", strings.Join(validLbTypes, ",")),
+		fmt.Sprintf("Type of Load Balancer. Options are %s.", strings.Join(i.ValidLoadBalancerTypes, ",")),
 	)
 
 	flags.StringVar(
@@ -142,7 +143,7 @@ func init() {
 		&args.wildcardPolicy,
 		wildcardPolicyFlag,
 		"",
-		fmt.Sprintf("Wildcard Policy for ingress. Options are %s", strings.Join(ValidWildcardPolicies, ",")),
+		fmt.Sprintf("Wildcard Policy for ingress. Options are %s", strings.Join(i.ValidWildcardPolicies, ",")),
 	)
 
 	flags.StringVar(
@@ -150,7 +151,7 @@ func init() {
 		namespaceOwnershipPolicyFlag,
 		"",
 		fmt.Sprintf("Namespace Ownership Policy for ingress. Options are %s",
-			strings.Join(ValidNamespaceOwnershipPolicies, ",")),
+			strings.Join(i.ValidNamespaceOwnershipPolicies, ",")),
 	)
 
 	flags.StringVar(
@@ -175,6 +176,13 @@ func init() {
 		"Component routes settings. Available keys [oauth, console, downloads]. For each key a pair of hostname and tlsSecretRef is expected to be supplied. "+
 			"Format should be a comma separate list 'oauth: hostname=example-hostname;tlsSecretRef=example-secret-ref,downloads:...",
 	)
+	flags.StringVar(
+		&args.fromFile,
+		fromFileFlag,
+		"",
+		"YAML or JSON file containing the ingress settings, using the field names of the API. "+
+			"Settings given with other flags take precedence over the ones in the file.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -243,7 +251,14 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Failed to get ingress '%s' for cluster '%s'", ingressID, clusterKey)
 	}
 
-	ingressBuilder := cmv1.NewIngress().ID(ingress.ID())
+	ingressBuilder := cmv1.NewIngress()
+	if args.fromFile != "" {
+		ingressBuilder, err = i.LoadFile(args.fromFile)
+		if err != nil {
+			return err
+		}
+	}
+	ingressBuilder = ingressBuilder.ID(ingress.ID())
 	if cmd.Flags().Changed(privateFlag) {
 		if args.private {
 			ingressBuilder = ingressBuilder.Listening(cmv1.ListeningMethodInternal)
@@ -254,10 +269,7 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	// Add route selectors
 	if cmd.Flags().Changed(labelMatchFlag) || cmd.Flags().Changed(routeSelectorFlag) {
-		if cluster.Hypershift().Enabled() {
-			return fmt.Errorf("Can't edit `%s` for Hosted Control Plane clusters", routeSelectorFlag)
-		}
-		routeSelectors, err := GetRouteSelector(args.routeSelector)
+		routeSelectors, err := i.ParseRouteSelectors(args.routeSelector)
 		if err != nil {
 			return err
 		}
@@ -265,56 +277,35 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 
 	if cmd.Flags().Changed(lbTypeFlag) {
-		if cluster.Hypershift().Enabled() {
-			return fmt.Errorf("Can't edit `%s` for Hosted Control Plane clusters", lbTypeFlag)
-		}
-		if cluster.AWS().STS().RoleARN() != "" {
-			return fmt.Errorf("Can't edit `%s` for STS clusters", lbTypeFlag)
-		}
 		ingressBuilder = ingressBuilder.LoadBalancerType(cmv1.LoadBalancerFlavor(args.lbType))
 	}
 
 	if cmd.Flags().Changed(excludedNamespacesFlag) {
-		if cluster.Hypershift().Enabled() {
-			return fmt.Errorf("Can't edit `%s` for Hosted Control Plane clusters", excludedNamespacesFlag)
+		excludedNamespaces, err := i.ParseExcludedNamespaces(args.excludedNamespaces)
+		if err != nil {
+			return err
 		}
-		_excludedNamespaces := GetExcludedNamespaces(args.excludedNamespaces)
-		ingressBuilder = ingressBuilder.ExcludedNamespaces(_excludedNamespaces...)
+		ingressBuilder = ingressBuilder.ExcludedNamespaces(excludedNamespaces...)
 	}
 
 	if cmd.Flags().Changed(wildcardPolicyFlag) {
-		if cluster.Hypershift().Enabled() {
-			return fmt.Errorf("Can't edit `%s` for Hosted Control Plane clusters", wildcardPolicyFlag)
-		}
 		ingressBuilder = ingressBuilder.RouteWildcardPolicy(cmv1.WildcardPolicy(args.wildcardPolicy))
 	}
 
 	if cmd.Flags().Changed(namespaceOwnershipPolicyFlag) {
-		if cluster.Hypershift().Enabled() {
-			return fmt.Errorf("Can't edit `%s` for Hosted Control Plane clusters", namespaceOwnershipPolicyFlag)
-		}
 		ingressBuilder = ingressBuilder.RouteNamespaceOwnershipPolicy(
 			cmv1.NamespaceOwnershipPolicy(args.namespaceOwnershipPolicy))
 	}
 
 	if cmd.Flags().Changed(clusterRoutesHostnameFlag) {
-		if cluster.Hypershift().Enabled() {
-			return fmt.Errorf("Can't edit `%s` for Hosted Control Plane clusters", clusterRoutesHostnameFlag)
-		}
 		ingressBuilder = ingressBuilder.ClusterRoutesHostname(args.clusterRoutesHostname)
 	}
 
 	if cmd.Flags().Changed(clusterRoutesTlsSecretRefFlag) {
-		if cluster.Hypershift().Enabled() {
-			return fmt.Errorf("Can't edit `%s` for Hosted Control Plane clusters", clusterRoutesTlsSecretRefFlag)
-		}
 		ingressBuilder = ingressBuilder.ClusterRoutesTlsSecretRef(args.clusterRoutesTlsSecretRef)
 	}
 
 	if cmd.Flags().Changed(componentRoutesFlag) {
-		if cluster.Hypershift().Enabled() {
-			return fmt.Errorf("Can't edit `%s` for Hosted Control Plane clusters", componentRoutesFlag)
-		}
 		componentRoutes, err := parseComponentRoutes(args.componentRoutes)
 		if err != nil {
 			return fmt.Errorf("An error occurred whilst parsing the supplied component routes: %s", err)
@@ -322,10 +313,15 @@ func run(cmd *cobra.Command, argv []string) error {
 		ingressBuilder = ingressBuilder.ComponentRoutes(componentRoutes)
 	}
 
+	current := ingress
 	ingress, err = ingressBuilder.Build()
 	if err != nil {
 		return fmt.Errorf("Failed to edit ingress for cluster '%s': %v", clusterKey, err)
 	}
+	err = i.Validate(cluster, current, ingress)
+	if err != nil {
+		return err
+	}
 
 	_, err = clusterCollection.
 		Cluster(cluster.ID()).
@@ -399,30 +395,3 @@ func parseComponentRoutes(input string) (map[string]*cmv1.ComponentRouteBuilder,
 	}
 	return result, nil
 }
-
-func GetExcludedNamespaces(excludedNamespaces string) []string {
-	if excludedNamespaces == "" {
-		return []string{}
-	}
-	sliceExcludedNamespaces := strings.Split(excludedNamespaces, ",")
-	for i := range sliceExcludedNamespaces {
-		sliceExcludedNamespaces[i] = strings.TrimSpace(sliceExcludedNamespaces[i])
-	}
-	return sliceExcludedNamespaces
-}
-
-func GetRouteSelector(labelMatches string) (map[string]string, error) {
-	if labelMatches == "" {
-		return map[string]string{}, nil
-	}
-	routeSelectors := map[string]string{}
-	for _, labelMatch := range strings.Split(labelMatches, ",") {
-		if !strings.Contains(labelMatch, "=") {
-			return nil, fmt.Errorf("Expected key=value format for label-match")
-		}
-		tokens := strings.Split(labelMatch, "=")
-		routeSelectors[strings.TrimSpace(tokens[0])] = strings.TrimSpace(tokens[1])
-	}
-
-	return routeSelectors, nil
-}
@@ -15,6 +15,7 @@ package machinepool
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/openshift-online/ocm-cli/pkg/arguments"
@@ -22,10 +23,12 @@ import (
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var args struct {
 	clusterKey  string
+	interactive bool
 	replicas    int
 	autoscaling c.Autoscaling
 	labels      string
@@ -40,7 +43,9 @@ var Cmd = &cobra.Command{
 	Example: `  #  Update the number of replicas for machine pool with ID 'a1b2'
   ocm edit machinepool --replicas=3 --cluster=mycluster a1b2
   # Enable autoscaling and Set 3-5 replicas on machine pool 'mp1' on cluster 'mycluster'
-  ocm edit machinepool --enable-autoscaling --min-replicas=3 max-replicas=5 --cluster=mycluster mp1`,
+  ocm edit machinepool --enable-autoscaling --min-replicas=3 max-replicas=5 --cluster=mycluster mp1
+  # Edit machine pool 'mp1' answering questions that show the current values
+  ocm edit machinepool --interactive --cluster=mycluster mp1`,
 	RunE: run,
 }
 
@@ -57,12 +62,15 @@ func init() {
 	//nolint:gosec
 	Cmd.MarkFlagRequired("cluster")
 
+	arguments.AddInteractiveFlag(flags, &args.interactive)
+
 	flags.IntVar(
 		&args.replicas,
 		"replicas",
 		-1,
 		"Count of machines for this machine pool.",
 	)
+	arguments.SetQuestion(flags, "replicas", "Replicas:")
 
 	arguments.AddAutoscalingFlags(flags, &args.autoscaling)
 
@@ -118,6 +126,13 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
 	}
 
+	if args.interactive {
+		err = promptEdit(cmd.Flags(), clusterCollection.Cluster(cluster.ID()), cluster, machinePoolID)
+		if err != nil {
+			return err
+		}
+	}
+
 	labels := make(map[string]string)
 	if args.labels != "" {
 		for _, label := range strings.Split(args.labels, ",") {
@@ -227,6 +242,98 @@ func run(cmd *cobra.Command, argv []string) error {
 	return nil
 }
 
+// promptEdit asks for the size, labels and taints of the machine pool, using the current values
+// as defaults.
+func promptEdit(fs *pflag.FlagSet, resource *cmv1.ClusterClient, cluster *cmv1.Cluster,
+	machinePoolID string) error {
+	var autoscaling *cmv1.MachinePoolAutoscaling
+	var replicas int
+	var labels map[string]string
+	var taints []*cmv1.Taint
+	if machinePoolID == "default" {
+		autoscaling = cluster.Nodes().AutoscaleCompute()
+		replicas = cluster.Nodes().Compute()
+	} else {
+		response, err := resource.MachinePools().MachinePool(machinePoolID).Get().Send()
+		if err != nil {
+			return fmt.Errorf("Failed to get machine pool '%s': %v", machinePoolID, err)
+		}
+		machinePool := response.Body()
+		autoscaling = machinePool.Autoscaling()
+		replicas = machinePool.Replicas()
+		labels = machinePool.Labels()
+		taints = machinePool.Taints()
+	}
+
+	// Use the current values as defaults of the questions:
+	if !fs.Changed("enable-autoscaling") && !fs.Changed("replicas") {
+		args.autoscaling.Enabled = autoscaling != nil
+	}
+	if !fs.Changed("replicas") {
+		args.replicas = replicas
+	}
+	if !fs.Changed("min-replicas") {
+		args.autoscaling.MinReplicas = autoscaling.MinReplicas()
+	}
+	if !fs.Changed("max-replicas") {
+		args.autoscaling.MaxReplicas = autoscaling.MaxReplicas()
+	}
+	if !fs.Changed("labels") {
+		args.labels = formatLabels(labels)
+	}
+	if !fs.Changed("taints") {
+		args.taints = formatTaints(taints)
+	}
+
+	err := arguments.PromptBool(fs, "enable-autoscaling")
+	if err != nil {
+		return err
+	}
+	if args.autoscaling.Enabled {
+		err = arguments.PromptInt(fs, "min-replicas", nil)
+		if err != nil {
+			return err
+		}
+		err = arguments.PromptInt(fs, "max-replicas", nil)
+	} else {
+		err = arguments.PromptInt(fs, "replicas", nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	// The labels and taints of the default machine pool can't be changed with this command:
+	if machinePoolID == "default" {
+		return nil
+	}
+	err = arguments.PromptString(fs, "labels")
+	if err != nil {
+		return err
+	}
+	return arguments.PromptString(fs, "taints")
+}
+
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	items := make([]string, len(keys))
+	for i, key := range keys {
+		items[i] = fmt.Sprintf("%s=%s", key, labels[key])
+	}
+	return strings.Join(items, ",")
+}
+
+func formatTaints(taints []*cmv1.Taint) string {
+	items := make([]string, len(taints))
+	for i, taint := range taints {
+		items[i] = fmt.Sprintf("%s=%s:%s", taint.Key(), taint.Value(), taint.Effect())
+	}
+	return strings.Join(items, ",")
+}
+
 func validateComputeNodes(nodes int, ccs bool, multiAZ bool) error {
 	var min int
 	if ccs {
@@ -0,0 +1,211 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package user
+
+import (
+	"fmt"
+	"strings"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	clusterKeys []string
+	group       string
+	fromFile    string
+	dryRun      bool
+	allowEmpty  bool
+}
+
+var Cmd = &cobra.Command{
+	Use:     "users --cluster={NAME|ID|EXTERNAL_ID} --from-file=FILE [flags]",
+	Aliases: []string{"user"},
+	Short:   "Sync the users of cluster groups",
+	Long: "Add and remove users of groups of one or more clusters so that they match the " +
+		"membership described in a file. The file contains either one user per line for the " +
+		"group given with --group, or, if it has the '.csv' extension, one user per row " +
+		"followed by the groups it belongs to. A CSV file given without --group describes all the " +
+		"groups of the cluster, so groups that aren't in it lose all their users. Removing all the " +
+		"users of a group requires --allow-empty.",
+	Example: `  # Sync the dedicated-admins group of a cluster with the users listed in a file
+  ocm edit users --cluster=mycluster --group=dedicated-admins --from-file=users.txt
+
+  # Show what would change in the groups of two clusters without changing them
+  ocm edit users --cluster=mycluster --cluster=othercluster --from-file=users.csv --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+
+	flags.StringSliceVarP(
+		&args.clusterKeys,
+		"cluster",
+		"c",
+		nil,
+		"Name or ID or external_id of the cluster to sync the groups of (required). "+
+			"Can be used multiple times.",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("cluster")
+
+	flags.StringVar(
+		&args.group,
+		"group",
+		"",
+		"Group to sync. Required when the file isn't a CSV file.",
+	)
+	flags.StringVar(
+		&args.fromFile,
+		"from-file",
+		"",
+		"File containing the users of the groups (required).",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("from-file")
+
+	flags.BoolVar(
+		&args.dryRun,
+		"dry-run",
+		false,
+		"Show the users that would be added and removed without changing them.",
+	)
+	flags.BoolVar(
+		&args.allowEmpty,
+		"allow-empty",
+		false,
+		"Allow removing all the users of a group.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster keys (names, identifiers or external identifiers) given by the user
+	// are reasonably safe so that there is no risk of SQL injection:
+	for _, clusterKey := range args.clusterKeys {
+		if !c.IsValidClusterKey(clusterKey) {
+			return fmt.Errorf(
+				"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+					"must contain only letters, digits, dashes and underscores",
+				clusterKey,
+			)
+		}
+	}
+
+	membership, err := c.LoadMembership(args.fromFile, args.group)
+	if err != nil {
+		return err
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	var clustersFailed []string
+	for _, clusterKey := range args.clusterKeys {
+		err = syncCluster(connection, clusterKey, membership)
+		if err != nil {
+			fmt.Printf("Failed to sync users of cluster '%s': %v\n", clusterKey, err)
+			clustersFailed = append(clustersFailed, clusterKey)
+		}
+	}
+
+	if len(clustersFailed) > 0 {
+		return fmt.Errorf("Failed to sync the users of the following clusters: %s",
+			strings.Join(clustersFailed, ", "))
+	}
+	return nil
+}
+
+func syncCluster(connection *sdk.Connection, clusterKey string, membership c.Membership) error {
+	// Get the client for the cluster management api
+	clusterCollection := connection.ClustersMgmt().V1().Clusters()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return err
+	}
+	if cluster.State() != cmv1.ClusterStateReady {
+		return fmt.Errorf("Cluster is not yet ready")
+	}
+
+	groups, err := c.GetGroups(clusterCollection, cluster.ID())
+	if err != nil {
+		return err
+	}
+	if c.IsMembershipCSV(args.fromFile) && args.group == "" {
+		membership = c.CompleteMembership(groups, membership)
+	}
+	changes, err := c.DiffMembership(groups, membership)
+	if err != nil {
+		return err
+	}
+	if !args.allowEmpty {
+		err = c.CheckEmptyGroups(changes, membership)
+		if err != nil {
+			return fmt.Errorf("%v, use --allow-empty if that is intended", err)
+		}
+	}
+
+	failed := 0
+	for _, change := range changes {
+		if change.Empty() {
+			fmt.Printf("Group '%s' of cluster '%s' is already in sync\n", change.Group, clusterKey)
+			continue
+		}
+		for _, user := range change.Add {
+			if args.dryRun {
+				fmt.Printf("Would add '%s' user '%s' to cluster '%s'\n", change.Group, user, clusterKey)
+				continue
+			}
+			err = c.AddGroupUser(clusterCollection, cluster.ID(), change.Group, user)
+			if err != nil {
+				fmt.Printf("Failed to add '%s' user '%s' to cluster '%s': %v\n",
+					change.Group, user, clusterKey, err)
+				failed++
+				continue
+			}
+			fmt.Printf("Added '%s' user '%s' to cluster '%s'\n", change.Group, user, clusterKey)
+		}
+		for _, user := range change.Remove {
+			if args.dryRun {
+				fmt.Printf("Would remove '%s' user '%s' from cluster '%s'\n", change.Group, user, clusterKey)
+				continue
+			}
+			err = c.RemoveGroupUser(clusterCollection, cluster.ID(), change.Group, user)
+			if err != nil {
+				fmt.Printf("Failed to remove '%s' user '%s' from cluster '%s': %v\n",
+					change.Group, user, clusterKey, err)
+				failed++
+				continue
+			}
+			fmt.Printf("Removed '%s' user '%s' from cluster '%s'\n", change.Group, user, clusterKey)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d user changes failed", failed)
+	}
+	return nil
+}
@@ -18,9 +18,11 @@ package job
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
+	"github.com/openshift-online/ocm-cli/pkg/job"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 )
 
@@ -28,9 +30,10 @@ import (
 var Cmd = &cobra.Command{
 	Use:   "job QUEUE_NAME JOB_ID RECEIPT_ID FAILURE_REASON",
 	Short: "Mark the Job as a failure",
-	Long:  "Mark the Job as a failure with specific reason on the specified Job Queue.",
-	Args:  cobra.ExactArgs(4),
-	RunE:  run,
+	Long: "Mark the Job as a failure with specific reason on the specified Job Queue and " +
+		"print the result in JSON format.",
+	Args: cobra.ExactArgs(4),
+	RunE: run,
 }
 
 func run(_ *cobra.Command, argv []string) error {
@@ -52,5 +55,11 @@ func run(_ *cobra.Command, argv []string) error {
 		return fmt.Errorf("unable to fail a Job: %v", err)
 	}
 
-	return nil
+	return job.PrintResult(os.Stdout, &job.Result{
+		Queue:         argv[0],
+		ID:            argv[1],
+		ReceiptID:     argv[2],
+		Status:        "failure",
+		FailureReason: argv[3],
+	})
 }
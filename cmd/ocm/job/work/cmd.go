@@ -0,0 +1,182 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package work
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/job"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	concurrency   int
+	timeout       time.Duration
+	extension     time.Duration
+	maxExtensions int
+	onTimeout     string
+	gracePeriod   time.Duration
+	pollInterval  time.Duration
+	exitWhenEmpty bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "work [flags] QUEUE_NAME -- COMMAND [ARGS...]",
+	Short: "Process the jobs of a queue running a command",
+	Long: "Pop jobs from the job queue and run the command for each of them. The job is passed " +
+		"to the command in the OCM_JOB_QUEUE, OCM_JOB_ID, OCM_JOB_RECEIPT_ID, OCM_JOB_ARGUMENTS " +
+		"and OCM_JOB_ATTEMPTS environment variables. The job is marked as successful if the " +
+		"command exits with code zero, and as failed otherwise.\n" +
+		"\n" +
+		"The first interrupt stops fetching new jobs and waits for the jobs in progress to " +
+		"finish. A second interrupt stops the commands in progress and abandons their jobs, " +
+		"which are delivered again once the queue considers them abandoned.",
+	Example: `  # Process the jobs of the 'my-queue' queue with a script, four at a time
+  ocm job work my-queue --concurrency 4 -- ./process.sh
+
+  # Process the jobs waiting in the queue and exit
+  ocm job work my-queue --exit-when-empty --timeout 10m -- ./process.sh`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.IntVar(
+		&args.concurrency,
+		"concurrency",
+		1,
+		"Number of jobs processed in parallel.",
+	)
+	fs.DurationVar(
+		&args.timeout,
+		"timeout",
+		0,
+		"Maximum time the command can run for a job. Defaults to the maximum run time of "+
+			"the queue.",
+	)
+	fs.DurationVar(
+		&args.extension,
+		"extend-by",
+		0,
+		"Time added to the timeout of a job that is still running when it times out. The "+
+			"job queue doesn't support extending jobs, so a job that runs longer than the "+
+			"maximum run time of the queue may be delivered again to another worker.",
+	)
+	fs.IntVar(
+		&args.maxExtensions,
+		"max-extensions",
+		1,
+		"Maximum number of times the timeout of a job is extended when --extend-by is used.",
+	)
+	fs.StringVar(
+		&args.onTimeout,
+		"on-timeout",
+		job.TimeoutFail,
+		fmt.Sprintf("Action taken when a job times out: '%s' marks it as failed, '%s' stops "+
+			"the command without reporting the result, so the job is delivered again.",
+			job.TimeoutFail, job.TimeoutAbandon),
+	)
+	fs.DurationVar(
+		&args.gracePeriod,
+		"grace-period",
+		10*time.Second,
+		"Time given to the command to finish after it is asked to stop, before it is killed.",
+	)
+	fs.DurationVar(
+		&args.pollInterval,
+		"poll-interval",
+		5*time.Second,
+		"Time to wait before checking again when the queue is empty.",
+	)
+	fs.BoolVar(
+		&args.exitWhenEmpty,
+		"exit-when-empty",
+		false,
+		"Exit when the queue is empty instead of waiting for new jobs.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	if cmd.ArgsLenAtDash() != 1 {
+		return fmt.Errorf("Expected the queue name followed by '--' and the command")
+	}
+	queueName := argv[0]
+	command := argv[1:]
+	if args.concurrency < 1 {
+		return fmt.Errorf("Concurrency must be at least 1")
+	}
+	if args.onTimeout != job.TimeoutFail && args.onTimeout != job.TimeoutAbandon {
+		return fmt.Errorf("Invalid --on-timeout value '%s', must be '%s' or '%s'",
+			args.onTimeout, job.TimeoutFail, job.TimeoutAbandon)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	timeout := args.timeout
+	if !cmd.Flags().Changed("timeout") {
+		queue, err := job.GetQueue(connection, queueName)
+		if err != nil {
+			return err
+		}
+		timeout = time.Duration(queue.MaxRunTime()) * time.Second
+	}
+
+	// The first signal stops fetching jobs, the second one aborts the jobs in progress:
+	stop, stopCancel := context.WithCancel(context.Background())
+	defer stopCancel()
+	abort, abortCancel := context.WithCancel(context.Background())
+	defer abortCancel()
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		fmt.Fprintf(os.Stderr, "Waiting for the jobs in progress to finish, interrupt again "+
+			"to abandon them\n")
+		stopCancel()
+		<-signals
+		abortCancel()
+	}()
+
+	stats := job.NewWorker(job.NewQueue(connection, queueName), command).
+		Concurrency(args.concurrency).
+		Timeout(timeout).
+		Extensions(args.maxExtensions, args.extension).
+		OnTimeout(args.onTimeout).
+		GracePeriod(args.gracePeriod).
+		PollInterval(args.pollInterval).
+		ExitWhenEmpty(args.exitWhenEmpty).
+		Output(os.Stderr).
+		Run(stop, abort)
+	fmt.Fprintf(os.Stderr, "Processed %d jobs: %d succeeded, %d failed, %d abandoned\n",
+		stats.Succeeded+stats.Failed+stats.Abandoned, stats.Succeeded, stats.Failed,
+		stats.Abandoned)
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	"os"
 	"strings"
 
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/spf13/cobra"
 
@@ -29,15 +30,17 @@ import (
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	"github.com/openshift-online/ocm-cli/pkg/output"
+	"github.com/openshift-online/ocm-cli/pkg/region"
 )
 
 var args struct {
-	parameter []string
-	header    []string
-	managed   bool
-	noHeaders bool
-	columns   string
-	padding   int
+	parameter  []string
+	header     []string
+	managed    bool
+	noHeaders  bool
+	columns    string
+	padding    int
+	allRegions bool
 }
 
 // Cmd Constant:
@@ -45,9 +48,12 @@ var Cmd = &cobra.Command{
 	Use:     "clusters [flags] [PARTIAL_CLUSTER_ID_OR_NAME]",
 	Aliases: []string{"cluster"},
 	Short:   "List clusters",
-	Long:    "List clusters, optionally filtering by substring of ID or Name",
-	Args:    cobra.RangeArgs(0, 1),
-	RunE:    run,
+	Long: "List clusters, optionally filtering by substring of ID or Name. With --all-regions " +
+		"the clusters of all the regions of the environment are listed, with a REGION column.",
+	Example: `  # List the clusters of all the regions
+  ocm list clusters --all-regions`,
+	Args: cobra.RangeArgs(0, 1),
+	RunE: run,
 }
 
 func init() {
@@ -85,6 +91,7 @@ func init() {
 		-1,
 		"Change all column sizes.",
 	)
+	region.AddAllFlag(fs, &args.allRegions, "clusters")
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -114,10 +121,19 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 	defer printer.Close()
 
-	// Create the output table:
+	// Create the output table. When listing all the regions the region of each cluster is
+	// added as the first column:
+	columns := args.columns
+	regionOf := map[string]string{}
+	if args.allRegions {
+		columns = "rh_region, " + columns
+	}
 	table, err := printer.NewTable().
 		Name("clusters").
-		Columns(args.columns).
+		Columns(columns).
+		Value("rh_region", func(cluster *v1.Cluster) string {
+			return regionOf[cluster.ID()]
+		}).
 		Build(ctx)
 	if err != nil {
 		return err
@@ -178,9 +194,43 @@ func run(cmd *cobra.Command, argv []string) error {
 		table.WriteHeaders()
 	}
 
+	if !args.allRegions {
+		return fetchClusters(connection, searchQuery, func(cluster *v1.Cluster) error {
+			return table.WriteObject(cluster)
+		})
+	}
+
+	// Fetch the clusters of all the regions, then write them in the order of the regions:
+	results, err := ocm.ListAllRegions("clusters", func(connection *sdk.Connection) ([]*v1.Cluster,
+		error) {
+		var clusters []*v1.Cluster
+		err := fetchClusters(connection, searchQuery, func(cluster *v1.Cluster) error {
+			clusters = append(clusters, cluster)
+			return nil
+		})
+		return clusters, err
+	})
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		for _, cluster := range result.Items {
+			regionOf[cluster.ID()] = result.Region
+			err = table.WriteObject(cluster)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fetchClusters retrieves the clusters matching the search query page by page, and calls the given
+// function for each of them.
+func fetchClusters(connection *sdk.Connection, search string, each func(*v1.Cluster) error) error {
 	// Create the request. Note that this request can be created outside of the loop and used
 	// for all the iterations just changing the values of the `size` and `page` parameters.
-	request := connection.ClustersMgmt().V1().Clusters().List().Search(searchQuery)
+	request := connection.ClustersMgmt().V1().Clusters().List().Search(search)
 	arguments.ApplyParameterFlag(request, args.parameter)
 	arguments.ApplyHeaderFlag(request, args.header)
 
@@ -196,13 +246,13 @@ func run(cmd *cobra.Command, argv []string) error {
 			return fmt.Errorf("Can't retrieve clusters: %v", err)
 		}
 
-		// Display the items of the fetched page:
+		// Process the items of the fetched page:
 		response.Items().Each(func(cluster *v1.Cluster) bool {
-			err = table.WriteObject(cluster)
+			err = each(cluster)
 			return err == nil
 		})
 		if err != nil {
-			break
+			return err
 		}
 
 		// If the number of fetched items is less than requested, then this was the last
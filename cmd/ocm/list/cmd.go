@@ -18,6 +18,7 @@ import (
 	"github.com/openshift-online/ocm-cli/cmd/ocm/list/cluster"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/list/idp"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/list/ingress"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/list/job"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/list/machinepool"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/list/org"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/list/quota"
@@ -40,6 +41,7 @@ func init() {
 	Cmd.AddCommand(cluster.Cmd)
 	Cmd.AddCommand(idp.Cmd)
 	Cmd.AddCommand(ingress.Cmd)
+	Cmd.AddCommand(job.Cmd)
 	Cmd.AddCommand(org.Cmd)
 	Cmd.AddCommand(machinepool.Cmd)
 	Cmd.AddCommand(quota.Cmd)
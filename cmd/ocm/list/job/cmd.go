@@ -0,0 +1,134 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/job"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/output"
+)
+
+var args struct {
+	queue   string
+	columns string
+}
+
+var Cmd = &cobra.Command{
+	Use:     "jobs --queue=QUEUE_NAME",
+	Aliases: []string{"job"},
+	Short:   "List jobs of a job queue",
+	Long: "List the jobs of a job queue, with the number of attempts, the time when they are " +
+		"considered abandoned and their receipt, to find jobs that are stuck.",
+	Example: `  # List the jobs of the queue 'my-queue'
+  ocm list jobs --queue=my-queue
+
+  # Include the arguments of the jobs
+  ocm list jobs --queue=my-queue --columns id,attempts,abandoned_at,arguments`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.StringVarP(
+		&args.queue,
+		"queue",
+		"q",
+		"",
+		"Name of the job queue to list the jobs of (required).",
+	)
+	fs.StringVar(
+		&args.columns,
+		"columns",
+		"id, attempts, created_at, abandoned_at, receipt_id",
+		"Comma separated list of columns to display. Available columns are 'id', "+
+			"'attempts', 'created_at', 'updated_at', 'abandoned_at', 'receipt_id' and "+
+			"'arguments'.",
+	)
+
+	//nolint:gosec
+	Cmd.MarkFlagRequired("queue")
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Create a context:
+	ctx := context.Background()
+
+	// Load the configuration:
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	jobs, err := job.ListJobs(connection, args.queue)
+	if err != nil {
+		return err
+	}
+	if len(jobs) == 0 {
+		fmt.Printf("There are no jobs in queue '%s'\n", args.queue)
+		return nil
+	}
+
+	// Create the output printer:
+	printer, err := output.NewPrinter().
+		Writer(os.Stdout).
+		Pager(cfg.Pager).
+		Build(ctx)
+	if err != nil {
+		return err
+	}
+	defer printer.Close()
+
+	// Create the output table:
+	table, err := printer.NewTable().
+		Name("jobs").
+		Columns(args.columns).
+		Build(ctx)
+	if err != nil {
+		return err
+	}
+	defer table.Close()
+
+	// Write the column headers:
+	err = table.WriteHeaders()
+	if err != nil {
+		return err
+	}
+
+	// Write the rows:
+	for _, item := range jobs {
+		err = table.WriteObject(job.NewRow(item))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
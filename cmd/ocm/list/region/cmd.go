@@ -21,6 +21,9 @@ import (
 	"github.com/openshift-online/ocm-cli/pkg/cluster"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	"github.com/openshift-online/ocm-cli/pkg/provider"
+	"github.com/openshift-online/ocm-cli/pkg/region"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/spf13/cobra"
 )
 
@@ -29,6 +32,7 @@ var args struct {
 	ccs                bool
 	awsAccessKeyID     string
 	awsSecretAccessKey string
+	allRegions         bool
 }
 
 var Cmd = &cobra.Command{
@@ -72,6 +76,8 @@ func init() {
 		"AWS Secret Access",
 	)
 
+	region.AddAllFlag(fs, &args.allRegions, "cloud provider regions")
+
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -91,42 +97,64 @@ func run(cmd *cobra.Command, argv []string) error {
 			},
 		}
 	}
-	connection, err := ocm.NewConnection().Build()
-	if err != nil {
-		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	list := func(connection *sdk.Connection) ([]*cmv1.CloudRegion, error) {
+		return provider.GetRegions(connection.ClustersMgmt().V1(), args.provider, ccs)
 	}
-	defer connection.Close()
-
-	regions, err := provider.GetRegions(connection.ClustersMgmt().V1(), args.provider, ccs)
-	if err != nil {
-		return err
+	var results []ocm.RegionItems[*cmv1.CloudRegion]
+	if args.allRegions {
+		var err error
+		results, err = ocm.ListAllRegions("cloud provider regions", list)
+		if err != nil {
+			return err
+		}
+	} else {
+		connection, err := ocm.NewConnection().Build()
+		if err != nil {
+			return fmt.Errorf("Failed to create OCM connection: %v", err)
+		}
+		defer connection.Close()
+		regions, err := list(connection)
+		if err != nil {
+			return err
+		}
+		results = append(results, ocm.RegionItems[*cmv1.CloudRegion]{Items: regions})
 	}
 
-	// Create the writer that will be used to print the tabulated results:
+	// Create the writer that will be used to print the tabulated results. When listing all the
+	// regions the region of the API is added as the first column:
 	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.TabIndent)
+	prefix := func(name string) string {
+		if args.allRegions {
+			return name + "\t\t"
+		}
+		return ""
+	}
 
 	//We display only the enabled region for both ccs and non ccs regions
 	if args.provider == "aws" && args.ccs {
-		fmt.Fprintf(writer, "ID\t\tSUPPORTS MULTI-AZ\n")
-		for _, region := range regions {
-			if !region.Enabled() {
-				continue
+		fmt.Fprintf(writer, "%sID\t\tSUPPORTS MULTI-AZ\n", prefix("REGION"))
+		for _, result := range results {
+			for _, item := range result.Items {
+				if !item.Enabled() {
+					continue
+				}
+				fmt.Fprintf(writer, "%s%s\t\t%v\n",
+					prefix(result.Region), item.ID(), item.SupportsMultiAZ())
 			}
-			fmt.Fprintf(writer, "%s\t\t%v\n",
-				region.ID(), region.SupportsMultiAZ())
 		}
 	} else {
-		fmt.Fprintf(writer, "ID\t\tON RED HAT INFRA\t\tCCS ONLY\t\tSUPPORTS MULTI-AZ\n")
-		for _, region := range regions {
-			if !region.Enabled() {
-				continue
+		fmt.Fprintf(writer, "%sID\t\tON RED HAT INFRA\t\tCCS ONLY\t\tSUPPORTS MULTI-AZ\n",
+			prefix("REGION"))
+		for _, result := range results {
+			for _, item := range result.Items {
+				if !item.Enabled() {
+					continue
+				}
+				fmt.Fprintf(writer, "%s%s\t\t%v\t\t%v\t\t%v\n", prefix(result.Region),
+					item.ID(), !item.CCSOnly(), item.CCSOnly(), item.SupportsMultiAZ())
 			}
-			fmt.Fprintf(writer, "%s\t\t%v\t\t%v\t\t%v\n",
-				region.ID(), !region.CCSOnly(), region.CCSOnly(), region.SupportsMultiAZ())
 		}
-
 	}
 
-	err = writer.Flush()
-	return err
+	return writer.Flush()
 }
@@ -17,10 +17,19 @@ limitations under the License.
 package version
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/region"
+	"github.com/openshift-online/ocm-cli/pkg/utils"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/spf13/cobra"
 )
 
@@ -28,6 +37,12 @@ var args struct {
 	defaultVersion bool
 	channelGroup   string
 	marketplaceGcp string
+	matrix         bool
+	min            string
+	max            string
+	product        string
+	json           bool
+	allRegions     bool
 }
 
 var Cmd = &cobra.Command{
@@ -36,7 +51,16 @@ var Cmd = &cobra.Command{
 	Short:   "List available versions",
 	Long:    "List the versions available for provisioning a cluster",
 	Example: `  # List all supported cluster versions
-  ocm list versions`,
+  ocm list versions
+
+  # Show which channel groups and products offer each 4.15 version
+  ocm list versions --matrix --min 4.15.0 --max 4.15.99
+
+  # Show the versions enabled for ROSA in JSON format
+  ocm list versions --matrix --product rosa --json
+
+  # Compare the versions available in all the regions
+  ocm list versions --all-regions`,
 	Args: cobra.NoArgs,
 	RunE: run,
 }
@@ -63,9 +87,100 @@ func init() {
 		"",
 		"List only versions that support 'marketplace-gcp' subscription type",
 	)
+	fs.BoolVar(
+		&args.matrix,
+		"matrix",
+		false,
+		"Show the channel groups, products, end of life and available upgrades of each version. "+
+			"Versions of all channel groups are shown unless --channel-group is used. When the "+
+			"default and product flags differ between channel groups, the groups where they are "+
+			"set are shown.",
+	)
+	fs.StringVar(
+		&args.min,
+		"min",
+		"",
+		"Show only versions greater than or equal to this one. Versions that can't be parsed "+
+			"are excluded. Requires --matrix.",
+	)
+	fs.StringVar(
+		&args.max,
+		"max",
+		"",
+		"Show only versions less than or equal to this one. Versions that can't be parsed "+
+			"are excluded. Requires --matrix.",
+	)
+	fs.StringVar(
+		&args.product,
+		"product",
+		"",
+		fmt.Sprintf("Show only versions enabled for the product. Supported options are: %s. "+
+			"Requires --matrix.", utils.SliceToSortedString(cluster.MatrixProducts)),
+	)
+	fs.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the version matrix in JSON format. Requires --matrix.",
+	)
+	region.AddAllFlag(fs, &args.allRegions, "versions")
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	if !args.matrix {
+		for _, flag := range []string{"min", "max", "product", "json"} {
+			if cmd.Flags().Changed(flag) {
+				return fmt.Errorf("--%s flag is meaningless without --matrix", flag)
+			}
+		}
+	}
+	if args.product != "" && !utils.Contains(cluster.MatrixProducts, args.product) {
+		return fmt.Errorf("Unknown product '%s', supported options are: %s",
+			args.product, utils.SliceToSortedString(cluster.MatrixProducts))
+	}
+
+	channelGroup := ""
+	if cmd.Flags().Changed("channel-group") {
+		channelGroup = args.channelGroup
+	}
+
+	// When listing all the regions the versions of each region are retrieved separately, and
+	// the region is added as the first column:
+	if args.allRegions {
+		if args.matrix {
+			results, err := ocm.ListAllRegions("versions", func(connection *sdk.Connection) (
+				[]*cluster.VersionMatrixRow, error) {
+				return getMatrix(connection.ClustersMgmt().V1(), channelGroup)
+			})
+			if err != nil {
+				return err
+			}
+			var rows []*cluster.VersionMatrixRow
+			for _, result := range results {
+				for _, row := range result.Items {
+					row.Region = result.Region
+					rows = append(rows, row)
+				}
+			}
+			return printMatrix(rows)
+		}
+		results, err := ocm.ListAllRegions("versions", func(connection *sdk.Connection) ([]string,
+			error) {
+			return getVersions(connection.ClustersMgmt().V1())
+		})
+		if err != nil {
+			return err
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(writer, "REGION\tVERSION\n")
+		for _, result := range results {
+			for _, version := range result.Items {
+				fmt.Fprintf(writer, "%s\t%s\n", result.Region, version)
+			}
+		}
+		return writer.Flush()
+	}
+
 	// Create the client for the OCM API:
 	connection, err := ocm.NewConnection().Build()
 	if err != nil {
@@ -74,18 +189,125 @@ func run(cmd *cobra.Command, argv []string) error {
 	defer connection.Close()
 
 	client := connection.ClustersMgmt().V1()
-	versions, defaultVersion, err := cluster.GetEnabledVersions(client, args.channelGroup, args.marketplaceGcp, "")
+
+	if args.matrix {
+		rows, err := getMatrix(client, channelGroup)
+		if err != nil {
+			return err
+		}
+		return printMatrix(rows)
+	}
+
+	versions, err := getVersions(client)
 	if err != nil {
-		return fmt.Errorf("Can't retrieve versions: %v", err)
+		return err
 	}
+	for _, v := range versions {
+		fmt.Println(v)
+	}
+
+	return nil
+}
 
+// getVersions returns the enabled versions, or only the default one if the --default flag is used.
+func getVersions(client *cmv1.Client) ([]string, error) {
+	versions, defaultVersion, err := cluster.GetEnabledVersions(client, args.channelGroup, args.marketplaceGcp, "")
+	if err != nil {
+		return nil, fmt.Errorf("Can't retrieve versions: %v", err)
+	}
 	if args.defaultVersion {
-		fmt.Println(defaultVersion)
-	} else {
-		for _, v := range versions {
-			fmt.Println(v)
+		return []string{defaultVersion}, nil
+	}
+	return versions, nil
+}
+
+func getMatrix(client *cmv1.Client, channelGroup string) ([]*cluster.VersionMatrixRow, error) {
+	filter := "enabled = 'true'"
+	if channelGroup != "" {
+		filter = fmt.Sprintf("%s AND channel_group = '%s'", filter, channelGroup)
+	}
+	if args.marketplaceGcp != "" {
+		filter = fmt.Sprintf("%s AND gcp_marketplace_enabled = '%s'", filter, args.marketplaceGcp)
+	}
+	versions, err := cluster.GetVersions(client, filter)
+	if err != nil {
+		return nil, fmt.Errorf("Can't retrieve versions: %v", err)
+	}
+	return cluster.BuildVersionMatrix(versions, cluster.VersionMatrixFilter{
+		Min:     args.min,
+		Max:     args.max,
+		Product: args.product,
+	})
+}
+
+func printMatrix(rows []*cluster.VersionMatrixRow) error {
+	if args.json {
+		data, err := json.Marshal(rows)
+		if err != nil {
+			return fmt.Errorf("Failed to marshal version matrix: %v", err)
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+
+	prefix := ""
+	if args.allRegions {
+		prefix = "REGION\t"
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "%sVERSION\tCHANNEL GROUPS\tDEFAULT\tROSA\tHCP\tGCP MARKETPLACE\tEND OF LIFE\tUPGRADES\n",
+		prefix)
+	for _, row := range rows {
+		endOfLife := ""
+		if row.EndOfLife != nil {
+			endOfLife = row.EndOfLife.Format("2006-01-02")
 		}
+		if args.allRegions {
+			fmt.Fprintf(writer, "%s\t", row.Region)
+		}
+		names := make([]string, len(row.ChannelGroups))
+		for i, group := range row.ChannelGroups {
+			names[i] = group.Name
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			row.Version,
+			strings.Join(names, ","),
+			groupsWith(row, func(group *cluster.VersionMatrixChannelGroup) bool {
+				return group.Default
+			}),
+			groupsWith(row, func(group *cluster.VersionMatrixChannelGroup) bool {
+				return group.ROSAEnabled
+			}),
+			groupsWith(row, func(group *cluster.VersionMatrixChannelGroup) bool {
+				return group.HCPEnabled
+			}),
+			groupsWith(row, func(group *cluster.VersionMatrixChannelGroup) bool {
+				return group.GCPMarketplaceEnabled
+			}),
+			endOfLife,
+			strings.Join(row.AvailableUpgrades, ","),
+		)
 	}
+	//nolint:gosec
+	writer.Flush()
 
 	return nil
 }
+
+// groupsWith returns 'yes' if the flag is set in all the channel groups of the row, 'no' if it
+// isn't set in any of them, and otherwise the names of the channel groups where it is set.
+func groupsWith(row *cluster.VersionMatrixRow, flag func(*cluster.VersionMatrixChannelGroup) bool) string {
+	var names []string
+	for _, group := range row.ChannelGroups {
+		if flag(group) {
+			names = append(names, group.Name)
+		}
+	}
+	switch len(names) {
+	case 0:
+		return "no"
+	case len(row.ChannelGroups):
+		return "yes"
+	default:
+		return strings.Join(names, ",")
+	}
+}
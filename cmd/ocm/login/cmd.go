@@ -27,10 +27,10 @@ import (
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	"github.com/openshift-online/ocm-cli/pkg/properties"
+	"github.com/openshift-online/ocm-cli/pkg/region"
 	"github.com/openshift-online/ocm-cli/pkg/urls"
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	"github.com/openshift-online/ocm-sdk-go/authentication"
-	"github.com/openshift-online/ocm-sdk-go/authentication/securestore"
 	"github.com/spf13/cobra"
 )
 
@@ -47,11 +47,12 @@ var args struct {
 	token         string
 	user          string
 	password      string
-	rhRegion      string
 	insecure      bool
 	persistent    bool
 	useAuthCode   bool
 	useDeviceCode bool
+
+	credentialHelper string
 }
 
 var Cmd = &cobra.Command{
@@ -105,15 +106,6 @@ func init() {
 			"file or "+sdk.DefaultURL+" as a last resort. The value should be a complete URL "+
 			"or a valid URL alias: "+strings.Join(urls.ValidOCMUrlAliases(), ", "),
 	)
-	flags.StringVar(
-		&args.rhRegion,
-		"rh-region",
-		"",
-		"OCM data sovereignty region identifier. --url will be used to initiate a service discovery "+
-			"request to find the region URL matching the provided identifier. Use `ocm list rh-regions` "+
-			"to see available regions.",
-	)
-	flags.MarkHidden("rh-region")
 	flags.StringVar(
 		&args.token,
 		"token",
@@ -148,6 +140,15 @@ func init() {
 			"this option is provided then the user name and password will be stored "+
 			"persistently, in clear text, which is potentially unsafe.",
 	)
+	flags.StringVar(
+		&args.credentialHelper,
+		"credential-helper",
+		"",
+		"Command that prints the access token, refresh token or client credentials as a JSON "+
+			"object, for example '{\"refresh_token\": \"...\"}'. The command runs whenever "+
+			"credentials are needed and they are kept only in memory, so no token or secret is "+
+			"saved to the configuration. Arguments can be quoted like in a shell.",
+	)
 	flags.BoolVar(
 		&args.useAuthCode,
 		"use-auth-code",
@@ -175,11 +176,9 @@ func run(cmd *cobra.Command, argv []string) error {
 	var err error
 
 	// Fail fast if OCM_KEYRING is provided and invalid
-	if keyring, ok := config.IsKeyringManaged(); ok {
-		err := securestore.ValidateBackend(keyring)
-		if err != nil {
-			return err
-		}
+	err = config.CurrentStore().Validate()
+	if err != nil {
+		return err
 	}
 
 	if args.useAuthCode {
@@ -218,12 +217,14 @@ func run(cmd *cobra.Command, argv []string) error {
 	havePassword := args.user != "" && args.password != ""
 	haveClientCreds := args.clientID != "" && args.clientSecret != ""
 	haveToken := args.token != ""
-	if !havePassword && !haveClientCreds && !haveToken {
+	haveHelper := args.credentialHelper != ""
+	if !havePassword && !haveClientCreds && !haveToken && !haveHelper {
 		// Allow bare `ocm login` to suggest the token page without noise of full help.
 		fmt.Fprintf(
 			os.Stderr,
 			"In order to log in it is mandatory to use '--token', '--user' and "+
-				"'--password', or '--client-id' and '--client-secret'.\n"+
+				"'--password', '--client-id' and '--client-secret', or "+
+				"'--credential-helper'.\n"+
 				"You can obtain a token at: %s .\n"+
 				"See 'ocm login --help' for full help.\n",
 			urls.OfflineTokenPage,
@@ -296,23 +297,23 @@ func run(cmd *cobra.Command, argv []string) error {
 		return err
 	}
 
-	// If an --rh-region is provided, --url is resolved as above and then used to initiate
-	// service discovery for the environment --url is a part of, but the gatewayURL (and
-	// ultimately the cfg.URL) is then updated to the URL of the matching --rh-region:
+	// If the global --rh-region flag is provided, --url is resolved as above and then used to
+	// initiate service discovery for the environment --url is a part of, but the gatewayURL (and
+	// ultimately the cfg.URL) is then updated to the URL of the matching region:
 	//   1. resolve the gatewayURL as above
-	//   2. fetch a well-known file from sdk.GetRhRegion
-	//   3. update the gatewayURL to the region URL matching args.rhRegion
+	//   2. fetch a well-known file from sdk.GetRhRegions
+	//   3. update the gatewayURL to the region URL matching the --rh-region flag
 	//
 	// So `--url=https://api.stage.openshift.com --rh-region=singapore` might result in
 	// gatewayURL/cfg.URL being mutated to "https://api.singapore.stage.openshift.com"
 	//
 	// See ocm-sdk-go/rh_region.go for full details on how service discovery works.
-	if args.rhRegion != "" {
-		regValue, err := sdk.GetRhRegion(gatewayURL, args.rhRegion)
+	if name := region.Selected(); name != "" {
+		regional, err := region.Find(gatewayURL, name)
 		if err != nil {
-			return fmt.Errorf("Can't find region: %w", err)
+			return err
 		}
-		gatewayURL = fmt.Sprintf("https://%s", regValue.URL)
+		gatewayURL = regional.URL
 	}
 
 	if overrideUrl := os.Getenv(properties.URLEnvKey); overrideUrl != "" {
@@ -328,6 +329,7 @@ func run(cmd *cobra.Command, argv []string) error {
 	cfg.User = args.user
 	cfg.Password = args.password
 	cfg.Insecure = args.insecure
+	cfg.CredentialHelper = args.credentialHelper
 
 	// Create a connection and get the token to verify that the crendentials are correct:
 	connection, err := ocm.NewConnection().Config(cfg).Build()
@@ -22,7 +22,6 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/openshift-online/ocm-cli/pkg/config"
-	"github.com/openshift-online/ocm-sdk-go/authentication/securestore"
 )
 
 var Cmd = &cobra.Command{
@@ -35,10 +34,10 @@ var Cmd = &cobra.Command{
 
 func run(cmd *cobra.Command, argv []string) error {
 
-	if keyring, ok := config.IsKeyringManaged(); ok {
-		err := securestore.RemoveConfigFromKeyring(keyring)
+	if store := config.CurrentStore(); store.Kind != config.StorePlaintext {
+		err := store.Remove()
 		if err != nil {
-			return fmt.Errorf("can't remove configuration from keyring: %w", err)
+			return fmt.Errorf("can't remove configuration from %s: %w", store, err)
 		}
 		return nil
 	}
@@ -0,0 +1,35 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinepool
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/cmd/ocm/machinepool/migrate"
+)
+
+var Cmd = &cobra.Command{
+	Use:     "machinepool COMMAND",
+	Aliases: []string{"machine-pool", "machinepools", "machine-pools"},
+	Short:   "Manage machine pools",
+	Long:    "Perform operations on the machine pools of a cluster.",
+	Args:    cobra.NoArgs,
+}
+
+func init() {
+	Cmd.AddCommand(migrate.Cmd)
+}
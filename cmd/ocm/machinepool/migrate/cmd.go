@@ -0,0 +1,248 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	clusterKey   string
+	from         string
+	to           string
+	instanceType string
+	step         int
+	interval     time.Duration
+	timeout      time.Duration
+	dryRun       bool
+	resume       bool
+	checkpoint   string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "migrate --cluster={NAME|ID|EXTERNAL_ID} --from=MACHINE_POOL_ID --to-instance-type=TYPE",
+	Short: "Move the nodes of a machine pool to a new instance type",
+	Long: "Move the nodes of a machine pool to a new machine pool with a different instance type.\n\n" +
+		"The new machine pool is created with the same labels, taints and placement as the\n" +
+		"original one. It is scaled up in steps, and after the new nodes are ready the same\n" +
+		"number of nodes is removed from the original machine pool. Finally the original\n" +
+		"machine pool is deleted. If the original machine pool is autoscaled, autoscaling is\n" +
+		"disabled keeping its current number of nodes first, and enabled in the new one at the end.\n" +
+		"Other machine pools of the cluster can't be autoscaled during the migration, as the nodes\n" +
+		"of each machine pool are calculated from the compute nodes of the cluster.\n\n" +
+		"Progress is saved before the first step and after each step, so an interrupted migration\n" +
+		"can be continued with the --resume flag.",
+	Example: `  # Show the steps needed to move machine pool 'workers' to instance type 'm5.2xlarge'
+  ocm machinepool migrate --cluster=mycluster --from=workers --to-instance-type=m5.2xlarge --dry-run
+
+  # Move the nodes two at a time
+  ocm machinepool migrate --cluster=mycluster --from=workers --to-instance-type=m5.2xlarge --step=2
+
+  # Continue an interrupted migration
+  ocm machinepool migrate --cluster=mycluster --from=workers --resume`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVarP(
+		&args.clusterKey,
+		"cluster",
+		"c",
+		"",
+		"Name or ID or external_id of the cluster (required).",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("cluster")
+	flags.StringVar(
+		&args.from,
+		"from",
+		"",
+		"Identifier of the machine pool to migrate (required).",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("from")
+	flags.StringVar(
+		&args.instanceType,
+		"to-instance-type",
+		"",
+		"Instance type of the new machine pool (required unless --resume is used).",
+	)
+	flags.StringVar(
+		&args.to,
+		"to",
+		"",
+		"Identifier of the new machine pool. The default is the identifier of the original "+
+			"machine pool followed by the instance type.",
+	)
+	flags.IntVar(
+		&args.step,
+		"step",
+		1,
+		"Maximum number of nodes moved in each step.",
+	)
+	flags.DurationVar(
+		&args.interval,
+		"interval",
+		30*time.Second,
+		"How often to check the number of nodes while waiting.",
+	)
+	flags.DurationVar(
+		&args.timeout,
+		"timeout",
+		30*time.Minute,
+		"How long to wait for the nodes of each step.",
+	)
+	flags.BoolVar(
+		&args.dryRun,
+		"dry-run",
+		false,
+		"Print the steps of the migration without changing anything.",
+	)
+	flags.BoolVar(
+		&args.resume,
+		"resume",
+		false,
+		"Continue a migration that was interrupted, using the saved checkpoint.",
+	)
+	flags.StringVar(
+		&args.checkpoint,
+		"checkpoint",
+		"",
+		"File where the progress of the migration is saved. The default is a file in the "+
+			"'ocm/migrations' directory of the user configuration directory.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := args.clusterKey
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+	if !args.resume && args.instanceType == "" {
+		return fmt.Errorf("Flag --to-instance-type is required unless --resume is used")
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+	clustersClient := connection.ClustersMgmt().V1().Clusters()
+
+	client := c.NewMigrationClient(clustersClient, cluster.ID())
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	// Load the checkpoint of a previous run, if any:
+	path := args.checkpoint
+	if path == "" {
+		path, err = c.MigrationCheckpointPath(cluster.ID(), args.from)
+		if err != nil {
+			return err
+		}
+	}
+	migration, err := c.LoadMigration(path)
+	if err != nil {
+		return err
+	}
+	switch {
+	case migration != nil && !args.resume:
+		return fmt.Errorf("There is an unfinished migration of machine pool '%s' saved in '%s', "+
+			"use --resume to continue it or remove the file to start again", args.from, path)
+	case migration == nil && args.resume:
+		return fmt.Errorf("There is no migration of machine pool '%s' to resume, checkpoint "+
+			"'%s' doesn't exist", args.from, path)
+	case migration != nil:
+		if migration.ClusterID != cluster.ID() || migration.From != args.from {
+			return fmt.Errorf("Checkpoint '%s' is for machine pool '%s' of cluster '%s'",
+				path, migration.From, migration.ClusterID)
+		}
+		if args.instanceType != "" && args.instanceType != migration.InstanceType {
+			return fmt.Errorf("Checkpoint '%s' is a migration to instance type '%s', not '%s'",
+				path, migration.InstanceType, args.instanceType)
+		}
+	default:
+		response, err := clustersClient.Cluster(cluster.ID()).
+			MachinePools().
+			MachinePool(args.from).
+			Get().
+			Send()
+		if err != nil {
+			return fmt.Errorf("Failed to get machine pool '%s' of cluster '%s': %v",
+				args.from, clusterKey, err)
+		}
+		nodes, err := client.PoolNodes(ctx, args.from)
+		if err != nil {
+			return err
+		}
+		to := args.to
+		if to == "" {
+			to = c.MigrationPoolID(args.from, args.instanceType)
+		}
+		migration, err = c.PlanMigration(cluster.ID(), response.Body(), nodes, to,
+			args.instanceType, args.step)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.dryRun {
+		c.PrintMigration(os.Stdout, migration)
+		return nil
+	}
+
+	err = c.NewMigrator(client, migration).
+		Checkpoint(func(m *c.Migration) error {
+			return c.SaveMigration(path, m)
+		}).
+		Interval(args.interval).
+		Timeout(args.timeout).
+		Output(os.Stdout).
+		Run(ctx)
+	if err != nil {
+		return fmt.Errorf("%v\nProgress is saved in '%s', run the command again with --resume "+
+			"to continue", err, path)
+	}
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Failed to remove migration checkpoint '%s': %v", path, err)
+	}
+	return nil
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/openshift-online/ocm-cli/cmd/ocm/account"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/auth"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/completion"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/config"
@@ -39,9 +40,12 @@ import (
 	"github.com/openshift-online/ocm-cli/cmd/ocm/gcp"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/get"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/hibernate"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/job"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/list"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/login"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/logout"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/machinepool"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/network"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/patch"
 	plugincmd "github.com/openshift-online/ocm-cli/cmd/ocm/plugin"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/pop"
@@ -51,6 +55,7 @@ import (
 	"github.com/openshift-online/ocm-cli/cmd/ocm/success"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/token"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/tunnel"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/upgrade"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/version"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/whoami"
 
@@ -81,9 +86,11 @@ func init() {
 	// Add the command line flags:
 	fs := root.PersistentFlags()
 	arguments.AddDebugFlag(fs)
+	arguments.AddRegionFlag(fs)
 
 	// Register the subcommands:
 	root.AddCommand(account.Cmd)
+	root.AddCommand(auth.Cmd)
 	root.AddCommand(cluster.Cmd)
 	root.AddCommand(completion.Cmd)
 	root.AddCommand(config.Cmd)
@@ -94,9 +101,12 @@ func init() {
 	root.AddCommand(fail.Cmd)
 	root.AddCommand(get.Cmd)
 	root.AddCommand(hibernate.Cmd)
+	root.AddCommand(job.Cmd)
 	root.AddCommand(list.Cmd)
 	root.AddCommand(login.Cmd)
 	root.AddCommand(logout.Cmd)
+	root.AddCommand(machinepool.Cmd)
+	root.AddCommand(network.Cmd)
 	root.AddCommand(patch.Cmd)
 	root.AddCommand(plugincmd.Cmd)
 	root.AddCommand(post.Cmd)
@@ -106,6 +116,7 @@ func init() {
 	root.AddCommand(success.Cmd)
 	root.AddCommand(token.Cmd)
 	root.AddCommand(tunnel.Cmd)
+	root.AddCommand(upgrade.Cmd)
 	root.AddCommand(version.Cmd)
 	root.AddCommand(whoami.Cmd)
 	root.AddCommand(gcp.NewGcpCmd())
@@ -122,6 +133,10 @@ func main() {
 	}
 	args := os.Args
 	pluginHandler := plugin.NewDefaultPluginHandler([]string{"ocm"})
+
+	// Add the plugins that have manifests, so that they appear in the help and completions:
+	plugin.AddCommands(root, pluginHandler)
+
 	if len(args) > 1 {
 		cmdPathPieces := args[1:]
 
@@ -150,6 +165,11 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Plugins executed by their commands report their own errors:
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		os.Exit(exitErr.ExitCode())
+	}
+
 	// Replace well known errors with user friendly messages:
 	message := err.Error()
 	switch {
@@ -0,0 +1,234 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/network"
+)
+
+var args struct {
+	machineCIDR net.IPNet
+	serviceCIDR net.IPNet
+	podCIDR     net.IPNet
+	hostPrefix  int
+	nodes       int
+	subnets     []string
+	used        []string
+	usedFile    string
+	json        bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Plan the networks of a cluster",
+	Long: "Check that the machine, service and pod CIDRs of a cluster don't overlap each other, " +
+		"the subnets of the VPC or the ranges already in use, and that the pod CIDR is large " +
+		"enough for the nodes. The ranges that aren't given are suggested, using the defaults " +
+		"when they are free.",
+	Example: `  # Suggest networks that don't overlap the ranges listed in a file
+  ocm network plan --used-file ranges.txt
+
+  # Check the networks of a cluster with 200 nodes installed in an existing VPC
+  ocm network plan --machine-cidr 10.0.0.0/16 --pod-cidr 10.128.0.0/14 --host-prefix 23 \
+    --nodes 200 --subnet 10.0.0.0/19 --subnet 10.0.32.0/19`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.IPNetVar(
+		&args.machineCIDR,
+		"machine-cidr",
+		net.IPNet{},
+		"Block of IP addresses used by the nodes, for example \"10.0.0.0/16\".",
+	)
+	flags.IPNetVar(
+		&args.serviceCIDR,
+		"service-cidr",
+		net.IPNet{},
+		"Block of IP addresses for services, for example \"172.30.0.0/16\".",
+	)
+	flags.IPNetVar(
+		&args.podCIDR,
+		"pod-cidr",
+		net.IPNet{},
+		"Block of IP addresses from which Pod IP addresses are allocated, for example \"10.128.0.0/14\".",
+	)
+	flags.IntVar(
+		&args.hostPrefix,
+		"host-prefix",
+		0,
+		fmt.Sprintf("Subnet prefix length to assign to each node. Defaults to %d.", network.DefaultHostPrefix),
+	)
+	flags.IntVar(
+		&args.nodes,
+		"nodes",
+		0,
+		"Number of nodes that the cluster needs to support.",
+	)
+	flags.StringSliceVar(
+		&args.subnets,
+		"subnet",
+		nil,
+		"CIDR of an existing subnet of the VPC where the cluster will be installed. Can be "+
+			"repeated.",
+	)
+	flags.StringSliceVar(
+		&args.used,
+		"used",
+		nil,
+		"CIDR of a range already in use that the cluster must not overlap. Can be repeated.",
+	)
+	flags.StringVar(
+		&args.usedFile,
+		"used-file",
+		"",
+		"File containing the CIDRs of the ranges already in use, one per line.",
+	)
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Print the plan in JSON format.",
+	)
+}
+
+// planRange is the JSON representation of one of the ranges of the plan.
+type planRange struct {
+	CIDR      string `json:"cidr"`
+	Suggested bool   `json:"suggested"`
+}
+
+// planOutput is the JSON representation of the plan.
+type planOutput struct {
+	MachineCIDR      planRange `json:"machine_cidr"`
+	ServiceCIDR      planRange `json:"service_cidr"`
+	PodCIDR          planRange `json:"pod_cidr"`
+	HostPrefix       int       `json:"host_prefix"`
+	MaxNodes         int       `json:"max_nodes"`
+	AddressesPerNode int       `json:"addresses_per_node"`
+	MachineAddresses int       `json:"machine_addresses"`
+	Problems         []string  `json:"problems"`
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	if args.nodes < 0 {
+		return fmt.Errorf("Number of nodes must be greater than or equal to zero")
+	}
+
+	given := &network.Plan{
+		MachineCIDR: cidrFlag(args.machineCIDR),
+		ServiceCIDR: cidrFlag(args.serviceCIDR),
+		PodCIDR:     cidrFlag(args.podCIDR),
+		HostPrefix:  args.hostPrefix,
+		Nodes:       args.nodes,
+	}
+	var err error
+	given.Subnets, err = network.ParseCIDRs(args.subnets)
+	if err != nil {
+		return err
+	}
+	given.Used, err = network.ParseCIDRs(args.used)
+	if err != nil {
+		return err
+	}
+	if args.usedFile != "" {
+		used, err := network.ReadCIDRFile(args.usedFile)
+		if err != nil {
+			return fmt.Errorf("Failed to read ranges in use: %v", err)
+		}
+		given.Used = append(given.Used, used...)
+	}
+
+	plan, err := given.Suggest()
+	if err != nil {
+		return err
+	}
+	capacity := plan.Capacity()
+	var problems []string
+	for _, problem := range plan.Validate() {
+		problems = append(problems, problem.Error())
+	}
+
+	if args.json {
+		output := planOutput{
+			MachineCIDR:      planRange{plan.MachineCIDR.String(), given.MachineCIDR == nil},
+			ServiceCIDR:      planRange{plan.ServiceCIDR.String(), given.ServiceCIDR == nil},
+			PodCIDR:          planRange{plan.PodCIDR.String(), given.PodCIDR == nil},
+			HostPrefix:       plan.HostPrefix,
+			MaxNodes:         capacity.MaxNodes,
+			AddressesPerNode: capacity.AddressesPerNode,
+			MachineAddresses: capacity.MachineAddresses,
+			Problems:         problems,
+		}
+		data, err := json.Marshal(output)
+		if err != nil {
+			return err
+		}
+		err = dump.Pretty(os.Stdout, data)
+		if err != nil {
+			return err
+		}
+	} else {
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(writer, "RANGE\tCIDR\tSOURCE\n")
+		fmt.Fprintf(writer, "machine\t%s\t%s\n", plan.MachineCIDR, source(given.MachineCIDR))
+		fmt.Fprintf(writer, "service\t%s\t%s\n", plan.ServiceCIDR, source(given.ServiceCIDR))
+		fmt.Fprintf(writer, "pod\t%s\t%s\n", plan.PodCIDR, source(given.PodCIDR))
+		err = writer.Flush()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nHost prefix:        /%d\n", plan.HostPrefix)
+		fmt.Printf("Max nodes:          %d\n", capacity.MaxNodes)
+		fmt.Printf("Addresses per node: %d\n", capacity.AddressesPerNode)
+		fmt.Printf("Machine addresses:  %d\n", capacity.MachineAddresses)
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", problem)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("Network plan isn't valid")
+	}
+	return nil
+}
+
+// cidrFlag returns nil if the flag wasn't given.
+func cidrFlag(cidr net.IPNet) *net.IPNet {
+	if cidr.IP == nil {
+		return nil
+	}
+	return &cidr
+}
+
+func source(given *net.IPNet) string {
+	if given == nil {
+		return "suggested"
+	}
+	return "given"
+}
@@ -17,7 +17,11 @@ limitations under the License.
 package plugin
 
 import (
+	"github.com/openshift-online/ocm-cli/cmd/ocm/plugin/doctor"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/plugin/install"
 	plugin "github.com/openshift-online/ocm-cli/cmd/ocm/plugin/list"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/plugin/remove"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/plugin/upgrade"
 
 	"github.com/spf13/cobra"
 )
@@ -25,11 +29,15 @@ import (
 var Cmd = &cobra.Command{
 	Use:     "plugin COMMAND",
 	Aliases: []string{"plugins"},
-	Short:   "Get information about plugins",
-	Long:    "Get information about installed ocm plugins",
+	Short:   "Get information about and manage plugins",
+	Long:    "Get information about installed ocm plugins, and install them from plugin indexes",
 	Args:    cobra.MinimumNArgs(1),
 }
 
 func init() {
+	Cmd.AddCommand(doctor.Cmd)
+	Cmd.AddCommand(install.Cmd)
 	Cmd.AddCommand(plugin.Cmd)
+	Cmd.AddCommand(remove.Cmd)
+	Cmd.AddCommand(upgrade.Cmd)
 }
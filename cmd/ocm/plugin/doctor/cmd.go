@@ -0,0 +1,54 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package doctor
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/plugin"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check for problems with plugins",
+	Long: "Check the plugins in the PATH and in the managed plugins directory, reporting " +
+		"plugins shadowed by built-in commands, plugins shadowed by other copies that come " +
+		"first in the PATH, plugins whose names differ only in dashes and underscores, files " +
+		"that aren't executable and invalid manifests.",
+	Example: `  # Check the installed plugins
+  ocm plugin doctor`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	plugins, err := plugin.FindPlugins()
+	if err != nil {
+		return err
+	}
+	problems := plugin.Diagnose(cmd.Root(), plugins)
+	if len(problems) == 0 {
+		fmt.Printf("Found %d plugins, no problems detected\n", len(plugins))
+		return nil
+	}
+	for _, problem := range problems {
+		fmt.Printf("[%s] %s: %s\n", problem.Kind, problem.Plugin.Command(), problem.Message)
+	}
+	return fmt.Errorf("Found %d problems with plugins", len(problems))
+}
@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/plugin"
+)
+
+var args struct {
+	index string
+	force bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "install [flags] NAME[@VERSION]...",
+	Short: "Install plugins from an index",
+	Long: "Install plugins from a plugin index into the directory managed by ocm, which is " +
+		"'~/.ocm/plugins' unless the OCM_PLUGINS_DIR environment variable is set. The index is " +
+		"a local YAML file or directory, or a 'file://' URL. The most recent version is " +
+		"installed unless a version is given, in which case the plugin is pinned to that " +
+		"version and isn't changed by 'ocm plugin upgrade'.",
+	Example: `  # Install the most recent version of the 'foo' plugin
+  ocm plugin install --index /srv/plugins/index.yaml foo
+
+  # Install version 1.2.0 of the 'foo' plugin, and pin it
+  ocm plugin install --index file:///srv/plugins foo@1.2.0`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.StringVar(
+		&args.index,
+		"index",
+		plugin.DefaultIndex(),
+		"Plugin index, a local path or 'file://' URL of a YAML file or directory. Defaults "+
+			"to the value of the OCM_PLUGIN_INDEX environment variable.",
+	)
+	fs.BoolVar(
+		&args.force,
+		"force",
+		false,
+		"Reinstall plugins that are already installed.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	index, err := plugin.LoadIndex(args.index)
+	if err != nil {
+		return err
+	}
+
+	for _, reference := range argv {
+		name, version := plugin.ParseReference(reference)
+		receipt, err := plugin.LoadReceipt(name)
+		if err != nil {
+			return err
+		}
+		if receipt != nil && !args.force {
+			return fmt.Errorf("Plugin '%s' version '%s' is already installed, use 'ocm plugin "+
+				"upgrade' to change its version or --force to reinstall it", name, receipt.Version)
+		}
+
+		entry := index.Plugin(name)
+		if entry == nil {
+			return fmt.Errorf("Plugin '%s' isn't in index '%s'", name, args.index)
+		}
+		var item *plugin.IndexVersion
+		if version != "" {
+			item = entry.Version(version)
+			if item == nil {
+				return fmt.Errorf("Plugin '%s' doesn't have version '%s', available versions are: %v",
+					name, version, entry.VersionNames())
+			}
+		} else {
+			item = entry.Latest()
+			if item == nil {
+				return fmt.Errorf("Plugin '%s' doesn't have any version", name)
+			}
+		}
+
+		receipt, err = plugin.Install(args.index, entry, item, version != "")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Installed plugin '%s' version '%s'\n", receipt.Name, receipt.Version)
+	}
+	return nil
+}
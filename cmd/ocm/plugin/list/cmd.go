@@ -20,27 +20,35 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
-	"runtime"
-	"sort"
-	"strings"
+	"text/tabwriter"
 
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/output"
+	"github.com/openshift-online/ocm-cli/pkg/plugin"
 	"github.com/spf13/cobra"
 )
 
 var Cmd = &cobra.Command{
 	Use:   "list",
 	Short: "List ocm plugins",
-	Long:  "List all the plugins under the user executable path",
-	Args:  cobra.NoArgs,
-	RunE:  run,
+	Long: "List all the plugins under the user executable path, in the order they are looked " +
+		"up. The version and description columns are taken from the manifests of the plugins. " +
+		"Warnings are written for plugins that are shadowed by built-in commands or by other " +
+		"plugins, or that can't be executed. Use 'ocm plugin doctor' for a complete report.",
+	Example: `  # List the plugins with their versions
+  ocm plugin list --columns name,version,description,path
+
+  # List the installed plugins that have newer versions in their index
+  ocm plugin list --outdated`,
+	Args: cobra.NoArgs,
+	RunE: run,
 }
 
 var args struct {
 	columns  string
 	nameOnly bool
+	outdated bool
+	index    string
 }
 
 func init() {
@@ -55,11 +63,30 @@ func init() {
 		&args.columns,
 		"columns",
 		"name, path",
-		"Comma separated list of columns to display.",
+		"Comma separated list of columns to display. Available columns are 'name', 'version', "+
+			"'description' and 'path'.",
+	)
+	fs.BoolVar(
+		&args.outdated,
+		"outdated",
+		false,
+		"List the plugins installed with 'ocm plugin install' that have a newer version "+
+			"available in their index.",
+	)
+	fs.StringVar(
+		&args.index,
+		"index",
+		"",
+		"Plugin index used with --outdated instead of the index each plugin was installed "+
+			"from.",
 	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	if args.outdated {
+		return runOutdated()
+	}
+
 	// Create a context:
 	ctx := context.Background()
 
@@ -76,10 +103,14 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 
 	// Find the plugins:
-	plugins, err := findPlugins()
+	plugins, err := plugin.FindPlugins()
 	if err != nil {
 		return err
 	}
+	// Report the plugins that can't be executed or that are ambiguous:
+	for _, problem := range plugin.Diagnose(cmd.Root(), plugins) {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", problem.Message)
+	}
 
 	// Create the output printer:
 	printer, err := output.NewPrinter().
@@ -108,8 +139,8 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 
 	// Write the rows:
-	for _, plugin := range plugins {
-		err = table.WriteObject(plugin)
+	for _, item := range plugins {
+		err = table.WriteObject(item)
 		if err != nil {
 			break
 		}
@@ -121,116 +152,31 @@ func run(cmd *cobra.Command, argv []string) error {
 	return nil
 }
 
-// Plugin contains the description fo a Plugin.
-type Plugin struct {
-	Name string
-	Path string
-}
-
-// findPlugins scans the directories listed in the `PATH` environment variable looking for
-// files that are plugins.
-func findPlugins() (result []Plugin, err error) {
-	defaultPath := filepath.SplitList(os.Getenv("PATH"))
-	newPath := uniquePath(defaultPath)
-
-	for _, dir := range newPath {
-		_, err = os.Stat(dir)
-		if os.IsNotExist(err) {
-			err = nil
-			continue
-		}
-		if err != nil {
-			return
-		}
-		var list []Plugin
-		list, err = listPlugins(dir)
-		if err != nil {
-			return
-		}
-		result = append(result, list...)
-	}
-	return
-}
-
-// listPlugins scans the given directory looking for files that are plugins.
-func listPlugins(dir string) (result []Plugin, err error) {
-	items, err := os.ReadDir(dir)
+func runOutdated() error {
+	updates, err := plugin.FindUpdates(args.index)
 	if err != nil {
-		return
+		return err
 	}
-	for _, item := range items {
-		if item.IsDir() {
-			continue
-		}
-		name := item.Name()
-		if !strings.HasPrefix(name, pluginPrefix) {
+	var available []*plugin.Update
+	for _, update := range updates {
+		if update.Err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: can't check updates of plugin '%s': %v\n",
+				update.Name, update.Err)
 			continue
 		}
-		path := filepath.Join(dir, name)
-		var exec bool
-		exec, err = isExecutable(path)
-		if err != nil {
-			return
-		}
-		if !exec {
-			fmt.Printf("Warning: %s identified as an ocm plugin, but it is not executable.\n", path)
-		}
-		if runtime.GOOS == "windows" {
-			name = strings.TrimSuffix(name, ".exe")
-		}
-		plugin := Plugin{
-			Name: name,
-			Path: dir,
-		}
-		result = append(result, plugin)
+		available = append(available, update)
 	}
-	return
-}
-
-// uniquePath remove the duplicate items from the PATH
-func uniquePath(path []string) []string {
-	keys := make(map[string]int)
-	uniPath := make([]string, 0)
-
-	for _, p := range path {
-		if p == "" {
-			p = "."
+	if len(available) == 0 {
+		if len(available) == len(updates) {
+			fmt.Println("All installed plugins are up to date")
 		}
-		keys[p] = 1
+		return nil
 	}
-
-	for element := range keys {
-		uniPath = append(uniPath, element)
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "NAME\tINSTALLED\tLATEST\tPINNED\n")
+	for _, update := range available {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%t\n",
+			update.Name, update.Installed, update.Latest, update.Pinned)
 	}
-
-	sort.Strings(uniPath)
-
-	return uniPath
+	return writer.Flush()
 }
-
-// detect if the plugin is excutable
-func isExecutable(file string) (bool, error) {
-	info, err := os.Stat(file)
-	if err != nil {
-		return false, err
-	}
-
-	if runtime.GOOS == "windows" {
-		fileExt := strings.ToLower(filepath.Ext(file))
-
-		switch fileExt {
-		case ".bat", ".cmd", ".com", ".exe", ".ps1":
-			return true, nil
-		}
-		return false, nil
-	}
-
-	if m := info.Mode(); !m.IsDir() && m&0111 != 0 {
-		return true, nil
-	}
-
-	return false, nil
-}
-
-// pluginPrefix is the prefix that plugin file names should have.
-const pluginPrefix = "ocm-"
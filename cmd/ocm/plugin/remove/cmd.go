@@ -0,0 +1,47 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remove
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/plugin"
+)
+
+var Cmd = &cobra.Command{
+	Use:     "remove NAME...",
+	Aliases: []string{"uninstall"},
+	Short:   "Remove plugins installed from an index",
+	Long:    "Remove plugins installed with 'ocm plugin install'.",
+	Example: `  # Remove the 'foo' plugin
+  ocm plugin remove foo`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: run,
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	for _, name := range argv {
+		err := plugin.Remove(name)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed plugin '%s'\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/plugin"
+)
+
+var args struct {
+	index string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "upgrade [flags] [NAME[@VERSION]...]",
+	Short: "Upgrade plugins installed from an index",
+	Long: "Upgrade plugins installed with 'ocm plugin install' to the most recent version " +
+		"available in their index. When no plugin is given all the installed plugins are " +
+		"upgraded. Pinned plugins are only changed when a version is given, which also pins " +
+		"the plugin to the new version.",
+	Example: `  # Upgrade all the plugins that aren't pinned
+  ocm plugin upgrade
+
+  # Change the pinned version of the 'foo' plugin
+  ocm plugin upgrade foo@1.3.0`,
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.StringVar(
+		&args.index,
+		"index",
+		"",
+		"Plugin index, a local path or 'file://' URL of a YAML file or directory. Defaults "+
+			"to the index each plugin was installed from.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	var receipts []*plugin.Receipt
+	versions := map[string]string{}
+	if len(argv) == 0 {
+		var err error
+		receipts, err = plugin.LoadReceipts()
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, reference := range argv {
+			name, version := plugin.ParseReference(reference)
+			receipt, err := plugin.LoadReceipt(name)
+			if err != nil {
+				return err
+			}
+			if receipt == nil {
+				return fmt.Errorf("Plugin '%s' wasn't installed with 'ocm plugin install'", name)
+			}
+			receipts = append(receipts, receipt)
+			versions[name] = version
+		}
+	}
+
+	indexes := map[string]*plugin.Index{}
+	for _, receipt := range receipts {
+		version := versions[receipt.Name]
+		if receipt.Pinned && version == "" {
+			fmt.Printf("Plugin '%s' is pinned to version '%s'\n", receipt.Name, receipt.Version)
+			continue
+		}
+
+		source := args.index
+		if source == "" {
+			source = receipt.Index
+		}
+		index, ok := indexes[source]
+		if !ok {
+			var err error
+			index, err = plugin.LoadIndex(source)
+			if err != nil {
+				return err
+			}
+			indexes[source] = index
+		}
+
+		entry := index.Plugin(receipt.Name)
+		if entry == nil {
+			return fmt.Errorf("Plugin '%s' isn't in index '%s'", receipt.Name, source)
+		}
+		var item *plugin.IndexVersion
+		if version != "" {
+			item = entry.Version(version)
+			if item == nil {
+				return fmt.Errorf("Plugin '%s' doesn't have version '%s', available versions are: %v",
+					receipt.Name, version, entry.VersionNames())
+			}
+		} else {
+			item = entry.Latest()
+			if item == nil || !plugin.IsNewer(item.Version, receipt.Version) {
+				fmt.Printf("Plugin '%s' version '%s' is up to date\n", receipt.Name, receipt.Version)
+				continue
+			}
+		}
+
+		previous := receipt.Version
+		receipt, err := plugin.Install(source, entry, item, version != "")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Upgraded plugin '%s' from version '%s' to '%s'\n",
+			receipt.Name, previous, receipt.Version)
+	}
+	return nil
+}
@@ -17,29 +17,27 @@ limitations under the License.
 package job
 
 import (
+	"context"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
+	"github.com/openshift-online/ocm-cli/pkg/job"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
-	"github.com/openshift-online/ocm-sdk-go/jobqueue/v1"
 )
 
 // Cmd Constant:
 var Cmd = &cobra.Command{
 	Use:   "job QUEUE_NAME",
 	Short: "Pop (i.e. fetch) a Job",
-	Long:  "Fetch a job from the specified Job Queue.",
-	Args:  cobra.ExactArgs(1),
-	RunE:  run,
+	Long: "Fetch a job from the specified Job Queue and print it in JSON format. When the " +
+		"queue is empty nothing is printed to the standard output.",
+	Args: cobra.ExactArgs(1),
+	RunE: run,
 }
 
 func run(_ *cobra.Command, argv []string) error {
-	var (
-		pop *v1.QueuePopResponse
-		err error
-	)
-
 	// Create the client for the OCM API:
 	connection, err := ocm.NewConnection().Build()
 	if err != nil {
@@ -50,34 +48,15 @@ func run(_ *cobra.Command, argv []string) error {
 	// Get the client for the Job Queue management api
 	client := connection.JobQueue().V1()
 
-	// Send a request to create the Job:
-	pop, err = client.Queues().Queue(argv[0]).Pop().Send()
+	// Send a request to fetch a Job:
+	pop, err := job.PopJob(context.Background(), client.Queues().Queue(argv[0]))
 	if err != nil {
-		if pop != nil && pop.Status() == 204 {
-			// No Content
-			fmt.Printf("No job found\n")
-			return nil
-		}
 		return fmt.Errorf("unable to fetch a Job: %v", err)
 	}
-	fmt.Printf("{\n"+
-		"  \"id\": \"%s\",\n"+
-		"  \"kind\": \"Job\",\n"+
-		"  \"href\": \"%s\",\n"+
-		"  \"attempts\": \"%d\",\n"+
-		"  \"abandoned_at\": \"%s\",\n"+
-		"  \"created_at\": \"%s\",\n"+
-		"  \"updated_at\": \"%s\",\n"+
-		"  \"receipt_id\": \"%s\",\n"+
-		"}",
-		pop.ID(),
-		pop.HREF(),
-		pop.Attempts(),
-		pop.AbandonedAt(),
-		pop.CreatedAt(),
-		pop.UpdatedAt(),
-		pop.ReceiptId(),
-	)
+	if pop == nil {
+		fmt.Fprintf(os.Stderr, "No job found\n")
+		return nil
+	}
 
-	return nil
+	return job.PrintJob(os.Stdout, pop)
 }
@@ -17,14 +17,16 @@ limitations under the License.
 package job
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/openshift-online/ocm-cli/pkg/arguments"
+	"github.com/openshift-online/ocm-cli/pkg/job"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
-	"github.com/openshift-online/ocm-sdk-go/jobqueue/v1"
 )
 
 var args struct {
@@ -35,7 +37,7 @@ var args struct {
 var Cmd = &cobra.Command{
 	Use:   "job QUEUE_NAME",
 	Short: "Push (i.e. create) a new Job",
-	Long:  "Create a new Job on the specified Job Queue.",
+	Long:  "Create a new Job on the specified Job Queue and print it in JSON format.",
 	Args:  cobra.ExactArgs(1),
 	RunE:  run,
 }
@@ -47,11 +49,6 @@ func init() {
 }
 
 func run(_ *cobra.Command, argv []string) error {
-	var (
-		push *v1.QueuePushResponse
-		err  error
-	)
-
 	// Create the client for the OCM API:
 	connection, err := ocm.NewConnection().Build()
 	if err != nil {
@@ -63,30 +60,19 @@ func run(_ *cobra.Command, argv []string) error {
 	client := connection.JobQueue().V1()
 
 	// Send a request to create a Job:
-	request := client.Queues().Queue(argv[0]).Push()
+	var jobArguments string
 	for _, arg := range args.parameter {
 		if strings.HasPrefix(arg, "Arguments") {
-			params := strings.Split(arg, "=")
-			// Apply parameters
-			request.Arguments(params[1])
+			params := strings.SplitN(arg, "=", 2)
+			if len(params) == 2 {
+				jobArguments = params[1]
+			}
 		}
 	}
-	push, err = request.Send()
+	push, err := job.PushJob(context.Background(), client.Queues().Queue(argv[0]), jobArguments)
 	if err != nil {
 		return fmt.Errorf("unable to create Job: %v", err)
 	}
-	fmt.Printf("{\n"+
-		"  \"id\": \"%s\",\n"+
-		"  \"kind\": \"Job\",\n"+
-		"  \"href\": \"%s\",\n"+
-		"  \"arguments\": \"%s\",\n"+
-		"  \"created_at\": \"%s\",\n"+
-		"}",
-		push.ID(),
-		push.HREF(),
-		push.Arguments(),
-		push.CreatedAt(),
-	)
 
-	return nil
+	return job.PrintJob(os.Stdout, push)
 }
@@ -18,6 +18,9 @@ package job
 
 import (
 	"fmt"
+	"os"
+
+	"github.com/openshift-online/ocm-cli/pkg/job"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	"github.com/spf13/cobra"
 )
@@ -26,7 +29,7 @@ import (
 var Cmd = &cobra.Command{
 	Use:   "job QUEUE_NAME JOB_ID RECEIPT_ID",
 	Short: "Mark the Job as a success",
-	Long:  "Mark the Job as a success on the specified Job Queue.",
+	Long:  "Mark the Job as a success on the specified Job Queue and print the result in JSON format.",
 	Args:  cobra.ExactArgs(3),
 	RunE:  run,
 }
@@ -50,5 +53,10 @@ func run(_ *cobra.Command, argv []string) error {
 		return fmt.Errorf("unable to success a job: %v", err)
 	}
 
-	return nil
+	return job.PrintResult(os.Stdout, &job.Result{
+		Queue:     argv[0],
+		ID:        argv[1],
+		ReceiptID: argv[2],
+		Status:    "success",
+	})
 }
@@ -17,34 +17,49 @@ limitations under the License.
 package tunnel
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/url"
 	"os"
 	"os/exec"
+	"os/signal"
 	"regexp"
 	"strings"
+	"syscall"
 
 	c "github.com/openshift-online/ocm-cli/pkg/cluster"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/tunnel"
 	clustersmgmtv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/spf13/cobra"
 )
 
 var args struct {
-	useSubnets bool
+	useSubnets            bool
+	userspace             bool
+	listen                string
+	identityFiles         []string
+	knownHostsFile        string
+	insecureIgnoreHostKey bool
 }
 
 var Cmd = &cobra.Command{
 	Use:   "tunnel [flags] {CLUSTERID|CLUSTER_NAME|CLUSTER_NAME_SEARCH} -- [sshuttle arguments]",
 	Short: "tunnel to a cluster",
-	Long: "Use sshuttle to create a ssh tunnel to a cluster by ID or Name or" +
+	Long: "Use sshuttle to create a ssh tunnel to a cluster by ID or Name or " +
 		"cluster name search string according to the api: " +
-		"https://api.openshift.com/#/clusters/get_api_clusters_mgmt_v1_clusters",
-	Example: " ocm tunnel <cluster_id>\n ocm tunnel %test%",
-	RunE:    run,
-	Hidden:  true,
-	Args:    cobra.ArbitraryArgs,
+		"https://api.openshift.com/#/clusters/get_api_clusters_mgmt_v1_clusters\n" +
+		"\n" +
+		"With --userspace sshuttle and root permissions aren't needed: a local proxy that " +
+		"accepts SOCKS5 and HTTP CONNECT requests forwards the connections to the cluster " +
+		"through SSH. Only the cluster is reachable through it, so applications use it with the " +
+		"proxy-url setting of the kubeconfig instead of the proxy environment variables.",
+	Example: " ocm tunnel <cluster_id>\n ocm tunnel %test%\n" +
+		" ocm tunnel --userspace --listen 127.0.0.1:1080 <cluster_id>",
+	RunE:   run,
+	Hidden: true,
+	Args:   cobra.ArbitraryArgs,
 }
 
 func init() {
@@ -57,6 +72,39 @@ func init() {
 		"If specified, tunnel the entire subnets of MachineCIDR, ServiceCIDR and PodCIDR. "+
 			"Otherwise, only tunnel to the IPs of console and API Servers. ",
 	)
+	flags.BoolVar(
+		&args.userspace,
+		"userspace",
+		false,
+		"Run a local SOCKS5 and HTTP CONNECT proxy that forwards connections through SSH, "+
+			"instead of using sshuttle.",
+	)
+	flags.StringVar(
+		&args.listen,
+		"listen",
+		"127.0.0.1:1080",
+		"Address where the proxy listens when --userspace is used.",
+	)
+	flags.StringSliceVar(
+		&args.identityFiles,
+		"identity",
+		nil,
+		"SSH private key used when --userspace is used, in addition to the keys of the SSH "+
+			"agent. Defaults to the keys in '~/.ssh'.",
+	)
+	flags.StringVar(
+		&args.knownHostsFile,
+		"known-hosts",
+		"",
+		"Known hosts file used to verify the SSH server when --userspace is used. Defaults "+
+			"to '~/.ssh/known_hosts'.",
+	)
+	flags.BoolVar(
+		&args.insecureIgnoreHostKey,
+		"insecure-ignore-host-key",
+		false,
+		"Don't verify the host key of the SSH server when --userspace is used.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -81,9 +129,18 @@ func run(cmd *cobra.Command, argv []string) error {
 		)
 	}
 
-	path, err := exec.LookPath("sshuttle")
-	if err != nil {
-		return fmt.Errorf("to run this, you need install the sshuttle tool first")
+	if args.userspace && len(argv) > 1 {
+		return fmt.Errorf("sshuttle arguments can't be used with --userspace")
+	}
+
+	var path string
+	if !args.userspace {
+		var err error
+		path, err = exec.LookPath("sshuttle")
+		if err != nil {
+			return fmt.Errorf("to run this, you need install the sshuttle tool first, " +
+				"or use --userspace")
+		}
 	}
 
 	// Create the client for the OCM API:
@@ -105,6 +162,10 @@ func run(cmd *cobra.Command, argv []string) error {
 		return err
 	}
 
+	if args.userspace {
+		return runUserspace(cluster, sshURL)
+	}
+
 	sshuttleArgs := []string{
 		"--remote", sshURL,
 	}
@@ -145,6 +206,100 @@ func run(cmd *cobra.Command, argv []string) error {
 	return nil
 }
 
+// runUserspace runs a local proxy that forwards connections to the cluster through SSH.
+func runUserspace(cluster *clustersmgmtv1.Cluster, sshURL string) error {
+	destinations, err := buildDestinations(cluster)
+	if err != nil {
+		return err
+	}
+
+	user, host, _ := strings.Cut(sshURL, "@")
+	dialer, err := tunnel.NewSSHDialer(tunnel.SSHConfig{
+		User:                  user,
+		Address:               net.JoinHostPort(host, "22"),
+		IdentityFiles:         args.identityFiles,
+		KnownHostsFile:        args.knownHostsFile,
+		InsecureIgnoreHostKey: args.insecureIgnoreHostKey,
+	})
+	if err != nil {
+		return err
+	}
+	defer dialer.Close()
+	err = dialer.Connect()
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", args.listen)
+	if err != nil {
+		return fmt.Errorf("can't listen on '%s': %v", args.listen, err)
+	}
+	address := listener.Addr().String()
+
+	fmt.Printf("\nProxy listening on %s, forwarding to cluster '%s' through %s\n",
+		address, cluster.Name(), sshURL)
+	fmt.Printf("\nUse it from other terminals setting the proxy of the cluster in the kubeconfig:\n\n")
+	fmt.Printf("  kubectl config set-cluster %s --proxy-url=socks5://%s\n",
+		kubeconfigClusterName(cluster.API().URL()), address)
+	fmt.Printf("\nPress Ctrl+C to stop.\n")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return tunnel.NewProxy(dialer, destinations).
+		Logger(func(format string, values ...interface{}) {
+			fmt.Fprintf(os.Stderr, format+"\n", values...)
+		}).
+		Serve(ctx, listener)
+}
+
+// buildDestinations returns the destinations reachable through the userspace tunnel: the API and
+// the routes of the cluster and, with --subnets, the machine, service and pod networks.
+func buildDestinations(cluster *clustersmgmtv1.Cluster) (*tunnel.Destinations, error) {
+	destinations := &tunnel.Destinations{}
+	for _, rawURL := range []string{cluster.API().URL(), cluster.Console().URL()} {
+		if rawURL == "" {
+			continue
+		}
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse url: %s", err)
+		}
+		destinations.Hosts = append(destinations.Hosts, u.Hostname())
+	}
+
+	// The console host name is in the domain of the routes, which also contains the OAuth
+	// server needed to log in:
+	consoleURL, err := url.Parse(cluster.Console().URL())
+	if err == nil && consoleURL.Hostname() != "" {
+		_, domain, found := strings.Cut(consoleURL.Hostname(), ".")
+		if found {
+			destinations.Domains = append(destinations.Domains, domain)
+		}
+	}
+
+	if args.useSubnets {
+		network := cluster.Network()
+		for _, cidr := range []string{network.MachineCIDR(), network.ServiceCIDR(),
+			network.PodCIDR()} {
+			err = destinations.AddNetwork(cidr)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return destinations, nil
+}
+
+// kubeconfigClusterName returns the name that 'oc login' gives to the cluster in the kubeconfig,
+// for example 'api-mycluster-example-com:6443'.
+func kubeconfigClusterName(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return apiURL
+	}
+	return strings.ReplaceAll(u.Host, ".", "-")
+}
+
 func generateSSHURI(cluster *clustersmgmtv1.Cluster) (string, error) {
 	r := regexp.MustCompile(`(?mi)^https:\/\/api\.(.*):6443`)
 	apiURL := cluster.API().URL()
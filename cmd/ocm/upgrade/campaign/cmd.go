@@ -0,0 +1,330 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package campaign
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/arguments"
+	"github.com/openshift-online/ocm-cli/pkg/campaign"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	file              string
+	version           string
+	search            string
+	waves             []string
+	maxFailures       int
+	maxFailurePercent int
+	waveTimeout       time.Duration
+	stateFile         string
+	pollInterval      time.Duration
+	dryRun            bool
+	report            bool
+	json              bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "campaign [flags] NAME",
+	Short: "Upgrade a fleet of clusters in waves",
+	Long: "Upgrade the clusters matching a search to a target version in waves. The waves are " +
+		"executed one after the other, waiting for the upgrade policies of each wave to finish " +
+		"before starting the next one. The progress is saved to a state file, so running the " +
+		"command again for an existing campaign resumes it.",
+	Example: `  # Upgrade all the OSD clusters to 4.15.3, first the canary ones and then the rest
+  ocm upgrade campaign osd-4.15.3 --version 4.15.3 --search "product.id = 'osd'" \
+    --wave "canary=name like 'canary-%'" --wave prod
+
+  # Start a campaign described in a file
+  ocm upgrade campaign --file campaign.yaml
+
+  # Resume an interrupted or halted campaign
+  ocm upgrade campaign osd-4.15.3
+
+  # Show the progress of a campaign
+  ocm upgrade campaign osd-4.15.3 --report`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.StringVarP(
+		&args.file,
+		"file",
+		"f",
+		"",
+		"YAML file containing the name, version, search and waves of the campaign.",
+	)
+	fs.StringVar(
+		&args.version,
+		"version",
+		"",
+		"Version that the clusters will be upgraded to.",
+	)
+	fs.StringVar(
+		&args.search,
+		"search",
+		"",
+		"Search query selecting the clusters of the campaign, for example \"product.id = 'osd'\".",
+	)
+	fs.StringArrayVar(
+		&args.waves,
+		"wave",
+		nil,
+		"Wave of the campaign, in the order they will be executed. The value is the name of the "+
+			"wave, followed by an optional equals sign and a search query selecting the clusters "+
+			"of the wave. A wave without search query takes all the remaining clusters. Can be "+
+			"used multiple times.",
+	)
+	fs.IntVar(
+		&args.maxFailures,
+		"max-failures",
+		0,
+		"Number of failed clusters tolerated in each wave before halting the campaign.",
+	)
+	fs.IntVar(
+		&args.maxFailurePercent,
+		"max-failure-percent",
+		0,
+		"Percentage of failed clusters tolerated in each wave before halting the campaign. "+
+			"Takes precedence over --max-failures.",
+	)
+	fs.DurationVar(
+		&args.waveTimeout,
+		"wave-timeout",
+		0,
+		"Maximum time to wait for the clusters of a wave to finish upgrading. Clusters that "+
+			"haven't finished by then are considered failed.",
+	)
+	fs.StringVar(
+		&args.stateFile,
+		"state-file",
+		"",
+		"File where the progress of the campaign is saved. Defaults to a file named after the "+
+			"campaign in the 'ocm/campaigns' directory of the user configuration directory.",
+	)
+	fs.DurationVar(
+		&args.pollInterval,
+		"poll-interval",
+		time.Minute,
+		"Time to wait between checks of the upgrade policies.",
+	)
+	fs.BoolVar(
+		&args.dryRun,
+		"dry-run",
+		false,
+		"Show the clusters of each wave without creating upgrade policies.",
+	)
+	fs.BoolVar(
+		&args.report,
+		"report",
+		false,
+		"Show the progress of the campaign without running it.",
+	)
+	fs.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Show the report in JSON format.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	spec, err := buildSpec(cmd, argv)
+	if err != nil {
+		return err
+	}
+
+	stateFile := args.stateFile
+	if stateFile == "" {
+		stateFile, err = campaign.DefaultStateFile(spec.Name)
+		if err != nil {
+			return err
+		}
+	}
+	state, err := campaign.LoadState(stateFile)
+	if err != nil {
+		return err
+	}
+
+	if args.report {
+		if state == nil {
+			return fmt.Errorf("Campaign '%s' doesn't have a state file at '%s'", spec.Name, stateFile)
+		}
+		return report(state)
+	}
+
+	if state == nil {
+		err = spec.Validate()
+		if err != nil {
+			return err
+		}
+		state = campaign.NewState(*spec)
+	} else {
+		if specChanged(cmd) {
+			return fmt.Errorf(
+				"Campaign '%s' already exists in '%s', run the command with only its name "+
+					"to resume it",
+				spec.Name, stateFile,
+			)
+		}
+		if state.Status == campaign.StatusCompleted {
+			fmt.Printf("Campaign '%s' is already completed\n", spec.Name)
+			return nil
+		}
+		fmt.Printf("Resuming campaign '%s'\n", spec.Name)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Stop gracefully on interruption, the state is saved so the campaign can be resumed:
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = campaign.NewRunner(connection, state, stateFile).
+		PollInterval(args.pollInterval).
+		DryRun(args.dryRun).
+		Output(os.Stdout).
+		Run(ctx)
+	if err != nil {
+		return err
+	}
+	if !args.dryRun {
+		fmt.Printf("Campaign '%s' completed\n", spec.Name)
+	}
+	return nil
+}
+
+// buildSpec creates the specification of the campaign from the file or the command line flags.
+func buildSpec(cmd *cobra.Command, argv []string) (spec *campaign.Spec, err error) {
+	if args.file != "" {
+		if specFlagsChanged(cmd) {
+			return nil, fmt.Errorf("--file can't be combined with --version, --search or --wave")
+		}
+		spec, err = campaign.LoadSpec(args.file)
+		if err != nil {
+			return nil, err
+		}
+		if len(argv) == 1 && argv[0] != spec.Name {
+			return nil, fmt.Errorf("Campaign name '%s' doesn't match name '%s' in file '%s'",
+				argv[0], spec.Name, args.file)
+		}
+	} else {
+		if len(argv) != 1 {
+			return nil, fmt.Errorf("Expected exactly one campaign name or the --file flag")
+		}
+		spec = &campaign.Spec{
+			Name:    argv[0],
+			Version: args.version,
+			Search:  args.search,
+		}
+		for _, value := range args.waves {
+			name, search := arguments.ParseNameValuePair(value)
+			spec.Waves = append(spec.Waves, campaign.Wave{
+				Name:   name,
+				Search: search,
+			})
+		}
+	}
+
+	for i := range spec.Waves {
+		if cmd.Flags().Changed("max-failures") {
+			spec.Waves[i].MaxFailures = args.maxFailures
+		}
+		if cmd.Flags().Changed("max-failure-percent") {
+			spec.Waves[i].MaxFailurePercent = args.maxFailurePercent
+		}
+		if cmd.Flags().Changed("wave-timeout") {
+			spec.Waves[i].Timeout = args.waveTimeout
+		}
+	}
+	return spec, nil
+}
+
+func specFlagsChanged(cmd *cobra.Command) bool {
+	fs := cmd.Flags()
+	return fs.Changed("version") || fs.Changed("search") || fs.Changed("wave")
+}
+
+func specChanged(cmd *cobra.Command) bool {
+	fs := cmd.Flags()
+	return specFlagsChanged(cmd) || fs.Changed("file") || fs.Changed("max-failures") ||
+		fs.Changed("max-failure-percent") || fs.Changed("wave-timeout")
+}
+
+func report(state *campaign.State) error {
+	if args.json {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("Failed to marshal campaign state: %v", err)
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+
+	fmt.Printf("Campaign: %s\n"+
+		"Version:  %s\n"+
+		"Status:   %s\n"+
+		"Started:  %s\n"+
+		"Updated:  %s\n\n",
+		state.Spec.Name,
+		state.Spec.Version,
+		state.Status,
+		state.Started.Format(time.RFC3339),
+		state.Updated.Format(time.RFC3339),
+	)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "WAVE\tSTATUS\tCOMPLETED\tFAILED\tPENDING\n")
+	for _, wave := range state.Waves {
+		completed, failed, pending := wave.Counts()
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%d\n", wave.Name, wave.Status, completed, failed, pending)
+	}
+	//nolint:gosec
+	writer.Flush()
+	fmt.Println()
+
+	writer = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "WAVE\tID\tNAME\tVERSION\tSTATUS\tMESSAGE\n")
+	for _, wave := range state.Waves {
+		for _, cluster := range wave.Clusters {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				wave.Name, cluster.ID, cluster.Name, cluster.CurrentVersion,
+				cluster.Status, cluster.Message)
+		}
+	}
+	//nolint:gosec
+	writer.Flush()
+
+	return nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to discover add-ons and manage their installations.
+
+package addon
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	asv1 "github.com/openshift-online/ocm-sdk-go/addonsmgmt/v1"
+)
+
+// GetAddOn returns the add-on with the given identifier.
+func GetAddOn(connection *sdk.Connection, addOnID string) (*asv1.Addon, error) {
+	response, err := connection.AddonsMgmt().V1().Addons().
+		Addon(addOnID).
+		Get().
+		Send()
+	if response != nil && response.Status() == http.StatusNotFound {
+		return nil, fmt.Errorf("Add-on '%s' doesn't exist", addOnID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get add-on '%s': %v", addOnID, err)
+	}
+	return response.Body(), nil
+}
+
+// GetAddOnInstallation returns the installation of the given add-on in the given cluster, or nil
+// if the add-on isn't installed.
+func GetAddOnInstallation(connection *sdk.Connection, clusterID string,
+	addOnID string) (*asv1.AddonInstallation, error) {
+	response, err := connection.AddonsMgmt().V1().Clusters().
+		Cluster(clusterID).
+		Addons().
+		Addon(addOnID).
+		Get().
+		Send()
+	if response != nil && response.Status() == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get add-on installation '%s' for cluster '%s': %v",
+			addOnID, clusterID, err)
+	}
+	return response.Body(), nil
+}
+
+// GetAddOnVersion returns the given version of the add-on.
+func GetAddOnVersion(connection *sdk.Connection, addOnID string,
+	versionID string) (*asv1.AddonVersion, error) {
+	response, err := connection.AddonsMgmt().V1().Addons().
+		Addon(addOnID).
+		Versions().
+		Version(versionID).
+		Get().
+		Send()
+	if response != nil && response.Status() == http.StatusNotFound {
+		return nil, fmt.Errorf("Version '%s' of add-on '%s' doesn't exist", versionID, addOnID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get version '%s' of add-on '%s': %v", versionID, addOnID, err)
+	}
+	return response.Body(), nil
+}
+
+// GetParameters returns the enabled parameters of the add-on, in the order they should be
+// presented to the user. Parameters of the current add-on version take precedence over the
+// parameters of the add-on itself.
+func GetParameters(addOn *asv1.Addon) []*asv1.AddonParameter {
+	return GetVersionParameters(addOn, addOn.Version())
+}
+
+// GetVersionParameters is like GetParameters, but uses the parameters of the given add-on version
+// instead of the current one.
+func GetVersionParameters(addOn *asv1.Addon, version *asv1.AddonVersion) []*asv1.AddonParameter {
+	parameters := version.Parameters().Items()
+	if len(parameters) == 0 {
+		parameters = addOn.Parameters().Slice()
+	}
+
+	var result []*asv1.AddonParameter
+	for _, parameter := range parameters {
+		if enabled, ok := parameter.GetEnabled(); ok && !enabled {
+			continue
+		}
+		result = append(result, parameter)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Order() < result[j].Order()
+	})
+	return result
+}
+
+// GetRequirements returns the requirements of the add-on, preferring those of the current add-on
+// version.
+func GetRequirements(addOn *asv1.Addon) []*asv1.AddonRequirement {
+	requirements := addOn.Version().Requirements()
+	if len(requirements) == 0 {
+		requirements = addOn.Requirements()
+	}
+	return requirements
+}
+
+// InstallationValues returns the parameter values of an add-on installation.
+func InstallationValues(installation *asv1.AddonInstallation) map[string]string {
+	values := map[string]string{}
+	for _, parameter := range installation.Parameters().Slice() {
+		values[parameter.Id()] = parameter.Value()
+	}
+	return values
+}
+
+// BuildParameters creates the list of installation parameters for the given values.
+func BuildParameters(values map[string]string) *asv1.AddonInstallationParameterListBuilder {
+	ids := make([]string, 0, len(values))
+	for id := range values {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	items := make([]*asv1.AddonInstallationParameterBuilder, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, asv1.NewAddonInstallationParameter().Id(id).Value(values[id]))
+	}
+	return asv1.NewAddonInstallationParameterList().Items(items...)
+}
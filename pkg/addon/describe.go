@@ -0,0 +1,128 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	asv1 "github.com/openshift-online/ocm-sdk-go/addonsmgmt/v1"
+)
+
+// PrintAddOnDescription writes the details of an add-on, its parameters and requirements and, if
+// not nil, the state and parameter values of its installation.
+func PrintAddOnDescription(stream io.Writer, addOn *asv1.Addon, installation *asv1.AddonInstallation) error {
+	writer := tabwriter.NewWriter(stream, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "ID:\t%s\n", addOn.ID())
+	fmt.Fprintf(writer, "Name:\t%s\n", addOn.Name())
+	if addOn.Version().ID() != "" {
+		fmt.Fprintf(writer, "Version:\t%s\n", addOn.Version().ID())
+	}
+	if addOn.DocsLink() != "" {
+		fmt.Fprintf(writer, "Documentation:\t%s\n", addOn.DocsLink())
+	}
+	if addOn.TargetNamespace() != "" {
+		fmt.Fprintf(writer, "Namespace:\t%s\n", addOn.TargetNamespace())
+	}
+	if installation != nil {
+		state := string(installation.State())
+		if state == "" {
+			state = string(asv1.AddonInstallationStateInstalling)
+		}
+		fmt.Fprintf(writer, "State:\t%s\n", state)
+		if installation.StateDescription() != "" {
+			fmt.Fprintf(writer, "State Description:\t%s\n", installation.StateDescription())
+		}
+		if installation.AddonVersion().ID() != "" {
+			fmt.Fprintf(writer, "Installed Version:\t%s\n", installation.AddonVersion().ID())
+		}
+	}
+	err := writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	parameters := GetParameters(addOn)
+	if len(parameters) > 0 {
+		var values map[string]string
+		if installation != nil {
+			values = InstallationValues(installation)
+		}
+		fmt.Fprintf(stream, "\nParameters:\n")
+		writer = tabwriter.NewWriter(stream, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(writer, "  ID\tTYPE\tREQUIRED\tEDITABLE\tDEFAULT\tVALUE\tOPTIONS\n")
+		for _, parameter := range parameters {
+			fmt.Fprintf(writer, "  %s\t%s\t%t\t%t\t%s\t%s\t%s\n",
+				parameter.ID(),
+				parameter.ValueType(),
+				parameter.Required(),
+				parameter.Editable(),
+				parameter.DefaultValue(),
+				values[parameter.ID()],
+				strings.Join(optionValues(parameter), ","),
+			)
+		}
+		err = writer.Flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	requirements := GetRequirements(addOn)
+	if len(requirements) > 0 {
+		fmt.Fprintf(stream, "\nRequirements:\n")
+		writer = tabwriter.NewWriter(stream, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(writer, "  ID\tRESOURCE\tFULFILLED\tDATA\tERRORS\n")
+		for _, requirement := range requirements {
+			if enabled, ok := requirement.GetEnabled(); ok && !enabled {
+				continue
+			}
+			fulfilled := "unknown"
+			if value, ok := requirement.Status().GetFulfilled(); ok {
+				fulfilled = fmt.Sprintf("%t", value)
+			}
+			fmt.Fprintf(writer, "  %s\t%s\t%s\t%s\t%s\n",
+				requirement.ID(),
+				requirement.Resource(),
+				fulfilled,
+				formatData(requirement.Data()),
+				strings.Join(requirement.Status().ErrorMsgs(), "; "),
+			)
+		}
+		err = writer.Flush()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatData(data map[string]interface{}) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", key, data[key]))
+	}
+	return strings.Join(pairs, ",")
+}
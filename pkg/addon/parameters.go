@@ -0,0 +1,319 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to collect and validate the values of add-on parameters.
+
+package addon
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	asv1 "github.com/openshift-online/ocm-sdk-go/addonsmgmt/v1"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"github.com/openshift-online/ocm-cli/pkg/arguments"
+)
+
+// ParseParameterFlags parses the values of the '--param' flag, each of them in 'name=value' format.
+func ParseParameterFlags(flags []string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, flag := range flags {
+		if !strings.Contains(flag, "=") {
+			return nil, fmt.Errorf("Expected name=value format for parameter '%s'", flag)
+		}
+		name, value := arguments.ParseNameValuePair(flag)
+		if name == "" {
+			return nil, fmt.Errorf("Expected name=value format for parameter '%s'", flag)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// LoadValuesFile reads parameter values from a YAML or JSON file containing a map of parameter
+// identifiers to values.
+func LoadValuesFile(file string) (map[string]string, error) {
+	// #nosec G304
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("can't read values file '%s': %v", file, err)
+	}
+	raw := map[string]interface{}{}
+	err = yaml.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse values file '%s': %v", file, err)
+	}
+	values := map[string]string{}
+	for name, value := range raw {
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("Value of parameter '%s' in values file '%s' must be a scalar",
+				name, file)
+		case nil:
+			values[name] = ""
+		default:
+			values[name] = fmt.Sprint(value)
+		}
+	}
+	return values, nil
+}
+
+// CollectValues merges the parameter values of a values file with those given with the '--param'
+// flag, the latter taking precedence.
+func CollectValues(valuesFile string, flags []string) (map[string]string, error) {
+	values := map[string]string{}
+	if valuesFile != "" {
+		fileValues, err := LoadValuesFile(valuesFile)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fileValues {
+			values[name] = value
+		}
+	}
+	flagValues, err := ParseParameterFlags(flags)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range flagValues {
+		values[name] = value
+	}
+	return values, nil
+}
+
+// ValidateValue checks that a value is acceptable for the given parameter.
+func ValidateValue(parameter *asv1.AddonParameter, value string) error {
+	options := parameter.Options()
+	if len(options) > 0 {
+		valid := false
+		for _, option := range options {
+			if option.Value() == value {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("Value '%s' of parameter '%s' must be one of: %s",
+				value, parameter.ID(), strings.Join(optionValues(parameter), ", "))
+		}
+	}
+
+	switch parameter.ValueType() {
+	case asv1.AddonParameterValueTypeBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("Value '%s' of parameter '%s' must be a boolean", value, parameter.ID())
+		}
+	case asv1.AddonParameterValueTypeNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("Value '%s' of parameter '%s' must be a number", value, parameter.ID())
+		}
+	case asv1.AddonParameterValueTypeCIDR:
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			return fmt.Errorf("Value '%s' of parameter '%s' must be a CIDR", value, parameter.ID())
+		}
+	}
+
+	if parameter.Validation() != "" {
+		re, err := regexp.Compile(parameter.Validation())
+		if err != nil {
+			return fmt.Errorf("Invalid validation expression for parameter '%s': %v",
+				parameter.ID(), err)
+		}
+		if !re.MatchString(value) {
+			if parameter.ValidationErrMsg() != "" {
+				return fmt.Errorf("Value '%s' of parameter '%s' isn't valid: %s",
+					value, parameter.ID(), parameter.ValidationErrMsg())
+			}
+			return fmt.Errorf("Value '%s' of parameter '%s' must match '%s'",
+				value, parameter.ID(), parameter.Validation())
+		}
+	}
+	return nil
+}
+
+// ValidateValues checks the given values against the parameters of an add-on. The existing values
+// of the installation should be passed when editing, so that changes to parameters that aren't
+// editable are rejected. When installing, required parameters without value get their default
+// value.
+func ValidateValues(parameters []*asv1.AddonParameter, values map[string]string,
+	existing map[string]string) error {
+	known := map[string]*asv1.AddonParameter{}
+	for _, parameter := range parameters {
+		known[parameter.ID()] = parameter
+	}
+
+	for name, value := range values {
+		parameter, ok := known[name]
+		if !ok {
+			return fmt.Errorf("Unknown parameter '%s', valid parameters are: %s",
+				name, strings.Join(parameterIDs(parameters), ", "))
+		}
+		if existing != nil {
+			err := validateChange(parameter, existing[name], value)
+			if err != nil {
+				return err
+			}
+		}
+		err := ValidateValue(parameter, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	if existing != nil {
+		return nil
+	}
+	for _, parameter := range parameters {
+		if !parameter.Required() {
+			continue
+		}
+		if _, ok := values[parameter.ID()]; ok {
+			continue
+		}
+		if parameter.DefaultValue() == "" {
+			return fmt.Errorf("Parameter '%s' is required", parameter.ID())
+		}
+		values[parameter.ID()] = parameter.DefaultValue()
+	}
+	return nil
+}
+
+// validateChange checks that a parameter can be changed from its current value.
+func validateChange(parameter *asv1.AddonParameter, current string, value string) error {
+	if value == current {
+		return nil
+	}
+	if !parameter.Editable() {
+		return fmt.Errorf("Parameter '%s' can't be changed after installation", parameter.ID())
+	}
+	direction := parameter.EditableDirection()
+	if direction == "" || current == "" {
+		return nil
+	}
+	currentNumber, err1 := strconv.ParseFloat(current, 64)
+	valueNumber, err2 := strconv.ParseFloat(value, 64)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+	switch direction {
+	case "up":
+		if valueNumber < currentNumber {
+			return fmt.Errorf("Parameter '%s' can only be increased from %s", parameter.ID(), current)
+		}
+	case "down":
+		if valueNumber > currentNumber {
+			return fmt.Errorf("Parameter '%s' can only be decreased from %s", parameter.ID(), current)
+		}
+	}
+	return nil
+}
+
+// PromptValues asks interactively for the values of the parameters that don't have a value yet.
+// When editing, only editable parameters are prompted for, using their current values as default.
+func PromptValues(parameters []*asv1.AddonParameter, values map[string]string,
+	existing map[string]string) error {
+	// Parameters are presented as flags so that the interactive helpers can be reused:
+	fs := pflag.NewFlagSet("parameters", pflag.ContinueOnError)
+	var interactive bool
+	arguments.AddInteractiveFlag(fs, &interactive)
+	err := fs.Set("interactive", "true")
+	if err != nil {
+		return err
+	}
+
+	for _, parameter := range parameters {
+		name := parameter.ID()
+		if _, ok := values[name]; ok {
+			continue
+		}
+		defaultValue := parameter.DefaultValue()
+		if existing != nil {
+			if !parameter.Editable() {
+				continue
+			}
+			if current, ok := existing[name]; ok {
+				defaultValue = current
+			}
+		}
+
+		usage := parameter.Description()
+		question := parameter.Name()
+		if question == "" {
+			question = name
+		}
+		if parameter.Required() {
+			question += " (required)"
+		}
+
+		switch {
+		case len(parameter.Options()) > 0:
+			fs.String(name, defaultValue, usage)
+			arguments.SetQuestion(fs, name, question+":")
+			options := []arguments.Option{}
+			for _, option := range parameter.Options() {
+				options = append(options, arguments.Option{
+					Value:       option.Value(),
+					Description: option.Name(),
+				})
+			}
+			err = arguments.PromptOneOf(fs, name, options)
+		case parameter.ValueType() == asv1.AddonParameterValueTypeBoolean:
+			boolDefault, _ := strconv.ParseBool(defaultValue)
+			fs.Bool(name, boolDefault, usage)
+			arguments.SetQuestion(fs, name, question+":")
+			err = arguments.PromptBool(fs, name)
+		default:
+			fs.String(name, defaultValue, usage)
+			arguments.SetQuestion(fs, name, question+":")
+			err = arguments.PromptString(fs, name)
+		}
+		if err != nil {
+			return err
+		}
+
+		value := fs.Lookup(name).Value.String()
+		if existing != nil && value == existing[name] {
+			continue
+		}
+		if value == "" && !parameter.Required() {
+			continue
+		}
+		values[name] = value
+	}
+	return nil
+}
+
+func parameterIDs(parameters []*asv1.AddonParameter) []string {
+	ids := make([]string, 0, len(parameters))
+	for _, parameter := range parameters {
+		ids = append(ids, parameter.ID())
+	}
+	return ids
+}
+
+func optionValues(parameter *asv1.AddonParameter) []string {
+	values := make([]string, 0, len(parameter.Options()))
+	for _, option := range parameter.Options() {
+		values = append(values, option.Value())
+	}
+	return values
+}
@@ -0,0 +1,118 @@
+package addon
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	asv1 "github.com/openshift-online/ocm-sdk-go/addonsmgmt/v1"
+)
+
+func buildParameter(builder *asv1.AddonParameterBuilder) *asv1.AddonParameter {
+	parameter, err := builder.Build()
+	Expect(err).To(BeNil())
+	return parameter
+}
+
+var _ = Describe("Collect values", func() {
+	It("gives precedence to the flags over the values file", func() {
+		file := filepath.Join(GinkgoT().TempDir(), "values.yaml")
+		err := os.WriteFile(file, []byte("size: 3\nemail: ops@example.com\nenabled: true\n"), 0600)
+		Expect(err).To(BeNil())
+		values, err := CollectValues(file, []string{"size=5"})
+		Expect(err).To(BeNil())
+		Expect(values).To(Equal(map[string]string{
+			"size":    "5",
+			"email":   "ops@example.com",
+			"enabled": "true",
+		}))
+	})
+	It("rejects flags without value", func() {
+		_, err := CollectValues("", []string{"size"})
+		Expect(err).To(HaveOccurred())
+	})
+	It("rejects values that aren't scalars", func() {
+		file := filepath.Join(GinkgoT().TempDir(), "values.yaml")
+		err := os.WriteFile(file, []byte("size:\n  min: 3\n"), 0600)
+		Expect(err).To(BeNil())
+		_, err = CollectValues(file, nil)
+		Expect(err).To(MatchError(ContainSubstring("must be a scalar")))
+	})
+})
+
+var _ = Describe("Validate value", func() {
+	It("checks the options", func() {
+		parameter := buildParameter(asv1.NewAddonParameter().ID("tier").Options(
+			asv1.NewAddonParameterOption().Value("small"),
+			asv1.NewAddonParameterOption().Value("large"),
+		))
+		Expect(ValidateValue(parameter, "large")).To(Succeed())
+		Expect(ValidateValue(parameter, "medium")).To(MatchError(ContainSubstring("small, large")))
+	})
+	It("checks the value type", func() {
+		parameter := buildParameter(asv1.NewAddonParameter().ID("cidr").
+			ValueType(asv1.AddonParameterValueTypeCIDR))
+		Expect(ValidateValue(parameter, "10.0.0.0/16")).To(Succeed())
+		Expect(ValidateValue(parameter, "10.0.0.0")).To(HaveOccurred())
+	})
+	It("uses the validation error message", func() {
+		parameter := buildParameter(asv1.NewAddonParameter().ID("email").
+			Validation("^[^@]+@[^@]+$").
+			ValidationErrMsg("must be an email address"))
+		Expect(ValidateValue(parameter, "ops@example.com")).To(Succeed())
+		Expect(ValidateValue(parameter, "ops")).To(MatchError(ContainSubstring("must be an email address")))
+	})
+})
+
+var _ = Describe("Validate values", func() {
+	var parameters []*asv1.AddonParameter
+
+	BeforeEach(func() {
+		parameters = []*asv1.AddonParameter{
+			buildParameter(asv1.NewAddonParameter().ID("size").
+				ValueType(asv1.AddonParameterValueTypeNumber).
+				Required(true).
+				Editable(true).
+				EditableDirection("up").
+				DefaultValue("1")),
+			buildParameter(asv1.NewAddonParameter().ID("region").Required(true)),
+			buildParameter(asv1.NewAddonParameter().ID("email")),
+		}
+	})
+
+	When("installing", func() {
+		It("fills the defaults of required parameters", func() {
+			values := map[string]string{"region": "us-east-1"}
+			Expect(ValidateValues(parameters, values, nil)).To(Succeed())
+			Expect(values).To(HaveKeyWithValue("size", "1"))
+			Expect(values).ToNot(HaveKey("email"))
+		})
+		It("fails for required parameters without default", func() {
+			err := ValidateValues(parameters, map[string]string{}, nil)
+			Expect(err).To(MatchError(ContainSubstring("'region' is required")))
+		})
+		It("rejects unknown parameters", func() {
+			err := ValidateValues(parameters, map[string]string{"region": "a", "color": "red"}, nil)
+			Expect(err).To(MatchError(ContainSubstring("Unknown parameter 'color'")))
+		})
+	})
+
+	When("editing", func() {
+		existing := map[string]string{"size": "2", "region": "us-east-1"}
+
+		It("accepts changes in the editable direction", func() {
+			Expect(ValidateValues(parameters, map[string]string{"size": "3"}, existing)).To(Succeed())
+			err := ValidateValues(parameters, map[string]string{"size": "1"}, existing)
+			Expect(err).To(MatchError(ContainSubstring("can only be increased")))
+		})
+		It("rejects changes to parameters that aren't editable", func() {
+			err := ValidateValues(parameters, map[string]string{"region": "eu-west-1"}, existing)
+			Expect(err).To(MatchError(ContainSubstring("can't be changed")))
+		})
+		It("accepts unchanged values of parameters that aren't editable", func() {
+			Expect(ValidateValues(parameters, map[string]string{"region": "us-east-1"}, existing)).
+				To(Succeed())
+		})
+	})
+})
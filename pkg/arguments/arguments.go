@@ -32,6 +32,7 @@ import (
 	"github.com/openshift-online/ocm-cli/pkg/cluster"
 	"github.com/openshift-online/ocm-cli/pkg/debug"
 	"github.com/openshift-online/ocm-cli/pkg/output"
+	"github.com/openshift-online/ocm-cli/pkg/region"
 )
 
 type FilePath string
@@ -58,6 +59,11 @@ func AddDebugFlag(fs *pflag.FlagSet) {
 	debug.AddFlag(fs)
 }
 
+// AddRegionFlag adds the '--rh-region' flag to the given set of command line flags.
+func AddRegionFlag(fs *pflag.FlagSet) {
+	region.AddFlag(fs)
+}
+
 // AddParameterFlag adds the '--parameter' flag to the given set of command line flags.
 func AddParameterFlag(fs *pflag.FlagSet, values *[]string) {
 	fs.StringArrayVarP(
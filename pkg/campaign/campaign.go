@@ -0,0 +1,281 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the types used to describe an upgrade campaign and to persist its progress,
+// so that a campaign can be resumed or reported on after the command exits.
+
+package campaign
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Status values of campaigns, waves and clusters:
+const (
+	StatusPending   = "pending"
+	StatusScheduled = "scheduled"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped"
+	StatusHalted    = "halted"
+)
+
+// Spec describes an upgrade campaign: the clusters that it applies to, the version that they will
+// be upgraded to and the waves that the clusters are upgraded in.
+type Spec struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+	Search  string `json:"search,omitempty" yaml:"search,omitempty"`
+	Waves   []Wave `json:"waves" yaml:"waves"`
+}
+
+// Wave is a group of clusters that are upgraded together. The clusters of a wave are those that
+// match both the search of the campaign and the search of the wave, and that haven't already been
+// assigned to a previous wave.
+type Wave struct {
+	Name              string        `json:"name" yaml:"name"`
+	Search            string        `json:"search,omitempty" yaml:"search,omitempty"`
+	MaxFailures       int           `json:"max_failures,omitempty" yaml:"max_failures,omitempty"`
+	MaxFailurePercent int           `json:"max_failure_percent,omitempty" yaml:"max_failure_percent,omitempty"`
+	Timeout           time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// State is the persisted progress of a campaign.
+type State struct {
+	Spec    Spec        `json:"spec"`
+	Status  string      `json:"status"`
+	Started time.Time   `json:"started"`
+	Updated time.Time   `json:"updated"`
+	Waves   []WaveState `json:"waves"`
+}
+
+// WaveState is the persisted progress of a wave.
+type WaveState struct {
+	Name     string          `json:"name"`
+	Status   string          `json:"status"`
+	Started  time.Time       `json:"started,omitempty"`
+	Finished time.Time       `json:"finished,omitempty"`
+	Clusters []*ClusterState `json:"clusters,omitempty"`
+}
+
+// ClusterState is the persisted progress of the upgrade of a single cluster.
+type ClusterState struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	CurrentVersion string `json:"current_version,omitempty"`
+	PolicyID       string `json:"policy_id,omitempty"`
+	PolicyState    string `json:"policy_state,omitempty"`
+	Status         string `json:"status"`
+	Message        string `json:"message,omitempty"`
+
+	// Scheduled is the time when the current attempt to upgrade the cluster was scheduled,
+	// used to check the timeout of the wave.
+	Scheduled time.Time `json:"scheduled,omitempty"`
+}
+
+// Regular expression used to check campaign names, as they are used to build file names:
+var nameRE = regexp.MustCompile(`^(\w|-|\.)+$`)
+
+// LoadSpec reads a campaign specification from the given YAML or JSON file.
+func LoadSpec(file string) (spec *Spec, err error) {
+	// #nosec G304
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("can't read campaign file '%s': %v", file, err)
+	}
+	spec = &Spec{}
+	err = yaml.Unmarshal(data, spec)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse campaign file '%s': %v", file, err)
+	}
+	return spec, nil
+}
+
+// Validate checks that the specification is complete and consistent.
+func (s *Spec) Validate() error {
+	if !nameRE.MatchString(s.Name) {
+		return fmt.Errorf(
+			"Campaign name '%s' isn't valid: it must contain only letters, digits, "+
+				"dashes, dots and underscores",
+			s.Name,
+		)
+	}
+	if s.Version == "" {
+		return fmt.Errorf("Campaign '%s' doesn't have a target version", s.Name)
+	}
+	if len(s.Waves) == 0 {
+		return fmt.Errorf("Campaign '%s' doesn't have any wave", s.Name)
+	}
+	names := map[string]bool{}
+	for i, wave := range s.Waves {
+		if wave.Name == "" {
+			return fmt.Errorf("Wave %d of campaign '%s' doesn't have a name", i+1, s.Name)
+		}
+		if names[wave.Name] {
+			return fmt.Errorf("Wave name '%s' is used more than once", wave.Name)
+		}
+		names[wave.Name] = true
+		if wave.MaxFailures < 0 {
+			return fmt.Errorf("Maximum failures of wave '%s' can't be negative", wave.Name)
+		}
+		if wave.MaxFailurePercent < 0 || wave.MaxFailurePercent > 100 {
+			return fmt.Errorf("Maximum failure percent of wave '%s' must be between 0 and 100",
+				wave.Name)
+		}
+	}
+	return nil
+}
+
+// SearchQuery returns the query used to select the clusters of the given wave.
+func (s *Spec) SearchQuery(wave Wave) string {
+	var terms []string
+	if s.Search != "" {
+		terms = append(terms, s.Search)
+	}
+	if wave.Search != "" {
+		terms = append(terms, wave.Search)
+	}
+	switch len(terms) {
+	case 0:
+		return ""
+	case 1:
+		return terms[0]
+	default:
+		return fmt.Sprintf("(%s) and (%s)", terms[0], terms[1])
+	}
+}
+
+// NewState creates the initial state of a campaign.
+func NewState(spec Spec) *State {
+	now := time.Now().UTC()
+	state := &State{
+		Spec:    spec,
+		Status:  StatusPending,
+		Started: now,
+		Updated: now,
+	}
+	for _, wave := range spec.Waves {
+		state.Waves = append(state.Waves, WaveState{
+			Name:   wave.Name,
+			Status: StatusPending,
+		})
+	}
+	return state
+}
+
+// Assigned returns true if the cluster with the given identifier already belongs to a wave.
+func (s *State) Assigned(clusterID string) bool {
+	for _, wave := range s.Waves {
+		for _, cluster := range wave.Clusters {
+			if cluster.ID == clusterID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Counts returns the number of clusters of the wave that have completed, failed, or are still in
+// progress.
+func (w *WaveState) Counts() (completed, failed, pending int) {
+	for _, cluster := range w.Clusters {
+		switch cluster.Status {
+		case StatusCompleted, StatusSkipped:
+			completed++
+		case StatusFailed:
+			failed++
+		default:
+			pending++
+		}
+	}
+	return
+}
+
+// ThresholdExceeded checks if the failures of the wave are above what the wave specification
+// tolerates. When the wave has a maximum failure percent it takes precedence over the absolute
+// maximum number of failures.
+func (w *WaveState) ThresholdExceeded(wave Wave) bool {
+	_, failed, _ := w.Counts()
+	if failed == 0 {
+		return false
+	}
+	if wave.MaxFailurePercent > 0 {
+		return failed*100 > wave.MaxFailurePercent*len(w.Clusters)
+	}
+	return failed > wave.MaxFailures
+}
+
+// DefaultStateFile returns the file where the state of the named campaign is stored when no
+// explicit file is given.
+func DefaultStateFile(name string) (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "ocm", "campaigns", name+".json"), nil
+}
+
+// LoadState reads the state of a campaign from the given file. It returns nil if the file doesn't
+// exist.
+func LoadState(file string) (state *State, err error) {
+	// #nosec G304
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't read campaign state file '%s': %v", file, err)
+	}
+	state = &State{}
+	err = json.Unmarshal(data, state)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse campaign state file '%s': %v", file, err)
+	}
+	return state, nil
+}
+
+// Save writes the state of the campaign to the given file.
+func (s *State) Save(file string) error {
+	s.Updated = time.Now().UTC()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("can't marshal campaign state: %v", err)
+	}
+	dir := filepath.Dir(file)
+	err = os.MkdirAll(dir, os.FileMode(0755))
+	if err != nil {
+		return fmt.Errorf("can't create directory %s: %v", dir, err)
+	}
+	// Write to a temporary file first so that an interrupted write doesn't corrupt the state:
+	tmp := file + ".tmp"
+	err = os.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return fmt.Errorf("can't write file '%s': %v", tmp, err)
+	}
+	err = os.Rename(tmp, file)
+	if err != nil {
+		return fmt.Errorf("can't write file '%s': %v", file, err)
+	}
+	return nil
+}
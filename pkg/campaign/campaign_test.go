@@ -0,0 +1,121 @@
+package campaign
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Campaign", func() {
+	Context("Validates spec", func() {
+		It("Accepts a complete spec", func() {
+			spec := Spec{Name: "osd-4.15", Version: "4.15.3", Waves: []Wave{{Name: "canary"}}}
+			Expect(spec.Validate()).To(Succeed())
+		})
+
+		It("Rejects a spec without waves", func() {
+			spec := Spec{Name: "osd-4.15", Version: "4.15.3"}
+			Expect(spec.Validate()).To(MatchError(ContainSubstring("doesn't have any wave")))
+		})
+
+		It("Rejects duplicated wave names", func() {
+			spec := Spec{Name: "osd-4.15", Version: "4.15.3", Waves: []Wave{{Name: "a"}, {Name: "a"}}}
+			Expect(spec.Validate()).To(MatchError(ContainSubstring("more than once")))
+		})
+
+		It("Rejects unsafe names", func() {
+			spec := Spec{Name: "../x", Version: "4.15.3", Waves: []Wave{{Name: "a"}}}
+			Expect(spec.Validate()).To(MatchError(ContainSubstring("isn't valid")))
+		})
+	})
+
+	Context("Builds search queries", func() {
+		It("Combines campaign and wave search", func() {
+			spec := Spec{Search: "product.id = 'osd'"}
+			Expect(spec.SearchQuery(Wave{Search: "name like 'c-%'"})).To(
+				Equal("(product.id = 'osd') and (name like 'c-%')"))
+		})
+
+		It("Uses only the defined search", func() {
+			spec := Spec{}
+			Expect(spec.SearchQuery(Wave{Search: "name like 'c-%'"})).To(Equal("name like 'c-%'"))
+			Expect(spec.SearchQuery(Wave{})).To(BeEmpty())
+		})
+	})
+
+	Context("Evaluates failure thresholds", func() {
+		wave := WaveState{Clusters: []*ClusterState{
+			{Status: StatusCompleted},
+			{Status: StatusFailed},
+			{Status: StatusScheduled},
+			{Status: StatusSkipped},
+		}}
+
+		It("Counts clusters by status", func() {
+			completed, failed, pending := wave.Counts()
+			Expect(completed).To(Equal(2))
+			Expect(failed).To(Equal(1))
+			Expect(pending).To(Equal(1))
+		})
+
+		It("Halts on any failure by default", func() {
+			Expect(wave.ThresholdExceeded(Wave{})).To(BeTrue())
+		})
+
+		It("Tolerates the maximum number of failures", func() {
+			Expect(wave.ThresholdExceeded(Wave{MaxFailures: 1})).To(BeFalse())
+		})
+
+		It("Prefers the maximum failure percent", func() {
+			Expect(wave.ThresholdExceeded(Wave{MaxFailures: 5, MaxFailurePercent: 20})).To(BeTrue())
+			Expect(wave.ThresholdExceeded(Wave{MaxFailurePercent: 25})).To(BeFalse())
+		})
+	})
+
+	Context("Persists state", func() {
+		It("Saves and loads the state", func() {
+			file := filepath.Join(GinkgoT().TempDir(), "campaigns", "test.json")
+			state := NewState(Spec{Name: "test", Version: "4.15.3", Waves: []Wave{{
+				Name:    "canary",
+				Timeout: time.Hour,
+			}}})
+			state.Waves[0].Clusters = append(state.Waves[0].Clusters, &ClusterState{
+				ID:     "123",
+				Status: StatusScheduled,
+			})
+			Expect(state.Save(file)).To(Succeed())
+
+			loaded, err := LoadState(file)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loaded.Spec.Waves[0].Timeout).To(Equal(time.Hour))
+			Expect(loaded.Assigned("123")).To(BeTrue())
+			Expect(loaded.Assigned("456")).To(BeFalse())
+		})
+
+		It("Returns nil for a missing state file", func() {
+			state, err := LoadState(filepath.Join(GinkgoT().TempDir(), "missing.json"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(state).To(BeNil())
+		})
+
+		It("Loads the spec from YAML", func() {
+			file := filepath.Join(GinkgoT().TempDir(), "campaign.yaml")
+			Expect(os.WriteFile(file, []byte(`name: test
+version: 4.15.3
+waves:
+- name: canary
+  search: name like 'c-%'
+  max_failures: 1
+  timeout: 2h
+`), 0600)).To(Succeed())
+			spec, err := LoadSpec(file)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(spec.Waves).To(HaveLen(1))
+			Expect(spec.Waves[0].MaxFailures).To(Equal(1))
+			Expect(spec.Waves[0].Timeout).To(Equal(2 * time.Hour))
+		})
+	})
+})
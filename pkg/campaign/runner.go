@@ -0,0 +1,380 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the logic that drives a campaign wave by wave.
+
+package campaign
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/utils"
+)
+
+// Runner executes a campaign, saving its state after every change. Don't create instances of this
+// type directly; use the NewRunner function instead.
+type Runner struct {
+	connection   *sdk.Connection
+	state        *State
+	stateFile    string
+	pollInterval time.Duration
+	scheduleIn   time.Duration
+	dryRun       bool
+	out          io.Writer
+}
+
+// NewRunner creates a runner for the campaign with the given state.
+func NewRunner(connection *sdk.Connection, state *State, stateFile string) *Runner {
+	return &Runner{
+		connection:   connection,
+		state:        state,
+		stateFile:    stateFile,
+		pollInterval: time.Minute,
+		scheduleIn:   10 * time.Minute,
+		out:          io.Discard,
+	}
+}
+
+// PollInterval sets the time to wait between checks of the upgrade policies of a wave.
+func (r *Runner) PollInterval(value time.Duration) *Runner {
+	r.pollInterval = value
+	return r
+}
+
+// DryRun makes the runner select the clusters of each wave without creating upgrade policies.
+func (r *Runner) DryRun(value bool) *Runner {
+	r.dryRun = value
+	return r
+}
+
+// Output sets the writer where progress messages are written.
+func (r *Runner) Output(value io.Writer) *Runner {
+	r.out = value
+	return r
+}
+
+// Run executes the pending waves of the campaign, one after the other. It returns an error if a
+// wave exceeds its failure threshold, in which case the campaign is halted.
+func (r *Runner) Run(ctx context.Context) error {
+	r.state.Status = StatusRunning
+	for i := range r.state.Waves {
+		wave := r.state.Spec.Waves[i]
+		waveState := &r.state.Waves[i]
+		if waveState.Status == StatusCompleted {
+			continue
+		}
+		err := r.runWave(ctx, wave, waveState)
+		if err != nil {
+			return err
+		}
+		if r.dryRun {
+			continue
+		}
+		if waveState.ThresholdExceeded(wave) {
+			waveState.Status = StatusFailed
+			r.state.Status = StatusHalted
+			err = r.save()
+			if err != nil {
+				return err
+			}
+			_, failed, _ := waveState.Counts()
+			return fmt.Errorf(
+				"Wave '%s' has %d failed clusters out of %d, campaign '%s' halted",
+				wave.Name, failed, len(waveState.Clusters), r.state.Spec.Name,
+			)
+		}
+		waveState.Status = StatusCompleted
+		waveState.Finished = time.Now().UTC()
+		err = r.save()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(r.out, "Wave '%s' completed\n", wave.Name)
+	}
+	if r.dryRun {
+		return nil
+	}
+	r.state.Status = StatusCompleted
+	return r.save()
+}
+
+func (r *Runner) runWave(ctx context.Context, wave Wave, waveState *WaveState) error {
+	if waveState.Status == StatusPending {
+		err := r.selectClusters(wave, waveState)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(r.out, "Wave '%s' has %d clusters\n", wave.Name, len(waveState.Clusters))
+		if r.dryRun {
+			for _, cluster := range waveState.Clusters {
+				fmt.Fprintf(r.out, "  %s (%s) %s -> %s\n", cluster.Name, cluster.ID,
+					cluster.CurrentVersion, r.state.Spec.Version)
+			}
+			return nil
+		}
+		waveState.Status = StatusRunning
+		waveState.Started = time.Now().UTC()
+	}
+
+	// A dry run of a campaign that was already started only shows the state of the clusters,
+	// without scheduling or checking anything:
+	if r.dryRun {
+		fmt.Fprintf(r.out, "Wave '%s' is %s\n", wave.Name, waveState.Status)
+		for _, cluster := range waveState.Clusters {
+			action := cluster.Status
+			if cluster.Status == StatusFailed {
+				action = "would be retried"
+			}
+			fmt.Fprintf(r.out, "  %s (%s) %s\n", cluster.Name, cluster.ID, action)
+		}
+		return nil
+	}
+
+	// Clusters that failed in a previous execution are retried when the campaign is resumed:
+	for _, cluster := range waveState.Clusters {
+		if cluster.Status == StatusFailed {
+			cluster.Status = StatusPending
+			cluster.PolicyID = ""
+			cluster.Message = ""
+			cluster.Scheduled = time.Time{}
+		}
+	}
+
+	for _, cluster := range waveState.Clusters {
+		if cluster.Status == StatusPending {
+			r.schedule(cluster)
+			if cluster.Status == StatusScheduled {
+				cluster.Scheduled = time.Now().UTC()
+			}
+			fmt.Fprintf(r.out, "  %s: %s %s\n", cluster.Name, cluster.Status, cluster.Message)
+		}
+	}
+	err := r.save()
+	if err != nil {
+		return err
+	}
+
+	return r.wait(ctx, wave, waveState)
+}
+
+// selectClusters finds the clusters that belong to the wave.
+func (r *Runner) selectClusters(wave Wave, waveState *WaveState) error {
+	request := r.connection.ClustersMgmt().V1().Clusters().List().
+		Search(r.state.Spec.SearchQuery(wave))
+	size := 100
+	index := 1
+	for {
+		response, err := request.Size(size).Page(index).Send()
+		if err != nil {
+			return fmt.Errorf("Can't retrieve clusters of wave '%s': %v", wave.Name, err)
+		}
+		response.Items().Each(func(cluster *cmv1.Cluster) bool {
+			if r.state.Assigned(cluster.ID()) {
+				return true
+			}
+			clusterState := &ClusterState{
+				ID:             cluster.ID(),
+				Name:           cluster.Name(),
+				CurrentVersion: cluster.OpenshiftVersion(),
+				Status:         StatusPending,
+			}
+			switch {
+			case cluster.OpenshiftVersion() == r.state.Spec.Version:
+				clusterState.Status = StatusSkipped
+				clusterState.Message = "already at target version"
+			case cluster.State() != cmv1.ClusterStateReady:
+				clusterState.Status = StatusFailed
+				clusterState.Message = fmt.Sprintf("cluster is %s", cluster.State())
+			}
+			waveState.Clusters = append(waveState.Clusters, clusterState)
+			return true
+		})
+		if response.Size() < size {
+			break
+		}
+		index++
+	}
+	return nil
+}
+
+// schedule creates the upgrade policy of a cluster, or adopts an existing one for the same version.
+func (r *Runner) schedule(clusterState *ClusterState) {
+	cmv1Client := r.connection.ClustersMgmt().V1()
+	clusters := cmv1Client.Clusters()
+
+	policies, err := c.GetUpgradePolicies(clusters, clusterState.ID)
+	if err != nil {
+		clusterState.Status = StatusFailed
+		clusterState.Message = err.Error()
+		return
+	}
+	for _, policy := range policies {
+		if policy.ScheduleType() == cmv1.ScheduleTypeManual && policy.Version() == r.state.Spec.Version {
+			clusterState.PolicyID = policy.ID()
+			clusterState.Status = StatusScheduled
+			clusterState.Message = "adopted existing upgrade policy"
+			return
+		}
+	}
+	if len(policies) > 0 {
+		clusterState.Status = StatusFailed
+		clusterState.Message = fmt.Sprintf("cluster already has upgrade policy '%s'", policies[0].ID())
+		return
+	}
+
+	response, err := clusters.Cluster(clusterState.ID).Get().Send()
+	if err != nil {
+		clusterState.Status = StatusFailed
+		clusterState.Message = fmt.Sprintf("can't retrieve cluster: %v", err)
+		return
+	}
+	cluster := response.Body()
+	availableUpgrades, err := c.GetAvailableUpgrades(cmv1Client, c.GetVersionID(cluster),
+		cluster.Product().ID())
+	if err != nil {
+		clusterState.Status = StatusFailed
+		clusterState.Message = err.Error()
+		return
+	}
+	if !utils.Contains(availableUpgrades, r.state.Spec.Version) {
+		clusterState.Status = StatusFailed
+		clusterState.Message = fmt.Sprintf("version %s isn't an available upgrade from %s",
+			r.state.Spec.Version, cluster.OpenshiftVersion())
+		return
+	}
+
+	policy, err := c.AddUpgradePolicy(clusters, clusterState.ID, cmv1.NewUpgradePolicy().
+		ScheduleType(cmv1.ScheduleTypeManual).
+		NextRun(time.Now().UTC().Add(r.scheduleIn)).
+		Version(r.state.Spec.Version))
+	if err != nil {
+		clusterState.Status = StatusFailed
+		clusterState.Message = err.Error()
+		return
+	}
+	clusterState.PolicyID = policy.ID()
+	clusterState.Status = StatusScheduled
+	clusterState.Message = ""
+}
+
+// wait polls the upgrade policies of the wave till all of them have finished, the failure
+// threshold is exceeded or the wave times out. The timeout is measured for each cluster from the
+// time its upgrade was scheduled, so that clusters retried when the campaign is resumed get the
+// complete timeout.
+func (r *Runner) wait(ctx context.Context, wave Wave, waveState *WaveState) error {
+	for {
+		for _, cluster := range waveState.Clusters {
+			if cluster.Status != StatusScheduled && cluster.Status != StatusRunning {
+				continue
+			}
+			previous := cluster.Status
+			r.check(cluster)
+			if cluster.Status == previous && r.timedOut(wave, waveState, cluster) {
+				cluster.Status = StatusFailed
+				cluster.Message = fmt.Sprintf("upgrade didn't finish within %s", wave.Timeout)
+			}
+			if cluster.Status != previous {
+				fmt.Fprintf(r.out, "  %s: %s %s\n", cluster.Name, cluster.Status, cluster.Message)
+			}
+		}
+		err := r.save()
+		if err != nil {
+			return err
+		}
+
+		_, _, pending := waveState.Counts()
+		if pending == 0 || waveState.ThresholdExceeded(wave) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Campaign '%s' interrupted, resume it to continue: %v",
+				r.state.Spec.Name, ctx.Err())
+		case <-time.After(r.pollInterval):
+		}
+	}
+}
+
+// timedOut checks if the upgrade of a cluster has exceeded the timeout of the wave. States saved
+// before the schedule time was recorded use the start of the wave.
+func (r *Runner) timedOut(wave Wave, waveState *WaveState, clusterState *ClusterState) bool {
+	if wave.Timeout <= 0 {
+		return false
+	}
+	start := clusterState.Scheduled
+	if start.IsZero() {
+		start = waveState.Started
+	}
+	return time.Now().After(start.Add(wave.Timeout))
+}
+
+// check updates the state of a cluster from the state of its upgrade policy.
+func (r *Runner) check(clusterState *ClusterState) {
+	clusters := r.connection.ClustersMgmt().V1().Clusters()
+	policyState, err := c.GetUpgradePolicyState(clusters, clusterState.ID, clusterState.PolicyID)
+	if err != nil {
+		// Transient errors are ignored, the state will be checked again in the next iteration:
+		clusterState.Message = err.Error()
+		return
+	}
+
+	if policyState != nil {
+		clusterState.PolicyState = string(policyState.Value())
+		switch policyState.Value() {
+		case cmv1.UpgradePolicyStateValueCompleted:
+			clusterState.Status = StatusCompleted
+			clusterState.Message = ""
+		case cmv1.UpgradePolicyStateValueFailed, cmv1.UpgradePolicyStateValueCancelled:
+			clusterState.Status = StatusFailed
+			clusterState.Message = policyState.Description()
+		case cmv1.UpgradePolicyStateValueStarted:
+			clusterState.Status = StatusRunning
+		}
+		return
+	}
+
+	// The policy no longer exists, so check the version of the cluster to find out if the
+	// upgrade happened:
+	response, err := clusters.Cluster(clusterState.ID).Get().Send()
+	if err != nil {
+		clusterState.Message = fmt.Sprintf("can't retrieve cluster: %v", err)
+		return
+	}
+	clusterState.CurrentVersion = response.Body().OpenshiftVersion()
+	if clusterState.CurrentVersion == r.state.Spec.Version {
+		clusterState.Status = StatusCompleted
+		clusterState.Message = ""
+	} else {
+		clusterState.Status = StatusFailed
+		clusterState.Message = fmt.Sprintf("upgrade policy '%s' was removed and cluster is at %s",
+			clusterState.PolicyID, clusterState.CurrentVersion)
+	}
+}
+
+func (r *Runner) save() error {
+	if r.dryRun {
+		return nil
+	}
+	return r.state.Save(r.stateFile)
+}
@@ -26,6 +26,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/openshift-online/ocm-cli/pkg/network"
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 	asv1 "github.com/openshift-online/ocm-sdk-go/addonsmgmt/v1"
@@ -734,6 +735,44 @@ func GetUpgradePolicies(client *cmv1.ClustersClient, clusterID string) ([]*cmv1.
 	return response.Items().Slice(), nil
 }
 
+func AddUpgradePolicy(client *cmv1.ClustersClient, clusterID string,
+	builder *cmv1.UpgradePolicyBuilder) (*cmv1.UpgradePolicy, error) {
+	upgradePolicy, err := builder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to build upgrade policy for cluster '%s': %v", clusterID, err)
+	}
+
+	response, err := client.Cluster(clusterID).UpgradePolicies().
+		Add().
+		Body(upgradePolicy).
+		Send()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create upgrade policy for cluster '%s': %v", clusterID, err)
+	}
+
+	return response.Body(), nil
+}
+
+// GetUpgradePolicyState returns the state of the given upgrade policy. Upgrade policies are
+// removed once they run, so a nil state without error means that the policy no longer exists.
+func GetUpgradePolicyState(client *cmv1.ClustersClient, clusterID string,
+	upgradePolicyID string) (*cmv1.UpgradePolicyState, error) {
+	response, err := client.Cluster(clusterID).UpgradePolicies().
+		UpgradePolicy(upgradePolicyID).
+		State().
+		Get().
+		Send()
+	if response != nil && response.Status() == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get state of upgrade policy '%s' for cluster '%s': %v",
+			upgradePolicyID, clusterID, err)
+	}
+
+	return response.Body(), nil
+}
+
 func GetClusterAddOns(connection *sdk.Connection, clusterID string) ([]*AddOnItem, error) {
 	// Get organization ID (used to get add-on quotas)
 	acctResponse, err := connection.AccountsMgmt().V1().CurrentAccount().
@@ -884,6 +923,24 @@ func cidrIsEmpty(cidr net.IPNet) bool {
 	return cidr.String() == "<nil>"
 }
 
+// NetworkPlan returns the network plan of the given cluster configuration, with the CIDRs that
+// are empty unset.
+func NetworkPlan(config Spec) *network.Plan {
+	plan := &network.Plan{
+		HostPrefix: config.HostPrefix,
+	}
+	if !cidrIsEmpty(config.MachineCIDR) {
+		plan.MachineCIDR = &config.MachineCIDR
+	}
+	if !cidrIsEmpty(config.ServiceCIDR) {
+		plan.ServiceCIDR = &config.ServiceCIDR
+	}
+	if !cidrIsEmpty(config.PodCIDR) {
+		plan.PodCIDR = &config.PodCIDR
+	}
+	return plan
+}
+
 func ValidateClusterExpiration(
 	expirationTime string,
 	expirationDuration time.Duration,
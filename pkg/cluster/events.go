@@ -0,0 +1,412 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to build the timeline of events of a cluster.
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+// Sources of the events of the timeline:
+const (
+	EventSourceCluster        = "cluster"
+	EventSourceServiceLog     = "service-log"
+	EventSourceLimitedSupport = "limited-support"
+	EventSourceUpgrade        = "upgrade"
+	EventSourceSubscription   = "subscription"
+)
+
+// Event is an entry of the timeline of a cluster.
+type Event struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Source      string    `json:"source"`
+	Severity    string    `json:"severity"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description,omitempty"`
+}
+
+// EventInput contains the data used to build the timeline of a cluster.
+type EventInput struct {
+	Cluster               *cmv1.Cluster
+	ServiceLogs           []*slv1.LogEntry
+	LimitedSupportReasons []*cmv1.LimitedSupportReason
+	Upgrades              []UpgradeInfo
+	Subscription          *amv1.Subscription
+}
+
+// EventFilter selects the events of the timeline. Empty fields select all the events.
+type EventFilter struct {
+	Since      time.Time
+	Severities []string
+	Sources    []string
+}
+
+// GetEventInput retrieves the data needed to build the timeline of the cluster. The time and
+// severities of the filter are used to select the service logs in the server, so that the whole
+// history of the cluster isn't downloaded.
+func GetEventInput(connection *sdk.Connection, cluster *cmv1.Cluster,
+	filter EventFilter) (*EventInput, error) {
+	input := &EventInput{
+		Cluster: cluster,
+	}
+	clusterClient := connection.ClustersMgmt().V1().Clusters().Cluster(cluster.ID())
+
+	if len(filter.Sources) == 0 || containsFold(filter.Sources, EventSourceServiceLog) {
+		search := ServiceLogSearch(filter)
+		size := 100
+		for page := 1; ; page++ {
+			request := connection.ServiceLogs().V1().Clusters().ClusterLogs().List().
+				ClusterID(cluster.ID()).
+				Page(page).
+				Size(size)
+			if search != "" {
+				request = request.Search(search)
+			}
+			response, err := request.Send()
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get service logs: %v", err)
+			}
+			input.ServiceLogs = append(input.ServiceLogs, response.Items().Slice()...)
+			if response.Size() < size {
+				break
+			}
+		}
+	}
+
+	reasonsResponse, err := clusterClient.LimitedSupportReasons().List().Size(-1).Send()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get limited support reasons: %v", err)
+	}
+	input.LimitedSupportReasons = reasonsResponse.Items().Slice()
+
+	input.Upgrades, err = getUpgrades(clusterClient, cluster.Hypershift().Enabled())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get upgrade policies: %v", err)
+	}
+
+	if subscriptionID := cluster.Subscription().ID(); subscriptionID != "" {
+		subscriptionResponse, err := connection.AccountsMgmt().V1().Subscriptions().
+			Subscription(subscriptionID).Get().Send()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get subscription: %v", err)
+		}
+		input.Subscription = subscriptionResponse.Body()
+	}
+
+	return input, nil
+}
+
+// ServiceLogSearch returns the search query that selects the service logs matching the time and
+// severities of the filter, or an empty string if the filter selects all of them.
+func ServiceLogSearch(filter EventFilter) string {
+	var clauses []string
+	if !filter.Since.IsZero() {
+		clauses = append(clauses, fmt.Sprintf("timestamp >= '%s'",
+			filter.Since.UTC().Format(time.RFC3339)))
+	}
+	if len(filter.Severities) > 0 {
+		values := make([]string, len(filter.Severities))
+		for i, severity := range filter.Severities {
+			// The server compares the severities exactly, so use the spelling of the API:
+			for _, known := range serviceLogSeverities {
+				if strings.EqualFold(severity, string(known)) {
+					severity = string(known)
+					break
+				}
+			}
+			values[i] = fmt.Sprintf("'%s'", strings.ReplaceAll(severity, "'", "''"))
+		}
+		clauses = append(clauses, fmt.Sprintf("severity in (%s)", strings.Join(values, ", ")))
+	}
+	return strings.Join(clauses, " and ")
+}
+
+var serviceLogSeverities = []slv1.Severity{
+	slv1.SeverityDebug,
+	slv1.SeverityInfo,
+	slv1.SeverityWarning,
+	slv1.SeverityError,
+	slv1.SeverityFatal,
+}
+
+// BuildTimeline merges the data of the cluster into a list of events sorted by time. The API only
+// keeps the current state of limited support reasons, upgrade policies and subscriptions, so
+// their events reflect when they were created or last updated.
+func BuildTimeline(input *EventInput) []Event {
+	var events []Event
+	cluster := input.Cluster
+	if created := cluster.CreationTimestamp(); !created.IsZero() {
+		events = append(events, Event{
+			Timestamp: created,
+			Source:    EventSourceCluster,
+			Severity:  string(slv1.SeverityInfo),
+			Summary:   fmt.Sprintf("Cluster '%s' created", cluster.Name()),
+		})
+	}
+
+	for _, entry := range input.ServiceLogs {
+		events = append(events, Event{
+			Timestamp:   entry.Timestamp(),
+			Source:      EventSourceServiceLog,
+			Severity:    string(entry.Severity()),
+			Summary:     entry.Summary(),
+			Description: entry.Description(),
+		})
+	}
+
+	for _, reason := range input.LimitedSupportReasons {
+		events = append(events, Event{
+			Timestamp:   reason.CreationTimestamp(),
+			Source:      EventSourceLimitedSupport,
+			Severity:    string(slv1.SeverityError),
+			Summary:     fmt.Sprintf("Limited support reason added: %s", reason.Summary()),
+			Description: reason.Details(),
+		})
+	}
+
+	for _, upgrade := range input.Upgrades {
+		if !upgrade.CreatedAt.IsZero() {
+			events = append(events, Event{
+				Timestamp: upgrade.CreatedAt,
+				Source:    EventSourceUpgrade,
+				Severity:  string(slv1.SeverityInfo),
+				Summary:   fmt.Sprintf("Upgrade to %s requested", upgrade.Version),
+			})
+		}
+		timestamp := upgrade.UpdatedAt
+		if timestamp.IsZero() {
+			timestamp = upgrade.NextRun
+		}
+		severity := slv1.SeverityInfo
+		switch upgrade.State {
+		case cmv1.UpgradePolicyStateValueFailed:
+			severity = slv1.SeverityError
+		case cmv1.UpgradePolicyStateValueDelayed:
+			severity = slv1.SeverityWarning
+		}
+		events = append(events, Event{
+			Timestamp:   timestamp,
+			Source:      EventSourceUpgrade,
+			Severity:    string(severity),
+			Summary:     fmt.Sprintf("Upgrade to %s is %s", upgrade.Version, upgrade.State),
+			Description: upgrade.Description,
+		})
+	}
+
+	if subscription := input.Subscription; subscription != nil {
+		if created := subscription.CreatedAt(); !created.IsZero() {
+			events = append(events, Event{
+				Timestamp: created,
+				Source:    EventSourceSubscription,
+				Severity:  string(slv1.SeverityInfo),
+				Summary:   "Subscription created",
+			})
+		}
+		if updated := subscription.UpdatedAt(); !updated.IsZero() &&
+			!updated.Equal(subscription.CreatedAt()) {
+			severity := slv1.SeverityInfo
+			if !strings.EqualFold(subscription.Status(), "active") {
+				severity = slv1.SeverityWarning
+			}
+			events = append(events, Event{
+				Timestamp: updated,
+				Source:    EventSourceSubscription,
+				Severity:  string(severity),
+				Summary:   fmt.Sprintf("Subscription updated, status is %s", subscription.Status()),
+			})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+	return events
+}
+
+// FilterEvents returns the events selected by the filter.
+func FilterEvents(events []Event, filter EventFilter) []Event {
+	var result []Event
+	for _, event := range events {
+		if !filter.Since.IsZero() && event.Timestamp.Before(filter.Since) {
+			continue
+		}
+		if len(filter.Severities) > 0 && !containsFold(filter.Severities, event.Severity) {
+			continue
+		}
+		if len(filter.Sources) > 0 && !containsFold(filter.Sources, event.Source) {
+			continue
+		}
+		result = append(result, event)
+	}
+	return result
+}
+
+func containsFold(values []string, value string) bool {
+	for _, item := range values {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+var daysRE = regexp.MustCompile(`^(\d+)d$`)
+
+// ParseSince parses the value of the '--since' flag, which is a date in RFC3339 or 'YYYY-MM-DD'
+// format, or a duration like '36h' or '7d' that is subtracted from the given time.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if match := daysRE.FindStringSubmatch(value); match != nil {
+		days, err := strconv.Atoi(match[1])
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.AddDate(0, 0, -days), nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("Invalid time '%s', use a duration like '36h' or '7d', or a "+
+		"date like '2024-05-01'", value)
+}
+
+// PrintEvents writes the events as a table.
+func PrintEvents(stream io.Writer, events []Event) error {
+	writer := tabwriter.NewWriter(stream, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "TIME\tSEVERITY\tSOURCE\tSUMMARY\n")
+	for _, event := range events {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", event.Timestamp.Format(time.RFC3339),
+			event.Severity, event.Source, event.Summary)
+	}
+	return writer.Flush()
+}
+
+// ServiceLogTemplate is a template of a service log entry. The '${NAME}' placeholders of the text
+// fields are replaced with the values of the parameters.
+type ServiceLogTemplate struct {
+	Severity      string   `json:"severity"`
+	ServiceName   string   `json:"service_name"`
+	Summary       string   `json:"summary"`
+	Description   string   `json:"description"`
+	InternalOnly  bool     `json:"internal_only"`
+	LogType       string   `json:"log_type"`
+	EventStreamID string   `json:"event_stream_id"`
+	DocReferences []string `json:"doc_references"`
+}
+
+var placeholderRE = regexp.MustCompile(`\$\{([A-Za-z0-9_]+)\}`)
+
+// LoadServiceLogTemplate reads a service log template from a JSON file and replaces its
+// placeholders with the given parameters.
+func LoadServiceLogTemplate(file string, params map[string]string) (*ServiceLogTemplate, error) {
+	// #nosec G304
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read template '%s': %v", file, err)
+	}
+	template := &ServiceLogTemplate{}
+	err = json.Unmarshal(data, template)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse template '%s': %v", file, err)
+	}
+
+	var missing []string
+	replace := func(text string) string {
+		return placeholderRE.ReplaceAllStringFunc(text, func(placeholder string) string {
+			name := placeholderRE.FindStringSubmatch(placeholder)[1]
+			value, ok := params[name]
+			if !ok {
+				missing = append(missing, name)
+				return placeholder
+			}
+			return value
+		})
+	}
+	template.Summary = replace(template.Summary)
+	template.Description = replace(template.Description)
+	template.ServiceName = replace(template.ServiceName)
+	for i, reference := range template.DocReferences {
+		template.DocReferences[i] = replace(reference)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("Template '%s' needs parameters %s, use --param", file,
+			strings.Join(missing, ", "))
+	}
+
+	if template.Summary == "" {
+		return nil, fmt.Errorf("Template '%s' doesn't have a summary", file)
+	}
+	if template.Severity == "" {
+		template.Severity = string(slv1.SeverityInfo)
+	}
+	var severity string
+	for _, value := range []slv1.Severity{slv1.SeverityDebug, slv1.SeverityInfo,
+		slv1.SeverityWarning, slv1.SeverityError, slv1.SeverityFatal} {
+		if strings.EqualFold(template.Severity, string(value)) {
+			severity = string(value)
+		}
+	}
+	if severity == "" {
+		return nil, fmt.Errorf("Template '%s' has invalid severity '%s'", file, template.Severity)
+	}
+	template.Severity = severity
+	if template.ServiceName == "" {
+		template.ServiceName = "SREManualAction"
+	}
+	return template, nil
+}
+
+// BuildServiceLog creates the service log entry of the template for the cluster.
+func BuildServiceLog(template *ServiceLogTemplate, cluster *cmv1.Cluster) (*slv1.LogEntry, error) {
+	builder := slv1.NewLogEntry().
+		ClusterID(cluster.ID()).
+		ClusterUUID(cluster.ExternalID()).
+		SubscriptionID(cluster.Subscription().ID()).
+		Severity(slv1.Severity(template.Severity)).
+		ServiceName(template.ServiceName).
+		Summary(template.Summary).
+		Description(template.Description).
+		InternalOnly(template.InternalOnly).
+		DocReferences(template.DocReferences...)
+	if template.LogType != "" {
+		builder.LogType(slv1.LogType(template.LogType))
+	}
+	if template.EventStreamID != "" {
+		builder.EventStreamID(template.EventStreamID)
+	}
+	return builder.Build()
+}
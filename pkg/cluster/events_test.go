@@ -0,0 +1,158 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	entry, err := slv1.NewLogEntry().Timestamp(start.Add(48 * time.Hour)).
+		Severity(slv1.SeverityWarning).Summary("Node is unhealthy").Build()
+	if err != nil {
+		t.Fatalf("failed to build log entry: %v", err)
+	}
+	reason, err := cmv1.NewLimitedSupportReason().CreationTimestamp(start.Add(72 * time.Hour)).
+		Summary("Cluster is unreachable").Build()
+	if err != nil {
+		t.Fatalf("failed to build reason: %v", err)
+	}
+	subscription, err := amv1.NewSubscription().CreatedAt(start.Add(time.Hour)).
+		UpdatedAt(start.Add(24 * time.Hour)).Status("Disconnected").Build()
+	if err != nil {
+		t.Fatalf("failed to build subscription: %v", err)
+	}
+	input := &EventInput{
+		Cluster: newTestCluster(t, cmv1.NewCluster().Name("mycluster").
+			CreationTimestamp(start)),
+		ServiceLogs:           []*slv1.LogEntry{entry},
+		LimitedSupportReasons: []*cmv1.LimitedSupportReason{reason},
+		Upgrades: []UpgradeInfo{{
+			Version:   "4.15.2",
+			State:     cmv1.UpgradePolicyStateValueFailed,
+			CreatedAt: start.Add(36 * time.Hour),
+			UpdatedAt: start.Add(96 * time.Hour),
+		}},
+		Subscription: subscription,
+	}
+
+	events := BuildTimeline(input)
+	expected := []string{
+		"Cluster 'mycluster' created",
+		"Subscription created",
+		"Subscription updated, status is Disconnected",
+		"Upgrade to 4.15.2 requested",
+		"Node is unhealthy",
+		"Limited support reason added: Cluster is unreachable",
+		"Upgrade to 4.15.2 is failed",
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %+v", len(expected), len(events), events)
+	}
+	for i, summary := range expected {
+		if events[i].Summary != summary {
+			t.Errorf("expected event %d to be '%s', got '%s'", i, summary, events[i].Summary)
+		}
+	}
+
+	filtered := FilterEvents(events, EventFilter{
+		Since:      start.Add(30 * time.Hour),
+		Severities: []string{"error"},
+	})
+	if len(filtered) != 2 || filtered[0].Source != EventSourceLimitedSupport ||
+		filtered[1].Source != EventSourceUpgrade {
+		t.Errorf("unexpected filtered events %+v", filtered)
+	}
+}
+
+func TestServiceLogSearch(t *testing.T) {
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	tests := []struct {
+		filter   EventFilter
+		expected string
+	}{
+		{EventFilter{}, ""},
+		{EventFilter{Since: since}, "timestamp >= '2024-05-01T10:00:00Z'"},
+		{
+			EventFilter{Since: since, Severities: []string{"warning", "ERROR"}},
+			"timestamp >= '2024-05-01T10:00:00Z' and severity in ('Warning', 'Error')",
+		},
+	}
+	for _, test := range tests {
+		result := ServiceLogSearch(test.filter)
+		if result != test.expected {
+			t.Errorf("expected search \"%s\", got \"%s\"", test.expected, result)
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"36h":                  now.Add(-36 * time.Hour),
+		"7d":                   now.AddDate(0, 0, -7),
+		"2024-05-01":           time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		"2024-05-01T10:00:00Z": time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+	for value, expected := range tests {
+		result, err := ParseSince(value, now)
+		if err != nil {
+			t.Errorf("unexpected error for '%s': %v", value, err)
+			continue
+		}
+		if !result.Equal(expected) {
+			t.Errorf("expected %v for '%s', got %v", expected, value, result)
+		}
+	}
+	_, err := ParseSince("yesterday", now)
+	if err == nil {
+		t.Errorf("expected error for invalid value")
+	}
+}
+
+func TestLoadServiceLogTemplate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "template.json")
+	err := os.WriteFile(file, []byte(`{
+  "severity": "warning",
+  "summary": "Maintenance of ${COMPONENT}",
+  "description": "The ${COMPONENT} will be restarted at ${TIME}."
+}`), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = LoadServiceLogTemplate(file, map[string]string{"COMPONENT": "router"})
+	if err == nil || !strings.Contains(err.Error(), "TIME") {
+		t.Errorf("expected error about missing parameter, got %v", err)
+	}
+
+	template, err := LoadServiceLogTemplate(file, map[string]string{
+		"COMPONENT": "router",
+		"TIME":      "10:00 UTC",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if template.Severity != "Warning" || template.ServiceName != "SREManualAction" ||
+		template.Summary != "Maintenance of router" ||
+		template.Description != "The router will be restarted at 10:00 UTC." {
+		t.Errorf("unexpected template %+v", template)
+	}
+
+	entry, err := BuildServiceLog(template, newTestCluster(t, cmv1.NewCluster().ID("123").
+		ExternalID("abc")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.ClusterID() != "123" || entry.ClusterUUID() != "abc" ||
+		entry.Severity() != slv1.SeverityWarning {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+}
@@ -0,0 +1,245 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// Membership maps the name of each group to the users that should belong to it.
+type Membership map[string][]string
+
+// GroupChanges contains the users that need to be added to and removed from a group.
+type GroupChanges struct {
+	Group  string
+	Add    []string
+	Remove []string
+}
+
+// Empty returns true if the group doesn't need any change.
+func (c GroupChanges) Empty() bool {
+	return len(c.Add) == 0 && len(c.Remove) == 0
+}
+
+// IsMembershipCSV returns true if the given membership file is a CSV file.
+func IsMembershipCSV(file string) bool {
+	return strings.EqualFold(filepath.Ext(file), ".csv")
+}
+
+// LoadMembership reads the desired group membership from a file. Files with the '.csv' extension
+// contain one user per row followed by one or more groups, any other file contains one user per
+// line for the given group. Empty lines and lines starting with '#' are ignored. When a group is
+// given for a CSV file, only the rows for that group are used, and the group has no users if
+// there are no rows for it.
+func LoadMembership(file string, group string) (Membership, error) {
+	// #nosec G304
+	reader, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("Can't open membership file '%s': %v", file, err)
+	}
+	defer reader.Close()
+
+	var membership Membership
+	if IsMembershipCSV(file) {
+		membership, err = ParseMembershipCSV(reader)
+		if err == nil && group != "" {
+			users := membership[group]
+			if users == nil {
+				users = []string{}
+			}
+			membership = Membership{group: users}
+		}
+	} else {
+		if group == "" {
+			return nil, fmt.Errorf("A group is required for membership file '%s'", file)
+		}
+		membership, err = ParseMembershipList(reader, group)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Can't parse membership file '%s': %v", file, err)
+	}
+	return membership, nil
+}
+
+// ParseMembershipList reads a list of users of a group, one per line.
+func ParseMembershipList(reader io.Reader, group string) (Membership, error) {
+	users := []string{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		users = appendUnique(users, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return Membership{group: users}, nil
+}
+
+// ParseMembershipCSV reads rows containing a user followed by the groups it belongs to. A first
+// row starting with 'user' is considered a header and skipped.
+func ParseMembershipCSV(reader io.Reader) (Membership, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	membership := Membership{}
+	for i, record := range records {
+		user := strings.TrimSpace(record[0])
+		if i == 0 && strings.EqualFold(user, "user") {
+			continue
+		}
+		if user == "" {
+			continue
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("User '%s' on line %d doesn't have any group", user, i+1)
+		}
+		for _, group := range record[1:] {
+			group = strings.TrimSpace(group)
+			if group == "" {
+				continue
+			}
+			membership[group] = appendUnique(membership[group], user)
+		}
+	}
+	return membership, nil
+}
+
+// CompleteMembership returns a copy of the membership that also contains the groups of the cluster
+// that aren't in it, without users. This is used when the membership describes all the groups of
+// the cluster, so that the users of groups that aren't mentioned are removed.
+func CompleteMembership(groups []*cmv1.Group, desired Membership) Membership {
+	result := Membership{}
+	for name, users := range desired {
+		result[name] = users
+	}
+	for _, group := range groups {
+		if _, ok := result[group.ID()]; !ok {
+			result[group.ID()] = []string{}
+		}
+	}
+	return result
+}
+
+// CheckEmptyGroups returns an error if any of the changes removes all the users of a group. This
+// protects against empty or truncated membership files.
+func CheckEmptyGroups(changes []GroupChanges, desired Membership) error {
+	var groups []string
+	for _, change := range changes {
+		if len(change.Remove) > 0 && len(desired[change.Group]) == 0 {
+			groups = append(groups, change.Group)
+		}
+	}
+	switch len(groups) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("Refusing to remove all the users of group '%s'", groups[0])
+	default:
+		return fmt.Errorf("Refusing to remove all the users of groups '%s'",
+			strings.Join(groups, "', '"))
+	}
+}
+
+// DiffMembership computes the changes needed to make the groups of a cluster match the desired
+// membership. Groups that aren't part of the desired membership are left untouched.
+func DiffMembership(groups []*cmv1.Group, desired Membership) ([]GroupChanges, error) {
+	current := map[string][]*cmv1.User{}
+	for _, group := range groups {
+		current[group.ID()] = group.Users().Slice()
+	}
+
+	names := make([]string, 0, len(desired))
+	for name := range desired {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	changes := make([]GroupChanges, 0, len(names))
+	for _, name := range names {
+		users, ok := current[name]
+		if !ok {
+			return nil, fmt.Errorf("Group '%s' doesn't exist", name)
+		}
+		existing := map[string]bool{}
+		for _, user := range users {
+			existing[user.ID()] = true
+		}
+		wanted := map[string]bool{}
+		change := GroupChanges{Group: name}
+		for _, user := range desired[name] {
+			wanted[user] = true
+			if !existing[user] {
+				change.Add = append(change.Add, user)
+			}
+		}
+		for _, user := range users {
+			if !wanted[user.ID()] {
+				change.Remove = append(change.Remove, user.ID())
+			}
+		}
+		sort.Strings(change.Add)
+		sort.Strings(change.Remove)
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// AddGroupUser adds a user to a group of the cluster.
+func AddGroupUser(client *cmv1.ClustersClient, clusterID string, group string, user string) error {
+	body, err := cmv1.NewUser().ID(user).Build()
+	if err != nil {
+		return err
+	}
+	_, err = client.Cluster(clusterID).
+		Groups().
+		Group(group).
+		Users().
+		Add().
+		Body(body).
+		Send()
+	return err
+}
+
+// RemoveGroupUser removes a user from a group of the cluster.
+func RemoveGroupUser(client *cmv1.ClustersClient, clusterID string, group string, user string) error {
+	_, err := client.Cluster(clusterID).
+		Groups().
+		Group(group).
+		Users().
+		User(user).
+		Delete().
+		Send()
+	return err
+}
@@ -0,0 +1,149 @@
+package cluster
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+func TestParseMembershipList(t *testing.T) {
+	input := "# admins\nalice\n\n  bob  \nalice\n"
+	membership, err := ParseMembershipList(strings.NewReader(input), "dedicated-admins")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Membership{"dedicated-admins": {"alice", "bob"}}
+	if !reflect.DeepEqual(membership, expected) {
+		t.Errorf("expected %v, got %v", expected, membership)
+	}
+}
+
+func TestParseMembershipCSV(t *testing.T) {
+	input := "user,group\nalice,dedicated-admins,cluster-admins\nbob, dedicated-admins\n"
+	membership, err := ParseMembershipCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Membership{
+		"dedicated-admins": {"alice", "bob"},
+		"cluster-admins":   {"alice"},
+	}
+	if !reflect.DeepEqual(membership, expected) {
+		t.Errorf("expected %v, got %v", expected, membership)
+	}
+
+	_, err = ParseMembershipCSV(strings.NewReader("alice\n"))
+	if err == nil {
+		t.Errorf("expected error for user without group")
+	}
+}
+
+func TestDiffMembership(t *testing.T) {
+	group, err := cmv1.NewGroup().ID("dedicated-admins").Users(cmv1.NewUserList().Items(
+		cmv1.NewUser().ID("alice"),
+		cmv1.NewUser().ID("carol"),
+	)).Build()
+	if err != nil {
+		t.Fatalf("failed to build group: %s", err)
+	}
+	other, err := cmv1.NewGroup().ID("cluster-admins").Users(
+		cmv1.NewUserList().Items(cmv1.NewUser().ID("dave")),
+	).Build()
+	if err != nil {
+		t.Fatalf("failed to build group: %s", err)
+	}
+	groups := []*cmv1.Group{group, other}
+
+	changes, err := DiffMembership(groups, Membership{"dedicated-admins": {"bob", "alice"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []GroupChanges{{
+		Group:  "dedicated-admins",
+		Add:    []string{"bob"},
+		Remove: []string{"carol"},
+	}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected %v, got %v", expected, changes)
+	}
+
+	_, err = DiffMembership(groups, Membership{"unknown": {"alice"}})
+	if err == nil {
+		t.Errorf("expected error for unknown group")
+	}
+}
+
+func TestCompleteMembership(t *testing.T) {
+	group, err := cmv1.NewGroup().ID("dedicated-admins").Users(
+		cmv1.NewUserList().Items(cmv1.NewUser().ID("alice")),
+	).Build()
+	if err != nil {
+		t.Fatalf("failed to build group: %s", err)
+	}
+	other, err := cmv1.NewGroup().ID("cluster-admins").Users(
+		cmv1.NewUserList().Items(cmv1.NewUser().ID("dave")),
+	).Build()
+	if err != nil {
+		t.Fatalf("failed to build group: %s", err)
+	}
+	groups := []*cmv1.Group{group, other}
+
+	// Dave was the last user of 'cluster-admins' and was removed from the CSV file:
+	membership, err := ParseMembershipCSV(strings.NewReader("user,group\nalice,dedicated-admins\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	membership = CompleteMembership(groups, membership)
+	changes, err := DiffMembership(groups, membership)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []GroupChanges{
+		{Group: "cluster-admins", Remove: []string{"dave"}},
+		{Group: "dedicated-admins"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected %v, got %v", expected, changes)
+	}
+	err = CheckEmptyGroups(changes, membership)
+	if err == nil || !strings.Contains(err.Error(), "'cluster-admins'") {
+		t.Errorf("expected error for emptied group, got %v", err)
+	}
+}
+
+func TestCheckEmptyGroups(t *testing.T) {
+	group, err := cmv1.NewGroup().ID("dedicated-admins").Users(
+		cmv1.NewUserList().Items(cmv1.NewUser().ID("alice")),
+	).Build()
+	if err != nil {
+		t.Fatalf("failed to build group: %s", err)
+	}
+	groups := []*cmv1.Group{group}
+
+	// An empty file would remove all the users:
+	membership, err := ParseMembershipList(strings.NewReader(""), "dedicated-admins")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changes, err := DiffMembership(groups, membership)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = CheckEmptyGroups(changes, membership)
+	if err == nil {
+		t.Errorf("expected error for empty membership")
+	}
+
+	// Removing some users is fine:
+	membership = Membership{"dedicated-admins": {"bob"}}
+	changes, err = DiffMembership(groups, membership)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = CheckEmptyGroups(changes, membership)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,580 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to build the health report of a cluster.
+
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	asv1 "github.com/openshift-online/ocm-sdk-go/addonsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+// HealthStatus is the result of a health check.
+type HealthStatus string
+
+const (
+	HealthOK      HealthStatus = "ok"
+	HealthWarning HealthStatus = "warning"
+	HealthError   HealthStatus = "error"
+	HealthUnknown HealthStatus = "unknown"
+)
+
+// Names of the health checks:
+const (
+	HealthCheckState          = "state"
+	HealthCheckProvisioning   = "provisioning"
+	HealthCheckLimitedSupport = "limited-support"
+	HealthCheckCompute        = "compute"
+	HealthCheckUpgrades       = "upgrades"
+	HealthCheckAddOns         = "add-ons"
+	HealthCheckSubscription   = "subscription"
+	HealthCheckServiceLogs    = "service-logs"
+)
+
+// Points subtracted from the score of a cluster for each check that isn't ok:
+var healthPenalties = map[HealthStatus]int{
+	HealthError:   30,
+	HealthWarning: 10,
+	HealthUnknown: 5,
+}
+
+// HealthCheck is the result of checking one aspect of the health of a cluster.
+type HealthCheck struct {
+	Name    string       `json:"name"`
+	Status  HealthStatus `json:"status"`
+	Message string       `json:"message"`
+	Details []string     `json:"details,omitempty"`
+}
+
+// HealthReport is the health summary of a cluster. The score goes from 0 to 100, and the summary
+// is 'healthy', 'degraded' or 'unhealthy' depending on the worst check.
+type HealthReport struct {
+	ClusterID   string        `json:"cluster_id"`
+	ClusterName string        `json:"cluster_name"`
+	State       string        `json:"state"`
+	Summary     string        `json:"summary"`
+	Score       int           `json:"score"`
+	Checks      []HealthCheck `json:"checks"`
+}
+
+// UpgradeInfo contains the state of an upgrade policy of a cluster. The creation and update times
+// are only known for the control plane upgrade policies of hosted control plane clusters.
+type UpgradeInfo struct {
+	Version     string
+	NextRun     time.Time
+	State       cmv1.UpgradePolicyStateValue
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// HealthInput contains the data used to evaluate the health of a cluster. Errors contains the
+// errors found retrieving the data of each check.
+type HealthInput struct {
+	Cluster               *cmv1.Cluster
+	Status                *cmv1.ClusterStatus
+	LimitedSupportReasons []*cmv1.LimitedSupportReason
+	MachinePools          []*cmv1.MachinePool
+	NodePools             []*cmv1.NodePool
+	Upgrades              []UpgradeInfo
+	AddOns                []*asv1.AddonInstallation
+	Subscription          *amv1.Subscription
+	ServiceLogWarnings    []*slv1.LogEntry
+	ServiceLogsSince      time.Time
+	Errors                map[string]error
+}
+
+// GetHealthInput retrieves the data needed to evaluate the health of the cluster. Failures to
+// retrieve parts of the data are recorded in the errors of the result instead of being returned,
+// so that the rest of the report can still be generated. Only the service log warnings sent after
+// the given time are retrieved, so that old warnings don't affect the health of the cluster.
+func GetHealthInput(connection *sdk.Connection, cluster *cmv1.Cluster, since time.Time) *HealthInput {
+	input := &HealthInput{
+		Cluster:          cluster,
+		ServiceLogsSince: since,
+		Errors:           map[string]error{},
+	}
+	clusterClient := connection.ClustersMgmt().V1().Clusters().Cluster(cluster.ID())
+	hypershift := cluster.Hypershift().Enabled()
+
+	statusResponse, err := clusterClient.Status().Get().Send()
+	if err != nil {
+		input.Errors[HealthCheckProvisioning] = err
+	} else {
+		input.Status = statusResponse.Body()
+	}
+
+	reasonsResponse, err := clusterClient.LimitedSupportReasons().List().Size(-1).Send()
+	if err != nil {
+		input.Errors[HealthCheckLimitedSupport] = err
+	} else {
+		input.LimitedSupportReasons = reasonsResponse.Items().Slice()
+	}
+
+	if hypershift {
+		nodePoolsResponse, err := clusterClient.NodePools().List().Size(-1).Send()
+		if err != nil {
+			input.Errors[HealthCheckCompute] = err
+		} else {
+			input.NodePools = nodePoolsResponse.Items().Slice()
+		}
+	} else {
+		machinePoolsResponse, err := clusterClient.MachinePools().List().Size(-1).Send()
+		if err != nil {
+			input.Errors[HealthCheckCompute] = err
+		} else {
+			input.MachinePools = machinePoolsResponse.Items().Slice()
+		}
+	}
+
+	input.Upgrades, err = getUpgrades(clusterClient, hypershift)
+	if err != nil {
+		input.Errors[HealthCheckUpgrades] = err
+	}
+
+	addOnsResponse, err := connection.AddonsMgmt().V1().Clusters().Cluster(cluster.ID()).
+		Addons().List().Size(-1).Send()
+	if err != nil {
+		input.Errors[HealthCheckAddOns] = err
+	} else {
+		input.AddOns = addOnsResponse.Items().Slice()
+	}
+
+	if subscriptionID := cluster.Subscription().ID(); subscriptionID != "" {
+		subscriptionResponse, err := connection.AccountsMgmt().V1().Subscriptions().
+			Subscription(subscriptionID).Get().Send()
+		if err != nil {
+			input.Errors[HealthCheckSubscription] = err
+		} else {
+			input.Subscription = subscriptionResponse.Body()
+		}
+	}
+
+	input.ServiceLogWarnings, err = getServiceLogWarnings(connection, cluster.ID(), since)
+	if err != nil {
+		input.Errors[HealthCheckServiceLogs] = err
+	}
+
+	return input
+}
+
+func getServiceLogWarnings(connection *sdk.Connection, clusterID string,
+	since time.Time) ([]*slv1.LogEntry, error) {
+	search := fmt.Sprintf("severity = '%s'", slv1.SeverityWarning)
+	if !since.IsZero() {
+		search = fmt.Sprintf("%s and timestamp >= '%s'", search, since.UTC().Format(time.RFC3339))
+	}
+	var warnings []*slv1.LogEntry
+	size := 100
+	for page := 1; ; page++ {
+		response, err := connection.ServiceLogs().V1().Clusters().ClusterLogs().List().
+			ClusterID(clusterID).
+			Search(search).
+			Page(page).
+			Size(size).
+			Send()
+		if err != nil {
+			return nil, err
+		}
+		response.Items().Each(func(entry *slv1.LogEntry) bool {
+			if entry.Severity() == slv1.SeverityWarning && !entry.Timestamp().Before(since) {
+				warnings = append(warnings, entry)
+			}
+			return true
+		})
+		if response.Size() < size {
+			break
+		}
+	}
+	return warnings, nil
+}
+
+func getUpgrades(clusterClient *cmv1.ClusterClient, hypershift bool) ([]UpgradeInfo, error) {
+	var upgrades []UpgradeInfo
+	if hypershift {
+		response, err := clusterClient.ControlPlane().UpgradePolicies().List().Size(-1).Send()
+		if err != nil {
+			return nil, err
+		}
+		response.Items().Each(func(policy *cmv1.ControlPlaneUpgradePolicy) bool {
+			upgrades = append(upgrades, UpgradeInfo{
+				Version:     policy.Version(),
+				NextRun:     policy.NextRun(),
+				State:       policy.State().Value(),
+				Description: policy.State().Description(),
+				CreatedAt:   policy.CreationTimestamp(),
+				UpdatedAt:   policy.LastUpdateTimestamp(),
+			})
+			return true
+		})
+		return upgrades, nil
+	}
+	response, err := clusterClient.UpgradePolicies().List().Size(-1).Send()
+	if err != nil {
+		return nil, err
+	}
+	for _, policy := range response.Items().Slice() {
+		upgrade := UpgradeInfo{
+			Version: policy.Version(),
+			NextRun: policy.NextRun(),
+		}
+		stateResponse, err := clusterClient.UpgradePolicies().UpgradePolicy(policy.ID()).State().
+			Get().Send()
+		if stateResponse != nil && stateResponse.Status() == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		upgrade.State = stateResponse.Body().Value()
+		upgrade.Description = stateResponse.Body().Description()
+		upgrades = append(upgrades, upgrade)
+	}
+	return upgrades, nil
+}
+
+// EvaluateHealth runs the health checks on the data of the cluster and calculates the score.
+func EvaluateHealth(input *HealthInput) *HealthReport {
+	cluster := input.Cluster
+	report := &HealthReport{
+		ClusterID:   cluster.ID(),
+		ClusterName: cluster.Name(),
+		State:       string(cluster.State()),
+	}
+	checks := []struct {
+		name  string
+		check func(*HealthInput) HealthCheck
+	}{
+		{HealthCheckState, checkState},
+		{HealthCheckProvisioning, checkProvisioning},
+		{HealthCheckLimitedSupport, checkLimitedSupport},
+		{HealthCheckCompute, checkCompute},
+		{HealthCheckUpgrades, checkUpgrades},
+		{HealthCheckAddOns, checkAddOns},
+		{HealthCheckSubscription, checkSubscription},
+		{HealthCheckServiceLogs, checkServiceLogs},
+	}
+	report.Score = 100
+	worst := HealthOK
+	for _, item := range checks {
+		var result HealthCheck
+		if err := input.Errors[item.name]; err != nil {
+			result = HealthCheck{
+				Status:  HealthUnknown,
+				Message: fmt.Sprintf("Can't retrieve data: %v", err),
+			}
+		} else {
+			result = item.check(input)
+		}
+		result.Name = item.name
+		report.Checks = append(report.Checks, result)
+		report.Score -= healthPenalties[result.Status]
+		if result.Status == HealthError || (result.Status == HealthWarning && worst != HealthError) {
+			worst = result.Status
+		}
+	}
+	if report.Score < 0 {
+		report.Score = 0
+	}
+	switch worst {
+	case HealthError:
+		report.Summary = "unhealthy"
+	case HealthWarning:
+		report.Summary = "degraded"
+	default:
+		report.Summary = "healthy"
+	}
+	return report
+}
+
+func checkState(input *HealthInput) HealthCheck {
+	state := input.Cluster.State()
+	switch state {
+	case cmv1.ClusterStateReady:
+		return HealthCheck{Status: HealthOK, Message: "Cluster is ready"}
+	case cmv1.ClusterStateError:
+		return HealthCheck{Status: HealthError, Message: "Cluster is in error state"}
+	case cmv1.ClusterStateUninstalling:
+		return HealthCheck{Status: HealthError, Message: "Cluster is being uninstalled"}
+	case cmv1.ClusterStateHibernating, cmv1.ClusterStatePoweringDown, cmv1.ClusterStateResuming:
+		return HealthCheck{Status: HealthWarning, Message: fmt.Sprintf("Cluster is %s", state)}
+	case cmv1.ClusterStateInstalling, cmv1.ClusterStatePending, cmv1.ClusterStateValidating,
+		cmv1.ClusterStateWaiting:
+		return HealthCheck{Status: HealthWarning, Message: fmt.Sprintf("Cluster is being "+
+			"installed, state is '%s'", state)}
+	default:
+		return HealthCheck{Status: HealthWarning, Message: fmt.Sprintf("Cluster state is '%s'", state)}
+	}
+}
+
+func checkProvisioning(input *HealthInput) HealthCheck {
+	status := input.Status
+	if status == nil {
+		return HealthCheck{Status: HealthUnknown, Message: "Cluster status isn't available"}
+	}
+	if code := status.ProvisionErrorCode(); code != "" {
+		return HealthCheck{
+			Status:  HealthError,
+			Message: fmt.Sprintf("Provision error %s: %s", code, status.ProvisionErrorMessage()),
+		}
+	}
+	var details []string
+	if description := status.Description(); description != "" {
+		details = append(details, description)
+	}
+	if input.Cluster.State() == cmv1.ClusterStateReady && !status.DNSReady() {
+		return HealthCheck{Status: HealthWarning, Message: "DNS isn't ready", Details: details}
+	}
+	return HealthCheck{Status: HealthOK, Message: "No provision errors", Details: details}
+}
+
+func checkLimitedSupport(input *HealthInput) HealthCheck {
+	if len(input.LimitedSupportReasons) == 0 {
+		return HealthCheck{Status: HealthOK, Message: "Cluster is fully supported"}
+	}
+	var details []string
+	for _, reason := range input.LimitedSupportReasons {
+		details = append(details, reason.Summary())
+	}
+	return HealthCheck{
+		Status: HealthError,
+		Message: fmt.Sprintf("Cluster is in limited support, %d reasons",
+			len(input.LimitedSupportReasons)),
+		Details: details,
+	}
+}
+
+func checkCompute(input *HealthInput) HealthCheck {
+	var details []string
+	status := HealthOK
+	if input.Cluster.Hypershift().Enabled() {
+		for _, pool := range input.NodePools {
+			desired, description := poolReplicas(pool.Replicas(), pool.Autoscaling().MinReplica(),
+				pool.Autoscaling().MaxReplica(), pool.Autoscaling() != nil)
+			current := pool.Status().CurrentReplicas()
+			if current < desired {
+				status = HealthWarning
+				detail := fmt.Sprintf("Node pool '%s' has %d of %s replicas", pool.ID(), current,
+					description)
+				if message := pool.Status().Message(); message != "" {
+					detail += ": " + message
+				}
+				details = append(details, detail)
+			}
+		}
+		if status == HealthOK {
+			return HealthCheck{Status: HealthOK, Message: fmt.Sprintf("%d node pools have the "+
+				"desired replicas", len(input.NodePools))}
+		}
+		return HealthCheck{Status: status, Message: "Some node pools don't have the desired " +
+			"replicas", Details: details}
+	}
+
+	// For classic clusters only the total of compute nodes is known:
+	desired := 0
+	if input.Cluster.Nodes().AutoscaleCompute() != nil {
+		desired = input.Cluster.Nodes().AutoscaleCompute().MinReplicas()
+	} else {
+		desired = input.Cluster.Nodes().Compute()
+	}
+	for _, pool := range input.MachinePools {
+		if pool.ID() == "worker" {
+			continue
+		}
+		replicas, description := poolReplicas(pool.Replicas(), pool.Autoscaling().MinReplicas(),
+			pool.Autoscaling().MaxReplicas(), pool.Autoscaling() != nil)
+		desired += replicas
+		details = append(details, fmt.Sprintf("Machine pool '%s' wants %s replicas", pool.ID(),
+			description))
+	}
+	if input.Status == nil || input.Cluster.State() != cmv1.ClusterStateReady {
+		return HealthCheck{Status: HealthOK, Message: fmt.Sprintf("%d machine pools",
+			len(input.MachinePools)), Details: details}
+	}
+	current := input.Status.CurrentCompute()
+	if current < desired {
+		return HealthCheck{
+			Status: HealthWarning,
+			Message: fmt.Sprintf("Cluster has %d compute nodes, but at least %d are expected",
+				current, desired),
+			Details: details,
+		}
+	}
+	return HealthCheck{
+		Status:  HealthOK,
+		Message: fmt.Sprintf("Cluster has %d compute nodes, %d expected", current, desired),
+	}
+}
+
+// poolReplicas returns the minimum number of replicas of a pool and a description of the replicas
+// suitable for messages.
+func poolReplicas(replicas int, min int, max int, autoscaling bool) (int, string) {
+	if autoscaling {
+		return min, fmt.Sprintf("%d-%d", min, max)
+	}
+	return replicas, fmt.Sprintf("%d", replicas)
+}
+
+func checkUpgrades(input *HealthInput) HealthCheck {
+	status := HealthOK
+	var details []string
+	for _, upgrade := range input.Upgrades {
+		detail := fmt.Sprintf("Upgrade to %s is %s", upgrade.Version, upgrade.State)
+		switch upgrade.State {
+		case cmv1.UpgradePolicyStateValueFailed:
+			status = HealthError
+		case cmv1.UpgradePolicyStateValueDelayed:
+			if status != HealthError {
+				status = HealthWarning
+			}
+		case cmv1.UpgradePolicyStateValueScheduled, cmv1.UpgradePolicyStateValuePending:
+			if !upgrade.NextRun.IsZero() {
+				detail += fmt.Sprintf(", next run %s", upgrade.NextRun.Format(time.RFC3339))
+			}
+		}
+		if upgrade.Description != "" {
+			detail += ": " + upgrade.Description
+		}
+		details = append(details, detail)
+	}
+	switch {
+	case len(input.Upgrades) == 0:
+		return HealthCheck{Status: HealthOK, Message: "No upgrades scheduled"}
+	case status == HealthError:
+		return HealthCheck{Status: status, Message: "Upgrade failed", Details: details}
+	case status == HealthWarning:
+		return HealthCheck{Status: status, Message: "Upgrade delayed", Details: details}
+	default:
+		return HealthCheck{Status: status, Message: fmt.Sprintf("%d upgrades scheduled",
+			len(input.Upgrades)), Details: details}
+	}
+}
+
+func checkAddOns(input *HealthInput) HealthCheck {
+	status := HealthOK
+	var details []string
+	for _, addOn := range input.AddOns {
+		state := addOn.State()
+		switch state {
+		case asv1.AddonInstallationStateReady, asv1.AddonInstallationStateDeleted:
+			continue
+		case asv1.AddonInstallationStateFailed, asv1.AddonInstallationStateDeleteFailed:
+			status = HealthError
+		default:
+			if status != HealthError {
+				status = HealthWarning
+			}
+		}
+		detail := fmt.Sprintf("Add-on '%s' is %s", addOn.Addon().ID(), state)
+		if description := addOn.StateDescription(); description != "" {
+			detail += ": " + description
+		}
+		details = append(details, detail)
+	}
+	switch status {
+	case HealthError:
+		return HealthCheck{Status: status, Message: "Some add-ons failed", Details: details}
+	case HealthWarning:
+		return HealthCheck{Status: status, Message: "Some add-ons aren't ready", Details: details}
+	default:
+		return HealthCheck{Status: status, Message: fmt.Sprintf("%d add-ons ready",
+			len(input.AddOns))}
+	}
+}
+
+func checkSubscription(input *HealthInput) HealthCheck {
+	subscription := input.Subscription
+	if subscription == nil {
+		return HealthCheck{Status: HealthUnknown, Message: "Cluster doesn't have a subscription"}
+	}
+	status := subscription.Status()
+	message := fmt.Sprintf("Subscription is %s", status)
+	switch strings.ToLower(status) {
+	case "active":
+		return HealthCheck{Status: HealthOK, Message: message}
+	case "deprovisioned", "archived":
+		return HealthCheck{Status: HealthError, Message: message}
+	default:
+		return HealthCheck{Status: HealthWarning, Message: message}
+	}
+}
+
+func checkServiceLogs(input *HealthInput) HealthCheck {
+	period := ""
+	if !input.ServiceLogsSince.IsZero() {
+		period = fmt.Sprintf(" since %s", input.ServiceLogsSince.Format(time.RFC3339))
+	}
+	if len(input.ServiceLogWarnings) == 0 {
+		return HealthCheck{Status: HealthOK, Message: "No warnings in service logs" + period}
+	}
+	entries := append([]*slv1.LogEntry{}, input.ServiceLogWarnings...)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Timestamp().After(entries[j].Timestamp())
+	})
+	var details []string
+	for _, entry := range entries {
+		details = append(details, fmt.Sprintf("%s %s", entry.Timestamp().Format(time.RFC3339),
+			entry.Summary()))
+	}
+	return HealthCheck{
+		Status:  HealthWarning,
+		Message: fmt.Sprintf("%d warnings in service logs%s", len(entries), period),
+		Details: details,
+	}
+}
+
+// PrintHealthReport writes the health report in text format.
+func PrintHealthReport(stream io.Writer, report *HealthReport) error {
+	fmt.Fprintf(stream, "Cluster:  %s (%s)\n", report.ClusterName, report.ClusterID)
+	fmt.Fprintf(stream, "State:    %s\n", report.State)
+	fmt.Fprintf(stream, "Health:   %s (score %d/100)\n", report.Summary, report.Score)
+	fmt.Fprintf(stream, "\n")
+	writer := tabwriter.NewWriter(stream, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "CHECK\tSTATUS\tMESSAGE\n")
+	for _, check := range report.Checks {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", check.Name, check.Status, check.Message)
+	}
+	err := writer.Flush()
+	if err != nil {
+		return err
+	}
+	first := true
+	for _, check := range report.Checks {
+		if check.Status == HealthOK || len(check.Details) == 0 {
+			continue
+		}
+		if first {
+			fmt.Fprintf(stream, "\nWarnings:\n")
+			first = false
+		}
+		for _, detail := range check.Details {
+			fmt.Fprintf(stream, "  - [%s] %s\n", check.Name, detail)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package cluster
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	asv1 "github.com/openshift-online/ocm-sdk-go/addonsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+func findCheck(t *testing.T, report *HealthReport, name string) HealthCheck {
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("check '%s' not found", name)
+	return HealthCheck{}
+}
+
+func newHealthyInput(t *testing.T) *HealthInput {
+	status, err := cmv1.NewClusterStatus().DNSReady(true).CurrentCompute(3).Build()
+	if err != nil {
+		t.Fatalf("failed to build status: %v", err)
+	}
+	subscription, err := amv1.NewSubscription().Status("Active").Build()
+	if err != nil {
+		t.Fatalf("failed to build subscription: %v", err)
+	}
+	return &HealthInput{
+		Cluster: newTestCluster(t, cmv1.NewCluster().ID("123").Name("mycluster").
+			State(cmv1.ClusterStateReady).
+			Nodes(cmv1.NewClusterNodes().Compute(3))),
+		Status:       status,
+		Subscription: subscription,
+		Errors:       map[string]error{},
+	}
+}
+
+func TestEvaluateHealthHealthy(t *testing.T) {
+	report := EvaluateHealth(newHealthyInput(t))
+	if report.Summary != "healthy" || report.Score != 100 {
+		t.Errorf("expected healthy report with score 100, got %s with %d", report.Summary,
+			report.Score)
+	}
+	if len(report.Checks) != 8 {
+		t.Errorf("expected 8 checks, got %d", len(report.Checks))
+	}
+}
+
+func TestEvaluateHealthLimitedSupport(t *testing.T) {
+	input := newHealthyInput(t)
+	reason, err := cmv1.NewLimitedSupportReason().Summary("Cluster is unreachable").Build()
+	if err != nil {
+		t.Fatalf("failed to build reason: %v", err)
+	}
+	input.LimitedSupportReasons = []*cmv1.LimitedSupportReason{reason}
+	report := EvaluateHealth(input)
+	if report.Summary != "unhealthy" || report.Score != 70 {
+		t.Errorf("expected unhealthy report with score 70, got %s with %d", report.Summary,
+			report.Score)
+	}
+	check := findCheck(t, report, HealthCheckLimitedSupport)
+	if check.Status != HealthError || len(check.Details) != 1 {
+		t.Errorf("unexpected check %+v", check)
+	}
+}
+
+func TestEvaluateHealthWarnings(t *testing.T) {
+	input := newHealthyInput(t)
+	status, err := cmv1.NewClusterStatus().DNSReady(true).CurrentCompute(3).Build()
+	if err != nil {
+		t.Fatalf("failed to build status: %v", err)
+	}
+	input.Status = status
+	pool, err := cmv1.NewMachinePool().ID("extra").Autoscaling(
+		cmv1.NewMachinePoolAutoscaling().MinReplicas(2).MaxReplicas(4)).Build()
+	if err != nil {
+		t.Fatalf("failed to build machine pool: %v", err)
+	}
+	input.MachinePools = []*cmv1.MachinePool{pool}
+	addOn, err := asv1.NewAddonInstallation().Addon(asv1.NewAddon().ID("logging")).
+		State(asv1.AddonInstallationStateInstalling).Build()
+	if err != nil {
+		t.Fatalf("failed to build add-on: %v", err)
+	}
+	input.AddOns = []*asv1.AddonInstallation{addOn}
+	input.Upgrades = []UpgradeInfo{{
+		Version: "4.15.2",
+		State:   cmv1.UpgradePolicyStateValueDelayed,
+	}}
+	input.Errors[HealthCheckServiceLogs] = errors.New("forbidden")
+
+	report := EvaluateHealth(input)
+	if report.Summary != "degraded" || report.Score != 65 {
+		t.Errorf("expected degraded report with score 65, got %s with %d", report.Summary,
+			report.Score)
+	}
+	for name, expected := range map[string]HealthStatus{
+		HealthCheckCompute:     HealthWarning,
+		HealthCheckAddOns:      HealthWarning,
+		HealthCheckUpgrades:    HealthWarning,
+		HealthCheckServiceLogs: HealthUnknown,
+	} {
+		check := findCheck(t, report, name)
+		if check.Status != expected {
+			t.Errorf("expected check '%s' to be %s, got %s", name, expected, check.Status)
+		}
+	}
+
+	var buffer bytes.Buffer
+	err = PrintHealthReport(&buffer, report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	output := buffer.String()
+	for _, expected := range []string{
+		"Health:   degraded (score 65/100)",
+		"[add-ons] Add-on 'logging' is installing",
+		"[upgrades] Upgrade to 4.15.2 is delayed",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain '%s', got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestEvaluateHealthServiceLogsSince(t *testing.T) {
+	input := newHealthyInput(t)
+	input.ServiceLogsSince = time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	entry, err := slv1.NewLogEntry().Severity(slv1.SeverityWarning).Summary("Quota exceeded").
+		Timestamp(time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)).Build()
+	if err != nil {
+		t.Fatalf("failed to build log entry: %v", err)
+	}
+	input.ServiceLogWarnings = []*slv1.LogEntry{entry}
+
+	check := findCheck(t, EvaluateHealth(input), HealthCheckServiceLogs)
+	if check.Status != HealthWarning {
+		t.Errorf("expected service logs check to be %s, got %s", HealthWarning, check.Status)
+	}
+	expected := "1 warnings in service logs since 2024-05-01T00:00:00Z"
+	if check.Message != expected {
+		t.Errorf("expected message '%s', got '%s'", expected, check.Message)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// LoadHTPasswdFile reads a local htpasswd file and returns the hashed password of each user.
+func LoadHTPasswdFile(file string) (map[string]string, error) {
+	// #nosec G304
+	reader, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("Can't open htpasswd file '%s': %v", file, err)
+	}
+	defer reader.Close()
+	users, err := ParseHTPasswd(reader)
+	if err != nil {
+		return nil, fmt.Errorf("Can't parse htpasswd file '%s': %v", file, err)
+	}
+	return users, nil
+}
+
+// ParseHTPasswd reads lines in 'username:hashed-password' format. Empty lines and lines starting
+// with '#' are ignored.
+func ParseHTPasswd(reader io.Reader) (map[string]string, error) {
+	users := map[string]string{}
+	scanner := bufio.NewScanner(reader)
+	number := 0
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		username, hash, found := strings.Cut(line, ":")
+		if !found || username == "" || hash == "" {
+			return nil, fmt.Errorf("Expected 'username:password' format on line %d", number)
+		}
+		if _, ok := users[username]; ok {
+			return nil, fmt.Errorf("User '%s' on line %d is duplicated", username, number)
+		}
+		users[username] = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetHTPasswdUsers returns the users of an htpasswd identity provider.
+func GetHTPasswdUsers(client *cmv1.ClustersClient, clusterID string,
+	idpID string) ([]*cmv1.HTPasswdUser, error) {
+	response, err := client.Cluster(clusterID).
+		IdentityProviders().
+		IdentityProvider(idpID).
+		HtpasswdUsers().
+		List().
+		Page(1).
+		Size(-1).
+		Send()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get htpasswd users for cluster '%s': %v", clusterID, err)
+	}
+	return response.Items().Slice(), nil
+}
@@ -0,0 +1,34 @@
+package cluster
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHTPasswd(t *testing.T) {
+	input := "# admins\nalice:$2y$05$abc\n\nbob:$apr1$def\n"
+	users, err := ParseHTPasswd(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{
+		"alice": "$2y$05$abc",
+		"bob":   "$apr1$def",
+	}
+	if !reflect.DeepEqual(users, expected) {
+		t.Errorf("expected %v, got %v", expected, users)
+	}
+
+	invalid := []string{
+		"alice\n",
+		"alice:\n",
+		"alice:$2y$05$abc\nalice:$2y$05$def\n",
+	}
+	for _, input := range invalid {
+		_, err := ParseHTPasswd(strings.NewReader(input))
+		if err == nil {
+			t.Errorf("expected error for input %q", input)
+		}
+	}
+}
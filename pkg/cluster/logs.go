@@ -0,0 +1,232 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to retrieve and follow the install and uninstall logs of
+// clusters.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// LogType is the kind of log of a cluster.
+type LogType string
+
+const (
+	LogTypeInstall   LogType = "install"
+	LogTypeUninstall LogType = "uninstall"
+)
+
+// LogSource retrieves the content of a log.
+type LogSource interface {
+	// Fetch returns the content of the log starting at the given line. The boolean result is false
+	// if the log isn't available yet.
+	Fetch(ctx context.Context, offset int) (string, bool, error)
+
+	// Tail returns the given number of lines from the end of the log.
+	Tail(ctx context.Context, lines int) (string, bool, error)
+
+	// Active returns true while the log can still grow.
+	Active(ctx context.Context) (bool, error)
+}
+
+// apiLogSource is the log source that uses the clusters management API.
+type apiLogSource struct {
+	client  *cmv1.ClusterClient
+	logType LogType
+}
+
+// NewLogSource creates a source for the given log of a cluster.
+func NewLogSource(client *cmv1.ClustersClient, clusterID string, logType LogType) LogSource {
+	return &apiLogSource{
+		client:  client.Cluster(clusterID),
+		logType: logType,
+	}
+}
+
+func (s *apiLogSource) request() *cmv1.LogGetRequest {
+	if s.logType == LogTypeUninstall {
+		return s.client.Logs().Uninstall().Get()
+	}
+	return s.client.Logs().Install().Get()
+}
+
+func (s *apiLogSource) send(ctx context.Context, request *cmv1.LogGetRequest) (string, bool, error) {
+	response, err := request.SendContext(ctx)
+	if response != nil && response.Status() == http.StatusNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("Failed to get %s log: %v", s.logType, err)
+	}
+	return response.Body().Content(), true, nil
+}
+
+func (s *apiLogSource) Fetch(ctx context.Context, offset int) (string, bool, error) {
+	return s.send(ctx, s.request().Offset(offset))
+}
+
+func (s *apiLogSource) Tail(ctx context.Context, lines int) (string, bool, error) {
+	return s.send(ctx, s.request().Tail(lines))
+}
+
+func (s *apiLogSource) Active(ctx context.Context) (bool, error) {
+	response, err := s.client.Get().SendContext(ctx)
+	if response != nil && response.Status() == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("Failed to get cluster: %v", err)
+	}
+	state := response.Body().State()
+	if s.logType == LogTypeUninstall {
+		return state == cmv1.ClusterStateUninstalling, nil
+	}
+	switch state {
+	case cmv1.ClusterStateInstalling, cmv1.ClusterStatePending, cmv1.ClusterStateValidating,
+		cmv1.ClusterStateWaiting:
+		return true, nil
+	}
+	return false, nil
+}
+
+// LogStreamer writes a log to an output, optionally following it until it stops growing.
+type LogStreamer struct {
+	source   LogSource
+	tail     int
+	follow   bool
+	interval time.Duration
+	output   io.Writer
+}
+
+// NewLogStreamer creates a streamer that reads the log from the given source.
+func NewLogStreamer(source LogSource) *LogStreamer {
+	return &LogStreamer{
+		source:   source,
+		interval: 10 * time.Second,
+	}
+}
+
+// Tail sets the number of lines from the end of the log that are written. Zero means the complete
+// log.
+func (s *LogStreamer) Tail(value int) *LogStreamer {
+	s.tail = value
+	return s
+}
+
+// Follow indicates if the streamer should keep polling the log while the source is active.
+func (s *LogStreamer) Follow(value bool) *LogStreamer {
+	s.follow = value
+	return s
+}
+
+// Interval sets the time between polls when following the log.
+func (s *LogStreamer) Interval(value time.Duration) *LogStreamer {
+	s.interval = value
+	return s
+}
+
+// Output sets the writer where the log is written.
+func (s *LogStreamer) Output(value io.Writer) *LogStreamer {
+	s.output = value
+	return s
+}
+
+// Run writes the log. When following it returns once the source is no longer active and the
+// remaining lines have been written, or when the context is cancelled.
+func (s *LogStreamer) Run(ctx context.Context) error {
+	if !s.follow {
+		var content string
+		var ok bool
+		var err error
+		if s.tail > 0 {
+			content, ok, err = s.source.Tail(ctx, s.tail)
+		} else {
+			content, ok, err = s.source.Fetch(ctx, 0)
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("Log isn't available yet")
+		}
+		_, err = io.WriteString(s.output, content)
+		return err
+	}
+
+	offset := 0
+	first := true
+	for {
+		// Check the state before fetching, so that the last fetch contains all the lines that
+		// were written before the source became inactive:
+		active, err := s.source.Active(ctx)
+		if err != nil {
+			return err
+		}
+		content, ok, err := s.source.Fetch(ctx, offset)
+		if err != nil {
+			return err
+		}
+		if ok {
+			lines, rest := SplitLogLines(content)
+			offset += len(lines)
+			if first && s.tail > 0 && len(lines) > s.tail {
+				lines = lines[len(lines)-s.tail:]
+			}
+			first = false
+			if !active && rest != "" {
+				lines = append(lines, rest+"\n")
+			}
+			_, err = io.WriteString(s.output, strings.Join(lines, ""))
+			if err != nil {
+				return err
+			}
+		}
+		if !active {
+			if first {
+				return fmt.Errorf("Log isn't available")
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(s.interval):
+		}
+	}
+}
+
+// SplitLogLines splits the content of a log into complete lines, including the line breaks, and
+// the incomplete last line, if any. Incomplete lines are retrieved again in the next poll.
+func SplitLogLines(content string) (lines []string, rest string) {
+	for content != "" {
+		index := strings.IndexByte(content, '\n')
+		if index < 0 {
+			return lines, content
+		}
+		lines = append(lines, content[:index+1])
+		content = content[index+1:]
+	}
+	return lines, ""
+}
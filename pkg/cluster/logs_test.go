@@ -0,0 +1,97 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeLogSource returns a different version of the log in each poll.
+type fakeLogSource struct {
+	polls   []string
+	current int
+}
+
+func (s *fakeLogSource) Fetch(ctx context.Context, offset int) (string, bool, error) {
+	content := s.polls[s.current]
+	if content == "" {
+		return "", false, nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if offset > len(lines) {
+		return "", true, nil
+	}
+	return strings.Join(lines[offset:], ""), true, nil
+}
+
+func (s *fakeLogSource) Tail(ctx context.Context, count int) (string, bool, error) {
+	lines, rest := SplitLogLines(s.polls[s.current])
+	if len(lines) > count {
+		lines = lines[len(lines)-count:]
+	}
+	return strings.Join(lines, "") + rest, true, nil
+}
+
+func (s *fakeLogSource) Active(ctx context.Context) (bool, error) {
+	if s.current < len(s.polls)-1 {
+		s.current++
+	}
+	return s.current < len(s.polls)-1, nil
+}
+
+func TestSplitLogLines(t *testing.T) {
+	lines, rest := SplitLogLines("one\ntwo\nthr")
+	if !reflect.DeepEqual(lines, []string{"one\n", "two\n"}) || rest != "thr" {
+		t.Errorf("unexpected lines %q and rest %q", lines, rest)
+	}
+	lines, rest = SplitLogLines("one\n")
+	if !reflect.DeepEqual(lines, []string{"one\n"}) || rest != "" {
+		t.Errorf("unexpected lines %q and rest %q", lines, rest)
+	}
+}
+
+func TestLogStreamerTail(t *testing.T) {
+	source := &fakeLogSource{polls: []string{"one\ntwo\nthree\n"}}
+	var buffer bytes.Buffer
+	err := NewLogStreamer(source).Tail(2).Output(&buffer).Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buffer.String() != "two\nthree\n" {
+		t.Errorf("unexpected output %q", buffer.String())
+	}
+}
+
+func TestLogStreamerFollow(t *testing.T) {
+	source := &fakeLogSource{polls: []string{
+		"",
+		"",
+		"one\ntwo\nthr",
+		"one\ntwo\nthree\nfour\n",
+		"one\ntwo\nthree\nfour\nfive",
+	}}
+	var buffer bytes.Buffer
+	err := NewLogStreamer(source).
+		Follow(true).
+		Tail(1).
+		Interval(1).
+		Output(&buffer).
+		Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buffer.String() != "two\nthree\nfour\nfive\n" {
+		t.Errorf("unexpected output %q", buffer.String())
+	}
+}
+
+func TestLogStreamerFollowUnavailable(t *testing.T) {
+	source := &fakeLogSource{polls: []string{"", ""}}
+	var buffer bytes.Buffer
+	err := NewLogStreamer(source).Follow(true).Interval(1).Output(&buffer).Run(context.Background())
+	if err == nil {
+		t.Errorf("expected error for unavailable log")
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the types and functions used to validate and apply the options of machine
+// pools that depend on the cloud provider of the cluster.
+
+package cluster
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift-online/ocm-cli/pkg/utils"
+)
+
+// SpotOnDemand is the value of the spot maximum price that means that the price is capped at the
+// on-demand price.
+const SpotOnDemand = "on-demand"
+
+// Limits of the size of the root disk of machine pools, in GiB:
+const (
+	MinRootDiskSize = 128
+	MaxRootDiskSize = 16384
+)
+
+// MachinePoolOptions contains the options of a machine pool that are specific to the cloud
+// provider of the cluster. Zero values mean that the option isn't used.
+type MachinePoolOptions struct {
+	// SpotInstances requests AWS spot instances instead of on-demand instances.
+	SpotInstances bool
+
+	// SpotMaxPrice is the maximum hourly price for spot instances, or 'on-demand' to cap it at
+	// the on-demand price.
+	SpotMaxPrice string
+
+	// RootDiskSize is the size of the root disk of the nodes in GiB.
+	RootDiskSize int
+
+	// AvailabilityZone places the machine pool in a single availability zone of a multi zone
+	// cluster.
+	AvailabilityZone string
+
+	// Subnet places the machine pool in a subnet of a cluster that uses an existing VPC.
+	Subnet string
+
+	// SecurityGroupIDs are additional AWS security groups for the nodes.
+	SecurityGroupIDs []string
+
+	// SecureBoot requests secure boot for the GCP shielded VMs. It is nil when not given.
+	SecureBoot *bool
+}
+
+// ParseSpotMaxPrice converts the spot maximum price to a number. The result is nil for the
+// on-demand price.
+func ParseSpotMaxPrice(value string) (*float64, error) {
+	if value == "" || value == SpotOnDemand {
+		return nil, nil
+	}
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil || price <= 0 {
+		return nil, fmt.Errorf("Spot maximum price '%s' isn't valid: it must be a positive "+
+			"number or '%s'", value, SpotOnDemand)
+	}
+	return &price, nil
+}
+
+// Validate checks that the options are supported by the cloud provider and the network settings
+// of the given cluster.
+func (o *MachinePoolOptions) Validate(cluster *cmv1.Cluster) error {
+	provider := cluster.CloudProvider().ID()
+	isAWS := provider == ProviderAWS
+	isGCP := provider == ProviderGCP
+
+	if o.SpotInstances || (o.SpotMaxPrice != "" && o.SpotMaxPrice != SpotOnDemand) {
+		if !isAWS {
+			return fmt.Errorf("Spot instances are only supported for AWS clusters")
+		}
+		if !cluster.CCS().Enabled() {
+			return fmt.Errorf("Spot instances are only supported for clusters that use the " +
+				"customer cloud subscription (CCS)")
+		}
+		if !o.SpotInstances {
+			return fmt.Errorf("Spot maximum price can only be used with spot instances")
+		}
+		_, err := ParseSpotMaxPrice(o.SpotMaxPrice)
+		if err != nil {
+			return err
+		}
+	}
+
+	if o.RootDiskSize != 0 {
+		if !isAWS && !isGCP {
+			return fmt.Errorf("Root disk size isn't supported for '%s' clusters", provider)
+		}
+		if o.RootDiskSize < MinRootDiskSize || o.RootDiskSize > MaxRootDiskSize {
+			return fmt.Errorf("Root disk size must be between %d and %d GiB, but it is %d",
+				MinRootDiskSize, MaxRootDiskSize, o.RootDiskSize)
+		}
+	}
+
+	var subnets []string
+	if cluster.AWS() != nil {
+		subnets = cluster.AWS().SubnetIDs()
+	}
+	if o.AvailabilityZone != "" && o.Subnet != "" {
+		return fmt.Errorf("Availability zone and subnet can't be used together, the availability " +
+			"zone of the machine pool is the one of the subnet")
+	}
+	if o.AvailabilityZone != "" {
+		if !isAWS {
+			return fmt.Errorf("Availability zone is only supported for AWS clusters")
+		}
+		if !cluster.MultiAZ() {
+			return fmt.Errorf("Availability zone can only be used with multi zone clusters")
+		}
+		if len(subnets) > 0 {
+			return fmt.Errorf("Cluster uses an existing VPC, use the subnet instead of the " +
+				"availability zone")
+		}
+		zones := cluster.Nodes().AvailabilityZones()
+		if !utils.Contains(zones, o.AvailabilityZone) {
+			return fmt.Errorf("Availability zone '%s' isn't valid, valid zones of the cluster are: %s",
+				o.AvailabilityZone, strings.Join(zones, ", "))
+		}
+	}
+	if o.Subnet != "" {
+		if !isAWS {
+			return fmt.Errorf("Subnet is only supported for AWS clusters")
+		}
+		if len(subnets) == 0 {
+			return fmt.Errorf("Subnet can only be used with clusters that use an existing VPC")
+		}
+		if !utils.Contains(subnets, o.Subnet) {
+			return fmt.Errorf("Subnet '%s' isn't valid, valid subnets of the cluster are: %s",
+				o.Subnet, strings.Join(subnets, ", "))
+		}
+	}
+
+	if len(o.SecurityGroupIDs) > 0 {
+		if !isAWS {
+			return fmt.Errorf("Additional security groups are only supported for AWS clusters")
+		}
+		if len(subnets) == 0 {
+			return fmt.Errorf("Additional security groups can only be used with clusters that " +
+				"use an existing VPC")
+		}
+	}
+
+	if o.SecureBoot != nil {
+		if !isGCP {
+			return fmt.Errorf("Secure boot is only supported for GCP clusters")
+		}
+		// Machine pools use the shielded VM settings of the cluster, there is no way to change
+		// them for a single machine pool:
+		current := cluster.GCP().Security().SecureBoot()
+		if *o.SecureBoot != current {
+			return fmt.Errorf("Machine pools use the secure boot setting of the cluster, which "+
+				"is '%t', it can't be changed to '%t' for a machine pool", current, *o.SecureBoot)
+		}
+	}
+	return nil
+}
+
+// Apply adds the options to the given machine pool builder. The options should have been
+// validated before.
+func (o *MachinePoolOptions) Apply(cluster *cmv1.Cluster, builder *cmv1.MachinePoolBuilder) error {
+	aws := cmv1.NewAWSMachinePool()
+	if len(o.SecurityGroupIDs) > 0 {
+		groups := make([]string, len(o.SecurityGroupIDs))
+		for i, group := range o.SecurityGroupIDs {
+			groups[i] = strings.TrimSpace(group)
+		}
+		aws.AdditionalSecurityGroupIds(groups...)
+	}
+	if o.SpotInstances {
+		spot := cmv1.NewAWSSpotMarketOptions()
+		price, err := ParseSpotMaxPrice(o.SpotMaxPrice)
+		if err != nil {
+			return err
+		}
+		if price != nil {
+			spot.MaxPrice(*price)
+		}
+		aws.SpotMarketOptions(spot)
+	}
+	if !aws.Empty() {
+		builder.AWS(aws)
+	}
+
+	if o.RootDiskSize != 0 {
+		switch cluster.CloudProvider().ID() {
+		case ProviderAWS:
+			builder.RootVolume(cmv1.NewRootVolume().AWS(cmv1.NewAWSVolume().Size(o.RootDiskSize)))
+		case ProviderGCP:
+			builder.RootVolume(cmv1.NewRootVolume().GCP(cmv1.NewGCPVolume().Size(o.RootDiskSize)))
+		}
+	}
+	if o.AvailabilityZone != "" {
+		builder.AvailabilityZones(o.AvailabilityZone)
+	}
+	if o.Subnet != "" {
+		builder.Subnets(o.Subnet)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package cluster
+
+import (
+	"strings"
+	"testing"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+func newAWSCluster(t *testing.T, ccs bool, subnets ...string) *cmv1.Cluster {
+	return newTestCluster(t, cmv1.NewCluster().
+		CloudProvider(cmv1.NewCloudProvider().ID(ProviderAWS)).
+		CCS(cmv1.NewCCS().Enabled(ccs)).
+		MultiAZ(true).
+		Nodes(cmv1.NewClusterNodes().AvailabilityZones("us-east-1a", "us-east-1b", "us-east-1c")).
+		AWS(cmv1.NewAWS().SubnetIDs(subnets...)))
+}
+
+func newGCPCluster(t *testing.T, secureBoot bool) *cmv1.Cluster {
+	return newTestCluster(t, cmv1.NewCluster().
+		CloudProvider(cmv1.NewCloudProvider().ID(ProviderGCP)).
+		CCS(cmv1.NewCCS().Enabled(true)).
+		GCP(cmv1.NewGCP().Security(cmv1.NewGcpSecurity().SecureBoot(secureBoot))))
+}
+
+func TestMachinePoolOptionsValidate(t *testing.T) {
+	yes := true
+	no := false
+	tests := []struct {
+		name    string
+		cluster *cmv1.Cluster
+		options MachinePoolOptions
+		err     string
+	}{
+		{
+			name:    "spot instances on CCS AWS",
+			cluster: newAWSCluster(t, true),
+			options: MachinePoolOptions{SpotInstances: true, SpotMaxPrice: "0.5"},
+		},
+		{
+			name:    "spot instances without CCS",
+			cluster: newAWSCluster(t, false),
+			options: MachinePoolOptions{SpotInstances: true},
+			err:     "customer cloud subscription",
+		},
+		{
+			name:    "spot instances on GCP",
+			cluster: newGCPCluster(t, false),
+			options: MachinePoolOptions{SpotInstances: true},
+			err:     "only supported for AWS",
+		},
+		{
+			name:    "spot price without spot instances",
+			cluster: newAWSCluster(t, true),
+			options: MachinePoolOptions{SpotMaxPrice: "0.5"},
+			err:     "only be used with spot instances",
+		},
+		{
+			name:    "invalid spot price",
+			cluster: newAWSCluster(t, true),
+			options: MachinePoolOptions{SpotInstances: true, SpotMaxPrice: "cheap"},
+			err:     "isn't valid",
+		},
+		{
+			name:    "root disk on GCP",
+			cluster: newGCPCluster(t, false),
+			options: MachinePoolOptions{RootDiskSize: 300},
+		},
+		{
+			name:    "root disk too small",
+			cluster: newAWSCluster(t, true),
+			options: MachinePoolOptions{RootDiskSize: 64},
+			err:     "between 128 and 16384",
+		},
+		{
+			name:    "availability zone of the cluster",
+			cluster: newAWSCluster(t, true),
+			options: MachinePoolOptions{AvailabilityZone: "us-east-1b"},
+		},
+		{
+			name:    "availability zone outside the cluster",
+			cluster: newAWSCluster(t, true),
+			options: MachinePoolOptions{AvailabilityZone: "us-west-2a"},
+			err:     "valid zones of the cluster are: us-east-1a, us-east-1b, us-east-1c",
+		},
+		{
+			name:    "availability zone with existing VPC",
+			cluster: newAWSCluster(t, true, "subnet-1"),
+			options: MachinePoolOptions{AvailabilityZone: "us-east-1a"},
+			err:     "use the subnet",
+		},
+		{
+			name:    "subnet of the cluster",
+			cluster: newAWSCluster(t, true, "subnet-1", "subnet-2"),
+			options: MachinePoolOptions{Subnet: "subnet-2"},
+		},
+		{
+			name:    "subnet without existing VPC",
+			cluster: newAWSCluster(t, true),
+			options: MachinePoolOptions{Subnet: "subnet-2"},
+			err:     "existing VPC",
+		},
+		{
+			name:    "subnet and availability zone",
+			cluster: newAWSCluster(t, true, "subnet-1"),
+			options: MachinePoolOptions{Subnet: "subnet-1", AvailabilityZone: "us-east-1a"},
+			err:     "can't be used together",
+		},
+		{
+			name:    "security groups without existing VPC",
+			cluster: newAWSCluster(t, true),
+			options: MachinePoolOptions{SecurityGroupIDs: []string{"sg-1"}},
+			err:     "existing VPC",
+		},
+		{
+			name:    "secure boot matching the cluster",
+			cluster: newGCPCluster(t, true),
+			options: MachinePoolOptions{SecureBoot: &yes},
+		},
+		{
+			name:    "secure boot different to the cluster",
+			cluster: newGCPCluster(t, true),
+			options: MachinePoolOptions{SecureBoot: &no},
+			err:     "can't be changed",
+		},
+		{
+			name:    "secure boot on AWS",
+			cluster: newAWSCluster(t, true),
+			options: MachinePoolOptions{SecureBoot: &yes},
+			err:     "only supported for GCP",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.options.Validate(test.cluster)
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("expected error containing '%s', got: %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestMachinePoolOptionsApply(t *testing.T) {
+	options := MachinePoolOptions{
+		SpotInstances:    true,
+		SpotMaxPrice:     "0.25",
+		RootDiskSize:     500,
+		Subnet:           "subnet-1",
+		SecurityGroupIDs: []string{" sg-1", "sg-2 "},
+	}
+	builder := cmv1.NewMachinePool().ID("mp-1")
+	err := options.Apply(newAWSCluster(t, true, "subnet-1"), builder)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pool, err := builder.Build()
+	if err != nil {
+		t.Fatalf("failed to build machine pool: %v", err)
+	}
+	if price := pool.AWS().SpotMarketOptions().MaxPrice(); price != 0.25 {
+		t.Errorf("expected max price 0.25, got %v", price)
+	}
+	if size := pool.RootVolume().AWS().Size(); size != 500 {
+		t.Errorf("expected root disk size 500, got %d", size)
+	}
+	if subnets := pool.Subnets(); len(subnets) != 1 || subnets[0] != "subnet-1" {
+		t.Errorf("expected subnet 'subnet-1', got %v", subnets)
+	}
+	groups := pool.AWS().AdditionalSecurityGroupIds()
+	if strings.Join(groups, ",") != "sg-1,sg-2" {
+		t.Errorf("expected trimmed security groups, got %v", groups)
+	}
+
+	// On-demand spot instances don't have a maximum price, and GCP disks use the GCP volume:
+	options = MachinePoolOptions{SpotInstances: true, SpotMaxPrice: SpotOnDemand}
+	builder = cmv1.NewMachinePool()
+	err = options.Apply(newAWSCluster(t, true), builder)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pool, _ = builder.Build()
+	if _, ok := pool.AWS().SpotMarketOptions().GetMaxPrice(); ok {
+		t.Errorf("expected no maximum price")
+	}
+	options = MachinePoolOptions{RootDiskSize: 300}
+	builder = cmv1.NewMachinePool()
+	err = options.Apply(newGCPCluster(t, false), builder)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pool, _ = builder.Build()
+	if size := pool.RootVolume().GCP().Size(); size != 300 {
+		t.Errorf("expected GCP root disk size 300, got %d", size)
+	}
+	if pool.AWS() != nil {
+		t.Errorf("expected no AWS settings for GCP machine pool")
+	}
+}
@@ -0,0 +1,551 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the types and functions used to move the nodes of a machine pool to a new
+// machine pool with a different instance type.
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// Types of the steps of a machine pool migration:
+const (
+	MigrationFixReplicas = "fix-replicas"
+	MigrationCreate      = "create"
+	MigrationScaleUp     = "scale-up"
+	MigrationScaleDown   = "scale-down"
+	MigrationAutoscale   = "autoscale"
+	MigrationDelete      = "delete"
+)
+
+// MigrationStep is one of the steps of a machine pool migration.
+type MigrationStep struct {
+	Type     string `json:"type"`
+	Pool     string `json:"pool"`
+	Replicas int    `json:"replicas,omitempty"`
+
+	// MinReplicas and MaxReplicas are the limits of the autoscale step.
+	MinReplicas int `json:"min_replicas,omitempty"`
+	MaxReplicas int `json:"max_replicas,omitempty"`
+}
+
+// String returns a description of the step suitable for messages.
+func (s MigrationStep) String() string {
+	switch s.Type {
+	case MigrationFixReplicas:
+		return fmt.Sprintf("Disable autoscaling of machine pool '%s' keeping its %d replicas and "+
+			"wait for the nodes", s.Pool, s.Replicas)
+	case MigrationCreate:
+		return fmt.Sprintf("Create machine pool '%s' with %d replicas and wait for the nodes",
+			s.Pool, s.Replicas)
+	case MigrationScaleUp:
+		return fmt.Sprintf("Scale machine pool '%s' up to %d replicas and wait for the nodes",
+			s.Pool, s.Replicas)
+	case MigrationScaleDown:
+		return fmt.Sprintf("Scale machine pool '%s' down to %d replicas and wait for the nodes "+
+			"to be removed", s.Pool, s.Replicas)
+	case MigrationAutoscale:
+		return fmt.Sprintf("Enable autoscaling of machine pool '%s' with %d to %d replicas",
+			s.Pool, s.MinReplicas, s.MaxReplicas)
+	case MigrationDelete:
+		return fmt.Sprintf("Delete machine pool '%s'", s.Pool)
+	default:
+		return s.Type
+	}
+}
+
+// Migration describes the migration of the nodes of a machine pool to a new machine pool, and
+// how many of its steps are completed. It is saved as a checkpoint after each step.
+type Migration struct {
+	ClusterID    string          `json:"cluster_id"`
+	From         string          `json:"from"`
+	To           string          `json:"to"`
+	InstanceType string          `json:"instance_type"`
+	Steps        []MigrationStep `json:"steps"`
+	Completed    int             `json:"completed"`
+}
+
+// Done returns true if all the steps of the migration are completed.
+func (m *Migration) Done() bool {
+	return m.Completed >= len(m.Steps)
+}
+
+var invalidPoolChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// MigrationPoolID returns the default identifier of the machine pool that replaces the given
+// one, derived from the instance type.
+func MigrationPoolID(from string, instanceType string) string {
+	suffix := invalidPoolChars.ReplaceAllString(strings.ToLower(instanceType), "-")
+	return strings.Trim(from+"-"+suffix, "-")
+}
+
+// PlanMigration calculates the steps needed to move the nodes of the source machine pool to a new
+// machine pool, adding at most the given number of nodes in each step. The nodes of the source
+// machine pool are removed only after the same number of nodes are ready in the new one. The
+// current number of nodes of the source machine pool is used when it is autoscaled, and a
+// negative value means that it isn't known.
+func PlanMigration(clusterID string, source *cmv1.MachinePool, nodes int, to string,
+	instanceType string, step int) (*Migration, error) {
+	if source.ID() == "default" {
+		return nil, fmt.Errorf("The default machine pool can't be migrated, it is part of the " +
+			"cluster")
+	}
+	if to == "" || to == source.ID() {
+		return nil, fmt.Errorf("The new machine pool needs an identifier different to '%s'",
+			source.ID())
+	}
+	if instanceType == source.InstanceType() {
+		return nil, fmt.Errorf("Machine pool '%s' already uses instance type '%s'",
+			source.ID(), instanceType)
+	}
+	if step < 1 {
+		return nil, fmt.Errorf("The number of nodes added in each step must be at least 1")
+	}
+
+	migration := &Migration{
+		ClusterID:    clusterID,
+		From:         source.ID(),
+		To:           to,
+		InstanceType: instanceType,
+	}
+
+	// Autoscaling is disabled in the original machine pool first, as the replicas of an
+	// autoscaled machine pool can't be changed and the autoscaler would undo the changes, and it
+	// is enabled in the new machine pool at the end. To avoid removing nodes that are in use the
+	// original machine pool keeps its current size, or the maximum if it isn't known:
+	total := source.Replicas()
+	autoscaling := source.Autoscaling()
+	if autoscaling != nil {
+		total = autoscaling.MaxReplicas()
+		if nodes >= 0 {
+			total = max(min(nodes, total), autoscaling.MinReplicas())
+		}
+		migration.Steps = append(migration.Steps, MigrationStep{
+			Type:     MigrationFixReplicas,
+			Pool:     source.ID(),
+			Replicas: total,
+		})
+	}
+
+	moved := min(step, total)
+	migration.Steps = append(migration.Steps, MigrationStep{
+		Type:     MigrationCreate,
+		Pool:     to,
+		Replicas: moved,
+	})
+	if moved > 0 {
+		migration.Steps = append(migration.Steps, scaleDownStep(source.ID(), total-moved))
+	}
+	for moved < total {
+		next := min(moved+step, total)
+		migration.Steps = append(migration.Steps,
+			MigrationStep{
+				Type:     MigrationScaleUp,
+				Pool:     to,
+				Replicas: next,
+			},
+			scaleDownStep(source.ID(), total-next),
+		)
+		moved = next
+	}
+	if autoscaling != nil {
+		migration.Steps = append(migration.Steps, MigrationStep{
+			Type:        MigrationAutoscale,
+			Pool:        to,
+			MinReplicas: autoscaling.MinReplicas(),
+			MaxReplicas: autoscaling.MaxReplicas(),
+		})
+	}
+	migration.Steps = append(migration.Steps, MigrationStep{
+		Type: MigrationDelete,
+		Pool: source.ID(),
+	})
+	return migration, nil
+}
+
+func scaleDownStep(pool string, replicas int) MigrationStep {
+	return MigrationStep{
+		Type:     MigrationScaleDown,
+		Pool:     pool,
+		Replicas: replicas,
+	}
+}
+
+// PrintMigration writes the steps of the migration, marking the ones that are completed.
+func PrintMigration(w io.Writer, migration *Migration) {
+	fmt.Fprintf(w, "Migration of machine pool '%s' to machine pool '%s' with instance type '%s':\n",
+		migration.From, migration.To, migration.InstanceType)
+	for i, step := range migration.Steps {
+		mark := " "
+		if i < migration.Completed {
+			mark = "x"
+		}
+		fmt.Fprintf(w, "  [%s] %d. %s\n", mark, i+1, step)
+	}
+}
+
+// MigrationCheckpointPath returns the default path of the checkpoint file of the migration of the
+// given machine pool. It is in the 'ocm/migrations' directory of the user configuration directory.
+func MigrationCheckpointPath(clusterID string, pool string) (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "ocm", "migrations", clusterID+"-"+pool+".json"), nil
+}
+
+// LoadMigration reads a migration checkpoint. It returns nil if the file doesn't exist.
+func LoadMigration(path string) (*Migration, error) {
+	// #nosec G304
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read migration checkpoint '%s': %v", path, err)
+	}
+	migration := &Migration{}
+	err = json.Unmarshal(data, migration)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse migration checkpoint '%s': %v", path, err)
+	}
+	return migration, nil
+}
+
+// SaveMigration writes a migration checkpoint, replacing the previous one atomically.
+func SaveMigration(path string, migration *Migration) error {
+	data, err := json.MarshalIndent(migration, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return fmt.Errorf("Failed to create directory for migration checkpoint '%s': %v", path, err)
+	}
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return fmt.Errorf("Failed to write migration checkpoint '%s': %v", tmp, err)
+	}
+	err = os.Rename(tmp, path)
+	if err != nil {
+		return fmt.Errorf("Failed to write migration checkpoint '%s': %v", path, err)
+	}
+	return nil
+}
+
+// MigrationClient performs the operations of a migration on the machine pools of a cluster.
+type MigrationClient interface {
+	// CreatePool creates a copy of the source machine pool with a new identifier, instance type
+	// and number of replicas. It does nothing if the machine pool already exists.
+	CreatePool(ctx context.Context, source string, id string, instanceType string,
+		replicas int) error
+
+	// ScalePool sets the number of replicas of a machine pool. If the machine pool is autoscaled
+	// this disables autoscaling.
+	ScalePool(ctx context.Context, id string, replicas int) error
+
+	// AutoscalePool enables autoscaling of a machine pool.
+	AutoscalePool(ctx context.Context, id string, minReplicas int, maxReplicas int) error
+
+	// DeletePool deletes a machine pool. It does nothing if the machine pool doesn't exist.
+	DeletePool(ctx context.Context, id string) error
+
+	// PoolNodes returns the number of nodes of the machine pool.
+	PoolNodes(ctx context.Context, id string) (int, error)
+}
+
+// apiMigrationClient is the migration client that uses the clusters management API.
+type apiMigrationClient struct {
+	client *cmv1.ClusterClient
+}
+
+// NewMigrationClient creates a migration client for the machine pools of the given cluster.
+func NewMigrationClient(client *cmv1.ClustersClient, clusterID string) MigrationClient {
+	return &apiMigrationClient{
+		client: client.Cluster(clusterID),
+	}
+}
+
+func (c *apiMigrationClient) CreatePool(ctx context.Context, source string, id string,
+	instanceType string, replicas int) error {
+	pools := c.client.MachinePools()
+	existing, err := pools.MachinePool(id).Get().SendContext(ctx)
+	if err == nil {
+		if existing.Body().InstanceType() != instanceType {
+			return fmt.Errorf("Machine pool '%s' already exists with instance type '%s'",
+				id, existing.Body().InstanceType())
+		}
+		return nil
+	}
+	if existing == nil || existing.Status() != http.StatusNotFound {
+		return fmt.Errorf("Failed to get machine pool '%s': %v", id, err)
+	}
+	response, err := pools.MachinePool(source).Get().SendContext(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to get machine pool '%s': %v", source, err)
+	}
+	original := response.Body()
+
+	// Copy the labels, taints and placement, but not the instance type and the size:
+	builder := cmv1.NewMachinePool().
+		ID(id).
+		InstanceType(instanceType).
+		Replicas(replicas).
+		Labels(original.Labels()).
+		AvailabilityZones(original.AvailabilityZones()...).
+		Subnets(original.Subnets()...)
+	taints := make([]*cmv1.TaintBuilder, 0, len(original.Taints()))
+	for _, taint := range original.Taints() {
+		taints = append(taints, cmv1.NewTaint().Copy(taint))
+	}
+	builder.Taints(taints...)
+	if original.RootVolume() != nil {
+		builder.RootVolume(cmv1.NewRootVolume().Copy(original.RootVolume()))
+	}
+	if original.AWS() != nil {
+		builder.AWS(cmv1.NewAWSMachinePool().Copy(original.AWS()))
+	}
+	pool, err := builder.Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create machine pool '%s': %v", id, err)
+	}
+	_, err = pools.Add().Body(pool).SendContext(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to create machine pool '%s': %v", id, err)
+	}
+	return nil
+}
+
+func (c *apiMigrationClient) update(ctx context.Context, id string,
+	builder *cmv1.MachinePoolBuilder) error {
+	pool, err := builder.ID(id).Build()
+	if err != nil {
+		return fmt.Errorf("Failed to update machine pool '%s': %v", id, err)
+	}
+	_, err = c.client.MachinePools().MachinePool(id).Update().Body(pool).SendContext(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to update machine pool '%s': %v", id, err)
+	}
+	return nil
+}
+
+func (c *apiMigrationClient) ScalePool(ctx context.Context, id string, replicas int) error {
+	// Like 'edit machinepool --enable-autoscaling=false', setting a fixed number of replicas
+	// disables autoscaling:
+	return c.update(ctx, id, cmv1.NewMachinePool().Replicas(replicas))
+}
+
+func (c *apiMigrationClient) AutoscalePool(ctx context.Context, id string, minReplicas int,
+	maxReplicas int) error {
+	return c.update(ctx, id, cmv1.NewMachinePool().Autoscaling(
+		cmv1.NewMachinePoolAutoscaling().MinReplicas(minReplicas).MaxReplicas(maxReplicas)))
+}
+
+func (c *apiMigrationClient) DeletePool(ctx context.Context, id string) error {
+	response, err := c.client.MachinePools().MachinePool(id).Delete().SendContext(ctx)
+	if response != nil && response.Status() == http.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to delete machine pool '%s': %v", id, err)
+	}
+	return nil
+}
+
+// PoolNodes calculates the nodes of a machine pool. The API doesn't report the nodes of each
+// machine pool of a classic cluster, so they are the compute nodes of the cluster minus the
+// replicas of the other machine pools. That isn't possible when other machine pools are
+// autoscaled, as their number of nodes isn't known, so in that case it fails instead of waiting
+// for a number of nodes that may never be reached.
+func (c *apiMigrationClient) PoolNodes(ctx context.Context, id string) (int, error) {
+	status, err := c.client.Status().Get().SendContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to get cluster status: %v", err)
+	}
+	nodes := status.Body().CurrentCompute()
+	pools, err := c.client.MachinePools().List().Size(-1).SendContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to get machine pools: %v", err)
+	}
+	autoscaled := ""
+	pools.Items().Each(func(pool *cmv1.MachinePool) bool {
+		if pool.ID() == id {
+			return true
+		}
+		if pool.Autoscaling() != nil {
+			autoscaled = pool.ID()
+			return false
+		}
+		nodes -= pool.Replicas()
+		return true
+	})
+	if autoscaled != "" {
+		return 0, fmt.Errorf("Can't count the nodes of machine pool '%s' because machine pool "+
+			"'%s' is autoscaled, disable its autoscaling during the migration", id, autoscaled)
+	}
+	return max(nodes, 0), nil
+}
+
+// Migrator runs the steps of a migration, saving a checkpoint after each step so that it can be
+// resumed if it is interrupted.
+type Migrator struct {
+	client     MigrationClient
+	migration  *Migration
+	checkpoint func(*Migration) error
+	interval   time.Duration
+	timeout    time.Duration
+	output     io.Writer
+}
+
+// NewMigrator creates a migrator that runs the pending steps of the given migration.
+func NewMigrator(client MigrationClient, migration *Migration) *Migrator {
+	return &Migrator{
+		client:    client,
+		migration: migration,
+		interval:  30 * time.Second,
+		timeout:   30 * time.Minute,
+		output:    io.Discard,
+	}
+}
+
+// Checkpoint sets the function that saves the migration after each step.
+func (m *Migrator) Checkpoint(value func(*Migration) error) *Migrator {
+	m.checkpoint = value
+	return m
+}
+
+// Interval sets how often the number of nodes is checked while waiting.
+func (m *Migrator) Interval(value time.Duration) *Migrator {
+	m.interval = value
+	return m
+}
+
+// Timeout sets how long to wait for the nodes of each step.
+func (m *Migrator) Timeout(value time.Duration) *Migrator {
+	m.timeout = value
+	return m
+}
+
+// Output sets the writer where progress messages are written.
+func (m *Migrator) Output(value io.Writer) *Migrator {
+	m.output = value
+	return m
+}
+
+// Run runs the pending steps of the migration. The migration is saved before the first step, so
+// that it can be resumed even if that step fails.
+func (m *Migrator) Run(ctx context.Context) error {
+	err := m.save()
+	if err != nil {
+		return err
+	}
+	total := len(m.migration.Steps)
+	for !m.migration.Done() {
+		index := m.migration.Completed
+		step := &m.migration.Steps[index]
+		fmt.Fprintf(m.output, "Step %d/%d: %s\n", index+1, total, step)
+
+		err = m.apply(ctx, step)
+		if err != nil {
+			return err
+		}
+		switch step.Type {
+		case MigrationCreate, MigrationScaleUp:
+			err = m.wait(ctx, step.Pool, step.Replicas, math.MaxInt)
+		case MigrationScaleDown:
+			err = m.wait(ctx, step.Pool, 0, step.Replicas)
+		case MigrationFixReplicas:
+			err = m.wait(ctx, step.Pool, step.Replicas, step.Replicas)
+		}
+		if err != nil {
+			return err
+		}
+
+		m.migration.Completed++
+		err = m.save()
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(m.output, "Machine pool '%s' migrated to machine pool '%s'\n",
+		m.migration.From, m.migration.To)
+	return nil
+}
+
+func (m *Migrator) save() error {
+	if m.checkpoint == nil {
+		return nil
+	}
+	return m.checkpoint(m.migration)
+}
+
+func (m *Migrator) apply(ctx context.Context, step *MigrationStep) error {
+	switch step.Type {
+	case MigrationCreate:
+		return m.client.CreatePool(ctx, m.migration.From, step.Pool, m.migration.InstanceType,
+			step.Replicas)
+	case MigrationScaleUp, MigrationScaleDown, MigrationFixReplicas:
+		return m.client.ScalePool(ctx, step.Pool, step.Replicas)
+	case MigrationAutoscale:
+		return m.client.AutoscalePool(ctx, step.Pool, step.MinReplicas, step.MaxReplicas)
+	case MigrationDelete:
+		return m.client.DeletePool(ctx, step.Pool)
+	default:
+		return fmt.Errorf("Unknown migration step '%s'", step.Type)
+	}
+}
+
+// wait waits till the number of nodes of the machine pool is between the given limits.
+func (m *Migrator) wait(ctx context.Context, pool string, low int, high int) error {
+	expected := low
+	if low == 0 {
+		expected = high
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+	for {
+		nodes, err := m.client.PoolNodes(ctx, pool)
+		if err != nil {
+			return err
+		}
+		if nodes >= low && nodes <= high {
+			return nil
+		}
+		fmt.Fprintf(m.output, "Waiting for %d nodes in machine pool '%s', it has %d\n", expected,
+			pool, nodes)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Timed out waiting for %d nodes in machine pool '%s', it has %d",
+				expected, pool, nodes)
+		case <-time.After(m.interval):
+		}
+	}
+}
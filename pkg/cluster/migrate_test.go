@@ -0,0 +1,279 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+func newTestPool(t *testing.T, builder *cmv1.MachinePoolBuilder) *cmv1.MachinePool {
+	pool, err := builder.Build()
+	if err != nil {
+		t.Fatalf("can't build machine pool: %v", err)
+	}
+	return pool
+}
+
+// fakeMigrationClient simulates the machine pools of a cluster, where the nodes are added or
+// removed immediately.
+type fakeMigrationClient struct {
+	pools map[string]int
+	calls []string
+
+	// failAt makes the call with that index fail, counting from one.
+	failAt int
+}
+
+func (f *fakeMigrationClient) call(format string, a ...interface{}) error {
+	f.calls = append(f.calls, fmt.Sprintf(format, a...))
+	if len(f.calls) == f.failAt {
+		return fmt.Errorf("call %d failed", f.failAt)
+	}
+	return nil
+}
+
+func (f *fakeMigrationClient) CreatePool(ctx context.Context, source string, id string,
+	instanceType string, replicas int) error {
+	err := f.call("create %s from %s %s %d", id, source, instanceType, replicas)
+	if err == nil {
+		f.pools[id] = replicas
+	}
+	return err
+}
+
+func (f *fakeMigrationClient) ScalePool(ctx context.Context, id string, replicas int) error {
+	err := f.call("scale %s %d", id, replicas)
+	if err == nil {
+		f.pools[id] = replicas
+	}
+	return err
+}
+
+func (f *fakeMigrationClient) AutoscalePool(ctx context.Context, id string, minReplicas int,
+	maxReplicas int) error {
+	return f.call("autoscale %s %d-%d", id, minReplicas, maxReplicas)
+}
+
+func (f *fakeMigrationClient) DeletePool(ctx context.Context, id string) error {
+	err := f.call("delete %s", id)
+	if err == nil {
+		delete(f.pools, id)
+	}
+	return err
+}
+
+func (f *fakeMigrationClient) PoolNodes(ctx context.Context, id string) (int, error) {
+	return f.pools[id], nil
+}
+
+func TestPlanMigration(t *testing.T) {
+	source := newTestPool(t, cmv1.NewMachinePool().ID("workers").InstanceType("m5.xlarge").Replicas(3))
+	migration, err := PlanMigration("123", source, -1, "workers-new", "m5.2xlarge", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var steps []string
+	for _, step := range migration.Steps {
+		steps = append(steps, fmt.Sprintf("%s %s %d", step.Type, step.Pool, step.Replicas))
+	}
+	expected := []string{
+		"create workers-new 2",
+		"scale-down workers 1",
+		"scale-up workers-new 3",
+		"scale-down workers 0",
+		"delete workers 0",
+	}
+	if !reflect.DeepEqual(steps, expected) {
+		t.Errorf("expected steps %v but got %v", expected, steps)
+	}
+}
+
+func TestPlanMigrationAutoscaling(t *testing.T) {
+	source := newTestPool(t, cmv1.NewMachinePool().ID("workers").InstanceType("m5.xlarge").
+		Autoscaling(cmv1.NewMachinePoolAutoscaling().MinReplicas(1).MaxReplicas(5)))
+	migration, err := PlanMigration("123", source, 4, "workers-new", "m5.2xlarge", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fix := migration.Steps[0]
+	if fix.Type != MigrationFixReplicas || fix.Pool != "workers" || fix.Replicas != 4 {
+		t.Errorf("expected first step to keep the 4 nodes of the original pool but got %+v", fix)
+	}
+	if migration.Steps[2].Type != MigrationScaleDown {
+		t.Errorf("expected scale down after disabling autoscaling but got %+v", migration.Steps[2])
+	}
+	autoscale := migration.Steps[len(migration.Steps)-2]
+	if autoscale.Type != MigrationAutoscale || autoscale.MinReplicas != 1 || autoscale.MaxReplicas != 5 {
+		t.Errorf("expected autoscale step with 1 to 5 replicas but got %+v", autoscale)
+	}
+
+	// When the current number of nodes isn't known the maximum is used:
+	migration, err = PlanMigration("123", source, -1, "workers-new", "m5.2xlarge", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fix := migration.Steps[0]; fix.Replicas != 5 {
+		t.Errorf("expected first step to keep 5 nodes but got %+v", fix)
+	}
+}
+
+func TestPlanMigrationErrors(t *testing.T) {
+	pool := newTestPool(t, cmv1.NewMachinePool().ID("workers").InstanceType("m5.xlarge").Replicas(3))
+	defaultPool := newTestPool(t, cmv1.NewMachinePool().ID("default").InstanceType("m5.xlarge"))
+	tests := []struct {
+		name         string
+		source       *cmv1.MachinePool
+		to           string
+		instanceType string
+		step         int
+		err          string
+	}{
+		{"default pool", defaultPool, "new", "m5.2xlarge", 1, "default machine pool"},
+		{"same identifier", pool, "workers", "m5.2xlarge", 1, "identifier different"},
+		{"same instance type", pool, "new", "m5.xlarge", 1, "already uses"},
+		{"invalid step", pool, "new", "m5.2xlarge", 0, "at least 1"},
+	}
+	for _, test := range tests {
+		_, err := PlanMigration("123", test.source, -1, test.to, test.instanceType, test.step)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: expected error containing '%s' but got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestMigrationPoolID(t *testing.T) {
+	id := MigrationPoolID("workers", "n2-standard-4")
+	if id != "workers-n2-standard-4" {
+		t.Errorf("unexpected identifier '%s'", id)
+	}
+	id = MigrationPoolID("workers", "m5.2xlarge")
+	if id != "workers-m5-2xlarge" {
+		t.Errorf("unexpected identifier '%s'", id)
+	}
+}
+
+func TestMigratorResume(t *testing.T) {
+	source := newTestPool(t, cmv1.NewMachinePool().ID("workers").InstanceType("m5.xlarge").Replicas(2))
+	migration, err := PlanMigration("123", source, -1, "new", "m5.2xlarge", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "migrations", "123-workers.json")
+	checkpoint := func(m *Migration) error {
+		return SaveMigration(path, m)
+	}
+
+	// Fail the second scale down:
+	client := &fakeMigrationClient{pools: map[string]int{"default": 2, "workers": 2}, failAt: 4}
+	err = NewMigrator(client, migration).Checkpoint(checkpoint).Interval(time.Millisecond).Run(context.Background())
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+
+	loaded, err := LoadMigration(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded == nil || loaded.Completed != 3 {
+		t.Fatalf("expected checkpoint with three completed steps but got %+v", loaded)
+	}
+	client.failAt = 0
+	err = NewMigrator(client, loaded).Checkpoint(checkpoint).Interval(time.Millisecond).Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !loaded.Done() {
+		t.Errorf("expected migration to be done")
+	}
+	expected := map[string]int{"default": 2, "new": 2}
+	if !reflect.DeepEqual(client.pools, expected) {
+		t.Errorf("expected pools %v but got %v", expected, client.pools)
+	}
+}
+
+func TestMigratorSavesBeforeFirstStep(t *testing.T) {
+	source := newTestPool(t, cmv1.NewMachinePool().ID("workers").InstanceType("m5.xlarge").
+		Autoscaling(cmv1.NewMachinePoolAutoscaling().MinReplicas(1).MaxReplicas(5)))
+	migration, err := PlanMigration("123", source, 2, "new", "m5.2xlarge", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "migrations", "123-workers.json")
+
+	// Fail the first step:
+	client := &fakeMigrationClient{pools: map[string]int{"workers": 2}, failAt: 1}
+	err = NewMigrator(client, migration).
+		Checkpoint(func(m *Migration) error {
+			return SaveMigration(path, m)
+		}).
+		Interval(time.Millisecond).
+		Run(context.Background())
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	loaded, err := LoadMigration(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded == nil || loaded.Completed != 0 || !reflect.DeepEqual(loaded.Steps, migration.Steps) {
+		t.Fatalf("expected checkpoint with the complete plan but got %+v", loaded)
+	}
+}
+
+func TestMigratorTimeout(t *testing.T) {
+	migration := &Migration{
+		From: "workers",
+		To:   "new",
+		Steps: []MigrationStep{
+			{Type: MigrationScaleDown, Pool: "workers", Replicas: 1},
+		},
+	}
+
+	// The nodes of the pool are never removed:
+	client := &stuckMigrationClient{fakeMigrationClient{pools: map[string]int{"workers": 3}}}
+	err := NewMigrator(client, migration).Interval(time.Millisecond).Timeout(10 * time.Millisecond).
+		Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Timed out") {
+		t.Errorf("expected timeout error but got %v", err)
+	}
+}
+
+// stuckMigrationClient is a migration client where scaling doesn't change the nodes.
+type stuckMigrationClient struct {
+	fakeMigrationClient
+}
+
+func (f *stuckMigrationClient) ScalePool(ctx context.Context, id string, replicas int) error {
+	return f.call("scale %s %d", id, replicas)
+}
+
+func TestMigratorWaitsForPool(t *testing.T) {
+	migration := &Migration{
+		From: "workers",
+		To:   "new",
+		Steps: []MigrationStep{
+			{Type: MigrationScaleUp, Pool: "new", Replicas: 2},
+		},
+	}
+
+	// Other pools growing doesn't complete the step:
+	client := &stuckMigrationClient{fakeMigrationClient{pools: map[string]int{"new": 1, "other": 10}}}
+	err := NewMigrator(client, migration).Interval(time.Millisecond).Timeout(10 * time.Millisecond).
+		Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "machine pool 'new'") {
+		t.Errorf("expected timeout waiting for pool 'new' but got %v", err)
+	}
+}
+
+func TestLoadMigrationMissing(t *testing.T) {
+	migration, err := LoadMigration(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || migration != nil {
+		t.Errorf("expected nil migration and no error but got %v and %v", migration, err)
+	}
+}
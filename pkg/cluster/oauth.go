@@ -0,0 +1,156 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to obtain tokens from the OAuth server of a cluster.
+
+package cluster
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ClusterClient returns an HTTP client for the API and OAuth servers of a cluster. The
+// certificate authorities of the given file, if any, are trusted in addition to the system ones.
+// It also returns the content of the file, so that it can be stored in the kubeconfig.
+func ClusterClient(caFile string, insecure bool) (*http.Client, []byte, error) {
+	// #nosec G402
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: insecure,
+	}
+	var caData []byte
+	if caFile != "" {
+		var err error
+		// #nosec G304
+		caData, err = os.ReadFile(caFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("can't read certificate authority file '%s': %v", caFile, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, nil, fmt.Errorf("certificate authority file '%s' doesn't contain any "+
+				"PEM certificate", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		// The OAuth server returns the token in the location of a redirect:
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return client, caData, nil
+}
+
+// TokenRequestURL returns the page of the OAuth server where users can obtain a token with the
+// browser.
+func TokenRequestURL(oauthURL string) string {
+	return strings.TrimSuffix(oauthURL, "/") + "/oauth/token/request"
+}
+
+// RequestToken obtains a token from the OAuth server using a user name and password, like
+// 'oc login' does.
+func RequestToken(client *http.Client, oauthURL string, username string,
+	password string) (string, error) {
+	authorizeURL := strings.TrimSuffix(oauthURL, "/") + "/oauth/authorize?" + url.Values{
+		"response_type": {"token"},
+		"client_id":     {"openshift-challenging-client"},
+	}.Encode()
+	request, err := http.NewRequest(http.MethodGet, authorizeURL, nil)
+	if err != nil {
+		return "", err
+	}
+	request.SetBasicAuth(username, password)
+	request.Header.Set("X-CSRF-Token", "1")
+	response, err := client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("can't connect to OAuth server '%s': %v", oauthURL, err)
+	}
+	defer response.Body.Close()
+	switch response.StatusCode {
+	case http.StatusFound, http.StatusSeeOther:
+	case http.StatusUnauthorized:
+		return "", fmt.Errorf("invalid user name or password, or the identity provider doesn't " +
+			"support password login")
+	default:
+		return "", fmt.Errorf("unexpected response from OAuth server '%s': %s",
+			oauthURL, response.Status)
+	}
+	location, err := url.Parse(response.Header.Get("Location"))
+	if err != nil {
+		return "", fmt.Errorf("can't parse redirect of OAuth server: %v", err)
+	}
+	values, err := url.ParseQuery(location.Fragment)
+	if err != nil {
+		return "", fmt.Errorf("can't parse redirect of OAuth server: %v", err)
+	}
+	if message := values.Get("error_description"); message != "" {
+		return "", fmt.Errorf("OAuth server rejected the request: %s", message)
+	}
+	token := values.Get("access_token")
+	if token == "" {
+		return "", fmt.Errorf("OAuth server didn't return a token")
+	}
+	return token, nil
+}
+
+// GetCurrentUser returns the name of the user that owns the token, verifying that the API server
+// accepts it.
+func GetCurrentUser(client *http.Client, apiURL string, token string) (string, error) {
+	request, err := http.NewRequest(http.MethodGet,
+		strings.TrimSuffix(apiURL, "/")+"/apis/user.openshift.io/v1/users/~", nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	response, err := client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("can't connect to API server '%s': %v", apiURL, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusUnauthorized {
+		return "", fmt.Errorf("API server '%s' didn't accept the token", apiURL)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected response from API server '%s': %s", apiURL,
+			response.Status)
+	}
+	var user struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&user)
+	if err != nil {
+		return "", fmt.Errorf("can't parse user returned by API server '%s': %v", apiURL, err)
+	}
+	return user.Metadata.Name, nil
+}
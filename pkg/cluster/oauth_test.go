@@ -0,0 +1,78 @@
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/oauth/authorize" || r.Header.Get("X-CSRF-Token") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Location", fmt.Sprintf("%s/oauth/token/implicit#access_token=sha256~abc&"+
+			"token_type=Bearer", "https://"+r.Host))
+		w.WriteHeader(http.StatusFound)
+	}))
+	defer server.Close()
+	client, _, err := ClusterClient("", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	token, err := RequestToken(client, server.URL, "admin", "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "sha256~abc" {
+		t.Errorf("expected token 'sha256~abc', got '%s'", token)
+	}
+
+	_, err = RequestToken(client, server.URL, "admin", "wrong")
+	if err == nil {
+		t.Errorf("expected error for wrong password")
+	}
+}
+
+func TestGetCurrentUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sha256~abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"kind":"User","metadata":{"name":"admin"}}`)
+	}))
+	defer server.Close()
+	client, _, err := ClusterClient("", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	username, err := GetCurrentUser(client, server.URL, "sha256~abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if username != "admin" {
+		t.Errorf("expected user 'admin', got '%s'", username)
+	}
+
+	_, err = GetCurrentUser(client, server.URL, "sha256~xyz")
+	if err == nil {
+		t.Errorf("expected error for invalid token")
+	}
+}
+
+func TestTokenRequestURL(t *testing.T) {
+	url := TokenRequestURL("https://oauth.example.com/")
+	if url != "https://oauth.example.com/oauth/token/request" {
+		t.Errorf("unexpected URL '%s'", url)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to check the cluster-wide proxy settings.
+
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift-online/ocm-cli/pkg/utils"
+)
+
+// SupportsClusterWideProxy checks if the cluster-wide proxy can be configured for the cluster.
+// It can't for clusters installed in the default VPC.
+func SupportsClusterWideProxy(cluster *cmv1.Cluster) bool {
+	network := cluster.GCPNetwork()
+	defaultGCPNetwork := network != nil && network.VPCName() == "" &&
+		network.ControlPlaneSubnet() == "" && network.ComputeSubnet() == ""
+	return len(cluster.AWS().SubnetIDs()) != 0 || !defaultGCPNetwork
+}
+
+// ValidateProxy checks the values of the cluster-wide proxy settings. Nil values aren't changed
+// and empty values remove the setting, so they aren't checked.
+func ValidateProxy(httpProxy, httpsProxy, noProxy *string) error {
+	if httpProxy != nil && *httpProxy != "" {
+		err := utils.ValidateHTTPProxy(*httpProxy)
+		if err != nil {
+			return err
+		}
+	}
+	if httpsProxy != nil && *httpsProxy != "" {
+		err := utils.IsURL(*httpsProxy)
+		if err != nil {
+			return fmt.Errorf("Invalid 'proxy.https_proxy' attribute '%s'", *httpsProxy)
+		}
+	}
+	if noProxy != nil && *noProxy != "" {
+		values := strings.Split(*noProxy, ",")
+		err := utils.MatchNoPorxyRE(values)
+		if err != nil {
+			return err
+		}
+		duplicate, found := utils.HasDuplicates(values)
+		if found {
+			return fmt.Errorf("no-proxy values must be unique, duplicate key '%s' found", duplicate)
+		}
+	}
+	return nil
+}
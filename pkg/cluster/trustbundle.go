@@ -0,0 +1,276 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to manage the additional trust bundle of a cluster as a
+// set of certificates.
+
+package cluster
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// TrustBundleRedacted is the value returned by the server instead of the additional trust bundle
+// when it doesn't return its contents.
+const TrustBundleRedacted = "REDACTED"
+
+// TrustBundleWarningThreshold is how long before the expiration of a certificate of the trust
+// bundle a warning is displayed.
+const TrustBundleWarningThreshold = 30 * 24 * time.Hour
+
+// Certificate is one of the certificates of an additional trust bundle.
+type Certificate struct {
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	NotBefore   time.Time `json:"not_before"`
+	NotAfter    time.Time `json:"not_after"`
+	Fingerprint string    `json:"fingerprint"`
+	IsCA        bool      `json:"is_ca"`
+
+	// PEM is the PEM encoded certificate.
+	PEM string `json:"-"`
+
+	commonName string
+}
+
+// ID returns the short identifier of the certificate, the first 16 hexadecimal digits of the
+// SHA-256 fingerprint.
+func (c *Certificate) ID() string {
+	return c.Fingerprint[:16]
+}
+
+// Name returns the common name of the subject of the certificate, or the complete subject if it
+// doesn't have a common name.
+func (c *Certificate) Name() string {
+	if c.commonName != "" {
+		return c.commonName
+	}
+	return c.Subject
+}
+
+// Status returns 'expired', 'expiring' or 'valid' depending on the expiration date of the
+// certificate.
+func (c *Certificate) Status(now time.Time) string {
+	switch {
+	case !now.Before(c.NotAfter):
+		return "expired"
+	case c.NotAfter.Sub(now) < TrustBundleWarningThreshold:
+		return "expiring"
+	default:
+		return "valid"
+	}
+}
+
+// ParseTrustBundle parses the PEM encoded certificates of a trust bundle. Blocks that aren't
+// certificates and text that isn't PEM are rejected, so that a bundle that the server wouldn't
+// accept is detected before sending it.
+func ParseTrustBundle(data string) ([]*Certificate, error) {
+	var certs []*Certificate
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("Trust bundle contains a '%s' PEM block, only certificates "+
+				"are allowed", block.Type)
+		}
+		parsed, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse certificate %d of trust bundle: %v",
+				len(certs)+1, err)
+		}
+		sum := sha256.Sum256(parsed.Raw)
+		certs = append(certs, &Certificate{
+			Subject:     parsed.Subject.String(),
+			Issuer:      parsed.Issuer.String(),
+			NotBefore:   parsed.NotBefore,
+			NotAfter:    parsed.NotAfter,
+			Fingerprint: hex.EncodeToString(sum[:]),
+			IsCA:        parsed.IsCA,
+			PEM:         string(pem.EncodeToMemory(block)),
+			commonName:  parsed.Subject.CommonName,
+		})
+	}
+	if len(bytes.TrimSpace(rest)) > 0 {
+		return nil, fmt.Errorf("Trust bundle contains text that isn't a PEM encoded certificate")
+	}
+	return certs, nil
+}
+
+// FormatTrustBundle returns the PEM encoded certificates, in the format expected by the server.
+func FormatTrustBundle(certs []*Certificate) string {
+	var buffer strings.Builder
+	for _, cert := range certs {
+		buffer.WriteString(cert.PEM)
+	}
+	return buffer.String()
+}
+
+// AddCertificates adds the given certificates to the bundle, skipping the ones that are already
+// in it. It returns the new bundle and the certificates that were added.
+func AddCertificates(bundle []*Certificate, certs []*Certificate) (result []*Certificate,
+	added []*Certificate) {
+	result = append(result, bundle...)
+	for _, cert := range certs {
+		if findCertificate(result, cert.Fingerprint) >= 0 {
+			continue
+		}
+		result = append(result, cert)
+		added = append(added, cert)
+	}
+	return
+}
+
+// RemoveCertificates removes from the bundle the certificates that match the given keys. A key
+// matches a certificate if it is a prefix of at least eight digits of its fingerprint, its
+// common name or its complete subject. It is an error if a key doesn't match any certificate.
+func RemoveCertificates(bundle []*Certificate, keys []string) (result []*Certificate,
+	removed []*Certificate, err error) {
+	remove := map[string]bool{}
+	for _, key := range keys {
+		found := false
+		for _, cert := range bundle {
+			if cert.Matches(key) {
+				remove[cert.Fingerprint] = true
+				found = true
+			}
+		}
+		if !found {
+			err = fmt.Errorf("Trust bundle doesn't contain a certificate matching '%s'", key)
+			return
+		}
+	}
+	for _, cert := range bundle {
+		if remove[cert.Fingerprint] {
+			removed = append(removed, cert)
+		} else {
+			result = append(result, cert)
+		}
+	}
+	return
+}
+
+// Matches checks if the given key is a prefix of the fingerprint, the common name or the subject
+// of the certificate.
+func (c *Certificate) Matches(key string) bool {
+	fingerprint := strings.ToLower(strings.ReplaceAll(key, ":", ""))
+	if len(fingerprint) >= 8 && strings.HasPrefix(c.Fingerprint, fingerprint) {
+		return true
+	}
+	return key == c.commonName || key == c.Subject
+}
+
+func findCertificate(certs []*Certificate, fingerprint string) int {
+	for i, cert := range certs {
+		if cert.Fingerprint == fingerprint {
+			return i
+		}
+	}
+	return -1
+}
+
+// CertificateWarnings returns the warnings for the certificates that are expired or that will
+// expire soon.
+func CertificateWarnings(certs []*Certificate, now time.Time) []string {
+	var warnings []string
+	for _, cert := range certs {
+		switch cert.Status(now) {
+		case "expired":
+			warnings = append(warnings, fmt.Sprintf("Certificate '%s' (%s) expired on %s",
+				cert.Name(), cert.ID(), cert.NotAfter.Format(time.DateOnly)))
+		case "expiring":
+			days := int(cert.NotAfter.Sub(now).Hours() / 24)
+			warnings = append(warnings, fmt.Sprintf("Certificate '%s' (%s) expires in %d days, "+
+				"on %s", cert.Name(), cert.ID(), days, cert.NotAfter.Format(time.DateOnly)))
+		}
+	}
+	return warnings
+}
+
+// PrintTrustBundle writes a table with the certificates of a trust bundle.
+func PrintTrustBundle(stream io.Writer, certs []*Certificate, now time.Time) error {
+	writer := tabwriter.NewWriter(stream, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "ID\tSUBJECT\tNOT AFTER\tSTATUS\n")
+	for _, cert := range certs {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", cert.ID(), cert.Subject,
+			cert.NotAfter.Format(time.DateOnly), cert.Status(now))
+	}
+	return writer.Flush()
+}
+
+// ReadTrustBundleFile reads and parses a file containing PEM encoded certificates.
+func ReadTrustBundleFile(path string) ([]*Certificate, error) {
+	// #nosec G304
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read trust bundle file: %v", err)
+	}
+	certs, err := ParseTrustBundle(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse trust bundle file '%s': %v", path, err)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("Trust bundle file '%s' doesn't contain certificates", path)
+	}
+	return certs, nil
+}
+
+// CurrentTrustBundle returns the certificates of the additional trust bundle of the cluster. When
+// the given file isn't empty the certificates are read from it instead, which is needed when the
+// server doesn't return the contents of the trust bundle.
+func CurrentTrustBundle(cluster *cmv1.Cluster, file string) ([]*Certificate, error) {
+	if file != "" {
+		return ReadTrustBundleFile(file)
+	}
+	bundle := cluster.AdditionalTrustBundle()
+	if bundle == TrustBundleRedacted {
+		return nil, fmt.Errorf("The server doesn't return the contents of the additional trust "+
+			"bundle of cluster '%s', use --current-bundle-file with a copy of it, or 'replace' "+
+			"to set the complete bundle", cluster.Name())
+	}
+	certs, err := ParseTrustBundle(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse additional trust bundle of cluster '%s': %v",
+			cluster.Name(), err)
+	}
+	return certs, nil
+}
+
+// UpdateTrustBundle replaces the additional trust bundle of the cluster with the given
+// certificates. If there are no certificates the trust bundle is removed.
+func UpdateTrustBundle(client *cmv1.ClustersClient, clusterID string, certs []*Certificate) error {
+	bundle := FormatTrustBundle(certs)
+	return UpdateCluster(client, clusterID, Spec{
+		ClusterWideProxy: ClusterWideProxy{
+			AdditionalTrustBundle: &bundle,
+		},
+	})
+}
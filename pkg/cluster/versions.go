@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	goVersion "github.com/hashicorp/go-version"
 
@@ -87,15 +88,186 @@ func GetEnabledVersions(client *cmv1.Client,
 		page++
 	}
 
-	sort.Slice(versions, func(i, j int) (less bool) {
-		s1, s2 := versions[i], versions[j]
-		v1, err1 := goVersion.NewVersion(s1)
-		v2, err2 := goVersion.NewVersion(s2)
-		if err1 != nil || err2 != nil {
-			// Fall back to lexicographic comparison.
-			return s1 < s2
+	sortVersions(versions)
+	return versions, defaultVersion, nil
+}
+
+// VersionMatrixRow describes a version across all the channel groups that offer it.
+type VersionMatrixRow struct {
+	Region            string                       `json:"region,omitempty"`
+	Version           string                       `json:"version"`
+	ChannelGroups     []*VersionMatrixChannelGroup `json:"channel_groups"`
+	EndOfLife         *time.Time                   `json:"end_of_life,omitempty"`
+	AvailableUpgrades []string                     `json:"available_upgrades"`
+}
+
+// VersionMatrixChannelGroup contains the flags of a version in one channel group, as they can be
+// different in each of them. For example, a version may be the default only in 'candidate'.
+type VersionMatrixChannelGroup struct {
+	Name                  string `json:"name"`
+	Default               bool   `json:"default"`
+	ROSAEnabled           bool   `json:"rosa_enabled"`
+	HCPEnabled            bool   `json:"hosted_control_plane_enabled"`
+	GCPMarketplaceEnabled bool   `json:"gcp_marketplace_enabled"`
+}
+
+// VersionMatrixFilter restricts the rows of a version matrix. Empty fields don't filter.
+type VersionMatrixFilter struct {
+	Min     string
+	Max     string
+	Product string
+}
+
+// Products accepted by the version matrix filter. There is no filter for OSD because all the enabled
+// versions are available for it:
+const (
+	MatrixProductROSA           = "rosa"
+	MatrixProductHCP            = "hcp"
+	MatrixProductGCPMarketplace = "marketplace-gcp"
+)
+
+var MatrixProducts = []string{
+	MatrixProductROSA,
+	MatrixProductHCP,
+	MatrixProductGCPMarketplace,
+}
+
+// GetVersions returns all the versions matching the given search query.
+func GetVersions(client *cmv1.Client, search string) (versions []*cmv1.Version, err error) {
+	page := 1
+	size := 100
+	for {
+		response, err := client.Versions().List().
+			Search(search).
+			Page(page).
+			Size(size).
+			Send()
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, response.Items().Slice()...)
+		if response.Size() < size {
+			break
+		}
+		page++
+	}
+	return versions, nil
+}
+
+// BuildVersionMatrix merges the versions of all the channel groups into one row per version, sorted
+// in approximate SemVer order. When the filter has a minimum or maximum the versions that can't be
+// parsed are excluded, as it isn't possible to know if they are in the range.
+func BuildVersionMatrix(versions []*cmv1.Version, filter VersionMatrixFilter) ([]*VersionMatrixRow, error) {
+	var minVersion, maxVersion *goVersion.Version
+	var err error
+	if filter.Min != "" {
+		minVersion, err = goVersion.NewVersion(filter.Min)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid minimum version '%s': %v", filter.Min, err)
+		}
+	}
+	if filter.Max != "" {
+		maxVersion, err = goVersion.NewVersion(filter.Max)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid maximum version '%s': %v", filter.Max, err)
 		}
-		return v1.LessThan(v2)
+	}
+
+	rows := map[string]*VersionMatrixRow{}
+	for _, version := range versions {
+		if !version.Enabled() || !matchesProduct(version, filter.Product) {
+			continue
+		}
+		raw := version.RawID()
+		if raw == "" {
+			raw = strings.TrimSuffix(DropOpenshiftVPrefix(version.ID()), "-"+version.ChannelGroup())
+		}
+		if minVersion != nil || maxVersion != nil {
+			parsed, err := goVersion.NewVersion(raw)
+			if err != nil {
+				continue
+			}
+			if minVersion != nil && parsed.LessThan(minVersion) {
+				continue
+			}
+			if maxVersion != nil && parsed.GreaterThan(maxVersion) {
+				continue
+			}
+		}
+
+		row, ok := rows[raw]
+		if !ok {
+			row = &VersionMatrixRow{Version: raw}
+			rows[raw] = row
+		}
+		row.ChannelGroups = append(row.ChannelGroups, &VersionMatrixChannelGroup{
+			Name:                  version.ChannelGroup(),
+			Default:               version.Default(),
+			ROSAEnabled:           version.ROSAEnabled(),
+			HCPEnabled:            version.HostedControlPlaneEnabled(),
+			GCPMarketplaceEnabled: version.GCPMarketplaceEnabled(),
+		})
+		if endOfLife, ok := version.GetEndOfLifeTimestamp(); ok {
+			if row.EndOfLife == nil || endOfLife.Before(*row.EndOfLife) {
+				row.EndOfLife = &endOfLife
+			}
+		}
+		for _, upgrade := range version.AvailableUpgrades() {
+			row.AvailableUpgrades = appendUnique(row.AvailableUpgrades, upgrade)
+		}
+	}
+
+	result := make([]*VersionMatrixRow, 0, len(rows))
+	for _, row := range rows {
+		sort.Slice(row.ChannelGroups, func(i, j int) bool {
+			return row.ChannelGroups[i].Name < row.ChannelGroups[j].Name
+		})
+		sortVersions(row.AvailableUpgrades)
+		if row.AvailableUpgrades == nil {
+			row.AvailableUpgrades = []string{}
+		}
+		result = append(result, row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return versionLess(result[i].Version, result[j].Version)
 	})
-	return versions, defaultVersion, nil
+	return result, nil
+}
+
+func matchesProduct(version *cmv1.Version, product string) bool {
+	switch product {
+	case MatrixProductROSA:
+		return version.ROSAEnabled()
+	case MatrixProductHCP:
+		return version.HostedControlPlaneEnabled()
+	case MatrixProductGCPMarketplace:
+		return version.GCPMarketplaceEnabled()
+	default:
+		return true
+	}
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+func sortVersions(versions []string) {
+	sort.Slice(versions, func(i, j int) bool {
+		return versionLess(versions[i], versions[j])
+	})
+}
+
+func versionLess(s1, s2 string) bool {
+	v1, err1 := goVersion.NewVersion(s1)
+	v2, err2 := goVersion.NewVersion(s2)
+	if err1 != nil || err2 != nil {
+		// Fall back to lexicographic comparison.
+		return s1 < s2
+	}
+	return v1.LessThan(v2)
 }
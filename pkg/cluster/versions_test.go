@@ -0,0 +1,94 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+func newTestVersion(t *testing.T, vb *cmv1.VersionBuilder) *cmv1.Version {
+	version, err := vb.Build()
+	if err != nil {
+		t.Fatalf("failed to build version: %s", err)
+	}
+
+	return version
+}
+
+func TestBuildVersionMatrix(t *testing.T) {
+	endOfLife := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	versions := []*cmv1.Version{
+		newTestVersion(t, cmv1.NewVersion().ID("openshift-v4.15.3").RawID("4.15.3").
+			ChannelGroup("stable").Enabled(true).Default(true).ROSAEnabled(true).
+			EndOfLifeTimestamp(endOfLife).AvailableUpgrades("4.15.10", "4.15.4")),
+		newTestVersion(t, cmv1.NewVersion().ID("openshift-v4.15.3-fast").RawID("4.15.3").
+			ChannelGroup("fast").Enabled(true).HostedControlPlaneEnabled(true).
+			AvailableUpgrades("4.16.0")),
+		newTestVersion(t, cmv1.NewVersion().ID("openshift-v4.14.1").RawID("4.14.1").
+			ChannelGroup("stable").Enabled(true)),
+		newTestVersion(t, cmv1.NewVersion().ID("openshift-v4.16.0-candidate").
+			ChannelGroup("candidate").Enabled(true)),
+		newTestVersion(t, cmv1.NewVersion().ID("openshift-v4.13.0").RawID("4.13.0").
+			ChannelGroup("stable").Enabled(false)),
+		newTestVersion(t, cmv1.NewVersion().ID("openshift-vnightly").RawID("nightly").
+			ChannelGroup("nightly").Enabled(true)),
+	}
+
+	tests := []struct {
+		name     string
+		filter   VersionMatrixFilter
+		expected []string
+	}{
+		{
+			name:     "No filter",
+			expected: []string{"4.14.1", "4.15.3", "4.16.0", "nightly"},
+		},
+		{
+			name:     "Minimum and maximum",
+			filter:   VersionMatrixFilter{Min: "4.15.0", Max: "4.15.99"},
+			expected: []string{"4.15.3"},
+		},
+		{
+			name:     "Minimum excludes versions that can't be parsed",
+			filter:   VersionMatrixFilter{Min: "4.0.0"},
+			expected: []string{"4.14.1", "4.15.3", "4.16.0"},
+		},
+		{
+			name:     "ROSA product",
+			filter:   VersionMatrixFilter{Product: MatrixProductROSA},
+			expected: []string{"4.15.3"},
+		},
+	}
+
+	for _, test := range tests {
+		rows, err := BuildVersionMatrix(versions, test.filter)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", test.name, err)
+		}
+		var got []string
+		for _, row := range rows {
+			got = append(got, row.Version)
+		}
+		if !reflect.DeepEqual(test.expected, got) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+
+	rows, _ := BuildVersionMatrix(versions, VersionMatrixFilter{Min: "4.15.3", Max: "4.15.3"})
+	row := rows[0]
+	expectedGroups := []*VersionMatrixChannelGroup{
+		{Name: "fast", HCPEnabled: true},
+		{Name: "stable", Default: true, ROSAEnabled: true},
+	}
+	if !reflect.DeepEqual(expectedGroups, row.ChannelGroups) {
+		t.Errorf("expected flags per channel group %+v, got %+v", expectedGroups, row.ChannelGroups)
+	}
+	if row.EndOfLife == nil || !row.EndOfLife.Equal(endOfLife) {
+		t.Errorf("expected end of life %s, got %v", endOfLife, row.EndOfLife)
+	}
+	if !reflect.DeepEqual([]string{"4.15.4", "4.15.10", "4.16.0"}, row.AvailableUpgrades) {
+		t.Errorf("expected sorted upgrades, got %v", row.AvailableUpgrades)
+	}
+}
@@ -49,15 +49,14 @@ type Config struct {
 	URL          string   `json:"url,omitempty" doc:"URL of the API gateway. The value can be the complete URL or an alias. The valid aliases are 'production', 'staging' and 'integration'."`
 	User         string   `json:"user,omitempty" doc:"User name."`
 	Pager        string   `json:"pager,omitempty" doc:"Pager command, for example 'less'. If empty no pager will be used."`
+
+	CredentialHelper string `json:"credential_helper,omitempty" doc:"Command that prints the access token, refresh token or client credentials as a JSON object when they are needed. When set, tokens and secrets are kept only in memory and are never saved to the configuration."`
 }
 
-// Load loads the configuration from the OS keyring first if available, load from the configuration file if not
+// Load loads the configuration from the store selected by the OCM_KEYRING environment variable:
+// an OS keyring, the encrypted configuration file, or the configuration file if not set.
 func Load() (cfg *Config, err error) {
-	if keyring, ok := IsKeyringManaged(); ok {
-		return loadFromOS(keyring)
-	}
-
-	return loadFromFile()
+	return CurrentStore().Load()
 }
 
 // loadFromOS loads the configuration from the OS keyring. If the configuration doesn't exist
@@ -116,37 +115,13 @@ func loadFromFile() (cfg *Config, err error) {
 	return
 }
 
-// Save saves the given configuration to the configuration file.
+// Save saves the given configuration to the store selected by the OCM_KEYRING environment
+// variable. When the configuration uses a credential helper the tokens and secrets aren't saved.
 func Save(cfg *Config) error {
-	file, err := Location()
-	if err != nil {
-		return err
-	}
-
-	data, err := json.MarshalIndent(cfg, "", "  ")
-	if err != nil {
-		return fmt.Errorf("can't marshal config: %v", err)
-	}
-
-	if keyring, ok := IsKeyringManaged(); ok {
-		// Use the OS keyring if the OCM_CONFIG env var is set to a valid keyring backend
-		err := securestore.UpsertConfigToKeyring(keyring, data)
-		if err != nil {
-			return fmt.Errorf("can't save config to OS keyring [%s]: %v", keyring, err)
-		}
-		return nil
+	if cfg.CredentialHelper != "" {
+		cfg = cfg.withoutSecrets()
 	}
-
-	dir := filepath.Dir(file)
-	err = os.MkdirAll(dir, os.FileMode(0755))
-	if err != nil {
-		return fmt.Errorf("can't create directory %s: %v", dir, err)
-	}
-	err = os.WriteFile(file, data, 0600)
-	if err != nil {
-		return fmt.Errorf("can't write file '%s': %v", file, err)
-	}
-	return nil
+	return CurrentStore().Save(cfg)
 }
 
 // Location returns the location of the configuration file. If a configuration file
@@ -259,6 +234,7 @@ func (c *Config) Disarm() {
 	c.TokenURL = ""
 	c.URL = ""
 	c.User = ""
+	c.CredentialHelper = ""
 }
 
 // IsKeyringManaged returns the keyring name and a boolean indicating if the config is managed by the keyring.
@@ -267,11 +243,8 @@ func IsKeyringManaged() (keyring string, ok bool) {
 	return keyring, keyring != ""
 }
 
-// GetKeyrings returns the available keyrings on the current host
+// GetKeyrings returns the available keyrings on the current host, including the encrypted
+// configuration file.
 func GetKeyrings() []string {
-	backends := securestore.AvailableBackends()
-	if len(backends) == 0 {
-		return []string{"no available backends"}
-	}
-	return backends
+	return append(securestore.AvailableBackends(), EncryptedKeyring)
 }
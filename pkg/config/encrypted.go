@@ -0,0 +1,296 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to store the configuration in a file encrypted with a
+// passphrase, for hosts that don't have an OS keyring.
+
+package config
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// EncryptedKeyring is the value of the OCM_KEYRING environment variable that selects the
+// encrypted configuration file.
+const EncryptedKeyring = "encrypted"
+
+// Environment variables used to pass the passphrase of the encrypted configuration file:
+const (
+	PassphraseEnv   = "OCM_PASSPHRASE"
+	PassphraseFDEnv = "OCM_PASSPHRASE_FD"
+)
+
+// Parameters of the key derivation function:
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// encryptedFile is the content of the encrypted configuration file.
+type encryptedFile struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	N       int    `json:"n"`
+	R       int    `json:"r"`
+	P       int    `json:"p"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// passphraseCache keeps the passphrase in memory, so that it is requested only once per process.
+var passphraseCache struct {
+	sync.Mutex
+	value []byte
+}
+
+// EncryptedLocation returns the location of the encrypted configuration file. When OCM_CONFIG is
+// set it is that file with the '.enc' suffix. Otherwise it is the 'ocm.json.enc' file of the
+// configuration directory, or the legacy '~/.ocm.json.enc' file if it exists. Unlike Location,
+// the result doesn't depend on the existence of the plain text file, so that it doesn't change
+// when the configuration is migrated.
+func EncryptedLocation() (string, error) {
+	if ocmconfig := os.Getenv("OCM_CONFIG"); ocmconfig != "" {
+		return ocmconfig + ".enc", nil
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	legacy := filepath.Join(home, ".ocm.json.enc")
+	_, err = os.Stat(legacy)
+	if err == nil {
+		return legacy, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "ocm", "ocm.json.enc"), nil
+}
+
+// Encrypt encrypts the data with a key derived from the passphrase.
+func Encrypt(data []byte, passphrase []byte) ([]byte, error) {
+	file := encryptedFile{
+		Version: 1,
+		KDF:     "scrypt",
+		N:       scryptN,
+		R:       scryptR,
+		P:       scryptP,
+		Salt:    make([]byte, 32),
+		Nonce:   make([]byte, 24),
+	}
+	_, err := io.ReadFull(rand.Reader, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.ReadFull(rand.Reader, file.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	key, err := deriveKey(passphrase, &file)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], file.Nonce)
+	file.Data = secretbox.Seal(nil, data, &nonce, key)
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// Decrypt decrypts data encrypted with the Encrypt function.
+func Decrypt(data []byte, passphrase []byte) ([]byte, error) {
+	file := encryptedFile{}
+	err := json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse encrypted data: %v", err)
+	}
+	if file.Version != 1 || file.KDF != "scrypt" || len(file.Nonce) != 24 {
+		return nil, fmt.Errorf("encrypted data has unsupported version %d", file.Version)
+	}
+	// The parameters of the key derivation function determine how much memory and time it
+	// needs, so only the ones used by Encrypt are accepted:
+	if file.N != scryptN || file.R != scryptR || file.P != scryptP {
+		return nil, fmt.Errorf("encrypted data has unsupported key derivation parameters "+
+			"n=%d, r=%d and p=%d", file.N, file.R, file.P)
+	}
+	key, err := deriveKey(passphrase, &file)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], file.Nonce)
+	result, ok := secretbox.Open(nil, file.Data, &nonce, key)
+	if !ok {
+		return nil, fmt.Errorf("can't decrypt data, the passphrase is wrong or the data is corrupt")
+	}
+	return result, nil
+}
+
+func deriveKey(passphrase []byte, file *encryptedFile) (*[32]byte, error) {
+	derived, err := scrypt.Key(passphrase, file.Salt, file.N, file.R, file.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("can't derive key from passphrase: %v", err)
+	}
+	key := &[32]byte{}
+	copy(key[:], derived)
+	return key, nil
+}
+
+// readPassphrase returns the passphrase given in the OCM_PASSPHRASE environment variable, read from
+// the file descriptor given in the OCM_PASSPHRASE_FD environment variable, or typed in the
+// terminal. When confirm is true the passphrase is typed twice.
+func readPassphrase(confirm bool) ([]byte, error) {
+	passphraseCache.Lock()
+	defer passphraseCache.Unlock()
+	if passphraseCache.value != nil {
+		return passphraseCache.value, nil
+	}
+
+	var passphrase []byte
+	if value, ok := os.LookupEnv(PassphraseEnv); ok {
+		passphrase = []byte(value)
+	} else if value := os.Getenv(PassphraseFDEnv); value != "" {
+		fd, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("file descriptor '%s' of %s isn't valid", value, PassphraseFDEnv)
+		}
+		file := os.NewFile(uintptr(fd), "passphrase")
+		if file == nil {
+			return nil, fmt.Errorf("file descriptor %d of %s isn't valid", fd, PassphraseFDEnv)
+		}
+		line, err := bufio.NewReader(file).ReadString('\n')
+		file.Close()
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("can't read passphrase from file descriptor %d: %v", fd, err)
+		}
+		passphrase = []byte(strings.TrimRight(line, "\r\n"))
+	} else {
+		// #nosec G115
+		stdin := int(os.Stdin.Fd())
+		if !term.IsTerminal(stdin) {
+			return nil, fmt.Errorf("passphrase of the encrypted configuration is needed, set the "+
+				"%s or %s environment variable", PassphraseEnv, PassphraseFDEnv)
+		}
+		fmt.Fprintf(os.Stderr, "Passphrase of the encrypted configuration: ")
+		value, err := term.ReadPassword(stdin)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, fmt.Errorf("can't read passphrase: %v", err)
+		}
+		if confirm {
+			fmt.Fprintf(os.Stderr, "Repeat the passphrase: ")
+			repeated, err := term.ReadPassword(stdin)
+			fmt.Fprintln(os.Stderr)
+			if err != nil {
+				return nil, fmt.Errorf("can't read passphrase: %v", err)
+			}
+			if string(repeated) != string(value) {
+				return nil, fmt.Errorf("passphrases don't match")
+			}
+		}
+		passphrase = value
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase of the encrypted configuration is empty")
+	}
+	passphraseCache.value = passphrase
+	return passphrase, nil
+}
+
+// loadFromEncrypted loads the configuration from the encrypted file. If the file doesn't exist it
+// returns nil, like when the configuration isn't in the OS keyring.
+func loadFromEncrypted() (*Config, error) {
+	file, err := EncryptedLocation()
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G304
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't read encrypted config file '%s': %v", file, err)
+	}
+	passphrase, err := readPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	data, err = Decrypt(data, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("can't load encrypted config file '%s': %v", file, err)
+	}
+	cfg := &Config{}
+	err = json.Unmarshal(data, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse encrypted config file '%s': %v", file, err)
+	}
+	return cfg, nil
+}
+
+// saveToEncrypted saves the configuration to the encrypted file. The passphrase is requested
+// twice when the file doesn't exist yet.
+func saveToEncrypted(data []byte) error {
+	file, err := EncryptedLocation()
+	if err != nil {
+		return err
+	}
+	_, err = os.Stat(file)
+	passphrase, err := readPassphrase(os.IsNotExist(err))
+	if err != nil {
+		return err
+	}
+	encrypted, err := Encrypt(data, passphrase)
+	if err != nil {
+		return fmt.Errorf("can't encrypt config: %v", err)
+	}
+	return writeConfigFile(file, encrypted)
+}
+
+// writeConfigFile writes the file atomically, creating the directory if needed.
+func writeConfigFile(file string, data []byte) error {
+	dir := filepath.Dir(file)
+	err := os.MkdirAll(dir, os.FileMode(0755))
+	if err != nil {
+		return fmt.Errorf("can't create directory %s: %v", dir, err)
+	}
+	tmp := file + ".tmp"
+	err = os.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return fmt.Errorf("can't write file '%s': %v", tmp, err)
+	}
+	err = os.Rename(tmp, file)
+	if err != nil {
+		return fmt.Errorf("can't rename file '%s' to '%s': %v", tmp, file, err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to obtain credentials from credential helpers.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+)
+
+// HelperURLEnv is the environment variable that contains the URL of the API gateway when the
+// credential helper runs, so that the helper can return different credentials for each
+// environment.
+const HelperURLEnv = "OCM_CREDENTIAL_HELPER_URL"
+
+// helperTimeout is the maximum time that the credential helper can run.
+const helperTimeout = time.Minute
+
+// HelperOutput is the JSON document that credential helpers print to the standard output. It must
+// contain a token, or a client identifier and secret.
+type HelperOutput struct {
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ClientID     string `json:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// helperCache keeps the output of credential helpers in memory, so that the helper runs only once
+// per process unless the access token it returned expires.
+var helperCache = struct {
+	sync.Mutex
+	outputs map[string]*HelperOutput
+}{
+	outputs: map[string]*HelperOutput{},
+}
+
+// RunCredentialHelper runs the credential helper command and returns the credentials that it
+// prints. The result is cached in memory while it is usable.
+func RunCredentialHelper(command string, url string) (*HelperOutput, error) {
+	key := command + "\x00" + url
+	helperCache.Lock()
+	defer helperCache.Unlock()
+	if output, ok := helperCache.outputs[key]; ok && output.usable() {
+		return output, nil
+	}
+	output, err := runHelper(command, url)
+	if err != nil {
+		return nil, err
+	}
+	helperCache.outputs[key] = output
+	return output, nil
+}
+
+// runHelper runs the credential helper. The command is split into words like a shell does, so
+// arguments containing spaces can be quoted, but it isn't passed to a shell, so there are no
+// pipes, redirections or variable expansions.
+func runHelper(command string, url string) (*HelperOutput, error) {
+	words, err := shellquote.Split(command)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse credential helper '%s': %v", command, err)
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("credential helper is empty")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), helperTimeout)
+	defer cancel()
+	// #nosec G204
+	cmd := exec.CommandContext(ctx, words[0], words[1:]...)
+	cmd.Env = append(os.Environ(), HelperURLEnv+"="+url)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	err = cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("credential helper '%s' failed: %v", words[0], err)
+	}
+	output := &HelperOutput{}
+	err = json.Unmarshal(stdout.Bytes(), output)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse output of credential helper '%s': %v", words[0], err)
+	}
+	if output.AccessToken == "" && output.RefreshToken == "" &&
+		(output.ClientID == "" || output.ClientSecret == "") {
+		return nil, fmt.Errorf("credential helper '%s' didn't return a token or client "+
+			"credentials", words[0])
+	}
+	if !output.usable() {
+		return nil, fmt.Errorf("credential helper '%s' returned an expired token", words[0])
+	}
+	return output, nil
+}
+
+// usable checks if the credentials can still be used. Refresh tokens and client credentials are
+// used by the connection to request new access tokens, so only an access token returned alone
+// needs to be checked.
+func (o *HelperOutput) usable() bool {
+	if o.RefreshToken != "" || (o.ClientID != "" && o.ClientSecret != "") {
+		return true
+	}
+	usable, err := tokenUsable(o.AccessToken, time.Minute)
+	return err == nil && usable
+}
+
+// WithHelperCredentials returns a copy of the configuration that contains the credentials
+// returned by the credential helper. The configuration is returned unchanged if it doesn't have a
+// credential helper.
+func (c *Config) WithHelperCredentials() (*Config, error) {
+	if c.CredentialHelper == "" {
+		return c, nil
+	}
+	output, err := RunCredentialHelper(c.CredentialHelper, c.URL)
+	if err != nil {
+		return nil, err
+	}
+	result := *c
+	result.AccessToken = output.AccessToken
+	result.RefreshToken = output.RefreshToken
+	if output.ClientID != "" {
+		result.ClientID = output.ClientID
+	}
+	result.ClientSecret = output.ClientSecret
+	result.User = ""
+	result.Password = ""
+	return &result, nil
+}
+
+// withoutSecrets returns a copy of the configuration without tokens, secrets and passwords. It is
+// used to save configurations that use a credential helper, so that the credentials it returns
+// are never written to disk.
+func (c *Config) withoutSecrets() *Config {
+	result := *c
+	result.AccessToken = ""
+	result.RefreshToken = ""
+	result.ClientSecret = ""
+	result.Password = ""
+	return &result
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Credential helper", func() {
+	var dir string
+
+	// writeHelper creates a helper script that prints the given output and counts how many
+	// times it runs.
+	writeHelper := func(output string) string {
+		script := filepath.Join(dir, "helper")
+		content := fmt.Sprintf("#!/bin/sh\necho run >> %s/runs\necho \"$%s\" > %s/url\n"+
+			"cat <<'EOF'\n%s\nEOF\n", dir, HelperURLEnv, dir, output)
+		err := os.WriteFile(script, []byte(content), 0700)
+		Expect(err).ToNot(HaveOccurred())
+		return script
+	}
+
+	runs := func() int {
+		data, err := os.ReadFile(filepath.Join(dir, "runs"))
+		if os.IsNotExist(err) {
+			return 0
+		}
+		Expect(err).ToNot(HaveOccurred())
+		return strings.Count(string(data), "run")
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("Adds the credentials of the helper to a copy of the configuration", func() {
+		refreshToken := MakeTokenString("Refresh", 10*time.Hour)
+		helper := writeHelper(fmt.Sprintf(`{"refresh_token": "%s"}`, refreshToken))
+		cfg := &Config{
+			CredentialHelper: helper,
+			URL:              "https://api.example.com",
+			TokenURL:         "https://sso.example.com",
+		}
+
+		result, err := cfg.WithHelperCredentials()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RefreshToken).To(Equal(refreshToken))
+		Expect(cfg.RefreshToken).To(BeEmpty())
+		armed, _, err := result.Armed()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(armed).To(BeTrue())
+
+		url, err := os.ReadFile(filepath.Join(dir, "url"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(strings.TrimSpace(string(url))).To(Equal("https://api.example.com"))
+
+		// The second time the cached credentials are used:
+		_, err = cfg.WithHelperCredentials()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runs()).To(Equal(1))
+	})
+
+	It("Runs the helper again when the access token expires", func() {
+		accessToken := MakeTokenString("Bearer", 30*time.Second)
+		helper := writeHelper(fmt.Sprintf(`{"access_token": "%s"}`, accessToken))
+		_, err := RunCredentialHelper(helper, "https://api.example.com")
+		Expect(err).To(MatchError(ContainSubstring("expired")))
+		Expect(runs()).To(Equal(1))
+	})
+
+	It("Accepts client credentials", func() {
+		helper := writeHelper(`{"client_id": "my-client", "client_secret": "my-secret"}`)
+		cfg := &Config{
+			CredentialHelper: helper,
+			ClientID:         "cloud-services",
+		}
+		result, err := cfg.WithHelperCredentials()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.ClientID).To(Equal("my-client"))
+		Expect(result.ClientSecret).To(Equal("my-secret"))
+	})
+
+	It("Splits the command like a shell", func() {
+		script := filepath.Join(dir, "helper")
+		content := "#!/bin/sh\nprintf '{\"client_id\": \"%s\", \"client_secret\": \"%s\"}' \"$1\" \"$2\"\n"
+		err := os.WriteFile(script, []byte(content), 0700)
+		Expect(err).ToNot(HaveOccurred())
+		output, err := RunCredentialHelper(script+` "my client" 'my secret'`, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.ClientID).To(Equal("my client"))
+		Expect(output.ClientSecret).To(Equal("my secret"))
+	})
+
+	It("Fails if the helper command can't be parsed", func() {
+		_, err := RunCredentialHelper(`helper "unterminated`, "")
+		Expect(err).To(MatchError(ContainSubstring("can't parse")))
+	})
+
+	It("Fails if the helper doesn't return credentials", func() {
+		helper := writeHelper(`{}`)
+		_, err := RunCredentialHelper(helper, "")
+		Expect(err).To(MatchError(ContainSubstring("didn't return")))
+	})
+
+	It("Doesn't save the credentials", func() {
+		file := filepath.Join(dir, "ocm.json")
+		os.Setenv("OCM_CONFIG", file)
+		defer os.Unsetenv("OCM_CONFIG")
+
+		err := Save(&Config{
+			CredentialHelper: "my-helper",
+			AccessToken:      "my-access-token",
+			RefreshToken:     "my-refresh-token",
+			ClientID:         "my-client",
+			ClientSecret:     "my-secret",
+			URL:              "https://api.example.com",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("my-helper"))
+		Expect(string(data)).To(ContainSubstring("my-client"))
+		Expect(string(data)).ToNot(ContainSubstring("my-access-token"))
+		Expect(string(data)).ToNot(ContainSubstring("my-refresh-token"))
+		Expect(string(data)).ToNot(ContainSubstring("my-secret"))
+	})
+})
@@ -0,0 +1,225 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the types and functions used to describe the authentication state of the
+// configuration.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// RefreshWarningThreshold is the time before the expiration of the refresh token when commands
+// start to warn that it is about to expire.
+const RefreshWarningThreshold = time.Hour
+
+// Authentication methods:
+const (
+	AuthMethodNone              = "none"
+	AuthMethodCredentialHelper  = "credential-helper"
+	AuthMethodClientCredentials = "client-credentials"
+	AuthMethodPassword          = "password"
+	AuthMethodRefreshToken      = "refresh-token"
+	AuthMethodAccessToken       = "access-token"
+)
+
+// TokenInfo contains the details of a token extracted from its claims. The signature of the
+// token isn't verified.
+type TokenInfo struct {
+	Type      string     `json:"type,omitempty"`
+	Issuer    string     `json:"issuer,omitempty"`
+	Subject   string     `json:"subject,omitempty"`
+	Username  string     `json:"username,omitempty"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	IssuedAt  *time.Time `json:"issued_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Expired   bool       `json:"expired"`
+
+	// Encrypted indicates that the token is encrypted, so its claims, including the expiration
+	// time, are unknown.
+	Encrypted bool `json:"encrypted,omitempty"`
+
+	// Error is the error found parsing the token, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// StoreInfo describes where the credentials are stored.
+type StoreInfo struct {
+	Kind    string `json:"kind"`
+	File    string `json:"file,omitempty"`
+	Keyring string `json:"keyring,omitempty"`
+}
+
+// AuthStatus summarizes the authentication state of the configuration.
+type AuthStatus struct {
+	URL              string     `json:"url,omitempty"`
+	Region           string     `json:"region,omitempty"`
+	TokenURL         string     `json:"token_url,omitempty"`
+	Method           string     `json:"method"`
+	ClientID         string     `json:"client_id,omitempty"`
+	User             string     `json:"user,omitempty"`
+	CredentialHelper string     `json:"credential_helper,omitempty"`
+	Store            StoreInfo  `json:"store"`
+	LoggedIn         bool       `json:"logged_in"`
+	Reason           string     `json:"reason,omitempty"`
+	AccessToken      *TokenInfo `json:"access_token,omitempty"`
+	RefreshToken     *TokenInfo `json:"refresh_token,omitempty"`
+
+	// Accepted indicates if the server accepted the credentials. It is nil when the server
+	// wasn't checked.
+	Accepted *bool  `json:"accepted,omitempty"`
+	Account  string `json:"account,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// AuthMethod returns the method that will be used to authenticate: a credential helper, client
+// credentials, user and password, or the tokens stored in the configuration.
+func (c *Config) AuthMethod() string {
+	switch {
+	case c.CredentialHelper != "":
+		return AuthMethodCredentialHelper
+	case c.ClientID != "" && c.ClientSecret != "":
+		return AuthMethodClientCredentials
+	case c.User != "" && c.Password != "":
+		return AuthMethodPassword
+	case c.RefreshToken != "":
+		return AuthMethodRefreshToken
+	case c.AccessToken != "":
+		return AuthMethodAccessToken
+	default:
+		return AuthMethodNone
+	}
+}
+
+// InspectToken extracts the details of the given token. Errors are reported in the Error field of
+// the result instead of being returned, so that the rest of the details can still be displayed.
+func InspectToken(text string) *TokenInfo {
+	info := &TokenInfo{}
+	if IsEncryptedToken(text) {
+		info.Encrypted = true
+		return info
+	}
+	token, err := ParseToken(text)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		info.Error = fmt.Sprintf("expected map claims but got %T", token.Claims)
+		return info
+	}
+	info.Type = stringClaim(claims, "typ")
+	info.Issuer = stringClaim(claims, "iss")
+	info.Subject = stringClaim(claims, "sub")
+	info.Username = stringClaim(claims, "preferred_username")
+	info.Scopes = strings.Fields(stringClaim(claims, "scope"))
+	info.IssuedAt = timeClaim(claims, "iat")
+	info.ExpiresAt = timeClaim(claims, "exp")
+	if info.ExpiresAt != nil {
+		info.Expired = !time.Now().Before(*info.ExpiresAt)
+	}
+	return info
+}
+
+func stringClaim(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+func timeClaim(claims jwt.MapClaims, name string) *time.Time {
+	value, ok := claims[name].(float64)
+	if !ok || value == 0 {
+		return nil
+	}
+	result := time.Unix(int64(value), 0).UTC()
+	return &result
+}
+
+// NewAuthStatus describes the authentication state of the given configuration, stored in the
+// given store. The server isn't contacted, so the Accepted field of the result is nil.
+func NewAuthStatus(cfg *Config, store Store) *AuthStatus {
+	status := &AuthStatus{
+		Method: AuthMethodNone,
+		Store: StoreInfo{
+			Kind:    store.Kind,
+			Keyring: store.Keyring,
+		},
+	}
+	switch store.Kind {
+	case StorePlaintext:
+		status.Store.File, _ = Location()
+	case StoreEncrypted:
+		status.Store.File, _ = EncryptedLocation()
+	}
+	if cfg == nil {
+		status.Reason = "configuration doesn't exist"
+		return status
+	}
+	status.URL = cfg.URL
+	status.TokenURL = cfg.TokenURL
+	status.Method = cfg.AuthMethod()
+	status.ClientID = cfg.ClientID
+	status.User = cfg.User
+	status.CredentialHelper = cfg.CredentialHelper
+
+	// Tokens provided by the credential helper aren't stored, so we need to run it:
+	effective, err := cfg.WithHelperCredentials()
+	if err != nil {
+		status.Reason = err.Error()
+		return status
+	}
+	if effective.AccessToken != "" {
+		status.AccessToken = InspectToken(effective.AccessToken)
+	}
+	if effective.RefreshToken != "" {
+		status.RefreshToken = InspectToken(effective.RefreshToken)
+	}
+	armed, reason, err := effective.Armed()
+	if err != nil {
+		reason = err.Error()
+	}
+	status.LoggedIn = armed
+	status.Reason = reason
+	return status
+}
+
+// RefreshExpiresSoon checks if the refresh token expires before the given threshold and there is
+// no other way to obtain new tokens, so that the user needs to log in again. Encrypted refresh
+// tokens are never reported, as their expiration time is unknown.
+func (c *Config) RefreshExpiresSoon(threshold time.Duration) (soon bool, left time.Duration) {
+	if c.RefreshToken == "" || IsEncryptedToken(c.RefreshToken) {
+		return
+	}
+	if c.AuthMethod() != AuthMethodRefreshToken {
+		return
+	}
+	token, err := ParseToken(c.RefreshToken)
+	if err != nil {
+		return
+	}
+	expires, left, err := tokenExpiration(token)
+	if err != nil || !expires {
+		return
+	}
+	soon = left < threshold
+	return
+}
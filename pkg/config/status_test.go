@@ -0,0 +1,134 @@
+package config
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+// encryptedToken has the structure of an encrypted token, with a header that contains the
+// 'enc' and 'cty' fields.
+const encryptedToken = "eyJhbGciOiJSU0EtT0FFUCIsImVuYyI6IkEyNTZHQ00iLCJjdHkiOiJKV1QifQ.a.b.c.d"
+
+var _ = Describe("Authentication status", func() {
+	It("Extracts the claims of a token", func() {
+		token := MakeTokenObject(jwt.MapClaims{
+			"sub":                "f:123:myuser",
+			"preferred_username": "myuser",
+			"scope":              "openid api.iam.service_accounts",
+			"exp":                time.Now().Add(time.Hour).Unix(),
+		}).Raw
+
+		info := InspectToken(token)
+		Expect(info.Error).To(BeEmpty())
+		Expect(info.Type).To(Equal("Bearer"))
+		Expect(info.Issuer).To(Equal("https://sso.redhat.com/auth/realms/redhat-external"))
+		Expect(info.Subject).To(Equal("f:123:myuser"))
+		Expect(info.Username).To(Equal("myuser"))
+		Expect(info.Scopes).To(Equal([]string{"openid", "api.iam.service_accounts"}))
+		Expect(info.IssuedAt).ToNot(BeNil())
+		Expect(info.ExpiresAt).ToNot(BeNil())
+		Expect(*info.ExpiresAt).To(BeTemporally("~", time.Now().Add(time.Hour), time.Second))
+		Expect(info.Expired).To(BeFalse())
+	})
+
+	It("Reports expired and non expiring tokens", func() {
+		info := InspectToken(MakeTokenString("Bearer", -time.Minute))
+		Expect(info.Expired).To(BeTrue())
+
+		info = InspectToken(MakeTokenObject(jwt.MapClaims{"typ": "Offline", "exp": nil}).Raw)
+		Expect(info.ExpiresAt).To(BeNil())
+		Expect(info.Expired).To(BeFalse())
+	})
+
+	It("Marks encrypted tokens as unknown", func() {
+		info := InspectToken(encryptedToken)
+		Expect(info.Encrypted).To(BeTrue())
+		Expect(info.ExpiresAt).To(BeNil())
+		Expect(info.Error).To(BeEmpty())
+	})
+
+	It("Reports tokens that can't be parsed", func() {
+		info := InspectToken("junk")
+		Expect(info.Error).ToNot(BeEmpty())
+	})
+
+	It("Determines the authentication method", func() {
+		Expect((&Config{}).AuthMethod()).To(Equal(AuthMethodNone))
+		Expect((&Config{AccessToken: "a"}).AuthMethod()).To(Equal(AuthMethodAccessToken))
+		Expect((&Config{AccessToken: "a", RefreshToken: "r"}).AuthMethod()).
+			To(Equal(AuthMethodRefreshToken))
+		Expect((&Config{User: "u", Password: "p", RefreshToken: "r"}).AuthMethod()).
+			To(Equal(AuthMethodPassword))
+		Expect((&Config{ClientID: "i", ClientSecret: "s", RefreshToken: "r"}).AuthMethod()).
+			To(Equal(AuthMethodClientCredentials))
+		Expect((&Config{CredentialHelper: "h", ClientID: "i", ClientSecret: "s"}).AuthMethod()).
+			To(Equal(AuthMethodCredentialHelper))
+	})
+
+	It("Describes the configuration and the store", func() {
+		cfg := &Config{
+			URL:          "https://api.example.com",
+			TokenURL:     "https://sso.example.com",
+			ClientID:     "cloud-services",
+			AccessToken:  MakeTokenString("Bearer", time.Hour),
+			RefreshToken: encryptedToken,
+		}
+
+		status := NewAuthStatus(cfg, Store{Kind: StoreKeyring, Keyring: "secret-service"})
+		Expect(status.URL).To(Equal("https://api.example.com"))
+		Expect(status.Method).To(Equal(AuthMethodRefreshToken))
+		Expect(status.Store).To(Equal(StoreInfo{Kind: StoreKeyring, Keyring: "secret-service"}))
+		Expect(status.LoggedIn).To(BeTrue())
+		Expect(status.AccessToken.Type).To(Equal("Bearer"))
+		Expect(status.RefreshToken.Encrypted).To(BeTrue())
+		Expect(status.Accepted).To(BeNil())
+	})
+
+	It("Explains why the configuration isn't logged in", func() {
+		cfg := &Config{
+			URL:          "https://api.example.com",
+			TokenURL:     "https://sso.example.com",
+			RefreshToken: MakeTokenString("Refresh", -time.Minute),
+		}
+
+		status := NewAuthStatus(cfg, Store{Kind: StoreEncrypted})
+		Expect(status.LoggedIn).To(BeFalse())
+		Expect(status.Reason).To(Equal("refresh token is expired"))
+		Expect(status.Store.File).To(HaveSuffix(".enc"))
+	})
+
+	It("Detects refresh tokens that are about to expire", func() {
+		cfg := &Config{RefreshToken: MakeTokenString("Refresh", 30*time.Minute)}
+		soon, left := cfg.RefreshExpiresSoon(RefreshWarningThreshold)
+		Expect(soon).To(BeTrue())
+		Expect(left).To(BeNumerically("~", 30*time.Minute, time.Minute))
+
+		cfg = &Config{RefreshToken: MakeTokenString("Refresh", 10*time.Hour)}
+		soon, _ = cfg.RefreshExpiresSoon(RefreshWarningThreshold)
+		Expect(soon).To(BeFalse())
+
+		cfg = &Config{RefreshToken: MakeTokenObject(jwt.MapClaims{"typ": "Offline", "exp": nil}).Raw}
+		soon, _ = cfg.RefreshExpiresSoon(RefreshWarningThreshold)
+		Expect(soon).To(BeFalse())
+
+		cfg = &Config{RefreshToken: encryptedToken}
+		soon, _ = cfg.RefreshExpiresSoon(RefreshWarningThreshold)
+		Expect(soon).To(BeFalse())
+	})
+
+	It("Doesn't warn when there are credentials to obtain new tokens", func() {
+		cfg := &Config{
+			ClientID:     "my-client",
+			ClientSecret: "my-secret",
+			RefreshToken: MakeTokenString("Refresh", 30*time.Minute),
+		}
+		soon, _ := cfg.RefreshExpiresSoon(RefreshWarningThreshold)
+		Expect(soon).To(BeFalse())
+	})
+})
@@ -0,0 +1,203 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the types and functions used to select where the configuration is stored
+// and to move it between stores.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/openshift-online/ocm-sdk-go/authentication/securestore"
+)
+
+// Kinds of configuration stores:
+const (
+	StorePlaintext = "plaintext"
+	StoreEncrypted = "encrypted"
+	StoreKeyring   = "keyring"
+)
+
+// Store describes where the configuration is stored.
+type Store struct {
+	// Kind is plaintext, encrypted or keyring.
+	Kind string
+
+	// Keyring is the OS keyring backend used when the kind is keyring.
+	Keyring string
+}
+
+// CurrentStore returns the store selected by the OCM_KEYRING environment variable.
+func CurrentStore() Store {
+	keyring, ok := IsKeyringManaged()
+	switch {
+	case !ok:
+		return Store{Kind: StorePlaintext}
+	case keyring == EncryptedKeyring:
+		return Store{Kind: StoreEncrypted}
+	default:
+		return Store{Kind: StoreKeyring, Keyring: keyring}
+	}
+}
+
+// String returns a description of the store suitable for messages.
+func (s Store) String() string {
+	switch s.Kind {
+	case StoreKeyring:
+		return fmt.Sprintf("OS keyring '%s'", s.Keyring)
+	case StoreEncrypted:
+		file, err := EncryptedLocation()
+		if err != nil {
+			return "encrypted file"
+		}
+		return fmt.Sprintf("encrypted file '%s'", file)
+	default:
+		file, err := Location()
+		if err != nil {
+			return "file"
+		}
+		return fmt.Sprintf("file '%s'", file)
+	}
+}
+
+// Validate checks that the store can be used on this host.
+func (s Store) Validate() error {
+	if s.Kind == StoreKeyring {
+		return securestore.ValidateBackend(s.Keyring)
+	}
+	return nil
+}
+
+// Load loads the configuration from the store. The result may be nil if the store doesn't
+// contain a configuration.
+func (s Store) Load() (*Config, error) {
+	switch s.Kind {
+	case StoreKeyring:
+		return loadFromOS(s.Keyring)
+	case StoreEncrypted:
+		return loadFromEncrypted()
+	default:
+		return loadFromFile()
+	}
+}
+
+// Save writes the configuration to the store.
+func (s Store) Save(cfg *Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("can't marshal config: %v", err)
+	}
+	switch s.Kind {
+	case StoreKeyring:
+		err := securestore.UpsertConfigToKeyring(s.Keyring, data)
+		if err != nil {
+			return fmt.Errorf("can't save config to OS keyring [%s]: %v", s.Keyring, err)
+		}
+		return nil
+	case StoreEncrypted:
+		return saveToEncrypted(data)
+	default:
+		file, err := Location()
+		if err != nil {
+			return err
+		}
+		return writeConfigFile(file, data)
+	}
+}
+
+// Remove deletes the configuration from the store.
+func (s Store) Remove() error {
+	var file string
+	var err error
+	switch s.Kind {
+	case StoreKeyring:
+		return securestore.RemoveConfigFromKeyring(s.Keyring)
+	case StoreEncrypted:
+		file, err = EncryptedLocation()
+	default:
+		file, err = Location()
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Remove(file)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("can't remove file '%s': %v", file, err)
+	}
+	return nil
+}
+
+// Migrate copies the configuration from one store to another, verifies that it can be read back
+// from the destination, and then removes it from the source unless keepSource is true.
+func Migrate(from Store, to Store, keepSource bool) error {
+	if from == to {
+		return fmt.Errorf("configuration is already stored in %s", to)
+	}
+	err := to.Validate()
+	if err != nil {
+		return err
+	}
+	cfg, err := from.Load()
+	if err != nil {
+		return err
+	}
+	if cfg == nil || isEmpty(cfg) {
+		return fmt.Errorf("there is no configuration in %s", from)
+	}
+	existing, err := to.Load()
+	if err != nil {
+		return err
+	}
+	if existing != nil && !isEmpty(existing) {
+		return fmt.Errorf("%s already contains a configuration, remove it before migrating", to)
+	}
+
+	err = to.Save(cfg)
+	if err != nil {
+		return err
+	}
+	copied, err := to.Load()
+	if err != nil {
+		return fmt.Errorf("can't verify configuration saved to %s: %v", to, err)
+	}
+	expected, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	actual, err := json.Marshal(copied)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(expected, actual) {
+		return fmt.Errorf("configuration read from %s doesn't match the original, it has been "+
+			"kept in %s", to, from)
+	}
+
+	if keepSource {
+		return nil
+	}
+	return from.Remove()
+}
+
+// isEmpty checks if the configuration doesn't contain any setting.
+func isEmpty(cfg *Config) bool {
+	data, err := json.Marshal(cfg)
+	return err == nil && string(data) == "{}"
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	homedir "github.com/mitchellh/go-homedir"
+
+	"github.com/openshift-online/ocm-cli/pkg/properties"
+)
+
+var _ = Describe("Encrypted store", func() {
+	var file string
+
+	BeforeEach(func() {
+		file = filepath.Join(GinkgoT().TempDir(), "ocm.json")
+		os.Setenv("OCM_CONFIG", file)
+		os.Setenv(PassphraseEnv, "my-passphrase")
+		os.Unsetenv(properties.KeyringEnvKey)
+		passphraseCache.value = nil
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("OCM_CONFIG")
+		os.Unsetenv(PassphraseEnv)
+		os.Unsetenv(properties.KeyringEnvKey)
+		passphraseCache.value = nil
+	})
+
+	It("Encrypts and decrypts data", func() {
+		encrypted, err := Encrypt([]byte("my-data"), []byte("my-passphrase"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(encrypted)).ToNot(ContainSubstring("my-data"))
+		decrypted, err := Decrypt(encrypted, []byte("my-passphrase"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(decrypted)).To(Equal("my-data"))
+		_, err = Decrypt(encrypted, []byte("wrong"))
+		Expect(err).To(MatchError(ContainSubstring("passphrase is wrong")))
+	})
+
+	It("Rejects unsupported key derivation parameters", func() {
+		encrypted, err := Encrypt([]byte("my-data"), []byte("my-passphrase"))
+		Expect(err).ToNot(HaveOccurred())
+		hostile := strings.Replace(string(encrypted), `"n": 32768`, `"n": 1073741824`, 1)
+		Expect(hostile).ToNot(Equal(string(encrypted)))
+		_, err = Decrypt([]byte(hostile), []byte("my-passphrase"))
+		Expect(err).To(MatchError(ContainSubstring("unsupported key derivation parameters")))
+	})
+
+	It("Saves and loads the configuration when selected with OCM_KEYRING", func() {
+		os.Setenv(properties.KeyringEnvKey, EncryptedKeyring)
+		Expect(CurrentStore()).To(Equal(Store{Kind: StoreEncrypted}))
+
+		err := Save(&Config{RefreshToken: "my-token", URL: "https://api.example.com"})
+		Expect(err).ToNot(HaveOccurred())
+		data, err := os.ReadFile(file + ".enc")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).ToNot(ContainSubstring("my-token"))
+		_, err = os.Stat(file)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+
+		cfg, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.RefreshToken).To(Equal("my-token"))
+	})
+
+	It("Migrates the configuration between plain text and encrypted files", func() {
+		plaintext := Store{Kind: StorePlaintext}
+		encrypted := Store{Kind: StoreEncrypted}
+		Expect(plaintext.Save(&Config{RefreshToken: "my-token"})).To(Succeed())
+
+		Expect(Migrate(plaintext, encrypted, false)).To(Succeed())
+		_, err := os.Stat(file)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+		cfg, err := encrypted.Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.RefreshToken).To(Equal("my-token"))
+
+		Expect(Migrate(encrypted, plaintext, true)).To(Succeed())
+		cfg, err = plaintext.Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.RefreshToken).To(Equal("my-token"))
+		_, err = os.Stat(file + ".enc")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Doesn't overwrite an existing configuration", func() {
+		plaintext := Store{Kind: StorePlaintext}
+		encrypted := Store{Kind: StoreEncrypted}
+		Expect(plaintext.Save(&Config{RefreshToken: "my-token"})).To(Succeed())
+		Expect(encrypted.Save(&Config{RefreshToken: "other-token"})).To(Succeed())
+
+		err := Migrate(plaintext, encrypted, false)
+		Expect(err).To(MatchError(ContainSubstring("already contains")))
+		cfg, err := plaintext.Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.RefreshToken).To(Equal("my-token"))
+	})
+
+	It("Keeps the location of the encrypted file when the legacy file is removed", func() {
+		home := GinkgoT().TempDir()
+		os.Unsetenv("OCM_CONFIG")
+		GinkgoT().Setenv("HOME", home)
+		GinkgoT().Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+		homedir.Reset()
+		DeferCleanup(homedir.Reset)
+		legacy := filepath.Join(home, ".ocm.json")
+		Expect(os.WriteFile(legacy, []byte(`{"refresh_token": "my-token"}`), 0600)).To(Succeed())
+
+		plaintext := Store{Kind: StorePlaintext}
+		encrypted := Store{Kind: StoreEncrypted}
+		location, err := EncryptedLocation()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(location).To(Equal(filepath.Join(home, ".config", "ocm", "ocm.json.enc")))
+		Expect(Migrate(plaintext, encrypted, false)).To(Succeed())
+		_, err = os.Stat(legacy)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+		Expect(EncryptedLocation()).To(Equal(location))
+
+		os.Setenv(properties.KeyringEnvKey, EncryptedKeyring)
+		cfg, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.RefreshToken).To(Equal("my-token"))
+	})
+
+	It("Fails if there is nothing to migrate", func() {
+		err := Migrate(Store{Kind: StorePlaintext}, Store{Kind: StoreEncrypted}, false)
+		Expect(err).To(MatchError(ContainSubstring("there is no configuration")))
+	})
+})
@@ -127,3 +127,14 @@ func TokenType(token *jwt.Token) (typ string, err error) {
 	typ = value
 	return
 }
+
+// TokenExpiresAt returns the time when the given token expires, or the zero time if it doesn't
+// expire.
+func TokenExpiresAt(token *jwt.Token) (expiresAt time.Time, err error) {
+	expires, left, err := tokenExpiration(token)
+	if err != nil || !expires {
+		return
+	}
+	expiresAt = time.Now().Add(left).Truncate(time.Second)
+	return
+}
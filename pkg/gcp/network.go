@@ -0,0 +1,127 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	cloudresourcemanager "google.golang.org/api/cloudresourcemanager/v1"
+	compute "google.golang.org/api/compute/v1"
+	googleapi "google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+// Subnet contains the details of a GCP subnet that are needed to check that a cluster can use
+// it.
+type Subnet struct {
+	Name    string
+	Region  string
+	Network string
+
+	// CIDR is the primary range of the subnet, and SecondaryCIDRs are the secondary ranges.
+	CIDR           string
+	SecondaryCIDRs []string
+
+	// Purpose is the purpose of the subnet, for example 'PRIVATE' or 'PRIVATE_SERVICE_CONNECT'.
+	Purpose string
+}
+
+// NetworkClient reads the details of GCP networks that aren't available through the OCM API.
+type NetworkClient interface {
+	// Subnet returns the subnet with the given name, or nil if it doesn't exist in the region.
+	Subnet(ctx context.Context, project, region, name string) (*Subnet, error)
+
+	// NetworkExists checks if the VPC network exists in the project.
+	NetworkExists(ctx context.Context, project, name string) (bool, error)
+
+	// RoleMembers returns the members that have the given role in the IAM policy of the project.
+	RoleMembers(ctx context.Context, project, role string) ([]string, error)
+}
+
+type networkClient struct {
+	compute              *compute.Service
+	cloudResourceManager *cloudresourcemanager.Service
+}
+
+// NewNetworkClient creates a client that uses the given credentials, in the JSON format of a
+// service account key. When they are empty the application default credentials are used.
+func NewNetworkClient(ctx context.Context, credentials []byte) (NetworkClient, error) {
+	var options []option.ClientOption
+	if len(credentials) > 0 {
+		options = append(options, option.WithCredentialsJSON(credentials))
+	}
+	computeService, err := compute.NewService(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	cloudResourceManager, err := cloudresourcemanager.NewService(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &networkClient{
+		compute:              computeService,
+		cloudResourceManager: cloudResourceManager,
+	}, nil
+}
+
+func (c *networkClient) Subnet(ctx context.Context, project, region, name string) (*Subnet, error) {
+	subnet, err := c.compute.Subnetworks.Get(project, region, name).Context(ctx).Do()
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subnet '%s': %v", name, err)
+	}
+	result := &Subnet{
+		Name:    subnet.Name,
+		Region:  path.Base(subnet.Region),
+		Network: path.Base(subnet.Network),
+		CIDR:    subnet.IpCidrRange,
+		Purpose: subnet.Purpose,
+	}
+	for _, secondary := range subnet.SecondaryIpRanges {
+		result.SecondaryCIDRs = append(result.SecondaryCIDRs, secondary.IpCidrRange)
+	}
+	return result, nil
+}
+
+func (c *networkClient) NetworkExists(ctx context.Context, project, name string) (bool, error) {
+	_, err := c.compute.Networks.Get(project, name).Context(ctx).Do()
+	if isNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get network '%s': %v", name, err)
+	}
+	return true, nil
+}
+
+func (c *networkClient) RoleMembers(ctx context.Context, project, role string) ([]string, error) {
+	policy, err := c.cloudResourceManager.Projects.
+		GetIamPolicy(project, &cloudresourcemanager.GetIamPolicyRequest{}).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IAM policy of project '%s': %v", project, err)
+	}
+	var members []string
+	for _, binding := range policy.Bindings {
+		if binding.Role == role {
+			members = append(members, binding.Members...)
+		}
+	}
+	return members, nil
+}
+
+// ServiceAccountMember returns the IAM policy member of a service account.
+func ServiceAccountMember(email string) string {
+	return "serviceAccount:" + strings.ToLower(email)
+}
+
+func isNotFound(err error) bool {
+	var apiError *googleapi.Error
+	return errors.As(err, &apiError) && apiError.Code == http.StatusNotFound
+}
@@ -55,12 +55,21 @@ func generateEntriesOutput(cluster *cmv1.Cluster, ingress *cmv1.Ingress) map[str
 	if namespaceOwnershipPolicy != "" {
 		entries["Namespace Ownership Policy"] = namespaceOwnershipPolicy
 	}
-	routeSelectors := ""
-	if len(ingress.RouteSelectors()) > 0 {
-		routeSelectors = fmt.Sprintf("%v", ingress.RouteSelectors())
+	routeSelectors := make([]string, 0, len(ingress.RouteSelectors()))
+	for key, value := range ingress.RouteSelectors() {
+		routeSelectors = append(routeSelectors, fmt.Sprintf("%s=%s", key, value))
 	}
-	if routeSelectors != "" {
-		entries["Route Selectors"] = routeSelectors
+	if len(routeSelectors) > 0 {
+		entries["Route Selectors"] = utils.SliceToSortedString(routeSelectors)
+	}
+	if ingress.DNSName() != "" {
+		entries["DNS Name"] = ingress.DNSName()
+	}
+	if ingress.ClusterRoutesHostname() != "" {
+		entries["Cluster Routes Hostname"] = ingress.ClusterRoutesHostname()
+	}
+	if ingress.ClusterRoutesTlsSecretRef() != "" {
+		entries["Cluster Routes TLS Secret Ref"] = ingress.ClusterRoutesTlsSecretRef()
 	}
 	excludedNamespaces := utils.SliceToSortedString(ingress.ExcludedNamespaces())
 	if excludedNamespaces != "" {
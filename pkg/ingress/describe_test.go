@@ -45,3 +45,21 @@ var _ = Describe("Retrieve map of entries for output", func() {
 		Expect(mapOutput).To(HaveLen(10))
 	})
 })
+
+var _ = Describe("Retrieve cluster routes for output", func() {
+	It("includes the cluster routes and formats the route selectors", func() {
+		cluster, err := cmv1.NewCluster().ID("123").Build()
+		Expect(err).To(BeNil())
+		ingress, err := cmv1.NewIngress().
+			ID("123").
+			RouteSelectors(map[string]string{"tier": "frontend", "a": "b"}).
+			ClusterRoutesHostname("apps.example.com").
+			ClusterRoutesTlsSecretRef("apps-tls").
+			Build()
+		Expect(err).To(BeNil())
+		mapOutput := generateEntriesOutput(cluster, ingress)
+		Expect(mapOutput).To(HaveKeyWithValue("Route Selectors", "[a=b, tier=frontend]"))
+		Expect(mapOutput).To(HaveKeyWithValue("Cluster Routes Hostname", "apps.example.com"))
+		Expect(mapOutput).To(HaveKeyWithValue("Cluster Routes TLS Secret Ref", "apps-tls"))
+	})
+})
@@ -0,0 +1,324 @@
+package ingress
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"gopkg.in/yaml.v3"
+
+	"github.com/openshift-online/ocm-cli/pkg/utils"
+)
+
+var ValidLoadBalancerTypes = []string{
+	string(cmv1.LoadBalancerFlavorClassic),
+	string(cmv1.LoadBalancerFlavorNlb),
+}
+
+var ValidWildcardPolicies = []string{
+	string(cmv1.WildcardPolicyWildcardsDisallowed),
+	string(cmv1.WildcardPolicyWildcardsAllowed),
+}
+
+var ValidNamespaceOwnershipPolicies = []string{
+	string(cmv1.NamespaceOwnershipPolicyStrict),
+	string(cmv1.NamespaceOwnershipPolicyInterNamespaceAllowed),
+}
+
+var (
+	labelNameRE  = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?)?$`)
+	dnsLabelRE   = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+	dnsNameRE    = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	expressionRE = regexp.MustCompile(`(!=|^!|\s(in|notin)\s*\()`)
+)
+
+// editableFields are the fields of the JSON representation of an ingress that can be changed.
+// The rest of the fields of the ingress type are accepted in files, so that the output of
+// 'describe ingress --output' can be used as input, but they are ignored.
+var editableFields = []string{
+	"cluster_routes_hostname",
+	"cluster_routes_tls_secret_ref",
+	"component_routes",
+	"excluded_namespaces",
+	"listening",
+	"load_balancer_type",
+	"route_namespace_ownership_policy",
+	"route_selectors",
+	"route_wildcard_policy",
+}
+
+var ignoredFields = []string{
+	"kind",
+	"id",
+	"href",
+	"default",
+	"dns_name",
+}
+
+// ParseRouteSelectors parses a comma-separated list of 'key=value' labels. The API only supports
+// equality based selectors, so set based expressions like 'key in (a,b)' or 'key!=value' are
+// rejected with an explicit error instead of being sent to the server.
+func ParseRouteSelectors(value string) (map[string]string, error) {
+	result := map[string]string{}
+	if strings.TrimSpace(value) == "" {
+		return result, nil
+	}
+	for _, item := range splitSelectors(value) {
+		item = strings.TrimSpace(item)
+		if expressionRE.MatchString(item) {
+			return nil, fmt.Errorf("Route selector '%s' is a match expression, only 'key=value' "+
+				"selectors are supported", item)
+		}
+		key, label, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("Expected key=value format for route selector '%s'", item)
+		}
+		key = strings.TrimSpace(key)
+		label = strings.TrimSpace(strings.TrimPrefix(label, "="))
+		err := validateLabel(key, label)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = label
+	}
+	return result, nil
+}
+
+// splitSelectors splits the selectors by commas, but not the commas inside the parenthesis of set
+// based expressions, so that they can be reported as a whole.
+func splitSelectors(value string) []string {
+	var result []string
+	depth := 0
+	start := 0
+	for i, char := range value {
+		switch char {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(result, value[start:])
+}
+
+func validateLabel(key string, value string) error {
+	name := key
+	prefix, suffix, found := strings.Cut(key, "/")
+	if found {
+		if len(prefix) > 253 || !dnsNameRE.MatchString(prefix) {
+			return fmt.Errorf("Prefix of route selector key '%s' isn't a valid DNS subdomain", key)
+		}
+		name = suffix
+	}
+	if name == "" || len(name) > 63 || !labelNameRE.MatchString(name) {
+		return fmt.Errorf("Route selector key '%s' isn't valid: it must be 63 characters or "+
+			"less and contain only letters, digits, '-', '_' and '.'", key)
+	}
+	if len(value) > 63 || !labelNameRE.MatchString(value) {
+		return fmt.Errorf("Value '%s' of route selector '%s' isn't valid: it must be 63 "+
+			"characters or less and contain only letters, digits, '-', '_' and '.'", value, key)
+	}
+	return nil
+}
+
+// ParseExcludedNamespaces parses a comma-separated list of namespace names.
+func ParseExcludedNamespaces(value string) ([]string, error) {
+	result := []string{}
+	if strings.TrimSpace(value) == "" {
+		return result, nil
+	}
+	for _, namespace := range strings.Split(value, ",") {
+		namespace = strings.TrimSpace(namespace)
+		err := validateNamespace(namespace)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, namespace)
+	}
+	return result, nil
+}
+
+func validateNamespace(namespace string) error {
+	if !dnsLabelRE.MatchString(namespace) {
+		return fmt.Errorf("Namespace '%s' isn't valid: it must be 63 characters or less and "+
+			"contain only lowercase letters, digits and '-'", namespace)
+	}
+	return nil
+}
+
+// Validate checks the values of an ingress before it is sent to the server. The ingress can
+// contain only the fields that are being changed, in which case current is the ingress that is
+// being changed, so that the fields that must be set together are checked against the result of
+// the change. When creating an ingress current is nil.
+func Validate(cluster *cmv1.Cluster, current *cmv1.Ingress, ingress *cmv1.Ingress) error {
+	if value, ok := ingress.GetLoadBalancerType(); ok &&
+		!utils.Contains(ValidLoadBalancerTypes, string(value)) {
+		return fmt.Errorf("Load balancer type '%s' isn't valid, options are %s", value,
+			strings.Join(ValidLoadBalancerTypes, ", "))
+	}
+	if value, ok := ingress.GetRouteWildcardPolicy(); ok &&
+		!utils.Contains(ValidWildcardPolicies, string(value)) {
+		return fmt.Errorf("Wildcard policy '%s' isn't valid, options are %s", value,
+			strings.Join(ValidWildcardPolicies, ", "))
+	}
+	if value, ok := ingress.GetRouteNamespaceOwnershipPolicy(); ok &&
+		!utils.Contains(ValidNamespaceOwnershipPolicies, string(value)) {
+		return fmt.Errorf("Namespace ownership policy '%s' isn't valid, options are %s", value,
+			strings.Join(ValidNamespaceOwnershipPolicies, ", "))
+	}
+	if value, ok := ingress.GetListening(); ok &&
+		value != cmv1.ListeningMethodInternal && value != cmv1.ListeningMethodExternal {
+		return fmt.Errorf("Listening method '%s' isn't valid, options are %s, %s", value,
+			cmv1.ListeningMethodExternal, cmv1.ListeningMethodInternal)
+	}
+	for key, value := range ingress.RouteSelectors() {
+		err := validateLabel(key, value)
+		if err != nil {
+			return err
+		}
+	}
+	for _, namespace := range ingress.ExcludedNamespaces() {
+		err := validateNamespace(namespace)
+		if err != nil {
+			return err
+		}
+	}
+
+	hostname, hasHostname := ingress.GetClusterRoutesHostname()
+	secret, hasSecret := ingress.GetClusterRoutesTlsSecretRef()
+	if hostname != "" && (len(hostname) > 253 || !dnsNameRE.MatchString(hostname)) {
+		return fmt.Errorf("Cluster routes hostname '%s' isn't a valid DNS name", hostname)
+	}
+	if secret != "" && (len(secret) > 253 || !dnsNameRE.MatchString(secret)) {
+		return fmt.Errorf("Cluster routes TLS secret reference '%s' isn't a valid secret name",
+			secret)
+	}
+	if hasHostname || hasSecret {
+		if !hasHostname {
+			hostname = current.ClusterRoutesHostname()
+		}
+		if !hasSecret {
+			secret = current.ClusterRoutesTlsSecretRef()
+		}
+		if (hostname == "") != (secret == "") {
+			return fmt.Errorf("Cluster routes hostname and TLS secret reference must be set " +
+				"together")
+		}
+	}
+	for component, route := range ingress.ComponentRoutes() {
+		if route.Hostname() != "" && !dnsNameRE.MatchString(route.Hostname()) {
+			return fmt.Errorf("Hostname '%s' of component route '%s' isn't a valid DNS name",
+				route.Hostname(), component)
+		}
+	}
+
+	// Only the listening method can be changed for hosted control plane clusters:
+	if cluster.Hypershift().Enabled() {
+		fields := []struct {
+			name string
+			set  bool
+		}{
+			{"route selectors", ingress.RouteSelectors() != nil},
+			{"excluded namespaces", ingress.ExcludedNamespaces() != nil},
+			{"load balancer type", ingress.LoadBalancerType() != ""},
+			{"wildcard policy", ingress.RouteWildcardPolicy() != ""},
+			{"namespace ownership policy", ingress.RouteNamespaceOwnershipPolicy() != ""},
+			{"cluster routes", hasHostname || hasSecret},
+			{"component routes", ingress.ComponentRoutes() != nil},
+		}
+		for _, field := range fields {
+			if field.set {
+				return fmt.Errorf("Can't set the %s of an ingress for Hosted Control Plane "+
+					"clusters", field.name)
+			}
+		}
+	}
+	if ingress.LoadBalancerType() != "" && cluster.AWS().STS().RoleARN() != "" {
+		return fmt.Errorf("Can't set the load balancer type of an ingress for STS clusters")
+	}
+	return nil
+}
+
+// LoadFile reads the description of an ingress from a JSON or YAML file, using the same field
+// names as the API. It returns a builder that contains only the fields that can be changed.
+func LoadFile(path string) (*cmv1.IngressBuilder, error) {
+	// #nosec G304
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read ingress file '%s': %v", path, err)
+	}
+	var fields map[string]interface{}
+	err = yaml.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse ingress file '%s': %v", path, err)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("Ingress file '%s' is empty", path)
+	}
+	var unknown []string
+	for name := range fields {
+		if !utils.Contains(editableFields, name) && !utils.Contains(ignoredFields, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("Ingress file '%s' contains unknown fields: %s", path,
+			strings.Join(unknown, ", "))
+	}
+
+	// The YAML parser accepts JSON as well, so convert the result to JSON and use the SDK to
+	// parse it:
+	data, err = json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse ingress file '%s': %v", path, err)
+	}
+	ingress, err := cmv1.UnmarshalIngress(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse ingress file '%s': %v", path, err)
+	}
+
+	builder := cmv1.NewIngress()
+	if value, ok := ingress.GetListening(); ok {
+		builder.Listening(value)
+	}
+	if value, ok := ingress.GetRouteSelectors(); ok {
+		builder.RouteSelectors(value)
+	}
+	if value, ok := ingress.GetExcludedNamespaces(); ok {
+		builder.ExcludedNamespaces(value...)
+	}
+	if value, ok := ingress.GetLoadBalancerType(); ok {
+		builder.LoadBalancerType(value)
+	}
+	if value, ok := ingress.GetRouteWildcardPolicy(); ok {
+		builder.RouteWildcardPolicy(value)
+	}
+	if value, ok := ingress.GetRouteNamespaceOwnershipPolicy(); ok {
+		builder.RouteNamespaceOwnershipPolicy(value)
+	}
+	if value, ok := ingress.GetClusterRoutesHostname(); ok {
+		builder.ClusterRoutesHostname(value)
+	}
+	if value, ok := ingress.GetClusterRoutesTlsSecretRef(); ok {
+		builder.ClusterRoutesTlsSecretRef(value)
+	}
+	if value, ok := ingress.GetComponentRoutes(); ok {
+		routes := map[string]*cmv1.ComponentRouteBuilder{}
+		for name, route := range value {
+			routes[name] = cmv1.NewComponentRoute().Copy(route)
+		}
+		builder.ComponentRoutes(routes)
+	}
+	return builder, nil
+}
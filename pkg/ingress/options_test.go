@@ -0,0 +1,195 @@
+package ingress
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Parse route selectors", func() {
+	It("parses key=value selectors", func() {
+		selectors, err := ParseRouteSelectors("foo=bar, example.com/tier=frontend,empty=")
+		Expect(err).To(BeNil())
+		Expect(selectors).To(Equal(map[string]string{
+			"foo":              "bar",
+			"example.com/tier": "frontend",
+			"empty":            "",
+		}))
+	})
+	It("accepts the double equals operator", func() {
+		selectors, err := ParseRouteSelectors("foo==bar")
+		Expect(err).To(BeNil())
+		Expect(selectors).To(Equal(map[string]string{"foo": "bar"}))
+	})
+	It("returns an empty map for an empty string", func() {
+		selectors, err := ParseRouteSelectors("")
+		Expect(err).To(BeNil())
+		Expect(selectors).To(BeEmpty())
+	})
+	DescribeTable("rejects match expressions",
+		func(value string) {
+			_, err := ParseRouteSelectors(value)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("is a match expression"))
+		},
+		Entry("in", "tier in (frontend,backend)"),
+		Entry("notin", "foo=bar,tier notin (backend)"),
+		Entry("not equals", "tier!=backend"),
+		Entry("does not exist", "!tier"),
+	)
+	It("rejects selectors without value", func() {
+		_, err := ParseRouteSelectors("tier")
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("key=value"))
+	})
+	It("rejects invalid keys", func() {
+		_, err := ParseRouteSelectors("-foo=bar")
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("key '-foo' isn't valid"))
+	})
+})
+
+var _ = Describe("Parse excluded namespaces", func() {
+	It("parses namespaces", func() {
+		namespaces, err := ParseExcludedNamespaces("a, b-c")
+		Expect(err).To(BeNil())
+		Expect(namespaces).To(Equal([]string{"a", "b-c"}))
+	})
+	It("rejects invalid namespaces", func() {
+		_, err := ParseExcludedNamespaces("Upper")
+		Expect(err).ToNot(BeNil())
+	})
+})
+
+var _ = Describe("Validate ingress", func() {
+	var cluster, hostedCluster, stsCluster *cmv1.Cluster
+	var withSecret *cmv1.Ingress
+
+	BeforeEach(func() {
+		var err error
+		cluster, err = cmv1.NewCluster().ID("123").Build()
+		Expect(err).To(BeNil())
+		hostedCluster, err = cmv1.NewCluster().ID("123").
+			Hypershift(cmv1.NewHypershift().Enabled(true)).Build()
+		Expect(err).To(BeNil())
+		stsCluster, err = cmv1.NewCluster().ID("123").
+			AWS(cmv1.NewAWS().STS(cmv1.NewSTS().RoleARN("arn:aws:iam::123:role/installer"))).Build()
+		Expect(err).To(BeNil())
+		withSecret, err = cmv1.NewIngress().ID("abcd").ClusterRoutesTlsSecretRef("apps-tls").Build()
+		Expect(err).To(BeNil())
+	})
+
+	DescribeTable("checks the ingress",
+		func(getCluster func() *cmv1.Cluster, builder *cmv1.IngressBuilder, message string) {
+			ingress, err := builder.Build()
+			Expect(err).To(BeNil())
+			err = Validate(getCluster(), nil, ingress)
+			if message == "" {
+				Expect(err).To(BeNil())
+			} else {
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring(message))
+			}
+		},
+		Entry("valid settings", func() *cmv1.Cluster { return cluster },
+			cmv1.NewIngress().
+				LoadBalancerType(cmv1.LoadBalancerFlavorNlb).
+				RouteWildcardPolicy(cmv1.WildcardPolicyWildcardsAllowed).
+				RouteNamespaceOwnershipPolicy(cmv1.NamespaceOwnershipPolicyStrict).
+				ClusterRoutesHostname("apps.example.com").
+				ClusterRoutesTlsSecretRef("apps-tls"),
+			""),
+		Entry("invalid load balancer type", func() *cmv1.Cluster { return cluster },
+			cmv1.NewIngress().LoadBalancerType("alb"), "Load balancer type 'alb' isn't valid"),
+		Entry("invalid wildcard policy", func() *cmv1.Cluster { return cluster },
+			cmv1.NewIngress().RouteWildcardPolicy("Sometimes"), "Wildcard policy"),
+		Entry("invalid namespace ownership policy", func() *cmv1.Cluster { return cluster },
+			cmv1.NewIngress().RouteNamespaceOwnershipPolicy("Loose"), "Namespace ownership policy"),
+		Entry("hostname without secret", func() *cmv1.Cluster { return cluster },
+			cmv1.NewIngress().ClusterRoutesHostname("apps.example.com"), "must be set together"),
+		Entry("invalid hostname", func() *cmv1.Cluster { return cluster },
+			cmv1.NewIngress().ClusterRoutesHostname("Apps_Example").ClusterRoutesTlsSecretRef("tls"),
+			"isn't a valid DNS name"),
+		Entry("listening on hosted cluster", func() *cmv1.Cluster { return hostedCluster },
+			cmv1.NewIngress().Listening(cmv1.ListeningMethodInternal), ""),
+		Entry("route selectors on hosted cluster", func() *cmv1.Cluster { return hostedCluster },
+			cmv1.NewIngress().RouteSelectors(map[string]string{"a": "b"}),
+			"route selectors of an ingress for Hosted Control Plane"),
+		Entry("load balancer type on STS cluster", func() *cmv1.Cluster { return stsCluster },
+			cmv1.NewIngress().LoadBalancerType(cmv1.LoadBalancerFlavorNlb), "STS clusters"),
+	)
+
+	It("accepts the hostname when the current ingress has the secret", func() {
+		ingress, err := cmv1.NewIngress().ClusterRoutesHostname("apps.example.com").Build()
+		Expect(err).To(BeNil())
+		Expect(Validate(cluster, withSecret, ingress)).To(Succeed())
+	})
+
+	It("rejects removing the secret when the current ingress has the hostname", func() {
+		current, err := cmv1.NewIngress().ClusterRoutesHostname("apps.example.com").
+			ClusterRoutesTlsSecretRef("apps-tls").Build()
+		Expect(err).To(BeNil())
+		ingress, err := cmv1.NewIngress().ClusterRoutesTlsSecretRef("").Build()
+		Expect(err).To(BeNil())
+		err = Validate(cluster, current, ingress)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("must be set together"))
+	})
+})
+
+var _ = Describe("Load ingress file", func() {
+	write := func(name string, content string) string {
+		path := filepath.Join(GinkgoT().TempDir(), name)
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		return path
+	}
+
+	It("loads a YAML file", func() {
+		path := write("ingress.yaml", `
+id: abcd
+default: true
+listening: internal
+load_balancer_type: nlb
+route_selectors:
+  tier: frontend
+excluded_namespaces:
+- stage
+route_wildcard_policy: WildcardsAllowed
+cluster_routes_hostname: apps.example.com
+cluster_routes_tls_secret_ref: apps-tls
+`)
+		builder, err := LoadFile(path)
+		Expect(err).To(BeNil())
+		ingress, err := builder.Build()
+		Expect(err).To(BeNil())
+		Expect(ingress.ID()).To(BeEmpty())
+		Expect(ingress.Listening()).To(Equal(cmv1.ListeningMethodInternal))
+		Expect(ingress.LoadBalancerType()).To(Equal(cmv1.LoadBalancerFlavorNlb))
+		Expect(ingress.RouteSelectors()).To(Equal(map[string]string{"tier": "frontend"}))
+		Expect(ingress.ExcludedNamespaces()).To(Equal([]string{"stage"}))
+		Expect(ingress.RouteWildcardPolicy()).To(Equal(cmv1.WildcardPolicyWildcardsAllowed))
+		Expect(ingress.ClusterRoutesHostname()).To(Equal("apps.example.com"))
+		Expect(ingress.ClusterRoutesTlsSecretRef()).To(Equal("apps-tls"))
+		_, ok := ingress.GetRouteNamespaceOwnershipPolicy()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("loads a JSON file", func() {
+		path := write("ingress.json", `{"kind": "Ingress", "route_selectors": {"a": "b"}}`)
+		builder, err := LoadFile(path)
+		Expect(err).To(BeNil())
+		ingress, err := builder.Build()
+		Expect(err).To(BeNil())
+		Expect(ingress.RouteSelectors()).To(Equal(map[string]string{"a": "b"}))
+	})
+
+	It("rejects unknown fields", func() {
+		path := write("ingress.yaml", "route_selector:\n  a: b\nlb_type: nlb\n")
+		_, err := LoadFile(path)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("unknown fields: lb_type, route_selector"))
+	})
+})
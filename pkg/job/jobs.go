@@ -0,0 +1,240 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to inspect job queues and to print jobs.
+
+package job
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	jqv1 "github.com/openshift-online/ocm-sdk-go/jobqueue/v1"
+
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+)
+
+// Result is the outcome of marking a job as successful or failed. The job queue API doesn't
+// return a body for these requests, so this is what is printed instead.
+type Result struct {
+	Kind          string `json:"kind"`
+	Queue         string `json:"queue"`
+	ID            string `json:"id"`
+	ReceiptID     string `json:"receipt_id"`
+	Status        string `json:"status"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+// PopJob fetches the next job of the queue, or returns nil if the queue is empty.
+func PopJob(ctx context.Context, client *jqv1.QueueClient) (*jqv1.Job, error) {
+	response, err := client.Pop().SendContext(ctx)
+	if response != nil && response.Status() == http.StatusNoContent {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buildJob(response)
+}
+
+// PushJob creates a job in the queue with the given arguments.
+func PushJob(ctx context.Context, client *jqv1.QueueClient, arguments string) (*jqv1.Job, error) {
+	request := client.Push()
+	if arguments != "" {
+		request.Arguments(arguments)
+	}
+	response, err := request.SendContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildJob(response)
+}
+
+// jobResponse contains the job details returned by the pop and push requests, which have
+// different response types.
+type jobResponse interface {
+	ID() string
+	HREF() string
+	Arguments() string
+	Attempts() int
+	ReceiptId() string
+	GetAbandonedAt() (time.Time, bool)
+	GetCreatedAt() (time.Time, bool)
+	GetUpdatedAt() (time.Time, bool)
+}
+
+// buildJob converts the response of a pop or push request into a job.
+func buildJob(response jobResponse) (*jqv1.Job, error) {
+	builder := jqv1.NewJob().
+		ID(response.ID()).
+		HREF(response.HREF()).
+		Arguments(response.Arguments()).
+		Attempts(response.Attempts()).
+		ReceiptId(response.ReceiptId())
+	if value, ok := response.GetAbandonedAt(); ok {
+		builder.AbandonedAt(value)
+	}
+	if value, ok := response.GetCreatedAt(); ok {
+		builder.CreatedAt(value)
+	}
+	if value, ok := response.GetUpdatedAt(); ok {
+		builder.UpdatedAt(value)
+	}
+	return builder.Build()
+}
+
+// ListJobs returns the jobs of the queue. The SDK doesn't have a client for this collection, so
+// it is requested directly.
+func ListJobs(connection *sdk.Connection, queue string) ([]*jqv1.Job, error) {
+	path := fmt.Sprintf("/api/job_queue/v1/queues/%s/jobs", url.PathEscape(queue))
+	size := 100
+	page := 1
+	var result []*jqv1.Job
+	for {
+		response, err := connection.Get().
+			Path(path).
+			Parameter("page", strconv.Itoa(page)).
+			Parameter("size", strconv.Itoa(size)).
+			Send()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list jobs of queue '%s': %v", queue, err)
+		}
+		if response.Status() == http.StatusNotFound {
+			return nil, fmt.Errorf("Job queue '%s' doesn't exist or doesn't support listing jobs",
+				queue)
+		}
+		if response.Status() >= http.StatusBadRequest {
+			return nil, fmt.Errorf("Failed to list jobs of queue '%s': status %d: %s",
+				queue, response.Status(), response.String())
+		}
+		items, err := parseJobList(response.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse jobs of queue '%s': %v", queue, err)
+		}
+		result = append(result, items...)
+		if len(items) < size {
+			break
+		}
+		page++
+	}
+	return result, nil
+}
+
+func parseJobList(data []byte) ([]*jqv1.Job, error) {
+	var list struct {
+		Items json.RawMessage `json:"items"`
+	}
+	err := json.Unmarshal(data, &list)
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+	return jqv1.UnmarshalJobList([]byte(list.Items))
+}
+
+// PrintJob writes the job in JSON format.
+func PrintJob(stream io.Writer, job *jqv1.Job) error {
+	body := &bytes.Buffer{}
+	err := jqv1.MarshalJob(job, body)
+	if err != nil {
+		return fmt.Errorf("Failed to marshal job '%s': %v", job.ID(), err)
+	}
+	return dump.Pretty(stream, body.Bytes())
+}
+
+// PrintResult writes the result of marking a job as successful or failed in JSON format.
+func PrintResult(stream io.Writer, result *Result) error {
+	result.Kind = "JobResult"
+	body, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return dump.Pretty(stream, body)
+}
+
+// PrintQueueDescription writes the details of a queue and, if not nil, a summary of its jobs.
+func PrintQueueDescription(stream io.Writer, queue *jqv1.Queue, jobs []*jqv1.Job) error {
+	writer := tabwriter.NewWriter(stream, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "ID:\t%s\n", queue.ID())
+	fmt.Fprintf(writer, "Name:\t%s\n", queue.Name())
+	fmt.Fprintf(writer, "Max Attempts:\t%d\n", queue.MaxAttempts())
+	fmt.Fprintf(writer, "Max Run Time:\t%s\n", time.Duration(queue.MaxRunTime())*time.Second)
+	fmt.Fprintf(writer, "Created:\t%s\n", formatTime(queue.CreatedAt()))
+	fmt.Fprintf(writer, "Updated:\t%s\n", formatTime(queue.UpdatedAt()))
+	if jobs != nil {
+		now := time.Now()
+		inProgress, abandoned, retried := 0, 0, 0
+		for _, job := range jobs {
+			if job.Attempts() > 1 {
+				retried++
+			}
+			if abandonedAt, ok := job.GetAbandonedAt(); ok {
+				if abandonedAt.After(now) {
+					inProgress++
+				} else {
+					abandoned++
+				}
+			}
+		}
+		fmt.Fprintf(writer, "Jobs:\t%d\n", len(jobs))
+		fmt.Fprintf(writer, "Jobs In Progress:\t%d\n", inProgress)
+		fmt.Fprintf(writer, "Jobs Abandoned:\t%d\n", abandoned)
+		fmt.Fprintf(writer, "Jobs Retried:\t%d\n", retried)
+	}
+	return writer.Flush()
+}
+
+func formatTime(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.Format(time.RFC3339)
+}
+
+// Row contains the details of a job formatted for tables.
+type Row struct {
+	ID          string
+	Attempts    int
+	CreatedAt   string
+	UpdatedAt   string
+	AbandonedAt string
+	ReceiptID   string
+	Arguments   string
+}
+
+// NewRow formats the details of the job for tables.
+func NewRow(job *jqv1.Job) *Row {
+	return &Row{
+		ID:          job.ID(),
+		Attempts:    job.Attempts(),
+		CreatedAt:   formatTime(job.CreatedAt()),
+		UpdatedAt:   formatTime(job.UpdatedAt()),
+		AbandonedAt: formatTime(job.AbandonedAt()),
+		ReceiptID:   job.ReceiptId(),
+		Arguments:   job.Arguments(),
+	}
+}
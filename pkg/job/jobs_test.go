@@ -0,0 +1,44 @@
+package job
+
+import (
+	"bytes"
+	"time"
+
+	jqv1 "github.com/openshift-online/ocm-sdk-go/jobqueue/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Jobs", func() {
+	It("parses job lists", func() {
+		jobs, err := parseJobList([]byte(`{
+			"kind": "JobList",
+			"items": [
+				{"kind": "Job", "id": "a", "attempts": 3, "receipt_id": "r",
+				 "abandoned_at": "2024-01-01T10:00:00Z"},
+				{"kind": "Job", "id": "b"}
+			]
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobs).To(HaveLen(2))
+		row := NewRow(jobs[0])
+		Expect(row.Attempts).To(Equal(3))
+		Expect(row.ReceiptID).To(Equal("r"))
+		Expect(row.AbandonedAt).To(Equal("2024-01-01T10:00:00Z"))
+		Expect(NewRow(jobs[1]).AbandonedAt).To(BeEmpty())
+	})
+
+	It("summarizes the jobs of a queue", func() {
+		queue, err := jqv1.NewQueue().ID("q").Name("my-queue").MaxAttempts(3).MaxRunTime(600).Build()
+		Expect(err).ToNot(HaveOccurred())
+		running, _ := jqv1.NewJob().ID("a").Attempts(1).AbandonedAt(time.Now().Add(time.Hour)).Build()
+		stuck, _ := jqv1.NewJob().ID("b").Attempts(2).AbandonedAt(time.Now().Add(-time.Hour)).Build()
+		buffer := &bytes.Buffer{}
+		Expect(PrintQueueDescription(buffer, queue, []*jqv1.Job{running, stuck})).To(Succeed())
+		Expect(buffer.String()).To(ContainSubstring("Max Run Time:      10m0s"))
+		Expect(buffer.String()).To(MatchRegexp(`Jobs In Progress:\s+1`))
+		Expect(buffer.String()).To(MatchRegexp(`Jobs Abandoned:\s+1`))
+		Expect(buffer.String()).To(MatchRegexp(`Jobs Retried:\s+1`))
+	})
+})
@@ -0,0 +1,120 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to access job queues.
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	jqv1 "github.com/openshift-online/ocm-sdk-go/jobqueue/v1"
+)
+
+// Job contains the details of a job popped from a queue that are needed to process it.
+type Job struct {
+	ID          string
+	ReceiptID   string
+	Arguments   string
+	Attempts    int
+	AbandonedAt time.Time
+}
+
+// Queue is the interface of the job queue used by workers.
+type Queue interface {
+	// Name returns the name of the queue.
+	Name() string
+
+	// Pop fetches the next job, or returns nil if the queue is empty.
+	Pop(ctx context.Context) (*Job, error)
+
+	// Success marks the job as successfully processed.
+	Success(ctx context.Context, job *Job) error
+
+	// Failure marks the job as failed, with the given reason.
+	Failure(ctx context.Context, job *Job, reason string) error
+}
+
+// GetQueue returns the job queue with the given name.
+func GetQueue(connection *sdk.Connection, name string) (*jqv1.Queue, error) {
+	response, err := connection.JobQueue().V1().Queues().Queue(name).Get().Send()
+	if response != nil && response.Status() == http.StatusNotFound {
+		return nil, fmt.Errorf("Job queue '%s' doesn't exist", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get job queue '%s': %v", name, err)
+	}
+	return response.Body(), nil
+}
+
+// NewQueue returns a queue that uses the job queue API of the given connection.
+func NewQueue(connection *sdk.Connection, name string) Queue {
+	return &apiQueue{
+		client: connection.JobQueue().V1().Queues().Queue(name),
+		name:   name,
+	}
+}
+
+type apiQueue struct {
+	client *jqv1.QueueClient
+	name   string
+}
+
+func (q *apiQueue) Name() string {
+	return q.name
+}
+
+func (q *apiQueue) Pop(ctx context.Context) (*Job, error) {
+	job, err := PopJob(ctx, q.client)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch a job from queue '%s': %v", q.name, err)
+	}
+	if job == nil {
+		return nil, nil
+	}
+	return &Job{
+		ID:          job.ID(),
+		ReceiptID:   job.ReceiptId(),
+		Arguments:   job.Arguments(),
+		Attempts:    job.Attempts(),
+		AbandonedAt: job.AbandonedAt(),
+	}, nil
+}
+
+func (q *apiQueue) Success(ctx context.Context, job *Job) error {
+	_, err := q.client.Jobs().Job(job.ID).Success().
+		ReceiptId(job.ReceiptID).
+		SendContext(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to mark job '%s' as successful: %v", job.ID, err)
+	}
+	return nil
+}
+
+func (q *apiQueue) Failure(ctx context.Context, job *Job, reason string) error {
+	_, err := q.client.Jobs().Job(job.ID).Failure().
+		ReceiptId(job.ReceiptID).
+		FailureReason(reason).
+		SendContext(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to mark job '%s' as failed: %v", job.ID, err)
+	}
+	return nil
+}
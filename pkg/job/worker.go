@@ -0,0 +1,344 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the worker that pops jobs from a queue and processes them running a command.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Environment variables passed to the command that processes a job:
+const (
+	EnvQueue     = "OCM_JOB_QUEUE"
+	EnvID        = "OCM_JOB_ID"
+	EnvReceiptID = "OCM_JOB_RECEIPT_ID"
+	EnvArguments = "OCM_JOB_ARGUMENTS"
+	EnvAttempts  = "OCM_JOB_ATTEMPTS"
+)
+
+// Actions taken when a job times out:
+const (
+	// TimeoutFail stops the command and marks the job as failed.
+	TimeoutFail = "fail"
+
+	// TimeoutAbandon stops the command without reporting the result, so the job is delivered
+	// again once the queue considers it abandoned.
+	TimeoutAbandon = "abandon"
+)
+
+// Stats contains the number of jobs processed by a worker, by result.
+type Stats struct {
+	Succeeded int
+	Failed    int
+	Abandoned int
+}
+
+// Worker pops jobs from a queue and runs a command for each of them. Don't create instances of
+// this type directly; use the NewWorker function instead.
+type Worker struct {
+	queue         Queue
+	command       []string
+	concurrency   int
+	timeout       time.Duration
+	extension     time.Duration
+	maxExtensions int
+	onTimeout     string
+	gracePeriod   time.Duration
+	pollInterval  time.Duration
+	exitWhenEmpty bool
+	stdout        io.Writer
+	stderr        io.Writer
+	out           io.Writer
+
+	lock  sync.Mutex
+	stats Stats
+}
+
+// NewWorker creates a worker that processes the jobs of the queue running the given command.
+func NewWorker(queue Queue, command []string) *Worker {
+	return &Worker{
+		queue:        queue,
+		command:      command,
+		concurrency:  1,
+		onTimeout:    TimeoutFail,
+		gracePeriod:  10 * time.Second,
+		pollInterval: 5 * time.Second,
+		stdout:       os.Stdout,
+		stderr:       os.Stderr,
+		out:          io.Discard,
+	}
+}
+
+// Concurrency sets the number of jobs processed in parallel.
+func (w *Worker) Concurrency(value int) *Worker {
+	w.concurrency = value
+	return w
+}
+
+// Timeout sets the maximum time the command can run for a job. Zero means no timeout.
+func (w *Worker) Timeout(value time.Duration) *Worker {
+	w.timeout = value
+	return w
+}
+
+// Extensions sets how many times, and by how much, the timeout of a job is extended before the
+// timeout action is taken.
+func (w *Worker) Extensions(count int, extension time.Duration) *Worker {
+	w.maxExtensions = count
+	w.extension = extension
+	return w
+}
+
+// OnTimeout sets the action taken when a job times out, either TimeoutFail or TimeoutAbandon.
+func (w *Worker) OnTimeout(value string) *Worker {
+	w.onTimeout = value
+	return w
+}
+
+// GracePeriod sets the time given to the command to finish after it is asked to stop, before it
+// is killed.
+func (w *Worker) GracePeriod(value time.Duration) *Worker {
+	w.gracePeriod = value
+	return w
+}
+
+// PollInterval sets the time to wait before trying again when the queue is empty.
+func (w *Worker) PollInterval(value time.Duration) *Worker {
+	w.pollInterval = value
+	return w
+}
+
+// ExitWhenEmpty makes the worker stop when the queue is empty instead of waiting for new jobs.
+func (w *Worker) ExitWhenEmpty(value bool) *Worker {
+	w.exitWhenEmpty = value
+	return w
+}
+
+// CommandOutput sets the writers where the output of the command is written.
+func (w *Worker) CommandOutput(stdout, stderr io.Writer) *Worker {
+	w.stdout = stdout
+	w.stderr = stderr
+	return w
+}
+
+// Output sets the writer where progress messages are written.
+func (w *Worker) Output(value io.Writer) *Worker {
+	w.out = value
+	return w
+}
+
+// Run processes jobs till the stop context is cancelled, after which the jobs in progress are
+// allowed to finish. When the abort context is cancelled the commands in progress are stopped and
+// their jobs are abandoned.
+func (w *Worker) Run(stop context.Context, abort context.Context) Stats {
+	if w.onTimeout != TimeoutFail && w.onTimeout != TimeoutAbandon {
+		w.onTimeout = TimeoutFail
+	}
+	concurrency := w.concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var group sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			w.loop(stop, abort)
+		}()
+	}
+	group.Wait()
+	return w.stats
+}
+
+func (w *Worker) loop(stop context.Context, abort context.Context) {
+	for stop.Err() == nil {
+		job, err := w.queue.Pop(stop)
+		if stop.Err() != nil {
+			return
+		}
+		if err != nil {
+			w.log("Warning: %v", err)
+			w.sleep(stop)
+			continue
+		}
+		if job == nil {
+			if w.exitWhenEmpty {
+				return
+			}
+			w.sleep(stop)
+			continue
+		}
+		w.process(abort, job)
+	}
+}
+
+func (w *Worker) sleep(ctx context.Context) {
+	timer := time.NewTimer(w.pollInterval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// process runs the command for the job and reports the result.
+func (w *Worker) process(abort context.Context, job *Job) {
+	w.log("Processing job '%s' (attempt %d)", job.ID, job.Attempts)
+
+	// #nosec G204
+	cmd := exec.Command(w.command[0], w.command[1:]...)
+	cmd.Env = append(os.Environ(),
+		EnvQueue+"="+w.queue.Name(),
+		EnvID+"="+job.ID,
+		EnvReceiptID+"="+job.ReceiptID,
+		EnvArguments+"="+job.Arguments,
+		EnvAttempts+"="+strconv.Itoa(job.Attempts),
+	)
+	cmd.Stdout = w.stdout
+	cmd.Stderr = w.stderr
+
+	// Don't wait for processes started by the command that keep the output open once the command
+	// has been stopped:
+	cmd.WaitDelay = w.gracePeriod
+	err := cmd.Start()
+	if err != nil {
+		w.fail(job, fmt.Sprintf("can't start command: %v", err))
+		return
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var timeout <-chan time.Time
+	if w.timeout > 0 {
+		timer := time.NewTimer(w.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	elapsed := w.timeout
+	extensions := 0
+	for {
+		select {
+		case err = <-done:
+			w.finish(job, err)
+			return
+		case <-timeout:
+			if extensions < w.maxExtensions && w.extension > 0 {
+				extensions++
+				elapsed += w.extension
+				w.log("Job '%s' is still running, extending its timeout to %s (%d of %d)",
+					job.ID, elapsed, extensions, w.maxExtensions)
+				timer := time.NewTimer(w.extension)
+				defer timer.Stop()
+				timeout = timer.C
+				continue
+			}
+			w.terminate(cmd, done)
+			if w.onTimeout == TimeoutAbandon {
+				w.abandon(job, fmt.Sprintf("timed out after %s", elapsed))
+			} else {
+				w.fail(job, fmt.Sprintf("timed out after %s", elapsed))
+			}
+			return
+		case <-abort.Done():
+			w.terminate(cmd, done)
+			w.abandon(job, "worker was stopped")
+			return
+		}
+	}
+}
+
+// terminate asks the command to stop and kills it if it doesn't finish within the grace period.
+func (w *Worker) terminate(cmd *exec.Cmd, done chan error) {
+	err := cmd.Process.Signal(syscall.SIGTERM)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		<-done
+		return
+	}
+	timer := time.NewTimer(w.gracePeriod)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		_ = cmd.Process.Kill()
+		<-done
+	}
+}
+
+func (w *Worker) finish(job *Job, err error) {
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			w.fail(job, fmt.Sprintf("command exited with code %d", exitErr.ExitCode()))
+		} else {
+			w.fail(job, err.Error())
+		}
+		return
+	}
+	// Results are reported even if the worker is being stopped:
+	err = w.queue.Success(context.Background(), job)
+	if err != nil {
+		w.log("Warning: %v", err)
+		return
+	}
+	w.count(func(stats *Stats) { stats.Succeeded++ })
+	w.log("Job '%s' succeeded", job.ID)
+}
+
+func (w *Worker) fail(job *Job, reason string) {
+	err := w.queue.Failure(context.Background(), job, reason)
+	if err != nil {
+		w.log("Warning: %v", err)
+		return
+	}
+	w.count(func(stats *Stats) { stats.Failed++ })
+	w.log("Job '%s' failed: %s", job.ID, reason)
+}
+
+func (w *Worker) abandon(job *Job, reason string) {
+	w.count(func(stats *Stats) { stats.Abandoned++ })
+	if job.AbandonedAt.IsZero() {
+		w.log("Job '%s' abandoned: %s", job.ID, reason)
+		return
+	}
+	w.log("Job '%s' abandoned: %s, it will be delivered again after %s", job.ID, reason,
+		job.AbandonedAt.Format(time.RFC3339))
+}
+
+func (w *Worker) count(update func(stats *Stats)) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	update(&w.stats)
+}
+
+func (w *Worker) log(format string, values ...interface{}) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	fmt.Fprintf(w.out, format+"\n", values...)
+}
@@ -0,0 +1,128 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeQueue is a queue that keeps the jobs in memory.
+type fakeQueue struct {
+	lock      sync.Mutex
+	jobs      []*Job
+	succeeded []string
+	failed    map[string]string
+}
+
+func newFakeQueue(arguments ...string) *fakeQueue {
+	queue := &fakeQueue{failed: map[string]string{}}
+	for i, value := range arguments {
+		queue.jobs = append(queue.jobs, &Job{
+			ID:        fmt.Sprintf("job-%d", i),
+			ReceiptID: fmt.Sprintf("receipt-%d", i),
+			Arguments: value,
+			Attempts:  1,
+		})
+	}
+	return queue
+}
+
+func (q *fakeQueue) Name() string {
+	return "fake"
+}
+
+func (q *fakeQueue) Pop(ctx context.Context) (*Job, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if len(q.jobs) == 0 {
+		return nil, nil
+	}
+	job := q.jobs[0]
+	q.jobs = q.jobs[1:]
+	return job, nil
+}
+
+func (q *fakeQueue) Success(ctx context.Context, job *Job) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.succeeded = append(q.succeeded, job.ID)
+	return nil
+}
+
+func (q *fakeQueue) Failure(ctx context.Context, job *Job, reason string) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.failed[job.ID] = reason
+	return nil
+}
+
+var _ = Describe("Worker", func() {
+	BeforeEach(func() {
+		if runtime.GOOS == "windows" {
+			Skip("worker commands need a POSIX shell")
+		}
+	})
+
+	// The command exits with the code given in the arguments of the job, or sleeps if the
+	// argument is 'sleep':
+	command := []string{"sh", "-c", `if [ "$OCM_JOB_ARGUMENTS" = sleep ]; then exec sleep 10; fi; ` +
+		`test "$OCM_JOB_RECEIPT_ID" = "receipt-${OCM_JOB_ID#job-}" || exit 100; ` +
+		`exit "$OCM_JOB_ARGUMENTS"`}
+
+	run := func(worker *Worker) Stats {
+		return worker.
+			PollInterval(10*time.Millisecond).
+			GracePeriod(time.Second).
+			ExitWhenEmpty(true).
+			CommandOutput(io.Discard, io.Discard).
+			Run(context.Background(), context.Background())
+	}
+
+	It("reports success and failure based on the exit code", func() {
+		queue := newFakeQueue("0", "3", "0")
+		stats := run(NewWorker(queue, command).Concurrency(2))
+		Expect(stats).To(Equal(Stats{Succeeded: 2, Failed: 1}))
+		Expect(queue.succeeded).To(ConsistOf("job-0", "job-2"))
+		Expect(queue.failed).To(HaveKeyWithValue("job-1", "command exited with code 3"))
+	})
+
+	It("fails jobs that time out", func() {
+		queue := newFakeQueue("sleep")
+		stats := run(NewWorker(queue, command).
+			Timeout(50*time.Millisecond).
+			Extensions(1, 50*time.Millisecond))
+		Expect(stats).To(Equal(Stats{Failed: 1}))
+		Expect(queue.failed).To(HaveKeyWithValue("job-0", "timed out after 100ms"))
+	})
+
+	It("abandons jobs that time out", func() {
+		queue := newFakeQueue("sleep")
+		stats := run(NewWorker(queue, command).
+			Timeout(50 * time.Millisecond).
+			OnTimeout(TimeoutAbandon))
+		Expect(stats).To(Equal(Stats{Abandoned: 1}))
+		Expect(queue.failed).To(BeEmpty())
+	})
+
+	It("abandons jobs in progress when aborted", func() {
+		queue := newFakeQueue("sleep", "0")
+		stop, stopCancel := context.WithCancel(context.Background())
+		abort, abortCancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, func() {
+			stopCancel()
+			abortCancel()
+		})
+		stats := NewWorker(queue, command).
+			GracePeriod(time.Second).
+			CommandOutput(io.Discard, io.Discard).
+			Run(stop, abort)
+		Expect(stats).To(Equal(Stats{Abandoned: 1}))
+		Expect(queue.jobs).To(HaveLen(1))
+	})
+})
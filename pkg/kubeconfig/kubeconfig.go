@@ -0,0 +1,385 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubeconfig contains the functions used to read and write the kubeconfig files used by
+// 'oc' and 'kubectl', and to manage the contexts created by 'ocm cluster login'.
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v3"
+)
+
+// ExtensionName is the name of the context extension that marks the contexts created by ocm.
+const ExtensionName = "ocm.openshift.com"
+
+// Config is a kubeconfig file. Fields that aren't used by ocm are preserved.
+type Config struct {
+	Clusters       []NamedCluster         `yaml:"clusters"`
+	Contexts       []NamedContext         `yaml:"contexts"`
+	Users          []NamedUser            `yaml:"users"`
+	CurrentContext string                 `yaml:"current-context"`
+	Extra          map[string]interface{} `yaml:",inline"`
+}
+
+// NamedCluster is a cluster entry of a kubeconfig file.
+type NamedCluster struct {
+	Name    string  `yaml:"name"`
+	Cluster Cluster `yaml:"cluster"`
+}
+
+// Cluster contains the details needed to connect to the API server of a cluster.
+type Cluster struct {
+	Server                   string                 `yaml:"server"`
+	CertificateAuthorityData string                 `yaml:"certificate-authority-data,omitempty"`
+	InsecureSkipTLSVerify    bool                   `yaml:"insecure-skip-tls-verify,omitempty"`
+	ProxyURL                 string                 `yaml:"proxy-url,omitempty"`
+	Extra                    map[string]interface{} `yaml:",inline"`
+}
+
+// NamedUser is a user entry of a kubeconfig file.
+type NamedUser struct {
+	Name string `yaml:"name"`
+	User User   `yaml:"user"`
+}
+
+// User contains the credentials of a user.
+type User struct {
+	Token string                 `yaml:"token,omitempty"`
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// NamedContext is a context entry of a kubeconfig file.
+type NamedContext struct {
+	Name    string  `yaml:"name"`
+	Context Context `yaml:"context"`
+}
+
+// Context links a cluster and a user.
+type Context struct {
+	Cluster    string                 `yaml:"cluster"`
+	User       string                 `yaml:"user"`
+	Namespace  string                 `yaml:"namespace,omitempty"`
+	Extensions []NamedExtension       `yaml:"extensions,omitempty"`
+	Extra      map[string]interface{} `yaml:",inline"`
+}
+
+// NamedExtension is an extension of a context. The content is kept as a node because it depends
+// on the tool that created the extension.
+type NamedExtension struct {
+	Name      string    `yaml:"name"`
+	Extension yaml.Node `yaml:"extension"`
+}
+
+// Extension contains the details that ocm stores in the contexts that it creates.
+type Extension struct {
+	ClusterID   string `yaml:"cluster_id"`
+	ClusterName string `yaml:"cluster_name"`
+	Username    string `yaml:"username,omitempty"`
+}
+
+// ManagedContext contains the details of a context created by ocm.
+type ManagedContext struct {
+	Name      string
+	Current   bool
+	Server    string
+	User      string
+	Extension Extension
+}
+
+// DefaultPath returns the kubeconfig file used by default: the first file of the KUBECONFIG
+// environment variable, or '~/.kube/config'.
+func DefaultPath() (string, error) {
+	if value := os.Getenv("KUBECONFIG"); value != "" {
+		for _, file := range filepath.SplitList(value) {
+			if file != "" {
+				return file, nil
+			}
+		}
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+// Load reads the kubeconfig file. An empty configuration is returned if the file doesn't exist.
+func Load(path string) (*Config, error) {
+	// #nosec G304
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't read kubeconfig '%s': %v", path, err)
+	}
+	config := &Config{}
+	err = yaml.Unmarshal(data, config)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse kubeconfig '%s': %v", path, err)
+	}
+	return config, nil
+}
+
+// Save writes the kubeconfig file, creating the directory if needed.
+func Save(path string, config *Config) error {
+	if config.Extra == nil {
+		config.Extra = map[string]interface{}{}
+	}
+	if _, ok := config.Extra["apiVersion"]; !ok {
+		config.Extra["apiVersion"] = "v1"
+	}
+	if _, ok := config.Extra["kind"]; !ok {
+		config.Extra["kind"] = "Config"
+	}
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return fmt.Errorf("can't create directory for kubeconfig '%s': %v", path, err)
+	}
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return fmt.Errorf("can't write kubeconfig '%s': %v", path, err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// SetContext adds or replaces the context with the given name, together with its cluster and
+// user entries, and makes it the current context. Existing contexts that weren't created by ocm
+// aren't replaced, and neither are cluster and user entries used by other contexts.
+func (c *Config) SetContext(name string, cluster Cluster, user NamedUser, extension Extension) error {
+	if existing := c.context(name); existing != nil && existing.extension() == nil {
+		return fmt.Errorf("context '%s' already exists and wasn't created by ocm", name)
+	}
+	if c.cluster(name) != nil && !c.owns(func(context *Context) bool {
+		return context.Cluster == name
+	}) {
+		return fmt.Errorf("cluster '%s' already exists and wasn't created by ocm", name)
+	}
+	if c.user(user.Name) != nil && !c.owns(func(context *Context) bool {
+		return context.User == user.Name
+	}) {
+		return fmt.Errorf("user '%s' already exists and wasn't created by ocm", user.Name)
+	}
+
+	// Preserve settings of the existing cluster entry that ocm doesn't manage:
+	if existing := c.cluster(name); existing != nil {
+		cluster.ProxyURL = existing.Cluster.ProxyURL
+		cluster.Extra = existing.Cluster.Extra
+		existing.Cluster = cluster
+	} else {
+		c.Clusters = append(c.Clusters, NamedCluster{Name: name, Cluster: cluster})
+	}
+	if existing := c.user(user.Name); existing != nil {
+		existing.User = user.User
+	} else {
+		c.Users = append(c.Users, user)
+	}
+	node := yaml.Node{}
+	err := node.Encode(extension)
+	if err != nil {
+		return err
+	}
+	context := Context{
+		Cluster: name,
+		User:    user.Name,
+		Extensions: []NamedExtension{{
+			Name:      ExtensionName,
+			Extension: node,
+		}},
+	}
+	if existing := c.context(name); existing != nil {
+		context.Namespace = existing.Context.Namespace
+		context.Extra = existing.Context.Extra
+		for _, item := range existing.Context.Extensions {
+			if item.Name != ExtensionName {
+				context.Extensions = append(context.Extensions, item)
+			}
+		}
+		existing.Context = context
+	} else {
+		c.Contexts = append(c.Contexts, NamedContext{Name: name, Context: context})
+	}
+	c.CurrentContext = name
+	return nil
+}
+
+// Entries returns the contexts created by ocm, sorted by name.
+func (c *Config) Entries() []ManagedContext {
+	var entries []ManagedContext
+	for i := range c.Contexts {
+		context := &c.Contexts[i]
+		extension := context.extension()
+		if extension == nil {
+			continue
+		}
+		entry := ManagedContext{
+			Name:      context.Name,
+			Current:   context.Name == c.CurrentContext,
+			User:      context.Context.User,
+			Extension: *extension,
+		}
+		if cluster := c.cluster(context.Context.Cluster); cluster != nil {
+			entry.Server = cluster.Cluster.Server
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// Find returns the context created by ocm whose name, cluster name or cluster identifier matches
+// the given key.
+func (c *Config) Find(key string) (*ManagedContext, error) {
+	var matches []ManagedContext
+	for _, entry := range c.Entries() {
+		if entry.Name == key {
+			return &entry, nil
+		}
+		if entry.Extension.ClusterID == key || entry.Extension.ClusterName == key {
+			matches = append(matches, entry)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("there is no context created by ocm for '%s'", key)
+	case 1:
+		return &matches[0], nil
+	default:
+		return nil, fmt.Errorf("there are %d contexts for '%s', use the context name",
+			len(matches), key)
+	}
+}
+
+// Use makes the given context the current one.
+func (c *Config) Use(name string) {
+	c.CurrentContext = name
+}
+
+// Remove deletes the context, and its cluster and user entries if no other context uses them.
+func (c *Config) Remove(name string) {
+	var removed *Context
+	contexts := c.Contexts[:0]
+	for _, context := range c.Contexts {
+		if context.Name == name {
+			value := context.Context
+			removed = &value
+			continue
+		}
+		contexts = append(contexts, context)
+	}
+	c.Contexts = contexts
+	if removed == nil {
+		return
+	}
+	if c.CurrentContext == name {
+		c.CurrentContext = ""
+	}
+	clusterUsed, userUsed := false, false
+	for _, context := range c.Contexts {
+		clusterUsed = clusterUsed || context.Context.Cluster == removed.Cluster
+		userUsed = userUsed || context.Context.User == removed.User
+	}
+	if !clusterUsed {
+		clusters := c.Clusters[:0]
+		for _, cluster := range c.Clusters {
+			if cluster.Name != removed.Cluster {
+				clusters = append(clusters, cluster)
+			}
+		}
+		c.Clusters = clusters
+	}
+	if !userUsed {
+		users := c.Users[:0]
+		for _, user := range c.Users {
+			if user.Name != removed.User {
+				users = append(users, user)
+			}
+		}
+		c.Users = users
+	}
+}
+
+func (c *Config) context(name string) *NamedContext {
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			return &c.Contexts[i]
+		}
+	}
+	return nil
+}
+
+func (c *Config) cluster(name string) *NamedCluster {
+	for i := range c.Clusters {
+		if c.Clusters[i].Name == name {
+			return &c.Clusters[i]
+		}
+	}
+	return nil
+}
+
+func (c *Config) user(name string) *NamedUser {
+	for i := range c.Users {
+		if c.Users[i].Name == name {
+			return &c.Users[i]
+		}
+	}
+	return nil
+}
+
+// owns checks if the cluster or user entry selected by the given function is used only by contexts
+// created by ocm, so that it can be replaced.
+func (c *Config) owns(uses func(*Context) bool) bool {
+	owned := false
+	for i := range c.Contexts {
+		context := &c.Contexts[i]
+		if !uses(&context.Context) {
+			continue
+		}
+		if context.extension() == nil {
+			return false
+		}
+		owned = true
+	}
+	return owned
+}
+
+func (c *NamedContext) extension() *Extension {
+	for i := range c.Context.Extensions {
+		if c.Context.Extensions[i].Name != ExtensionName {
+			continue
+		}
+		extension := &Extension{}
+		err := c.Context.Extensions[i].Extension.Decode(extension)
+		if err != nil {
+			return nil
+		}
+		return extension
+	}
+	return nil
+}
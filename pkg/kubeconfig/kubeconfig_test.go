@@ -0,0 +1,203 @@
+package kubeconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setTestContext(t *testing.T, config *Config, name string, id string) {
+	err := config.SetContext(name, Cluster{
+		Server: "https://api." + name + ".example.com:6443",
+	}, NamedUser{
+		Name: "admin/" + name,
+		User: User{Token: "sha256~" + name},
+	}, Extension{
+		ClusterID:   id,
+		ClusterName: name,
+		Username:    "admin",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	config, err := Load(filepath.Join(t.TempDir(), "config"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(config.Contexts) != 0 {
+		t.Errorf("expected no contexts, got %d", len(config.Contexts))
+	}
+}
+
+func TestSavePreservesOtherFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	err := os.WriteFile(path, []byte(`apiVersion: v1
+kind: Config
+preferences: {}
+clusters:
+- name: other
+  cluster:
+    server: https://other.example.com
+    tls-server-name: other
+contexts:
+- name: other
+  context:
+    cluster: other
+    user: other
+    extensions:
+    - name: another.tool
+      extension:
+        value: 1
+users:
+- name: other
+  user:
+    client-certificate-data: abc
+current-context: other
+`), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	setTestContext(t, config, "mycluster", "123")
+	err = Save(path, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		"preferences: {}",
+		"tls-server-name: other",
+		"client-certificate-data: abc",
+		"another.tool",
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected saved file to contain '%s'", expected)
+		}
+	}
+
+	config, err = Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.CurrentContext != "mycluster" {
+		t.Errorf("expected current context 'mycluster', got '%s'", config.CurrentContext)
+	}
+	entries := config.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 context created by ocm, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Name != "mycluster" || !entry.Current || entry.Extension.ClusterID != "123" ||
+		entry.Server != "https://api.mycluster.example.com:6443" {
+		t.Errorf("unexpected context %+v", entry)
+	}
+}
+
+func TestSetContextRefusesForeignContext(t *testing.T) {
+	config := &Config{
+		Contexts: []NamedContext{{
+			Name:    "mycluster",
+			Context: Context{Cluster: "mycluster", User: "someone"},
+		}},
+	}
+	err := config.SetContext("mycluster", Cluster{}, NamedUser{Name: "admin"}, Extension{})
+	if err == nil || !strings.Contains(err.Error(), "wasn't created by ocm") {
+		t.Errorf("expected error for context created by another tool, got %v", err)
+	}
+}
+
+func TestSetContextRefusesForeignEntries(t *testing.T) {
+	config := &Config{
+		Clusters: []NamedCluster{{
+			Name:    "mycluster",
+			Cluster: Cluster{Server: "https://other.example.com:6443"},
+		}},
+	}
+	err := config.SetContext("mycluster", Cluster{}, NamedUser{Name: "admin"}, Extension{})
+	if err == nil || !strings.Contains(err.Error(), "cluster 'mycluster' already exists") {
+		t.Errorf("expected error for cluster created by another tool, got %v", err)
+	}
+	if config.Clusters[0].Cluster.Server != "https://other.example.com:6443" {
+		t.Errorf("expected cluster to be preserved")
+	}
+
+	config = &Config{}
+	setTestContext(t, config, "first", "123")
+	config.Contexts = append(config.Contexts, NamedContext{
+		Name:    "other",
+		Context: Context{Cluster: "other", User: "admin/second"},
+	})
+	config.Users = append(config.Users, NamedUser{Name: "admin/second"})
+	err = config.SetContext("second", Cluster{}, NamedUser{Name: "admin/second"}, Extension{})
+	if err == nil || !strings.Contains(err.Error(), "user 'admin/second' already exists") {
+		t.Errorf("expected error for user used by another context, got %v", err)
+	}
+
+	// Entries used only by contexts created by ocm can be replaced:
+	setTestContext(t, config, "first", "123")
+}
+
+func TestSetContextKeepsNamespaceAndProxy(t *testing.T) {
+	config := &Config{}
+	setTestContext(t, config, "mycluster", "123")
+	config.Contexts[0].Context.Namespace = "myproject"
+	config.Clusters[0].Cluster.ProxyURL = "socks5://127.0.0.1:1080"
+	setTestContext(t, config, "mycluster", "123")
+	if len(config.Contexts) != 1 || len(config.Clusters) != 1 || len(config.Users) != 1 {
+		t.Fatalf("expected entries to be replaced, got %d contexts, %d clusters and %d users",
+			len(config.Contexts), len(config.Clusters), len(config.Users))
+	}
+	if config.Contexts[0].Context.Namespace != "myproject" {
+		t.Errorf("expected namespace to be preserved")
+	}
+	if config.Clusters[0].Cluster.ProxyURL != "socks5://127.0.0.1:1080" {
+		t.Errorf("expected proxy URL to be preserved")
+	}
+}
+
+func TestFind(t *testing.T) {
+	config := &Config{}
+	setTestContext(t, config, "first", "123")
+	setTestContext(t, config, "second", "456")
+
+	entry, err := config.Find("second")
+	if err != nil || entry.Name != "second" {
+		t.Errorf("expected to find context by name, got %v, %v", entry, err)
+	}
+	entry, err = config.Find("123")
+	if err != nil || entry.Name != "first" {
+		t.Errorf("expected to find context by cluster identifier, got %v, %v", entry, err)
+	}
+	_, err = config.Find("789")
+	if err == nil {
+		t.Errorf("expected error for unknown cluster")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	config := &Config{}
+	setTestContext(t, config, "first", "123")
+	setTestContext(t, config, "second", "456")
+	config.Remove("second")
+	if config.CurrentContext != "" {
+		t.Errorf("expected current context to be cleared, got '%s'", config.CurrentContext)
+	}
+	if len(config.Contexts) != 1 || len(config.Clusters) != 1 || len(config.Users) != 1 {
+		t.Fatalf("expected entries to be removed, got %d contexts, %d clusters and %d users",
+			len(config.Contexts), len(config.Clusters), len(config.Users))
+	}
+	if config.Contexts[0].Name != "first" {
+		t.Errorf("expected context 'first' to remain, got '%s'", config.Contexts[0].Name)
+	}
+}
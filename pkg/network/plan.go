@@ -0,0 +1,462 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the planner for the networks of a cluster, that checks that the machine,
+// service and pod CIDRs don't overlap each other or other networks, and suggests ranges that
+// don't.
+
+package network
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"net"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Default values of the networks of a cluster, the same that the 'osd-4' flavour uses:
+const (
+	DefaultMachineCIDR = "10.0.0.0/16"
+	DefaultServiceCIDR = "172.30.0.0/16"
+	DefaultPodCIDR     = "10.128.0.0/14"
+	DefaultHostPrefix  = 23
+)
+
+// Limits of the host prefix accepted by the API:
+const (
+	MinHostPrefix = 23
+	MaxHostPrefix = 26
+)
+
+// Names of the ranges of a cluster, used in messages:
+const (
+	MachineRange = "machine"
+	ServiceRange = "service"
+	PodRange     = "pod"
+)
+
+// privateRanges are the address spaces where free ranges are searched, in this order.
+var privateRanges = []string{
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+}
+
+// Plan contains the networks of a cluster, and the networks that it must not overlap. Ranges
+// that are nil aren't set.
+type Plan struct {
+	MachineCIDR *net.IPNet
+	ServiceCIDR *net.IPNet
+	PodCIDR     *net.IPNet
+	HostPrefix  int
+
+	// Nodes is the number of nodes that the cluster needs to support, zero if not known.
+	Nodes int
+
+	// Subnets are the existing subnets of the VPC where the cluster will be installed. They
+	// must be inside the machine CIDR.
+	Subnets []*net.IPNet
+
+	// Used are the ranges already used by other networks, that must not overlap any of the
+	// ranges of the cluster.
+	Used []*net.IPNet
+}
+
+// Capacity describes how many nodes and addresses the ranges of a plan allow.
+type Capacity struct {
+	// MaxNodes is the number of nodes that can get a block of pod addresses.
+	MaxNodes int
+
+	// AddressesPerNode is the number of pod addresses of each node.
+	AddressesPerNode int
+
+	// MachineAddresses is the number of addresses of the machine CIDR.
+	MachineAddresses int
+}
+
+// namedRange is a range of a plan together with its name.
+type namedRange struct {
+	name string
+	cidr *net.IPNet
+}
+
+// ranges returns the ranges of the cluster that are set.
+func (p *Plan) ranges() []namedRange {
+	var result []namedRange
+	for _, item := range []namedRange{
+		{MachineRange, p.MachineCIDR},
+		{ServiceRange, p.ServiceCIDR},
+		{PodRange, p.PodCIDR},
+	} {
+		if item.cidr != nil {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Capacity calculates the capacity of the plan. The values that depend on ranges that aren't
+// set are zero.
+func (p *Plan) Capacity() Capacity {
+	var capacity Capacity
+	if p.HostPrefix > 0 && p.HostPrefix <= 32 {
+		capacity.AddressesPerNode = 1 << (32 - p.HostPrefix)
+	}
+	if p.PodCIDR != nil && p.HostPrefix != 0 {
+		ones, _ := p.PodCIDR.Mask.Size()
+		if ones <= p.HostPrefix && p.HostPrefix <= 32 {
+			capacity.MaxNodes = 1 << (p.HostPrefix - ones)
+		}
+	}
+	if p.MachineCIDR != nil {
+		ones, size := p.MachineCIDR.Mask.Size()
+		capacity.MachineAddresses = 1 << (size - ones)
+	}
+	return capacity
+}
+
+// Validate checks the plan and returns the list of problems, or nil if there are no problems.
+func (p *Plan) Validate() []error {
+	var problems []error
+	ranges := p.ranges()
+	for _, item := range ranges {
+		if item.cidr.IP.To4() == nil {
+			problems = append(problems, fmt.Errorf("The %s CIDR %s isn't an IPv4 network",
+				item.name, item.cidr))
+		}
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+
+	if p.HostPrefix != 0 && (p.HostPrefix < MinHostPrefix || p.HostPrefix > MaxHostPrefix) {
+		problems = append(problems, fmt.Errorf("The host prefix /%d isn't between /%d and /%d",
+			p.HostPrefix, MinHostPrefix, MaxHostPrefix))
+	}
+
+	for i := range ranges {
+		for j := i + 1; j < len(ranges); j++ {
+			if Overlaps(ranges[i].cidr, ranges[j].cidr) {
+				problems = append(problems, fmt.Errorf("The %s CIDR %s overlaps the %s CIDR %s",
+					ranges[i].name, ranges[i].cidr, ranges[j].name, ranges[j].cidr))
+			}
+		}
+	}
+
+	if p.PodCIDR != nil && p.HostPrefix != 0 {
+		ones, _ := p.PodCIDR.Mask.Size()
+		capacity := p.Capacity()
+		switch {
+		case ones > p.HostPrefix:
+			problems = append(problems, fmt.Errorf("The pod CIDR %s is smaller than the host "+
+				"prefix /%d", p.PodCIDR, p.HostPrefix))
+		case p.Nodes > capacity.MaxNodes:
+			problems = append(problems, fmt.Errorf("The pod CIDR %s with host prefix /%d allows "+
+				"%d nodes, but %d are needed", p.PodCIDR, p.HostPrefix, capacity.MaxNodes, p.Nodes))
+		}
+	}
+
+	for _, subnet := range p.Subnets {
+		for _, item := range ranges {
+			if item.name == MachineRange {
+				if !Contains(item.cidr, subnet) {
+					problems = append(problems, fmt.Errorf("The subnet %s isn't inside the "+
+						"machine CIDR %s", subnet, item.cidr))
+				}
+				continue
+			}
+			if Overlaps(item.cidr, subnet) {
+				problems = append(problems, fmt.Errorf("The subnet %s overlaps the %s CIDR %s",
+					subnet, item.name, item.cidr))
+			}
+		}
+	}
+
+	for _, used := range p.Used {
+		for _, item := range ranges {
+			if Overlaps(item.cidr, used) {
+				problems = append(problems, fmt.Errorf("The %s CIDR %s overlaps the range %s "+
+					"that is already in use", item.name, item.cidr, used))
+			}
+		}
+	}
+
+	return problems
+}
+
+// Suggest returns a copy of the plan where the ranges that aren't set are replaced by the
+// defaults or, if the defaults overlap the subnets, the ranges in use or other ranges of the
+// cluster, by the first range of the same size of the private address space that doesn't. The
+// suggested machine CIDR contains the subnets, and the suggested pod CIDR is large enough for
+// the requested number of nodes.
+func (p *Plan) Suggest() (*Plan, error) {
+	result := *p
+	if result.HostPrefix == 0 {
+		result.HostPrefix = DefaultHostPrefix
+	}
+
+	// The ranges that are set are kept, so the suggested ones must not overlap them:
+	var taken []*net.IPNet
+	taken = append(taken, p.Used...)
+	for _, item := range p.ranges() {
+		taken = append(taken, item.cidr)
+	}
+
+	var err error
+	if result.MachineCIDR == nil && len(p.Subnets) > 0 {
+		result.MachineCIDR, err = suggestMachineCIDR(p.Subnets, taken)
+		if err != nil {
+			return nil, err
+		}
+		taken = append(taken, result.MachineCIDR)
+	}
+	taken = append(taken, p.Subnets...)
+
+	// The pending ranges are placed from the largest to the smallest, as that leaves less
+	// space unused between them:
+	var pending []*pendingRange
+	if result.MachineCIDR == nil {
+		pending = append(pending, &pendingRange{
+			preferred: mustParse(DefaultMachineCIDR),
+			target:    &result.MachineCIDR,
+		})
+	}
+	if result.ServiceCIDR == nil {
+		pending = append(pending, &pendingRange{
+			preferred: mustParse(DefaultServiceCIDR),
+			target:    &result.ServiceCIDR,
+		})
+	}
+	if result.PodCIDR == nil {
+		preferred := mustParse(DefaultPodCIDR)
+		ones, size := preferred.Mask.Size()
+		if result.Nodes > 0 {
+			// Each node needs a block of the size of the host prefix:
+			needed := result.HostPrefix - bits.Len(uint(result.Nodes-1))
+			if needed < ones {
+				ones = needed
+			}
+		}
+		if ones < 1 {
+			return nil, fmt.Errorf("Can't find a pod CIDR for %d nodes with host prefix /%d",
+				result.Nodes, result.HostPrefix)
+		}
+		mask := net.CIDRMask(ones, size)
+		pending = append(pending, &pendingRange{
+			preferred: &net.IPNet{IP: preferred.IP.Mask(mask), Mask: mask},
+			target:    &result.PodCIDR,
+		})
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		iOnes, _ := pending[i].preferred.Mask.Size()
+		jOnes, _ := pending[j].preferred.Mask.Size()
+		return iOnes < jOnes
+	})
+	for _, item := range pending {
+		*item.target, err = findFree(item.preferred, taken)
+		if err != nil {
+			return nil, err
+		}
+		taken = append(taken, *item.target)
+	}
+
+	return &result, nil
+}
+
+// pendingRange is a range of the cluster that needs to be suggested.
+type pendingRange struct {
+	preferred *net.IPNet
+	target    **net.IPNet
+}
+
+// suggestMachineCIDR returns the default machine CIDR if it contains the subnets and doesn't
+// overlap the taken ranges. Otherwise it returns the smallest range that contains all the
+// subnets, which requires that they are all in the same private address space.
+func suggestMachineCIDR(subnets, taken []*net.IPNet) (*net.IPNet, error) {
+	preferred := mustParse(DefaultMachineCIDR)
+	if !overlapsAny(preferred, taken) && containsAll(preferred, subnets) {
+		return preferred, nil
+	}
+	space := privateSpace(subnets[0])
+	for _, subnet := range subnets[1:] {
+		if privateSpace(subnet) != space {
+			return nil, fmt.Errorf("The subnets %s and %s aren't in the same private address "+
+				"space, so they can't share one machine CIDR", subnets[0], subnet)
+		}
+	}
+	return cover(subnets), nil
+}
+
+// containsAll checks if all the inner ranges are completely inside the outer range.
+func containsAll(outer *net.IPNet, inners []*net.IPNet) bool {
+	for _, inner := range inners {
+		if !Contains(outer, inner) {
+			return false
+		}
+	}
+	return true
+}
+
+// privateSpace returns the private address space that contains the given range, or an empty
+// string if it isn't in any of them.
+func privateSpace(cidr *net.IPNet) string {
+	for _, value := range privateRanges {
+		if Contains(mustParse(value), cidr) {
+			return value
+		}
+	}
+	return ""
+}
+
+// cover returns the smallest range that contains all the given ranges.
+func cover(cidrs []*net.IPNet) *net.IPNet {
+	first, last := bounds(cidrs[0])
+	for _, cidr := range cidrs[1:] {
+		start, end := bounds(cidr)
+		if start < first {
+			first = start
+		}
+		if end > last {
+			last = end
+		}
+	}
+	ones := bits.LeadingZeros32(first ^ last)
+	mask := net.CIDRMask(ones, 32)
+	return &net.IPNet{IP: toIP(first).Mask(mask), Mask: mask}
+}
+
+// findFree returns the preferred range if it doesn't overlap any of the taken ranges. Otherwise
+// it returns the first range of the same size of the private address space that doesn't,
+// starting after the preferred range so that the result stays close to it.
+func findFree(preferred *net.IPNet, taken []*net.IPNet) (*net.IPNet, error) {
+	if !overlapsAny(preferred, taken) {
+		return preferred, nil
+	}
+	ones, _ := preferred.Mask.Size()
+	step := uint64(1) << (32 - ones)
+	mask := net.CIDRMask(ones, 32)
+	origin, _ := bounds(preferred)
+	for _, after := range []bool{true, false} {
+		for _, value := range privateRanges {
+			private := mustParse(value)
+			privateOnes, _ := private.Mask.Size()
+			if privateOnes > ones {
+				continue
+			}
+			start, end := bounds(private)
+			for block := uint64(start); block+step-1 <= uint64(end); block += step {
+				if (block >= uint64(origin)) != after {
+					continue
+				}
+				candidate := &net.IPNet{IP: toIP(uint32(block)), Mask: mask}
+				if !overlapsAny(candidate, taken) {
+					return candidate, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("Can't find a free /%d range in the private address space", ones)
+}
+
+// Overlaps checks if two ranges have any address in common.
+func Overlaps(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// Contains checks if the inner range is completely inside the outer range.
+func Contains(outer, inner *net.IPNet) bool {
+	outerOnes, _ := outer.Mask.Size()
+	innerOnes, _ := inner.Mask.Size()
+	return outer.Contains(inner.IP) && innerOnes >= outerOnes
+}
+
+func overlapsAny(cidr *net.IPNet, others []*net.IPNet) bool {
+	for _, other := range others {
+		if Overlaps(cidr, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCIDRs parses a list of IPv4 ranges.
+func ParseCIDRs(values []string) ([]*net.IPNet, error) {
+	var result []*net.IPNet
+	for _, value := range values {
+		_, cidr, err := net.ParseCIDR(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CIDR '%s': %v", value, err)
+		}
+		if cidr.IP.To4() == nil {
+			return nil, fmt.Errorf("Invalid CIDR '%s': only IPv4 networks are supported", value)
+		}
+		result = append(result, cidr)
+	}
+	return result, nil
+}
+
+// ReadCIDRFile reads a file containing one range per line. Empty lines and text after '#' are
+// ignored.
+func ReadCIDRFile(path string) ([]*net.IPNet, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var values []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line != "" {
+			values = append(values, line)
+		}
+	}
+	err = scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+	result, err := ParseCIDRs(values)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return result, nil
+}
+
+func mustParse(value string) *net.IPNet {
+	_, cidr, err := net.ParseCIDR(value)
+	if err != nil {
+		panic(err)
+	}
+	return cidr
+}
+
+// bounds returns the first and last addresses of an IPv4 range.
+func bounds(cidr *net.IPNet) (first, last uint32) {
+	first = binary.BigEndian.Uint32(cidr.IP.To4())
+	last = first | ^binary.BigEndian.Uint32(net.IP(cidr.Mask).To4())
+	return
+}
+
+func toIP(value uint32) net.IP {
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, value)
+	return ip
+}
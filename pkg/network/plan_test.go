@@ -0,0 +1,259 @@
+package network
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func parse(t *testing.T, value string) *net.IPNet {
+	_, cidr, err := net.ParseCIDR(value)
+	if err != nil {
+		t.Fatalf("can't parse CIDR '%s': %v", value, err)
+	}
+	return cidr
+}
+
+func parseAll(t *testing.T, values ...string) []*net.IPNet {
+	var result []*net.IPNet
+	for _, value := range values {
+		result = append(result, parse(t, value))
+	}
+	return result
+}
+
+func defaultPlan(t *testing.T) *Plan {
+	return &Plan{
+		MachineCIDR: parse(t, DefaultMachineCIDR),
+		ServiceCIDR: parse(t, DefaultServiceCIDR),
+		PodCIDR:     parse(t, DefaultPodCIDR),
+		HostPrefix:  DefaultHostPrefix,
+	}
+}
+
+func TestValidateDefaults(t *testing.T) {
+	problems := defaultPlan(t).Validate()
+	if len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+}
+
+func TestValidateProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(plan *Plan)
+		message string
+	}{
+		{
+			name: "machine overlaps pod",
+			modify: func(plan *Plan) {
+				plan.PodCIDR = parse(t, "10.0.128.0/17")
+			},
+			message: "The machine CIDR 10.0.0.0/16 overlaps the pod CIDR 10.0.128.0/17",
+		},
+		{
+			name: "service overlaps machine",
+			modify: func(plan *Plan) {
+				plan.ServiceCIDR = parse(t, "10.0.0.0/8")
+			},
+			message: "The machine CIDR 10.0.0.0/16 overlaps the service CIDR 10.0.0.0/8",
+		},
+		{
+			name: "host prefix out of range",
+			modify: func(plan *Plan) {
+				plan.HostPrefix = 28
+			},
+			message: "The host prefix /28 isn't between /23 and /26",
+		},
+		{
+			name: "pod CIDR smaller than host prefix",
+			modify: func(plan *Plan) {
+				plan.PodCIDR = parse(t, "10.128.0.0/24")
+			},
+			message: "The pod CIDR 10.128.0.0/24 is smaller than the host prefix /23",
+		},
+		{
+			name: "too many nodes",
+			modify: func(plan *Plan) {
+				plan.Nodes = 600
+			},
+			message: "allows 512 nodes, but 600 are needed",
+		},
+		{
+			name: "subnet outside the machine CIDR",
+			modify: func(plan *Plan) {
+				plan.Subnets = parseAll(t, "10.1.0.0/24")
+			},
+			message: "The subnet 10.1.0.0/24 isn't inside the machine CIDR 10.0.0.0/16",
+		},
+		{
+			name: "subnet overlaps the service CIDR",
+			modify: func(plan *Plan) {
+				plan.MachineCIDR = parse(t, "172.16.0.0/12")
+				plan.Subnets = parseAll(t, "172.30.1.0/24")
+			},
+			message: "The subnet 172.30.1.0/24 overlaps the service CIDR 172.30.0.0/16",
+		},
+		{
+			name: "range in use",
+			modify: func(plan *Plan) {
+				plan.Used = parseAll(t, "10.129.0.0/16")
+			},
+			message: "The pod CIDR 10.128.0.0/14 overlaps the range 10.129.0.0/16 that is already in use",
+		},
+		{
+			name: "IPv6",
+			modify: func(plan *Plan) {
+				plan.PodCIDR = parse(t, "fd01::/48")
+			},
+			message: "The pod CIDR fd01::/48 isn't an IPv4 network",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plan := defaultPlan(t)
+			test.modify(plan)
+			problems := plan.Validate()
+			for _, problem := range problems {
+				if strings.Contains(problem.Error(), test.message) {
+					return
+				}
+			}
+			t.Errorf("expected a problem containing '%s', got %v", test.message, problems)
+		})
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	plan := defaultPlan(t)
+	plan.HostPrefix = 24
+	capacity := plan.Capacity()
+	expected := Capacity{
+		MaxNodes:         1024,
+		AddressesPerNode: 256,
+		MachineAddresses: 65536,
+	}
+	if capacity != expected {
+		t.Errorf("expected %+v, got %+v", expected, capacity)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name    string
+		plan    Plan
+		machine string
+		service string
+		pod     string
+	}{
+		{
+			name:    "defaults",
+			machine: DefaultMachineCIDR,
+			service: DefaultServiceCIDR,
+			pod:     DefaultPodCIDR,
+		},
+		{
+			name: "defaults in use",
+			plan: Plan{
+				Used: parseAll(t, "10.0.0.0/12", "10.128.0.0/12", "172.30.0.0/15"),
+			},
+			machine: "10.16.0.0/16",
+			service: "192.168.0.0/16",
+			pod:     "10.144.0.0/14",
+		},
+		{
+			name: "given ranges are kept",
+			plan: Plan{
+				MachineCIDR: parse(t, "10.128.0.0/16"),
+			},
+			machine: "10.128.0.0/16",
+			service: DefaultServiceCIDR,
+			pod:     "10.132.0.0/14",
+		},
+		{
+			name: "machine CIDR covers the subnets",
+			plan: Plan{
+				Subnets: parseAll(t, "192.168.0.0/24", "192.168.3.0/24"),
+			},
+			machine: "192.168.0.0/22",
+			service: DefaultServiceCIDR,
+			pod:     DefaultPodCIDR,
+		},
+		{
+			name: "pod CIDR large enough for the nodes",
+			plan: Plan{
+				Nodes: 1000,
+			},
+			machine: DefaultMachineCIDR,
+			service: DefaultServiceCIDR,
+			pod:     "10.128.0.0/13",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plan, err := test.plan.Suggest()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if plan.MachineCIDR.String() != test.machine {
+				t.Errorf("expected machine CIDR %s, got %s", test.machine, plan.MachineCIDR)
+			}
+			if plan.ServiceCIDR.String() != test.service {
+				t.Errorf("expected service CIDR %s, got %s", test.service, plan.ServiceCIDR)
+			}
+			if plan.PodCIDR.String() != test.pod {
+				t.Errorf("expected pod CIDR %s, got %s", test.pod, plan.PodCIDR)
+			}
+			if problems := plan.Validate(); len(problems) != 0 {
+				t.Errorf("expected the suggested plan to be valid, got %v", problems)
+			}
+		})
+	}
+}
+
+func TestSuggestNoSpace(t *testing.T) {
+	plan := &Plan{
+		Used: parseAll(t, "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"),
+	}
+	_, err := plan.Suggest()
+	if err == nil || !strings.Contains(err.Error(), "Can't find a free") {
+		t.Errorf("expected an error because there is no space, got %v", err)
+	}
+}
+
+func TestSuggestSubnetsInDifferentSpaces(t *testing.T) {
+	plan := &Plan{
+		Subnets: parseAll(t, "10.0.0.0/24", "192.168.1.0/24"),
+	}
+	_, err := plan.Suggest()
+	if err == nil || !strings.Contains(err.Error(), "can't share one machine CIDR") {
+		t.Errorf("expected an error because the subnets are in different spaces, got %v", err)
+	}
+}
+
+func TestReadCIDRFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ranges.txt")
+	content := "# Ranges of the office\n10.0.0.0/16\n\n172.20.0.0/16  # VPN\n"
+	err := os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cidrs, err := ReadCIDRFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cidrs) != 2 || cidrs[0].String() != "10.0.0.0/16" || cidrs[1].String() != "172.20.0.0/16" {
+		t.Errorf("unexpected ranges %v", cidrs)
+	}
+
+	err = os.WriteFile(path, []byte("10.0.0.0/33\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadCIDRFile(path)
+	if err == nil {
+		t.Errorf("expected an error for an invalid range")
+	}
+}
@@ -15,6 +15,9 @@ package connection
 
 import (
 	"fmt"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/golang/glog"
 	sdk "github.com/openshift-online/ocm-sdk-go"
@@ -23,6 +26,7 @@ import (
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/debug"
 	"github.com/openshift-online/ocm-cli/pkg/info"
+	"github.com/openshift-online/ocm-cli/pkg/region"
 )
 
 // ConnectionBuilder contains the information and logic needed to build a connection to OCM. Don't
@@ -39,6 +43,10 @@ type ConnectionBuilder struct {
 	// defaults to whatever is in the ocm config file
 	apiUrlOverride string
 
+	// region is the name of the region whose API gateway is used instead of the API URL. The
+	// gateway is discovered using the API URL.
+	region string
+
 	// agent is the UserAgent for a given CLI.
 	// defaults to OCM_CLI+version
 	agent string
@@ -68,6 +76,12 @@ func (b *ConnectionBuilder) WithApiUrl(url string) *ConnectionBuilder {
 	return b
 }
 
+// Use the API gateway of the given region, discovered using the API URL
+func (b *ConnectionBuilder) WithRegion(name string) *ConnectionBuilder {
+	b.region = name
+	return b
+}
+
 // Override the default UserAgent String
 func (b *ConnectionBuilder) AsAgent(agent string) *ConnectionBuilder {
 	b.agent = agent
@@ -88,8 +102,15 @@ func (b *ConnectionBuilder) Build() (result *sdk.Connection, err error) {
 		}
 	}
 
+	// Obtain the credentials from the credential helper, if any. They are added to a copy of the
+	// configuration so that callers that save the configuration don't store them:
+	cfg, err := b.cfg.WithHelperCredentials()
+	if err != nil {
+		return
+	}
+
 	// Check that the configuration has credentials or tokens that haven't have expired:
-	armed, reason, err := b.cfg.Armed()
+	armed, reason, err := cfg.Armed()
 	if err != nil {
 		return
 	}
@@ -97,8 +118,9 @@ func (b *ConnectionBuilder) Build() (result *sdk.Connection, err error) {
 		err = fmt.Errorf("Not logged in, %s, run the 'login' command", reason)
 		return
 	}
+	warnRefreshExpiration(cfg)
 
-	builder := b.initConnectionBuilderFromConfig()
+	builder := initConnectionBuilderFromConfig(cfg)
 
 	logger, err := b.getLogger()
 	if err != nil {
@@ -109,49 +131,81 @@ func (b *ConnectionBuilder) Build() (result *sdk.Connection, err error) {
 	agent := b.getAgent()
 	builder.Agent(agent)
 
+	gatewayURL := cfg.URL
 	if b.apiUrlOverride != "" {
-		builder.URL(b.apiUrlOverride)
+		gatewayURL = b.apiUrlOverride
+		builder.URL(gatewayURL)
+	}
+	if b.region != "" {
+		var regional region.Region
+		regional, err = region.Find(gatewayURL, b.region)
+		if err != nil {
+			return
+		}
+		builder.URL(regional.URL)
 	}
 
 	// Create the connection:
 	return builder.Build()
 }
 
-func (b *ConnectionBuilder) initConnectionBuilderFromConfig() *sdk.ConnectionBuilder {
+func initConnectionBuilderFromConfig(cfg *config.Config) *sdk.ConnectionBuilder {
 	builder := sdk.NewConnectionBuilder()
 
 	// Prepare the builder for the connection adding only the properties that have explicit
 	// values in the configuration, so that default values won't be overridden:
-	if b.cfg.TokenURL != "" {
-		builder.TokenURL(b.cfg.TokenURL)
+	if cfg.TokenURL != "" {
+		builder.TokenURL(cfg.TokenURL)
 	}
-	if b.cfg.ClientID != "" || b.cfg.ClientSecret != "" {
-		builder.Client(b.cfg.ClientID, b.cfg.ClientSecret)
+	if cfg.ClientID != "" || cfg.ClientSecret != "" {
+		builder.Client(cfg.ClientID, cfg.ClientSecret)
 	}
-	if b.cfg.Scopes != nil {
-		builder.Scopes(b.cfg.Scopes...)
+	if cfg.Scopes != nil {
+		builder.Scopes(cfg.Scopes...)
 	}
-	if b.cfg.User != "" || b.cfg.Password != "" {
-		builder.User(b.cfg.User, b.cfg.Password)
+	if cfg.User != "" || cfg.Password != "" {
+		builder.User(cfg.User, cfg.Password)
 	}
-	if b.cfg.URL != "" {
-		builder.URL(b.cfg.URL)
+	if cfg.URL != "" {
+		builder.URL(cfg.URL)
 	}
 	tokens := make([]string, 0, 2)
-	if b.cfg.AccessToken != "" {
-		tokens = append(tokens, b.cfg.AccessToken)
+	if cfg.AccessToken != "" {
+		tokens = append(tokens, cfg.AccessToken)
 	}
-	if b.cfg.RefreshToken != "" {
-		tokens = append(tokens, b.cfg.RefreshToken)
+	if cfg.RefreshToken != "" {
+		tokens = append(tokens, cfg.RefreshToken)
 	}
 	if len(tokens) > 0 {
 		builder.Tokens(tokens...)
 	}
-	builder.Insecure(b.cfg.Insecure)
+	builder.Insecure(cfg.Insecure)
 
 	return builder
 }
 
+// refreshWarning makes sure that the warning about the expiration of the refresh token is written
+// only once, even if the process builds several connections.
+var refreshWarning sync.Once
+
+// warnRefreshExpiration writes a warning to the standard error stream when the refresh token is
+// about to expire, so that the user can log in again before commands start to fail.
+func warnRefreshExpiration(cfg *config.Config) {
+	soon, left := cfg.RefreshExpiresSoon(config.RefreshWarningThreshold)
+	if !soon {
+		return
+	}
+	refreshWarning.Do(func() {
+		if left <= 0 {
+			fmt.Fprintf(os.Stderr, "Warning: refresh token has expired, run the 'login' command "+
+				"to get a new one\n")
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Warning: refresh token expires in %s, run the 'login' command "+
+			"to get a new one\n", left.Round(time.Minute))
+	})
+}
+
 // Returns the configured logger or a default if there is none configured
 func (b *ConnectionBuilder) getLogger() (logging.Logger, error) {
 	if b.logger != nil {
@@ -8,6 +8,7 @@ import (
 	"github.com/openshift-online/ocm-cli/pkg/info"
 	conn "github.com/openshift-online/ocm-cli/pkg/ocm/connection-builder"
 	"github.com/openshift-online/ocm-cli/pkg/properties"
+	"github.com/openshift-online/ocm-cli/pkg/region"
 )
 
 func NewConnection() *conn.ConnectionBuilder {
@@ -23,5 +24,10 @@ func NewConnection() *conn.ConnectionBuilder {
 		connection = connection.WithApiUrl(overrideUrl)
 	}
 
+	// use the gateway of the region selected with the --rh-region flag
+	if name := region.Selected(); name != "" {
+		connection = connection.WithRegion(name)
+	}
+
 	return connection
 }
@@ -0,0 +1,105 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to implement the '--all-regions' option of the list
+// commands.
+
+package ocm
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/properties"
+	"github.com/openshift-online/ocm-cli/pkg/region"
+	"github.com/openshift-online/ocm-cli/pkg/urls"
+)
+
+// RegionItems contains the items listed in one region.
+type RegionItems[T any] struct {
+	Region string
+	Items  []T
+}
+
+// ListAllRegions calls the list function with a connection to the API gateway of each region of
+// the environment, in parallel, and returns the items of each region in the order of the regions.
+// Regions where the function fails are reported with a warning, and it only fails if it fails in
+// all the regions. The description of the items is used in the messages.
+func ListAllRegions[T any](what string, list func(*sdk.Connection) ([]T, error)) ([]RegionItems[T],
+	error) {
+	if region.Selected() != "" {
+		return nil, fmt.Errorf("Option --all-regions can't be used with --rh-region")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("Not logged in, run the 'login' command")
+	}
+	gatewayURL, err := urls.ResolveGatewayURL(os.Getenv(properties.URLEnvKey), cfg)
+	if err != nil {
+		return nil, err
+	}
+	regions, err := region.List(gatewayURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(regions) == 0 {
+		return nil, fmt.Errorf("There are no regions in the environment of '%s'", gatewayURL)
+	}
+
+	results := make([]RegionItems[T], len(regions))
+	errs := make([]error, len(regions))
+	var wg sync.WaitGroup
+	for i := range regions {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i].Region = regions[i].Name
+			results[i].Items, errs[i] = listRegion(cfg, regions[i], list)
+		}(i)
+	}
+	wg.Wait()
+
+	var listed []RegionItems[T]
+	for i, item := range regions {
+		if errs[i] != nil {
+			fmt.Fprintf(os.Stderr, "Warning: can't list %s of region '%s': %v\n", what, item.Name,
+				errs[i])
+			continue
+		}
+		listed = append(listed, results[i])
+	}
+	if len(listed) == 0 {
+		return nil, fmt.Errorf("Can't list %s of any region", what)
+	}
+	return listed, nil
+}
+
+func listRegion[T any](cfg *config.Config, item region.Region,
+	list func(*sdk.Connection) ([]T, error)) ([]T, error) {
+	connection, err := NewConnection().Config(cfg).WithApiUrl(item.URL).Build()
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Close()
+	return list(connection)
+}
@@ -0,0 +1,143 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions that add the plugins to the command tree, so that they appear
+// in the help and in the shell completions.
+
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// AddCommands adds to the root command a command for each plugin in the PATH that has a manifest.
+// Running these commands executes the plugin, and their help and completions are generated from
+// the manifest. Plugins whose names collide with existing commands or that implement nested
+// commands, like 'ocm-foo-bar', are ignored, so they are still executed as before when the
+// command isn't found.
+func AddCommands(root *cobra.Command, handler Handler) {
+	plugins, err := FindPlugins()
+	if err != nil {
+		return
+	}
+	added := map[string]bool{}
+	for _, plugin := range plugins {
+		name := plugin.Command()
+		if name == "" || strings.Contains(name, "-") || added[name] {
+			continue
+		}
+		added[name] = true
+
+		// Use the same plugin that would be executed, which is the first in the PATH:
+		path, found := handler.Lookup(name)
+		if !found {
+			continue
+		}
+		manifest, err := LoadManifest(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring manifest of plugin '%s': %v\n", path, err)
+			continue
+		}
+		if manifest == nil {
+			continue
+		}
+
+		// Plugins use underscores in file names for dashes in command names:
+		use := strings.ReplaceAll(name, "_", "-")
+		if hasCommand(root, use) {
+			continue
+		}
+		cmd, err := safeNewCommand(handler, path, ManifestCommand{
+			Name:        use,
+			Description: manifest.Description,
+			Flags:       manifest.Flags,
+			Commands:    manifest.Commands,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring manifest of plugin '%s': %v\n", path, err)
+			continue
+		}
+		cmd.Annotations = map[string]string{pluginAnnotation: path}
+		root.AddCommand(cmd)
+	}
+}
+
+func hasCommand(parent *cobra.Command, name string) bool {
+	for _, cmd := range parent.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// safeNewCommand creates the command for the plugin, returning an error instead of panicking if
+// the flags of the manifest can't be added, so that a broken plugin doesn't break all the
+// commands.
+func safeNewCommand(handler Handler, executablePath string,
+	description ManifestCommand) (cmd *cobra.Command, err error) {
+	defer func() {
+		if problem := recover(); problem != nil {
+			err = fmt.Errorf("%v", problem)
+		}
+	}()
+	cmd = newCommand(handler, executablePath, nil, description)
+	return
+}
+
+// newCommand creates the command for the plugin or one of its subcommands. The path contains the
+// names of the subcommands from the plugin to this command, which are passed to the plugin before
+// the rest of the arguments.
+func newCommand(handler Handler, executablePath string, path []string,
+	description ManifestCommand) *cobra.Command {
+	short := description.Description
+	if len(path) == 0 {
+		short = strings.TrimSpace(short + " (plugin)")
+	}
+	cmd := &cobra.Command{
+		Use:                description.Name,
+		Short:              short,
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, argv []string) error {
+			cmdArgs := append(append([]string{}, path...), argv...)
+			return handler.Execute(executablePath, cmdArgs, os.Environ())
+		},
+	}
+
+	// The flags are parsed by the plugin, they are added only to be shown in the help and
+	// completions:
+	fs := cmd.Flags()
+	for _, flag := range description.Flags {
+		switch flag.Type {
+		case "bool":
+			fs.BoolP(flag.Name, flag.Shorthand, false, flag.Description)
+		case "int":
+			fs.IntP(flag.Name, flag.Shorthand, 0, flag.Description)
+		default:
+			fs.StringP(flag.Name, flag.Shorthand, "", flag.Description)
+		}
+	}
+
+	for _, child := range description.Commands {
+		childPath := append(append([]string{}, path...), child.Name)
+		cmd.AddCommand(newCommand(handler, executablePath, childPath, child))
+	}
+	return cmd
+}
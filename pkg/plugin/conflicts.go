@@ -0,0 +1,194 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to detect plugins that can't be executed or that are
+// ambiguous, for example because they are shadowed by built-in commands or by other plugins.
+
+package plugin
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ProblemKind identifies the kind of problem found with a plugin.
+type ProblemKind string
+
+const (
+	// ProblemShadowed indicates that the plugin is never executed because a built-in command
+	// has the same name.
+	ProblemShadowed ProblemKind = "shadowed"
+
+	// ProblemDuplicate indicates that the plugin is never executed because a plugin with the
+	// same name comes first in the PATH.
+	ProblemDuplicate ProblemKind = "duplicate"
+
+	// ProblemCollision indicates that the plugin has the same command name as another plugin
+	// that uses dashes where it uses underscores, or the other way around.
+	ProblemCollision ProblemKind = "collision"
+
+	// ProblemNotExecutable indicates that the plugin file doesn't have execution permissions.
+	ProblemNotExecutable ProblemKind = "not-executable"
+
+	// ProblemManifest indicates that the manifest of the plugin can't be loaded.
+	ProblemManifest ProblemKind = "manifest"
+)
+
+// pluginAnnotation is the annotation added to the commands created for plugins, so that they
+// aren't confused with built-in commands.
+const pluginAnnotation = "ocm.openshift.com/plugin"
+
+// Problem describes a problem found with a plugin.
+type Problem struct {
+	Kind    ProblemKind
+	Plugin  Plugin
+	Message string
+}
+
+// Diagnose checks the given plugins, in the order returned by FindPlugins, and returns the
+// problems found. The root command is used to find the plugins shadowed by built-in commands.
+func Diagnose(root *cobra.Command, plugins []Plugin) []Problem {
+	var problems []Problem
+	first := map[string]Plugin{}
+	forms := map[string][]Plugin{}
+	for _, plugin := range plugins {
+		if plugin.ManifestError != nil {
+			problems = append(problems, Problem{
+				Kind:    ProblemManifest,
+				Plugin:  plugin,
+				Message: plugin.ManifestError.Error(),
+			})
+		}
+
+		// Files that aren't executable are skipped when looking up plugins, so they don't
+		// shadow other plugins:
+		if !plugin.Executable {
+			problems = append(problems, Problem{
+				Kind:    ProblemNotExecutable,
+				Plugin:  plugin,
+				Message: fmt.Sprintf("'%s' isn't executable", plugin.File()),
+			})
+			continue
+		}
+		if command := builtinCommand(root, plugin); command != "" {
+			problems = append(problems, Problem{
+				Kind:   ProblemShadowed,
+				Plugin: plugin,
+				Message: fmt.Sprintf("'%s' is shadowed by the built-in '%s' command",
+					plugin.File(), command),
+			})
+		}
+		if other, ok := first[plugin.Name]; ok {
+			problems = append(problems, Problem{
+				Kind:   ProblemDuplicate,
+				Plugin: plugin,
+				Message: fmt.Sprintf("'%s' is shadowed by '%s', which comes first in the PATH",
+					plugin.File(), other.File()),
+			})
+			continue
+		}
+		first[plugin.Name] = plugin
+		key := normalizedCommand(plugin)
+		forms[key] = append(forms[key], plugin)
+	}
+
+	// Report collisions once for each plugin, in the order they were found:
+	for _, plugin := range plugins {
+		if !plugin.Executable || first[plugin.Name].File() != plugin.File() {
+			continue
+		}
+		for _, other := range forms[normalizedCommand(plugin)] {
+			if other.Name == plugin.Name {
+				continue
+			}
+			problems = append(problems, Problem{
+				Kind:   ProblemCollision,
+				Plugin: plugin,
+				Message: fmt.Sprintf("'%s' collides with '%s', only one of them can be used as "+
+					"'ocm %s'", plugin.File(), other.File(), usage(plugin)),
+			})
+		}
+	}
+	return problems
+}
+
+// Ambiguities returns the problems that make the execution of the given plugin file ambiguous:
+// other plugins with the same name that are never executed, and plugins whose names differ only
+// in dashes and underscores.
+func Ambiguities(plugins []Plugin, file string) []Problem {
+	file = filepath.Clean(file)
+	var current *Plugin
+	for i := range plugins {
+		if filepath.Clean(plugins[i].File()) == file {
+			current = &plugins[i]
+			break
+		}
+	}
+	if current == nil {
+		return nil
+	}
+	var problems []Problem
+	for _, problem := range Diagnose(nil, plugins) {
+		if problem.Kind != ProblemDuplicate && problem.Kind != ProblemCollision {
+			continue
+		}
+		if problem.Plugin.Name == current.Name {
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+// builtinCommand returns the name of the built-in command that shadows the plugin, if any. The
+// plugin is only executed when the command isn't found, which never happens if its first word
+// is a built-in command.
+func builtinCommand(root *cobra.Command, plugin Plugin) string {
+	if root == nil {
+		return ""
+	}
+	word := strings.SplitN(plugin.Command(), "-", 2)[0]
+	word = strings.ReplaceAll(word, "_", "-")
+	if word == "help" {
+		return word
+	}
+	for _, cmd := range root.Commands() {
+		if _, ok := cmd.Annotations[pluginAnnotation]; ok {
+			continue
+		}
+		if cmd.Name() == word || cmd.HasAlias(word) {
+			return cmd.Name()
+		}
+	}
+	return ""
+}
+
+// normalizedCommand returns the command of the plugin using dashes instead of underscores.
+func normalizedCommand(plugin Plugin) string {
+	return strings.ReplaceAll(plugin.Command(), "_", "-")
+}
+
+// usage returns the command line that runs the plugin, for example 'foo-bar' for 'ocm-foo_bar'
+// and 'foo bar' for 'ocm-foo-bar'.
+func usage(plugin Plugin) string {
+	words := strings.Split(plugin.Command(), "-")
+	for i, word := range words {
+		words[i] = strings.ReplaceAll(word, "_", "-")
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,79 @@
+package plugin
+
+import (
+	"github.com/spf13/cobra"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diagnose", func() {
+	var root *cobra.Command
+
+	BeforeEach(func() {
+		root = &cobra.Command{Use: "ocm"}
+		root.AddCommand(&cobra.Command{Use: "cluster"})
+		root.AddCommand(&cobra.Command{Use: "list", Aliases: []string{"ls"}})
+		root.AddCommand(&cobra.Command{
+			Use:         "hello",
+			Annotations: map[string]string{pluginAnnotation: "/bin/ocm-hello"},
+		})
+	})
+
+	kinds := func(problems []Problem) []ProblemKind {
+		result := []ProblemKind{}
+		for _, problem := range problems {
+			result = append(result, problem.Kind)
+		}
+		return result
+	}
+
+	It("doesn't report plugins without problems", func() {
+		plugins := []Plugin{
+			{Name: "ocm-hello", Path: "/bin", Executable: true},
+			{Name: "ocm-foo-bar", Path: "/bin", Executable: true},
+		}
+		Expect(Diagnose(root, plugins)).To(BeEmpty())
+	})
+
+	It("reports plugins shadowed by built-in commands", func() {
+		plugins := []Plugin{
+			{Name: "ocm-cluster-foo", Path: "/bin", Executable: true},
+			{Name: "ocm-ls", Path: "/bin", Executable: true},
+		}
+		problems := Diagnose(root, plugins)
+		Expect(kinds(problems)).To(Equal([]ProblemKind{ProblemShadowed, ProblemShadowed}))
+		Expect(problems[1].Message).To(ContainSubstring("'list'"))
+	})
+
+	It("reports duplicates that are never executed", func() {
+		plugins := []Plugin{
+			{Name: "ocm-foo", Path: "/usr/local/bin", Executable: false},
+			{Name: "ocm-foo", Path: "/home/user/bin", Executable: true},
+			{Name: "ocm-foo", Path: "/usr/bin", Executable: true},
+		}
+		problems := Diagnose(root, plugins)
+		Expect(kinds(problems)).To(Equal([]ProblemKind{ProblemNotExecutable, ProblemDuplicate}))
+		Expect(problems[1].Plugin.Path).To(Equal("/usr/bin"))
+		Expect(problems[1].Message).To(ContainSubstring("/home/user/bin"))
+	})
+
+	It("reports collisions between dashes and underscores", func() {
+		plugins := []Plugin{
+			{Name: "ocm-foo_bar", Path: "/bin", Executable: true},
+			{Name: "ocm-foo-bar", Path: "/bin", Executable: true},
+		}
+		problems := Diagnose(root, plugins)
+		Expect(kinds(problems)).To(Equal([]ProblemKind{ProblemCollision, ProblemCollision}))
+	})
+
+	It("finds the ambiguities of the executed plugin", func() {
+		plugins := []Plugin{
+			{Name: "ocm-foo", Path: "/home/user/bin", Executable: true},
+			{Name: "ocm-foo", Path: "/usr/bin", Executable: true},
+			{Name: "ocm-bar", Path: "/usr/bin", Executable: true},
+		}
+		Expect(Ambiguities(plugins, "/home/user/bin/ocm-foo")).To(HaveLen(1))
+		Expect(Ambiguities(plugins, "/usr/bin/ocm-bar")).To(BeEmpty())
+	})
+})
@@ -0,0 +1,132 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to pass the credentials of the current session to plugins,
+// so that they don't need to read the configuration file themselves.
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+// Names of the environment variables used to pass credentials to plugins.
+const (
+	EnvAPIURL        = "OCM_PLUGIN_API_URL"
+	EnvAccessToken   = "OCM_PLUGIN_ACCESS_TOKEN"
+	EnvCredentialsFD = "OCM_PLUGIN_CREDENTIALS_FD"
+)
+
+// credentialsLifetime is the minimum time that the access token passed to plugins will be valid.
+const credentialsLifetime = 5 * time.Minute
+
+// Credentials contains the details of the current session that are passed to plugins.
+type Credentials struct {
+	URL         string     `json:"url"`
+	AccessToken string     `json:"access_token"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// LoadCredentials returns the API URL and a fresh access token for the current session, refreshing
+// and saving the tokens if needed.
+func LoadCredentials() (*Credentials, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("can't load config file: %v", err)
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("not logged in, run the 'login' command")
+	}
+
+	connection, err := ocm.NewConnection().Config(cfg).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	accessToken, refreshToken, err := connection.Tokens(credentialsLifetime)
+	if err != nil {
+		return nil, fmt.Errorf("can't get token: %v", err)
+	}
+	cfg.AccessToken = accessToken
+	cfg.RefreshToken = refreshToken
+	err = config.Save(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("can't save config file: %v", err)
+	}
+
+	credentials := &Credentials{
+		URL:         connection.URL(),
+		AccessToken: accessToken,
+	}
+	token, err := config.ParseToken(accessToken)
+	if err == nil {
+		expiresAt, err := config.TokenExpiresAt(token)
+		if err == nil && !expiresAt.IsZero() {
+			credentials.ExpiresAt = &expiresAt
+		}
+	}
+	return credentials, nil
+}
+
+// applyCredentials configures the command so that the plugin receives the credentials using the
+// given mode. It returns a function that must be called once the command has been started.
+func applyCredentials(cmd *exec.Cmd, mode string, credentials *Credentials) (func(), error) {
+	done := func() {}
+	switch mode {
+	case CredentialsEnv:
+		cmd.Env = append(cmd.Env,
+			EnvAPIURL+"="+credentials.URL,
+			EnvAccessToken+"="+credentials.AccessToken,
+		)
+	case CredentialsFD:
+		data, err := json.Marshal(credentials)
+		if err != nil {
+			return done, err
+		}
+		reader, writer, err := os.Pipe()
+		if err != nil {
+			return done, err
+		}
+		// The document is small enough to fit in the buffer of the pipe, so it can be written
+		// before the plugin starts reading:
+		_, err = writer.Write(data)
+		writer.Close()
+		if err != nil {
+			reader.Close()
+			return done, err
+		}
+		// Extra files start at descriptor 3, after standard input, output and error:
+		fd := 3 + len(cmd.ExtraFiles)
+		cmd.ExtraFiles = append(cmd.ExtraFiles, reader)
+		cmd.Env = append(cmd.Env,
+			EnvAPIURL+"="+credentials.URL,
+			EnvCredentialsFD+"="+strconv.Itoa(fd),
+		)
+		done = func() {
+			reader.Close()
+		}
+	}
+	return done, nil
+}
@@ -0,0 +1,194 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to find the plugins available in the PATH.
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Prefix is the prefix that plugin file names should have.
+const Prefix = "ocm-"
+
+// Plugin contains the description of a plugin found in the PATH.
+type Plugin struct {
+	// Name is the file name of the plugin, without the '.exe' extension on Windows.
+	Name string
+
+	// Path is the directory that contains the plugin.
+	Path string
+
+	// Executable indicates if the file has execution permissions.
+	Executable bool
+
+	// Manifest is the manifest of the plugin, or nil if it doesn't have one.
+	Manifest *Manifest
+
+	// ManifestError is the error found loading the manifest, if any.
+	ManifestError error
+}
+
+// Command returns the name of the command that runs the plugin, for example 'foo' for 'ocm-foo'.
+func (p Plugin) Command() string {
+	return strings.TrimPrefix(p.Name, Prefix)
+}
+
+// File returns the complete path of the plugin file.
+func (p Plugin) File() string {
+	name := p.Name
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(p.Path, name)
+}
+
+// Version returns the version from the manifest, if any.
+func (p Plugin) Version() string {
+	if p.Manifest == nil {
+		return ""
+	}
+	return p.Manifest.Version
+}
+
+// Description returns the description from the manifest, if any.
+func (p Plugin) Description() string {
+	if p.Manifest == nil {
+		return ""
+	}
+	return p.Manifest.Description
+}
+
+// FindPlugins scans the directories listed in the `PATH` environment variable, followed by the
+// managed directory, looking for files that are plugins. When the same plugin is found in several
+// directories the first one is the one that is executed.
+func FindPlugins() (result []Plugin, err error) {
+	defaultPath := filepath.SplitList(os.Getenv("PATH"))
+	newPath := uniquePath(defaultPath)
+	managedDir, dirErr := ManagedDir()
+	if dirErr == nil && !contains(newPath, managedDir) {
+		newPath = append(newPath, managedDir)
+	}
+
+	for _, dir := range newPath {
+		_, err = os.Stat(dir)
+		if os.IsNotExist(err) {
+			err = nil
+			continue
+		}
+		if err != nil {
+			return
+		}
+		var list []Plugin
+		list, err = ListPlugins(dir)
+		if err != nil {
+			return
+		}
+		result = append(result, list...)
+	}
+	return
+}
+
+// ListPlugins scans the given directory looking for files that are plugins.
+func ListPlugins(dir string) (result []Plugin, err error) {
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, item := range items {
+		if item.IsDir() {
+			continue
+		}
+		name := item.Name()
+		if !strings.HasPrefix(name, Prefix) || IsManifest(name) {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		var exec bool
+		exec, err = isExecutable(path)
+		if err != nil {
+			return
+		}
+		if runtime.GOOS == "windows" {
+			name = strings.TrimSuffix(name, ".exe")
+		}
+		plugin := Plugin{
+			Name:       name,
+			Path:       dir,
+			Executable: exec,
+		}
+		plugin.Manifest, plugin.ManifestError = LoadManifest(path)
+		result = append(result, plugin)
+	}
+	return
+}
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// uniquePath removes the duplicate items from the PATH, preserving the order, so that the plugins
+// are returned in the order they are looked up.
+func uniquePath(path []string) []string {
+	keys := make(map[string]bool)
+	uniPath := make([]string, 0)
+
+	for _, p := range path {
+		if p == "" {
+			p = "."
+		}
+		if keys[p] {
+			continue
+		}
+		keys[p] = true
+		uniPath = append(uniPath, p)
+	}
+
+	return uniPath
+}
+
+// detect if the plugin is excutable
+func isExecutable(file string) (bool, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return false, err
+	}
+
+	if runtime.GOOS == "windows" {
+		fileExt := strings.ToLower(filepath.Ext(file))
+
+		switch fileExt {
+		case ".bat", ".cmd", ".com", ".exe", ".ps1":
+			return true, nil
+		}
+		return false, nil
+	}
+
+	if m := info.Mode(); !m.IsDir() && m&0111 != 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
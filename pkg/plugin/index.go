@@ -0,0 +1,267 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the types and functions used to read plugin indexes.
+
+package plugin
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"gopkg.in/yaml.v3"
+)
+
+// IndexEnv is the environment variable that contains the default plugin index.
+const IndexEnv = "OCM_PLUGIN_INDEX"
+
+// Index contains the plugins available for installation.
+type Index struct {
+	// Location is the directory used to resolve relative paths of the index entries.
+	Location string `yaml:"-"`
+
+	Plugins []IndexPlugin `yaml:"plugins"`
+}
+
+// IndexPlugin describes a plugin of the index and its versions.
+type IndexPlugin struct {
+	Name        string         `yaml:"name"`
+	Description string         `yaml:"description"`
+	Versions    []IndexVersion `yaml:"versions"`
+
+	// location is the directory used to resolve relative paths of this entry.
+	location string
+}
+
+// IndexVersion describes a version of a plugin.
+type IndexVersion struct {
+	Version   string          `yaml:"version"`
+	Manifest  string          `yaml:"manifest"`
+	Artifacts []IndexArtifact `yaml:"artifacts"`
+}
+
+// IndexArtifact is the executable of a version of a plugin for an operating system and
+// architecture. Empty operating system or architecture match any.
+type IndexArtifact struct {
+	OS     string `yaml:"os"`
+	Arch   string `yaml:"arch"`
+	URL    string `yaml:"url"`
+	SHA256 string `yaml:"sha256"`
+}
+
+// DefaultIndex returns the index given in the OCM_PLUGIN_INDEX environment variable.
+func DefaultIndex() string {
+	return os.Getenv(IndexEnv)
+}
+
+// LoadIndex reads a plugin index. The source is a local path or a 'file://' URL of a YAML file
+// containing a list of plugins, or of a directory containing one YAML file per plugin.
+func LoadIndex(source string) (*Index, error) {
+	if source == "" {
+		return nil, fmt.Errorf("plugin index isn't set, use the --index flag or the %s "+
+			"environment variable", IndexEnv)
+	}
+	path, err := localPath(source, "")
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read plugin index '%s': %v", source, err)
+	}
+
+	index := &Index{}
+	if !info.IsDir() {
+		// #nosec G304
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("can't read plugin index '%s': %v", source, err)
+		}
+		err = yaml.Unmarshal(data, index)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse plugin index '%s': %v", source, err)
+		}
+		index.Location = filepath.Dir(path)
+	} else {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("can't read plugin index '%s': %v", source, err)
+		}
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+				continue
+			}
+			file := filepath.Join(path, entry.Name())
+			// #nosec G304
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("can't read plugin index file '%s': %v", file, err)
+			}
+			plugin := IndexPlugin{}
+			err = yaml.Unmarshal(data, &plugin)
+			if err != nil {
+				return nil, fmt.Errorf("can't parse plugin index file '%s': %v", file, err)
+			}
+			index.Plugins = append(index.Plugins, plugin)
+		}
+		index.Location = path
+	}
+
+	names := map[string]bool{}
+	for i := range index.Plugins {
+		plugin := &index.Plugins[i]
+		if plugin.Name == "" {
+			return nil, fmt.Errorf("plugin index '%s' contains a plugin without name", source)
+		}
+		if names[plugin.Name] {
+			return nil, fmt.Errorf("plugin index '%s' contains plugin '%s' more than once",
+				source, plugin.Name)
+		}
+		names[plugin.Name] = true
+		for _, item := range plugin.Versions {
+			_, err = version.NewVersion(item.Version)
+			if err != nil {
+				return nil, fmt.Errorf("plugin '%s' in index '%s' has invalid version '%s': %v",
+					plugin.Name, source, item.Version, err)
+			}
+		}
+		plugin.location = index.Location
+	}
+	return index, nil
+}
+
+// Plugin returns the plugin with the given name, or nil if it isn't in the index.
+func (i *Index) Plugin(name string) *IndexPlugin {
+	for j := range i.Plugins {
+		if i.Plugins[j].Name == name {
+			return &i.Plugins[j]
+		}
+	}
+	return nil
+}
+
+// Latest returns the most recent version of the plugin, or nil if it doesn't have versions.
+func (p *IndexPlugin) Latest() *IndexVersion {
+	var latest *IndexVersion
+	var latestVersion *version.Version
+	for i := range p.Versions {
+		current, err := version.NewVersion(p.Versions[i].Version)
+		if err != nil {
+			continue
+		}
+		if latestVersion == nil || current.GreaterThan(latestVersion) {
+			latest = &p.Versions[i]
+			latestVersion = current
+		}
+	}
+	return latest
+}
+
+// Version returns the given version of the plugin, or nil if it doesn't exist.
+func (p *IndexPlugin) Version(value string) *IndexVersion {
+	wanted, err := version.NewVersion(value)
+	if err != nil {
+		return nil
+	}
+	for i := range p.Versions {
+		current, err := version.NewVersion(p.Versions[i].Version)
+		if err == nil && current.Equal(wanted) {
+			return &p.Versions[i]
+		}
+	}
+	return nil
+}
+
+// VersionNames returns the versions of the plugin, sorted from the most recent.
+func (p *IndexPlugin) VersionNames() []string {
+	names := make([]string, 0, len(p.Versions))
+	for _, item := range p.Versions {
+		names = append(names, item.Version)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return IsNewer(names[i], names[j])
+	})
+	return names
+}
+
+// Artifact returns the artifact of the version for the current operating system and architecture.
+func (p *IndexPlugin) Artifact(item *IndexVersion) (*IndexArtifact, error) {
+	for i := range item.Artifacts {
+		artifact := &item.Artifacts[i]
+		if (artifact.OS == "" || artifact.OS == runtime.GOOS) &&
+			(artifact.Arch == "" || artifact.Arch == runtime.GOARCH) {
+			return artifact, nil
+		}
+	}
+	return nil, fmt.Errorf("version '%s' of plugin '%s' isn't available for %s/%s",
+		item.Version, p.Name, runtime.GOOS, runtime.GOARCH)
+}
+
+// Path returns the local path of a file referenced by the plugin entry.
+func (p *IndexPlugin) Path(reference string) (string, error) {
+	return localPath(reference, p.location)
+}
+
+// IsNewer returns true if version a is more recent than version b.
+func IsNewer(a, b string) bool {
+	va, err := version.NewVersion(a)
+	if err != nil {
+		return false
+	}
+	vb, err := version.NewVersion(b)
+	if err != nil {
+		return true
+	}
+	return va.GreaterThan(vb)
+}
+
+// localPath converts a local path or 'file://' URL to a path. Relative paths are resolved from
+// the given base directory.
+func localPath(reference string, base string) (string, error) {
+	path := reference
+	if strings.Contains(reference, "://") {
+		parsed, err := url.Parse(reference)
+		if err != nil {
+			return "", fmt.Errorf("can't parse URL '%s': %v", reference, err)
+		}
+		if parsed.Scheme != "file" {
+			return "", fmt.Errorf("URL '%s' isn't supported, only local paths and 'file://' "+
+				"URLs are supported", reference)
+		}
+		path = parsed.Path
+		if runtime.GOOS == "windows" {
+			path = strings.TrimPrefix(path, "/")
+		}
+	}
+	path = filepath.FromSlash(path)
+	if !filepath.IsAbs(path) && base != "" {
+		path = filepath.Join(base, path)
+	}
+	return path, nil
+}
+
+// ParseReference splits a plugin reference in 'NAME[@VERSION]' format.
+func ParseReference(reference string) (name string, version string) {
+	name, version, _ = strings.Cut(reference, "@")
+	return
+}
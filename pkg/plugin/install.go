@@ -0,0 +1,347 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to install plugins from an index into the directory
+// managed by ocm.
+
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v3"
+)
+
+// DirEnv is the environment variable that can be used to change the directory where plugins are
+// installed.
+const DirEnv = "OCM_PLUGINS_DIR"
+
+// Receipt records the installation of a plugin from an index.
+type Receipt struct {
+	Name      string    `yaml:"name"`
+	Version   string    `yaml:"version"`
+	Index     string    `yaml:"index"`
+	SHA256    string    `yaml:"sha256"`
+	Pinned    bool      `yaml:"pinned"`
+	Installed time.Time `yaml:"installed"`
+}
+
+var validName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ManagedDir returns the directory where plugins are installed. It is '~/.ocm/plugins' unless
+// the OCM_PLUGINS_DIR environment variable is set.
+func ManagedDir() (string, error) {
+	if dir := os.Getenv(DirEnv); dir != "" {
+		return dir, nil
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".ocm", "plugins"), nil
+}
+
+func executableName(name string) string {
+	file := Prefix + name
+	if runtime.GOOS == "windows" {
+		file += ".exe"
+	}
+	return file
+}
+
+func receiptPath(dir string, name string) string {
+	return filepath.Join(dir, "receipts", name+".yaml")
+}
+
+// LoadReceipt returns the receipt of the installed plugin, or nil if it wasn't installed from an
+// index.
+func LoadReceipt(name string) (*Receipt, error) {
+	dir, err := ManagedDir()
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G304
+	data, err := os.ReadFile(receiptPath(dir, name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't read receipt of plugin '%s': %v", name, err)
+	}
+	receipt := &Receipt{}
+	err = yaml.Unmarshal(data, receipt)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse receipt of plugin '%s': %v", name, err)
+	}
+	return receipt, nil
+}
+
+// LoadReceipts returns the receipts of all the plugins installed from indexes, sorted by name.
+func LoadReceipts() ([]*Receipt, error) {
+	dir, err := ManagedDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "receipts"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var receipts []*Receipt
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".yaml")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		receipt, err := LoadReceipt(name)
+		if err != nil {
+			return nil, err
+		}
+		if receipt != nil {
+			receipts = append(receipts, receipt)
+		}
+	}
+	sort.Slice(receipts, func(i, j int) bool {
+		return receipts[i].Name < receipts[j].Name
+	})
+	return receipts, nil
+}
+
+// Install copies the executable and manifest of the given version of a plugin into the managed
+// directory, verifying the checksum of the executable, and records the installation in a receipt.
+func Install(source string, plugin *IndexPlugin, item *IndexVersion, pinned bool) (*Receipt, error) {
+	if !validName.MatchString(plugin.Name) {
+		return nil, fmt.Errorf("plugin name '%s' isn't valid: it must contain only letters, "+
+			"digits, dashes and underscores", plugin.Name)
+	}
+	artifact, err := plugin.Artifact(item)
+	if err != nil {
+		return nil, err
+	}
+	// The index is saved in the receipt as an absolute path, so that upgrades work from any
+	// directory:
+	index, err := localPath(source, "")
+	if err != nil {
+		return nil, err
+	}
+	index, err = filepath.Abs(index)
+	if err != nil {
+		return nil, fmt.Errorf("can't get absolute path of plugin index '%s': %v", source, err)
+	}
+	if artifact.SHA256 == "" {
+		return nil, fmt.Errorf("version '%s' of plugin '%s' doesn't have a checksum",
+			item.Version, plugin.Name)
+	}
+	path, err := plugin.Path(artifact.URL)
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G304
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read executable of plugin '%s': %v", plugin.Name, err)
+	}
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	if !strings.EqualFold(checksum, artifact.SHA256) {
+		return nil, fmt.Errorf("checksum of executable '%s' of plugin '%s' is '%s' but the index "+
+			"expects '%s'", path, plugin.Name, checksum, artifact.SHA256)
+	}
+
+	var manifest []byte
+	if item.Manifest != "" {
+		manifestPath, err := plugin.Path(item.Manifest)
+		if err != nil {
+			return nil, err
+		}
+		// #nosec G304
+		manifest, err = os.ReadFile(manifestPath)
+		if err != nil {
+			return nil, fmt.Errorf("can't read manifest of plugin '%s': %v", plugin.Name, err)
+		}
+		_, err = ParseManifest(manifestPath, manifest)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dir, err := ManagedDir()
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(filepath.Join(dir, "receipts"), 0700)
+	if err != nil {
+		return nil, fmt.Errorf("can't create plugins directory '%s': %v", dir, err)
+	}
+
+	executable := filepath.Join(dir, executableName(plugin.Name))
+	err = writeFile(executable, data, 0700)
+	if err != nil {
+		return nil, err
+	}
+	if manifest != nil {
+		err = writeFile(ManifestPath(executable), manifest, 0600)
+	} else {
+		err = os.Remove(ManifestPath(executable))
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &Receipt{
+		Name:      plugin.Name,
+		Version:   item.Version,
+		Index:     index,
+		SHA256:    checksum,
+		Pinned:    pinned,
+		Installed: time.Now().UTC().Truncate(time.Second),
+	}
+	receiptData, err := yaml.Marshal(receipt)
+	if err != nil {
+		return nil, err
+	}
+	err = writeFile(receiptPath(dir, plugin.Name), receiptData, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// Remove deletes the executable, manifest and receipt of a plugin installed from an index.
+func Remove(name string) error {
+	receipt, err := LoadReceipt(name)
+	if err != nil {
+		return err
+	}
+	if receipt == nil {
+		return fmt.Errorf("plugin '%s' wasn't installed with 'ocm plugin install'", name)
+	}
+	dir, err := ManagedDir()
+	if err != nil {
+		return err
+	}
+	executable := filepath.Join(dir, executableName(name))
+	for _, path := range []string{executable, ManifestPath(executable), receiptPath(dir, name)} {
+		err = os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("can't remove plugin '%s': %v", name, err)
+		}
+	}
+	return nil
+}
+
+// writeFile writes the file atomically, so that a running plugin is never replaced by a partially
+// written one. The temporary file is hidden, so that discovery never mistakes it for a plugin, and
+// it is removed if anything fails.
+func writeFile(path string, data []byte, mode os.FileMode) (err error) {
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	defer func() {
+		if err != nil {
+			os.Remove(tmp)
+		}
+	}()
+	err = os.WriteFile(tmp, data, mode)
+	if err != nil {
+		return fmt.Errorf("can't write file '%s': %v", tmp, err)
+	}
+	err = os.Chmod(tmp, mode)
+	if err != nil {
+		return fmt.Errorf("can't change permissions of file '%s': %v", tmp, err)
+	}
+	err = os.Rename(tmp, path)
+	if err != nil {
+		return fmt.Errorf("can't rename file '%s' to '%s': %v", tmp, path, err)
+	}
+	return nil
+}
+
+// Update describes a plugin installed from an index for which a newer version is available, or
+// whose index can't be loaded, in which case Err contains the reason.
+type Update struct {
+	Name      string
+	Installed string
+	Latest    string
+	Pinned    bool
+	Err       error
+}
+
+// FindUpdates compares the installed plugins with the most recent versions available in their
+// indexes. When source isn't empty it is used instead of the index each plugin was installed
+// from. Failures to load the index of a plugin are reported in its update instead of failing
+// for all the plugins.
+func FindUpdates(source string) ([]*Update, error) {
+	receipts, err := LoadReceipts()
+	if err != nil {
+		return nil, err
+	}
+	indexes := map[string]*Index{}
+	failures := map[string]error{}
+	var updates []*Update
+	for _, receipt := range receipts {
+		location := source
+		if location == "" {
+			location = receipt.Index
+		}
+		index, ok := indexes[location]
+		if !ok {
+			err, failed := failures[location]
+			if !failed {
+				index, err = LoadIndex(location)
+			}
+			if err != nil {
+				failures[location] = err
+				updates = append(updates, &Update{
+					Name:      receipt.Name,
+					Installed: receipt.Version,
+					Pinned:    receipt.Pinned,
+					Err:       err,
+				})
+				continue
+			}
+			indexes[location] = index
+		}
+		entry := index.Plugin(receipt.Name)
+		if entry == nil {
+			continue
+		}
+		latest := entry.Latest()
+		if latest == nil || !IsNewer(latest.Version, receipt.Version) {
+			continue
+		}
+		updates = append(updates, &Update{
+			Name:      receipt.Name,
+			Installed: receipt.Version,
+			Latest:    latest.Version,
+			Pinned:    receipt.Pinned,
+		})
+	}
+	return updates, nil
+}
@@ -0,0 +1,150 @@
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Install", func() {
+	var indexDir string
+	var pluginsDir string
+
+	writeVersion := func(version string) string {
+		data := []byte("#!/bin/sh\necho " + version + "\n")
+		file := filepath.Join(indexDir, "hello-"+version)
+		Expect(os.WriteFile(file, data, 0600)).To(Succeed())
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	}
+
+	BeforeEach(func() {
+		indexDir = GinkgoT().TempDir()
+		pluginsDir = GinkgoT().TempDir()
+		GinkgoT().Setenv(DirEnv, pluginsDir)
+
+		sum1 := writeVersion("1.0.0")
+		sum2 := writeVersion("1.10.0")
+		Expect(os.WriteFile(filepath.Join(indexDir, "hello.manifest.yaml"),
+			[]byte("name: hello\nversion: 1.10.0\n"), 0600)).To(Succeed())
+		index := fmt.Sprintf(`
+plugins:
+- name: hello
+  description: Say hello
+  versions:
+  - version: 1.0.0
+    artifacts:
+    - url: hello-1.0.0
+      sha256: %s
+  - version: 1.10.0
+    manifest: hello.manifest.yaml
+    artifacts:
+    - url: hello-1.10.0
+      sha256: %s
+`, sum1, sum2)
+		Expect(os.WriteFile(filepath.Join(indexDir, "index.yaml"), []byte(index), 0600)).To(Succeed())
+	})
+
+	It("selects the most recent version", func() {
+		index, err := LoadIndex("file://" + filepath.Join(indexDir, "index.yaml"))
+		Expect(err).ToNot(HaveOccurred())
+		entry := index.Plugin("hello")
+		Expect(entry).ToNot(BeNil())
+		Expect(entry.Latest().Version).To(Equal("1.10.0"))
+		Expect(entry.Version("1.0.0")).ToNot(BeNil())
+		Expect(entry.Version("2.0.0")).To(BeNil())
+		Expect(index.Plugin("bye")).To(BeNil())
+	})
+
+	It("installs, reports updates and removes a plugin", func() {
+		source := filepath.Join(indexDir, "index.yaml")
+		index, err := LoadIndex(source)
+		Expect(err).ToNot(HaveOccurred())
+		entry := index.Plugin("hello")
+
+		receipt, err := Install(source, entry, entry.Version("1.0.0"), false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(receipt.Version).To(Equal("1.0.0"))
+		Expect(filepath.Join(pluginsDir, "ocm-hello")).To(BeARegularFile())
+
+		updates, err := FindUpdates("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updates).To(HaveLen(1))
+		Expect(updates[0].Latest).To(Equal("1.10.0"))
+
+		_, err = Install(source, entry, entry.Latest(), false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filepath.Join(pluginsDir, "ocm-hello.manifest.yaml")).To(BeARegularFile())
+		updates, err = FindUpdates("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updates).To(BeEmpty())
+
+		Expect(Remove("hello")).To(Succeed())
+		Expect(filepath.Join(pluginsDir, "ocm-hello")).ToNot(BeAnExistingFile())
+		receipt, err = LoadReceipt("hello")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(receipt).To(BeNil())
+	})
+
+	It("saves the absolute path of the index and reports indexes that can't be loaded", func() {
+		wd, err := os.Getwd()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.Chdir(indexDir)).To(Succeed())
+		DeferCleanup(os.Chdir, wd)
+
+		index, err := LoadIndex("index.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		entry := index.Plugin("hello")
+		receipt, err := Install("index.yaml", entry, entry.Version("1.0.0"), false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filepath.IsAbs(receipt.Index)).To(BeTrue())
+
+		Expect(os.Chdir(wd)).To(Succeed())
+		updates, err := FindUpdates("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updates).To(HaveLen(1))
+		Expect(updates[0].Err).ToNot(HaveOccurred())
+		Expect(updates[0].Latest).To(Equal("1.10.0"))
+
+		Expect(os.Remove(filepath.Join(indexDir, "index.yaml"))).To(Succeed())
+		updates, err = FindUpdates("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updates).To(HaveLen(1))
+		Expect(updates[0].Name).To(Equal("hello"))
+		Expect(updates[0].Err).To(MatchError(ContainSubstring("can't read plugin index")))
+	})
+
+	It("rejects executables with a wrong checksum", func() {
+		Expect(os.WriteFile(filepath.Join(indexDir, "hello-1.0.0"), []byte("tampered"),
+			0600)).To(Succeed())
+		index, err := LoadIndex(filepath.Join(indexDir, "index.yaml"))
+		Expect(err).ToNot(HaveOccurred())
+		entry := index.Plugin("hello")
+		_, err = Install(indexDir, entry, entry.Version("1.0.0"), true)
+		Expect(err).To(MatchError(ContainSubstring("checksum")))
+		Expect(filepath.Join(pluginsDir, "ocm-hello")).ToNot(BeAnExistingFile())
+	})
+
+	It("rejects remote indexes", func() {
+		_, err := LoadIndex("https://example.com/index.yaml")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Write file", func() {
+	It("removes the temporary file when it fails", func() {
+		dir := GinkgoT().TempDir()
+		path := filepath.Join(dir, "ocm-hello")
+		Expect(os.MkdirAll(filepath.Join(path, "busy"), 0700)).To(Succeed())
+		Expect(writeFile(path, []byte("#!/bin/sh\n"), 0700)).ToNot(Succeed())
+		entries, err := os.ReadDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Name()).To(Equal("ocm-hello"))
+	})
+})
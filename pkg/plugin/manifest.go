@@ -0,0 +1,189 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the types and functions used to read the optional manifests that describe
+// plugins.
+
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestSuffix is appended to the path of a plugin executable, without the '.exe' extension on
+// Windows, to obtain the path of its manifest. For example, the manifest of '/usr/bin/ocm-foo' is
+// '/usr/bin/ocm-foo.manifest.yaml'.
+const ManifestSuffix = ".manifest.yaml"
+
+// Values of the credentials field of the manifest.
+const (
+	// CredentialsNone means that the plugin doesn't receive credentials. This is the default.
+	CredentialsNone = "none"
+
+	// CredentialsEnv means that the plugin receives the API URL and the access token in the
+	// OCM_PLUGIN_API_URL and OCM_PLUGIN_ACCESS_TOKEN environment variables.
+	CredentialsEnv = "env"
+
+	// CredentialsFD means that the plugin receives a JSON document containing the API URL, the
+	// access token and its expiration time from the file descriptor whose number is in the
+	// OCM_PLUGIN_CREDENTIALS_FD environment variable.
+	CredentialsFD = "fd"
+)
+
+// Manifest describes a plugin, so that it can be shown in the help and completions of the ocm
+// command.
+type Manifest struct {
+	Name        string            `yaml:"name" json:"name"`
+	Version     string            `yaml:"version" json:"version,omitempty"`
+	Description string            `yaml:"description" json:"description,omitempty"`
+	Credentials string            `yaml:"credentials" json:"credentials,omitempty"`
+	Flags       []ManifestFlag    `yaml:"flags" json:"flags,omitempty"`
+	Commands    []ManifestCommand `yaml:"commands" json:"commands,omitempty"`
+}
+
+// ManifestCommand describes a subcommand of a plugin.
+type ManifestCommand struct {
+	Name        string            `yaml:"name" json:"name"`
+	Description string            `yaml:"description" json:"description,omitempty"`
+	Flags       []ManifestFlag    `yaml:"flags" json:"flags,omitempty"`
+	Commands    []ManifestCommand `yaml:"commands" json:"commands,omitempty"`
+}
+
+// ManifestFlag describes a flag of a plugin or of one of its subcommands. The type can be 'string',
+// 'bool' or 'int', and defaults to 'string'.
+type ManifestFlag struct {
+	Name        string `yaml:"name" json:"name"`
+	Shorthand   string `yaml:"shorthand" json:"shorthand,omitempty"`
+	Description string `yaml:"description" json:"description,omitempty"`
+	Type        string `yaml:"type" json:"type,omitempty"`
+}
+
+// ManifestPath returns the path of the manifest of the given plugin executable.
+func ManifestPath(executablePath string) string {
+	if runtime.GOOS == "windows" {
+		executablePath = strings.TrimSuffix(executablePath, ".exe")
+	}
+	return executablePath + ManifestSuffix
+}
+
+// IsManifest returns true if the given file name is the name of a plugin manifest.
+func IsManifest(name string) bool {
+	return strings.HasSuffix(name, ManifestSuffix)
+}
+
+// LoadManifest reads the manifest of the given plugin executable. It returns nil if the plugin
+// doesn't have a manifest.
+func LoadManifest(executablePath string) (*Manifest, error) {
+	path := ManifestPath(executablePath)
+	// #nosec G304
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't read plugin manifest '%s': %v", path, err)
+	}
+	return ParseManifest(path, data)
+}
+
+// ParseManifest parses and validates the YAML or JSON manifest of a plugin. The path is only used
+// in error messages.
+func ParseManifest(path string, data []byte) (*Manifest, error) {
+	manifest := &Manifest{}
+	err := yaml.Unmarshal(data, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse plugin manifest '%s': %v", path, err)
+	}
+	if manifest.Name == "" {
+		return nil, fmt.Errorf("plugin manifest '%s' doesn't have a name", path)
+	}
+	switch manifest.Credentials {
+	case "":
+		manifest.Credentials = CredentialsNone
+	case CredentialsNone, CredentialsEnv, CredentialsFD:
+	default:
+		return nil, fmt.Errorf(
+			"plugin manifest '%s' has unknown credentials mode '%s', valid values are "+
+				"'%s', '%s' and '%s'",
+			path, manifest.Credentials, CredentialsNone, CredentialsEnv, CredentialsFD,
+		)
+	}
+	err = validateFlags(path, manifest.Flags)
+	if err != nil {
+		return nil, err
+	}
+	err = validateCommands(path, manifest.Commands)
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func validateCommands(path string, commands []ManifestCommand) error {
+	for _, command := range commands {
+		if command.Name == "" {
+			return fmt.Errorf("plugin manifest '%s' has a command without name", path)
+		}
+		err := validateFlags(path, command.Flags)
+		if err != nil {
+			return err
+		}
+		err = validateCommands(path, command.Commands)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateFlags(path string, flags []ManifestFlag) error {
+	// Flags are added to the commands, and duplicates would make the flag set panic:
+	names := map[string]bool{}
+	shorthands := map[string]bool{}
+	for _, flag := range flags {
+		if flag.Name == "" {
+			return fmt.Errorf("plugin manifest '%s' has a flag without name", path)
+		}
+		if names[flag.Name] {
+			return fmt.Errorf("plugin manifest '%s' has more than one flag named '%s'",
+				path, flag.Name)
+		}
+		names[flag.Name] = true
+		if len(flag.Shorthand) > 1 {
+			return fmt.Errorf("shorthand of flag '%s' in plugin manifest '%s' must be one letter",
+				flag.Name, path)
+		}
+		if flag.Shorthand != "" {
+			if shorthands[flag.Shorthand] {
+				return fmt.Errorf("plugin manifest '%s' has more than one flag with shorthand '%s'",
+					path, flag.Shorthand)
+			}
+			shorthands[flag.Shorthand] = true
+		}
+		switch flag.Type {
+		case "", "string", "bool", "int":
+		default:
+			return fmt.Errorf("flag '%s' in plugin manifest '%s' has unknown type '%s'",
+				flag.Name, path, flag.Type)
+		}
+	}
+	return nil
+}
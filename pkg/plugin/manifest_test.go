@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse manifest", func() {
+	It("parses a complete manifest", func() {
+		manifest, err := ParseManifest("test", []byte(`
+name: hello
+version: 1.2.0
+description: Say hello
+credentials: env
+flags:
+- name: loud
+  shorthand: l
+  type: bool
+commands:
+- name: world
+  flags:
+  - name: times
+    type: int
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest.Name).To(Equal("hello"))
+		Expect(manifest.Version).To(Equal("1.2.0"))
+		Expect(manifest.Credentials).To(Equal(CredentialsEnv))
+		Expect(manifest.Flags).To(HaveLen(1))
+		Expect(manifest.Commands).To(HaveLen(1))
+		Expect(manifest.Commands[0].Flags[0].Type).To(Equal("int"))
+	})
+	It("doesn't pass credentials by default", func() {
+		manifest, err := ParseManifest("test", []byte(`{"name": "hello"}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest.Credentials).To(Equal(CredentialsNone))
+	})
+	It("rejects invalid manifests", func() {
+		invalid := []string{
+			`version: 1.0.0`,
+			`{name: hello, credentials: file}`,
+			`{name: hello, flags: [{name: loud, type: float}]}`,
+			`{name: hello, flags: [{name: loud, shorthand: ll}]}`,
+			`{name: hello, commands: [{description: nameless}]}`,
+			`{name: hello, flags: [{name: loud}, {name: loud, type: bool}]}`,
+			`{name: hello, flags: [{name: loud, shorthand: l}, {name: long, shorthand: l}]}`,
+			`{name: hello, commands: [{name: world, flags: [{name: times}, {name: times}]}]}`,
+		}
+		for _, data := range invalid {
+			_, err := ParseManifest("test", []byte(data))
+			Expect(err).To(HaveOccurred(), data)
+		}
+	})
+})
+
+var _ = Describe("Execute", func() {
+	var dir string
+	var executable string
+	var output string
+
+	BeforeEach(func() {
+		if runtime.GOOS == "windows" {
+			Skip("plugin scripts need a POSIX shell")
+		}
+		dir = GinkgoT().TempDir()
+		output = filepath.Join(dir, "output")
+		executable = filepath.Join(dir, "ocm-hello")
+		script := "#!/bin/sh\n" +
+			"{\n" +
+			"  echo \"url=$OCM_PLUGIN_API_URL\"\n" +
+			"  echo \"token=$OCM_PLUGIN_ACCESS_TOKEN\"\n" +
+			"  if [ -n \"$OCM_PLUGIN_CREDENTIALS_FD\" ]; then cat <&3; fi\n" +
+			"} > " + output + "\n"
+		err := os.WriteFile(executable, []byte(script), 0700) // #nosec G306
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	run := func(credentials string) string {
+		if credentials != "" {
+			manifest := "name: hello\ncredentials: " + credentials + "\n"
+			err := os.WriteFile(ManifestPath(executable), []byte(manifest), 0600)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		handler := &DefaultHandler{
+			Credentials: func() (*Credentials, error) {
+				expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+				return &Credentials{
+					URL:         "https://api.example.com",
+					AccessToken: "my-token",
+					ExpiresAt:   &expiresAt,
+				}, nil
+			},
+		}
+		environment := []string{"OCM_PLUGIN_ACCESS_TOKEN=inherited"}
+		err := handler.Execute(executable, nil, environment)
+		Expect(err).ToNot(HaveOccurred())
+		data, err := os.ReadFile(output)
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	It("doesn't pass credentials to plugins without manifest", func() {
+		Expect(run("")).To(Equal("url=\ntoken=\n"))
+	})
+	It("passes credentials in environment variables", func() {
+		Expect(run(CredentialsEnv)).To(Equal("url=https://api.example.com\ntoken=my-token\n"))
+	})
+	It("passes credentials in a file descriptor", func() {
+		Expect(run(CredentialsFD)).To(Equal(
+			"url=https://api.example.com\ntoken=\n" +
+				`{"url":"https://api.example.com","access_token":"my-token",` +
+				`"expires_at":"2030-01-01T00:00:00Z"}`,
+		))
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
@@ -25,6 +26,9 @@ type Handler interface {
 // DefaultHandler implements Handler
 type DefaultHandler struct {
 	ValidPrefixes []string
+
+	// Credentials returns the credentials passed to plugins whose manifest asks for them.
+	Credentials func() (*Credentials, error)
 }
 
 // NewDefaultPluginHandler instantiates the DefaultPluginHandler with a list of
@@ -32,10 +36,12 @@ type DefaultHandler struct {
 func NewDefaultPluginHandler(validPrefixes []string) Handler {
 	return &DefaultHandler{
 		ValidPrefixes: validPrefixes,
+		Credentials:   LoadCredentials,
 	}
 }
 
-// Lookup implements Handler
+// Lookup implements Handler. Plugins in the PATH take precedence over plugins installed in the
+// managed directory.
 func (h *DefaultHandler) Lookup(filename string) (string, bool) {
 	for _, prefix := range h.ValidPrefixes {
 		path, err := exec.LookPath(fmt.Sprintf("%s-%s", prefix, filename))
@@ -45,6 +51,18 @@ func (h *DefaultHandler) Lookup(filename string) (string, bool) {
 		return path, true
 	}
 
+	dir, err := ManagedDir()
+	if err != nil {
+		return "", false
+	}
+	for _, prefix := range h.ValidPrefixes {
+		path, err := exec.LookPath(filepath.Join(dir, fmt.Sprintf("%s-%s", prefix, filename)))
+		if err != nil || len(path) == 0 {
+			continue
+		}
+		return path, true
+	}
+
 	return "", false
 }
 
@@ -55,10 +73,52 @@ func (h *DefaultHandler) Execute(executablePath string, cmdArgs, environment []s
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
-	cmd.Env = environment
+	cmd.Env = removeCredentials(environment)
+
+	// Warn about other plugins that may have been intended:
+	plugins, err := FindPlugins()
+	if err == nil {
+		for _, problem := range Ambiguities(plugins, executablePath) {
+			fmt.Fprintf(os.Stderr, "Warning: plugin is ambiguous: %s\n", problem.Message)
+		}
+	}
+
+	// Pass the credentials if the manifest of the plugin asks for them:
+	manifest, err := LoadManifest(executablePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if manifest != nil && manifest.Credentials != CredentialsNone && h.Credentials != nil {
+		credentials, err := h.Credentials()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: can't pass credentials to plugin '%s': %v\n",
+				manifest.Name, err)
+		} else {
+			done, err := applyCredentials(cmd, manifest.Credentials, credentials)
+			defer done()
+			if err != nil {
+				return fmt.Errorf("can't pass credentials to plugin '%s': %v", manifest.Name, err)
+			}
+		}
+	}
+
 	return cmd.Run()
 }
 
+// removeCredentials removes from the environment the variables used to pass credentials, so that
+// plugins never receive credentials that weren't explicitly passed to them.
+func removeCredentials(environment []string) []string {
+	result := make([]string, 0, len(environment))
+	for _, variable := range environment {
+		name, _, _ := strings.Cut(variable, "=")
+		if name == EnvAPIURL || name == EnvAccessToken || name == EnvCredentialsFD {
+			continue
+		}
+		result = append(result, variable)
+	}
+	return result
+}
+
 // HandlePluginCommand receives a pluginHandler and command-line arguments and attempts to find
 // a plugin executable on the PATH that satisfies the given arguments.
 func HandlePluginCommand(pluginHandler Handler, cmdArgs []string) (found bool, err error) {
@@ -0,0 +1,486 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the checks that are run before creating a GCP cluster, to detect problems
+// with the network and the credentials that would otherwise make the installation fail.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"text/tabwriter"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/gcp"
+	"github.com/openshift-online/ocm-cli/pkg/network"
+	"github.com/openshift-online/ocm-cli/pkg/utils"
+)
+
+// Statuses of the preflight checks:
+const (
+	PreflightPassed  = "passed"
+	PreflightWarning = "warning"
+	PreflightFailed  = "failed"
+	PreflightSkipped = "skipped"
+)
+
+// Names of the preflight checks:
+const (
+	PreflightCheckCIDRs       = "cidrs"
+	PreflightCheckVPC         = "vpc"
+	PreflightCheckSubnets     = "subnets"
+	PreflightCheckSubnetCIDRs = "subnet-cidrs"
+	PreflightCheckPSC         = "psc-subnet"
+	PreflightCheckSharedVPC   = "shared-vpc"
+	PreflightCheckWIF         = "wif"
+)
+
+// PSCSubnetPurpose is the purpose that the subnet used for Private Service Connect must have.
+const PSCSubnetPurpose = "PRIVATE_SERVICE_CONNECT"
+
+// NetworkUserRole is the role that the installer needs in the host project of a shared VPC.
+const NetworkUserRole = "roles/compute.networkUser"
+
+// PreflightResult is the result of one of the preflight checks.
+type PreflightResult struct {
+	Check   string `json:"check"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// GCPPreflight checks that a GCP cluster can be created with the given configuration. The
+// existence of the VPC and the subnets is checked with the OCM API. The details of the subnets
+// and the permissions in the host project of a shared VPC need the GCP API, and those checks
+// are skipped if no network client is given.
+type GCPPreflight struct {
+	spec    cluster.Spec
+	network gcp.NetworkClient
+
+	// networkError is the reason why the network client isn't available.
+	networkError error
+
+	// These can be replaced in tests:
+	listVPCs     func() ([]*cmv1.CloudVPC, error)
+	getWifConfig func(id string) (*cmv1.WifConfig, *cmv1.WifConfigStatus, error)
+
+	wifConfig *cmv1.WifConfig
+	results   []PreflightResult
+}
+
+// NewGCPPreflight creates the preflight checks for the given cluster configuration.
+func NewGCPPreflight(client *cmv1.Client, spec cluster.Spec) *GCPPreflight {
+	return &GCPPreflight{
+		spec: spec,
+		listVPCs: func() ([]*cmv1.CloudVPC, error) {
+			return GetGCPVPCs(client, spec.CCS, spec.GcpAuthentication, spec.Region)
+		},
+		getWifConfig: func(id string) (*cmv1.WifConfig, *cmv1.WifConfigStatus, error) {
+			wifClient := client.GCP().WifConfigs().WifConfig(id)
+			config, err := wifClient.Get().Send()
+			if err != nil {
+				return nil, nil, err
+			}
+			status, err := wifClient.Status().Get().Send()
+			if err != nil {
+				return config.Body(), nil, err
+			}
+			return config.Body(), status.Body(), nil
+		},
+	}
+}
+
+// Network sets the client used to read the details of the GCP networks. If the error isn't nil
+// it is the reason why the client isn't available, and it is reported in the skipped checks.
+func (p *GCPPreflight) Network(client gcp.NetworkClient, err error) *GCPPreflight {
+	p.network = client
+	p.networkError = err
+	return p
+}
+
+// Run runs the checks and returns their results.
+func (p *GCPPreflight) Run(ctx context.Context) []PreflightResult {
+	p.results = nil
+	p.checkCIDRs()
+	project := p.checkWIF()
+	if p.spec.ExistingVPC.VPCName == "" {
+		p.add(PreflightCheckVPC, PreflightSkipped, "The installer creates the VPC")
+		return p.results
+	}
+	networkProject := project
+	if p.spec.ExistingVPC.VPCProjectID != "" {
+		networkProject = p.spec.ExistingVPC.VPCProjectID
+	}
+	p.checkVPC(ctx, networkProject)
+	subnets := p.checkSubnets(ctx, networkProject)
+	p.checkSubnetCIDRs(subnets)
+	p.checkPSC(ctx, networkProject)
+	p.checkSharedVPC(ctx, project)
+	return p.results
+}
+
+// Failed returns true if any of the results is a failure.
+func Failed(results []PreflightResult) bool {
+	for _, result := range results {
+		if result.Status == PreflightFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintPreflightResults writes a table with the results of the preflight checks.
+func PrintPreflightResults(stream io.Writer, results []PreflightResult) error {
+	writer := tabwriter.NewWriter(stream, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "CHECK\tSTATUS\tMESSAGE\n")
+	for _, result := range results {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", result.Check, result.Status, result.Message)
+	}
+	return writer.Flush()
+}
+
+func (p *GCPPreflight) add(check, status, format string, args ...interface{}) {
+	p.results = append(p.results, PreflightResult{
+		Check:   check,
+		Status:  status,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// clusterCIDRs returns the machine, service and pod CIDRs that are set, with their names.
+func (p *GCPPreflight) clusterCIDRs() (names []string, cidrs []*net.IPNet) {
+	for _, item := range []struct {
+		name string
+		cidr net.IPNet
+	}{
+		{"machine", p.spec.MachineCIDR},
+		{"service", p.spec.ServiceCIDR},
+		{"pod", p.spec.PodCIDR},
+	} {
+		if item.cidr.IP == nil {
+			continue
+		}
+		cidr := item.cidr
+		names = append(names, item.name)
+		cidrs = append(cidrs, &cidr)
+	}
+	return
+}
+
+func (p *GCPPreflight) checkCIDRs() {
+	_, cidrs := p.clusterCIDRs()
+	if len(cidrs) == 0 {
+		p.add(PreflightCheckCIDRs, PreflightSkipped, "No CIDRs given, the defaults will be used")
+		return
+	}
+	problems := cluster.NetworkPlan(p.spec).Validate()
+	if len(problems) > 0 {
+		p.add(PreflightCheckCIDRs, PreflightFailed, "%v", problems[0])
+		return
+	}
+	p.add(PreflightCheckCIDRs, PreflightPassed, "The machine, service and pod CIDRs don't overlap")
+}
+
+// checkWIF checks that the WIF configuration is ready, and returns the GCP project of the
+// cluster.
+func (p *GCPPreflight) checkWIF() string {
+	if p.spec.GcpAuthentication.Type != cluster.AuthenticationWif {
+		p.add(PreflightCheckWIF, PreflightSkipped, "The cluster uses a service account key")
+		return p.spec.CCS.GCP.ProjectID
+	}
+	config, status, err := p.getWifConfig(p.spec.GcpAuthentication.Id)
+	p.wifConfig = config
+	project := config.Gcp().ProjectId()
+	switch {
+	case err != nil:
+		p.add(PreflightCheckWIF, PreflightFailed, "Failed to get WIF configuration '%s': %v",
+			p.spec.GcpAuthentication.Id, err)
+	case !status.Configured():
+		p.add(PreflightCheckWIF, PreflightFailed, "WIF configuration '%s' isn't ready: %s, run "+
+			"'ocm gcp update wif-config' to fix it", config.DisplayName(), status.Description())
+	default:
+		p.add(PreflightCheckWIF, PreflightPassed, "WIF configuration '%s' is ready in project '%s'",
+			config.DisplayName(), project)
+	}
+	return project
+}
+
+func (p *GCPPreflight) checkVPC(ctx context.Context, project string) {
+	name := p.spec.ExistingVPC.VPCName
+
+	// The OCM API only returns the VPCs of the project of the cluster, so a shared VPC needs to
+	// be checked with the GCP API:
+	if p.spec.ExistingVPC.VPCProjectID != "" {
+		if p.network == nil {
+			p.add(PreflightCheckVPC, PreflightSkipped, "Can't check shared VPC '%s': %v", name,
+				p.networkError)
+			return
+		}
+		exists, err := p.network.NetworkExists(ctx, project, name)
+		switch {
+		case err != nil:
+			p.add(PreflightCheckVPC, PreflightFailed, "Failed to check VPC '%s' in host project "+
+				"'%s': %v", name, project, err)
+		case !exists:
+			p.add(PreflightCheckVPC, PreflightFailed, "VPC '%s' doesn't exist in host project '%s'",
+				name, project)
+		default:
+			p.add(PreflightCheckVPC, PreflightPassed, "VPC '%s' exists in host project '%s'",
+				name, project)
+		}
+		return
+	}
+
+	vpc, err := p.findVPC()
+	switch {
+	case err != nil:
+		p.add(PreflightCheckVPC, PreflightFailed, "Failed to list the VPCs of region '%s': %v",
+			p.spec.Region, err)
+	case vpc == nil:
+		p.add(PreflightCheckVPC, PreflightFailed, "VPC '%s' doesn't exist in region '%s'", name,
+			p.spec.Region)
+	default:
+		p.add(PreflightCheckVPC, PreflightPassed, "VPC '%s' exists in region '%s'", name,
+			p.spec.Region)
+	}
+}
+
+func (p *GCPPreflight) findVPC() (*cmv1.CloudVPC, error) {
+	vpcs, err := p.listVPCs()
+	if err != nil {
+		return nil, err
+	}
+	for _, vpc := range vpcs {
+		if vpc.Name() == p.spec.ExistingVPC.VPCName {
+			return vpc, nil
+		}
+	}
+	return nil, nil
+}
+
+// subnet checks that a subnet exists in the region and belongs to the VPC. It returns the
+// details of the subnet if the GCP API is available, and an error message if the subnet isn't
+// valid.
+func (p *GCPPreflight) subnet(ctx context.Context, project, name string) (*gcp.Subnet, string) {
+	vpc := p.spec.ExistingVPC.VPCName
+	if p.network != nil {
+		subnet, err := p.network.Subnet(ctx, project, p.spec.Region, name)
+		switch {
+		case err != nil:
+			return nil, fmt.Sprintf("Failed to get subnet '%s': %v", name, err)
+		case subnet == nil:
+			return nil, fmt.Sprintf("Subnet '%s' doesn't exist in region '%s' of project '%s'",
+				name, p.spec.Region, project)
+		case subnet.Network != vpc:
+			return nil, fmt.Sprintf("Subnet '%s' belongs to VPC '%s' instead of '%s'", name,
+				subnet.Network, vpc)
+		}
+		return subnet, ""
+	}
+	found, err := p.findVPC()
+	if err != nil {
+		return nil, fmt.Sprintf("Failed to list the VPCs of region '%s': %v", p.spec.Region, err)
+	}
+	if found == nil || !utils.Contains(found.Subnets(), name) {
+		return nil, fmt.Sprintf("Subnet '%s' doesn't exist in VPC '%s' in region '%s'", name,
+			vpc, p.spec.Region)
+	}
+	return nil, ""
+}
+
+func (p *GCPPreflight) checkSubnets(ctx context.Context, project string) []*gcp.Subnet {
+	if p.network == nil && p.spec.ExistingVPC.VPCProjectID != "" {
+		p.add(PreflightCheckSubnets, PreflightSkipped, "Can't check the subnets of a shared VPC: "+
+			"%v", p.networkError)
+		return nil
+	}
+	var subnets []*gcp.Subnet
+	names := []string{p.spec.ExistingVPC.ControlPlaneSubnet, p.spec.ExistingVPC.ComputeSubnet}
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		subnet, problem := p.subnet(ctx, project, name)
+		if problem != "" {
+			p.add(PreflightCheckSubnets, PreflightFailed, "%s", problem)
+			return nil
+		}
+		if subnet != nil {
+			subnets = append(subnets, subnet)
+		}
+	}
+	p.add(PreflightCheckSubnets, PreflightPassed, "Subnets '%s' and '%s' exist in VPC '%s' in "+
+		"region '%s'", names[0], names[1], p.spec.ExistingVPC.VPCName, p.spec.Region)
+	return subnets
+}
+
+func (p *GCPPreflight) checkSubnetCIDRs(subnets []*gcp.Subnet) {
+	if len(subnets) == 0 {
+		reason := "the subnets aren't valid"
+		if p.network == nil {
+			reason = fmt.Sprintf("%v", p.networkError)
+		}
+		p.add(PreflightCheckSubnetCIDRs, PreflightSkipped, "Can't check the CIDRs of the "+
+			"subnets: %s", reason)
+		return
+	}
+	names, cidrs := p.clusterCIDRs()
+	for _, subnet := range subnets {
+		_, primary, err := net.ParseCIDR(subnet.CIDR)
+		if err != nil {
+			p.add(PreflightCheckSubnetCIDRs, PreflightFailed, "Subnet '%s' has invalid CIDR '%s'",
+				subnet.Name, subnet.CIDR)
+			return
+		}
+		if p.spec.MachineCIDR.IP != nil && !network.Contains(&p.spec.MachineCIDR, primary) {
+			p.add(PreflightCheckSubnetCIDRs, PreflightFailed, "The CIDR %s of subnet '%s' isn't "+
+				"inside the machine CIDR %s", subnet.CIDR, subnet.Name, p.spec.MachineCIDR.String())
+			return
+		}
+		for _, value := range append([]string{subnet.CIDR}, subnet.SecondaryCIDRs...) {
+			_, subnetCIDR, err := net.ParseCIDR(value)
+			if err != nil {
+				continue
+			}
+			for i, cidr := range cidrs {
+				if names[i] == "machine" {
+					continue
+				}
+				if network.Overlaps(subnetCIDR, cidr) {
+					p.add(PreflightCheckSubnetCIDRs, PreflightFailed, "The CIDR %s of subnet '%s' "+
+						"overlaps the %s CIDR %s", value, subnet.Name, names[i], cidr)
+					return
+				}
+			}
+		}
+	}
+	p.add(PreflightCheckSubnetCIDRs, PreflightPassed, "The CIDRs of the subnets are inside the "+
+		"machine CIDR and don't overlap the service and pod CIDRs")
+}
+
+func (p *GCPPreflight) checkPSC(ctx context.Context, project string) {
+	name := p.spec.GcpPrivateSvcConnect.SvcAttachmentSubnet
+	if name == "" {
+		p.add(PreflightCheckPSC, PreflightSkipped, "The cluster doesn't use Private Service Connect")
+		return
+	}
+	if p.spec.Private == nil || !*p.spec.Private {
+		p.add(PreflightCheckPSC, PreflightFailed, "Private Service Connect requires a private "+
+			"cluster")
+		return
+	}
+	if name == p.spec.ExistingVPC.ControlPlaneSubnet || name == p.spec.ExistingVPC.ComputeSubnet {
+		p.add(PreflightCheckPSC, PreflightFailed, "The Private Service Connect subnet '%s' can't "+
+			"be the control plane or compute subnet", name)
+		return
+	}
+	if p.network == nil && p.spec.ExistingVPC.VPCProjectID != "" {
+		p.add(PreflightCheckPSC, PreflightSkipped, "Can't check the Private Service Connect "+
+			"subnet of a shared VPC: %v", p.networkError)
+		return
+	}
+	subnet, problem := p.subnet(ctx, project, name)
+	if problem != "" {
+		p.add(PreflightCheckPSC, PreflightFailed, "%s", problem)
+		return
+	}
+	if subnet == nil {
+		p.add(PreflightCheckPSC, PreflightWarning, "Subnet '%s' exists, but its purpose can't be "+
+			"checked: %v", name, p.networkError)
+		return
+	}
+	if subnet.Purpose != PSCSubnetPurpose {
+		p.add(PreflightCheckPSC, PreflightFailed, "Subnet '%s' has purpose '%s', but Private "+
+			"Service Connect requires purpose '%s'", name, subnet.Purpose, PSCSubnetPurpose)
+		return
+	}
+	_, subnetCIDR, err := net.ParseCIDR(subnet.CIDR)
+	if err == nil {
+		names, cidrs := p.clusterCIDRs()
+		for i, cidr := range cidrs {
+			if network.Overlaps(subnetCIDR, cidr) {
+				p.add(PreflightCheckPSC, PreflightFailed, "The CIDR %s of subnet '%s' overlaps "+
+					"the %s CIDR %s", subnet.CIDR, name, names[i], cidr)
+				return
+			}
+		}
+	}
+	p.add(PreflightCheckPSC, PreflightPassed, "Subnet '%s' has purpose '%s'", name,
+		PSCSubnetPurpose)
+}
+
+func (p *GCPPreflight) checkSharedVPC(ctx context.Context, project string) {
+	host := p.spec.ExistingVPC.VPCProjectID
+	if host == "" {
+		p.add(PreflightCheckSharedVPC, PreflightSkipped, "The cluster doesn't use a shared VPC")
+		return
+	}
+	if host == project {
+		p.add(PreflightCheckSharedVPC, PreflightFailed, "The host project of the shared VPC "+
+			"must be different to the project of the cluster '%s'", project)
+		return
+	}
+	if p.network == nil {
+		p.add(PreflightCheckSharedVPC, PreflightSkipped, "Can't check the permissions in host "+
+			"project '%s': %v", host, p.networkError)
+		return
+	}
+	accounts := p.installerAccounts(project)
+	if len(accounts) == 0 {
+		p.add(PreflightCheckSharedVPC, PreflightSkipped, "The service accounts of the installer "+
+			"aren't known")
+		return
+	}
+	members, err := p.network.RoleMembers(ctx, host, NetworkUserRole)
+	if err != nil {
+		p.add(PreflightCheckSharedVPC, PreflightWarning, "Can't check the permissions in host "+
+			"project '%s': %v", host, err)
+		return
+	}
+	for _, account := range accounts {
+		if !utils.Contains(members, gcp.ServiceAccountMember(account)) {
+			p.add(PreflightCheckSharedVPC, PreflightWarning, "Service account '%s' doesn't have "+
+				"role '%s' in host project '%s', make sure that it has it in the subnets", account,
+				NetworkUserRole, host)
+			return
+		}
+	}
+	p.add(PreflightCheckSharedVPC, PreflightPassed, "The installer has role '%s' in host "+
+		"project '%s'", NetworkUserRole, host)
+}
+
+// installerAccounts returns the emails of the service accounts that the installer uses to
+// create the resources of the cluster.
+func (p *GCPPreflight) installerAccounts(project string) []string {
+	if p.spec.GcpAuthentication.Type != cluster.AuthenticationWif {
+		if p.spec.CCS.GCP.ClientEmail == "" {
+			return nil
+		}
+		return []string{p.spec.CCS.GCP.ClientEmail}
+	}
+	var accounts []string
+	for _, account := range p.wifConfig.Gcp().ServiceAccounts() {
+		if account.AccessMethod() == cmv1.WifAccessMethodImpersonate {
+			accounts = append(accounts, fmt.Sprintf("%s@%s.iam.gserviceaccount.com",
+				account.ServiceAccountId(), project))
+		}
+	}
+	return accounts
+}
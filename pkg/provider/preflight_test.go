@@ -0,0 +1,300 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/gcp"
+)
+
+type fakeNetworkClient struct {
+	subnets  map[string]*gcp.Subnet
+	networks map[string]bool
+	members  []string
+}
+
+func (f *fakeNetworkClient) Subnet(ctx context.Context, project, region, name string) (*gcp.Subnet, error) {
+	subnet := f.subnets[project+"/"+region+"/"+name]
+	return subnet, nil
+}
+
+func (f *fakeNetworkClient) NetworkExists(ctx context.Context, project, name string) (bool, error) {
+	return f.networks[project+"/"+name], nil
+}
+
+func (f *fakeNetworkClient) RoleMembers(ctx context.Context, project, role string) ([]string, error) {
+	return f.members, nil
+}
+
+func mustCIDR(t *testing.T, value string) net.IPNet {
+	_, cidr, err := net.ParseCIDR(value)
+	if err != nil {
+		t.Fatalf("can't parse CIDR '%s': %v", value, err)
+	}
+	return *cidr
+}
+
+func newTestVPC(t *testing.T, name string, subnets ...string) *cmv1.CloudVPC {
+	vpc, err := cmv1.NewCloudVPC().Name(name).Subnets(subnets...).Build()
+	if err != nil {
+		t.Fatalf("can't build VPC: %v", err)
+	}
+	return vpc
+}
+
+func newTestSpec(t *testing.T) cluster.Spec {
+	private := true
+	return cluster.Spec{
+		Provider:    cluster.ProviderGCP,
+		Region:      "us-east1",
+		MachineCIDR: mustCIDR(t, "10.0.0.0/16"),
+		ServiceCIDR: mustCIDR(t, "172.30.0.0/16"),
+		PodCIDR:     mustCIDR(t, "10.128.0.0/14"),
+		HostPrefix:  23,
+		Private:     &private,
+		CCS: cluster.CCS{
+			Enabled: true,
+			GCP: cluster.GCPCredentials{
+				ProjectID:   "service",
+				ClientEmail: "osd-ccs-admin@service.iam.gserviceaccount.com",
+			},
+		},
+		GcpAuthentication: cluster.GcpAuthentication{Type: cluster.AuthenticationKey},
+		ExistingVPC: cluster.ExistingVPC{
+			VPCName:            "vpc",
+			ControlPlaneSubnet: "control",
+			ComputeSubnet:      "compute",
+		},
+	}
+}
+
+func newTestNetwork(project string) *fakeNetworkClient {
+	prefix := project + "/us-east1/"
+	return &fakeNetworkClient{
+		subnets: map[string]*gcp.Subnet{
+			prefix + "control": {Name: "control", Network: "vpc", CIDR: "10.0.0.0/19"},
+			prefix + "compute": {Name: "compute", Network: "vpc", CIDR: "10.0.32.0/19"},
+			prefix + "psc": {
+				Name:    "psc",
+				Network: "vpc",
+				CIDR:    "10.1.0.0/29",
+				Purpose: PSCSubnetPurpose,
+			},
+		},
+		networks: map[string]bool{project + "/vpc": true},
+	}
+}
+
+func newTestPreflight(t *testing.T, spec cluster.Spec, network gcp.NetworkClient) *GCPPreflight {
+	vpc := newTestVPC(t, "vpc", "control", "compute", "psc")
+	return &GCPPreflight{
+		spec:    spec,
+		network: network,
+		listVPCs: func() ([]*cmv1.CloudVPC, error) {
+			return []*cmv1.CloudVPC{vpc}, nil
+		},
+		getWifConfig: func(id string) (*cmv1.WifConfig, *cmv1.WifConfigStatus, error) {
+			return nil, nil, errors.New("not found")
+		},
+	}
+}
+
+// statuses returns the status of each of the checks in the results.
+func statuses(results []PreflightResult) map[string]string {
+	result := map[string]string{}
+	for _, item := range results {
+		result[item.Check] = item.Status
+	}
+	return result
+}
+
+func TestPreflightPasses(t *testing.T) {
+	spec := newTestSpec(t)
+	spec.GcpPrivateSvcConnect.SvcAttachmentSubnet = "psc"
+	results := newTestPreflight(t, spec, newTestNetwork("service")).Run(context.Background())
+	for _, result := range results {
+		if result.Status == PreflightFailed || result.Status == PreflightWarning {
+			t.Errorf("check '%s' is %s: %s", result.Check, result.Status, result.Message)
+		}
+	}
+	got := statuses(results)
+	for _, check := range []string{
+		PreflightCheckCIDRs, PreflightCheckVPC, PreflightCheckSubnets,
+		PreflightCheckSubnetCIDRs, PreflightCheckPSC,
+	} {
+		if got[check] != PreflightPassed {
+			t.Errorf("expected check '%s' to pass, got '%s'", check, got[check])
+		}
+	}
+}
+
+func TestPreflightFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(spec *cluster.Spec, network *fakeNetworkClient)
+		check   string
+		message string
+	}{
+		{
+			name: "overlapping cluster CIDRs",
+			modify: func(spec *cluster.Spec, network *fakeNetworkClient) {
+				spec.PodCIDR = mustCIDR(t, "10.0.128.0/17")
+				spec.HostPrefix = 23
+			},
+			check:   PreflightCheckCIDRs,
+			message: "overlaps",
+		},
+		{
+			name: "pod CIDR smaller than host prefix",
+			modify: func(spec *cluster.Spec, network *fakeNetworkClient) {
+				spec.PodCIDR = mustCIDR(t, "10.128.0.0/24")
+			},
+			check:   PreflightCheckCIDRs,
+			message: "host prefix",
+		},
+		{
+			name: "missing VPC",
+			modify: func(spec *cluster.Spec, network *fakeNetworkClient) {
+				spec.ExistingVPC.VPCName = "other"
+			},
+			check:   PreflightCheckVPC,
+			message: "doesn't exist",
+		},
+		{
+			name: "subnet in another region",
+			modify: func(spec *cluster.Spec, network *fakeNetworkClient) {
+				spec.Region = "europe-west1"
+			},
+			check:   PreflightCheckSubnets,
+			message: "region 'europe-west1'",
+		},
+		{
+			name: "subnet in another VPC",
+			modify: func(spec *cluster.Spec, network *fakeNetworkClient) {
+				network.subnets["service/us-east1/compute"].Network = "other"
+			},
+			check:   PreflightCheckSubnets,
+			message: "belongs to VPC 'other'",
+		},
+		{
+			name: "subnet outside the machine CIDR",
+			modify: func(spec *cluster.Spec, network *fakeNetworkClient) {
+				network.subnets["service/us-east1/compute"].CIDR = "192.168.0.0/24"
+			},
+			check:   PreflightCheckSubnetCIDRs,
+			message: "isn't inside the machine CIDR",
+		},
+		{
+			name: "secondary range overlapping the pod CIDR",
+			modify: func(spec *cluster.Spec, network *fakeNetworkClient) {
+				network.subnets["service/us-east1/compute"].SecondaryCIDRs = []string{"10.130.0.0/16"}
+			},
+			check:   PreflightCheckSubnetCIDRs,
+			message: "overlaps the pod CIDR",
+		},
+		{
+			name: "PSC subnet with wrong purpose",
+			modify: func(spec *cluster.Spec, network *fakeNetworkClient) {
+				spec.GcpPrivateSvcConnect.SvcAttachmentSubnet = "psc"
+				network.subnets["service/us-east1/psc"].Purpose = "PRIVATE"
+			},
+			check:   PreflightCheckPSC,
+			message: PSCSubnetPurpose,
+		},
+		{
+			name: "PSC subnet overlapping the service CIDR",
+			modify: func(spec *cluster.Spec, network *fakeNetworkClient) {
+				spec.GcpPrivateSvcConnect.SvcAttachmentSubnet = "psc"
+				network.subnets["service/us-east1/psc"].CIDR = "172.30.1.0/29"
+			},
+			check:   PreflightCheckPSC,
+			message: "overlaps the service CIDR",
+		},
+		{
+			name: "shared VPC in the same project",
+			modify: func(spec *cluster.Spec, network *fakeNetworkClient) {
+				spec.ExistingVPC.VPCProjectID = "service"
+			},
+			check:   PreflightCheckSharedVPC,
+			message: "must be different",
+		},
+		{
+			name: "missing WIF configuration",
+			modify: func(spec *cluster.Spec, network *fakeNetworkClient) {
+				spec.GcpAuthentication = cluster.GcpAuthentication{
+					Type: cluster.AuthenticationWif,
+					Id:   "wif",
+				}
+			},
+			check:   PreflightCheckWIF,
+			message: "Failed to get WIF configuration",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec := newTestSpec(t)
+			network := newTestNetwork("service")
+			test.modify(&spec, network)
+			results := newTestPreflight(t, spec, network).Run(context.Background())
+			if !Failed(results) {
+				t.Fatalf("expected the checks to fail, got %v", results)
+			}
+			for _, result := range results {
+				if result.Check != test.check {
+					continue
+				}
+				if result.Status != PreflightFailed {
+					t.Errorf("expected check '%s' to fail, got '%s'", result.Check, result.Status)
+				}
+				if !strings.Contains(result.Message, test.message) {
+					t.Errorf("expected message to contain '%s', got '%s'", test.message, result.Message)
+				}
+				return
+			}
+			t.Errorf("check '%s' wasn't run", test.check)
+		})
+	}
+}
+
+func TestPreflightSharedVPC(t *testing.T) {
+	spec := newTestSpec(t)
+	spec.ExistingVPC.VPCProjectID = "host"
+	network := newTestNetwork("host")
+	results := newTestPreflight(t, spec, network).Run(context.Background())
+	got := statuses(results)
+	if got[PreflightCheckVPC] != PreflightPassed || got[PreflightCheckSubnets] != PreflightPassed {
+		t.Errorf("expected the VPC and subnets of the host project to be found, got %v", results)
+	}
+	if got[PreflightCheckSharedVPC] != PreflightWarning {
+		t.Errorf("expected a warning for the missing role, got '%s'", got[PreflightCheckSharedVPC])
+	}
+
+	network.members = []string{gcp.ServiceAccountMember(spec.CCS.GCP.ClientEmail)}
+	results = newTestPreflight(t, spec, network).Run(context.Background())
+	if got := statuses(results)[PreflightCheckSharedVPC]; got != PreflightPassed {
+		t.Errorf("expected the shared VPC check to pass, got '%s'", got)
+	}
+}
+
+func TestPreflightWithoutNetworkClient(t *testing.T) {
+	spec := newTestSpec(t)
+	preflight := newTestPreflight(t, spec, nil)
+	preflight.networkError = errors.New("no credentials")
+	results := preflight.Run(context.Background())
+	got := statuses(results)
+	if got[PreflightCheckSubnets] != PreflightPassed {
+		t.Errorf("expected the subnets to be found with the OCM API, got '%s'", got[PreflightCheckSubnets])
+	}
+	if got[PreflightCheckSubnetCIDRs] != PreflightSkipped {
+		t.Errorf("expected the subnet CIDRs check to be skipped, got '%s'", got[PreflightCheckSubnetCIDRs])
+	}
+	if Failed(results) {
+		t.Errorf("expected no failures, got %v", results)
+	}
+}
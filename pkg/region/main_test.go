@@ -0,0 +1,13 @@
+package region
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRegion(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Region")
+}
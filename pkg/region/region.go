@@ -0,0 +1,141 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to implement the '--rh-region' command line option and to
+// discover the regional API gateways.
+
+package region
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	"github.com/spf13/pflag"
+)
+
+// Region is a regional API gateway.
+type Region struct {
+	// Name is the identifier of the region, for example 'singapore'.
+	Name string
+
+	// URL is the complete URL of the API gateway of the region.
+	URL string
+}
+
+// discover is the function used to fetch the regions. It is a variable so that tests can replace
+// it.
+var discover = sdk.GetRhRegions
+
+// selected is the name of the region given with the '--rh-region' flag.
+var selected string
+
+// AddFlag adds the '--rh-region' flag to the given set of command line flags.
+func AddFlag(flags *pflag.FlagSet) {
+	flags.StringVar(
+		&selected,
+		"rh-region",
+		"",
+		"OCM region identifier. Requests are sent to the API gateway of this region, found "+
+			"with the service discovery of the environment of the configured URL, using the "+
+			"stored credentials. Use 'ocm list rh-regions' to see the available regions.",
+	)
+}
+
+// AddAllFlag adds the '--all-regions' flag of the list commands to the given set of command line
+// flags. The description of the listed items is used in the help text.
+func AddAllFlag(flags *pflag.FlagSet, value *bool, what string) {
+	flags.BoolVar(
+		value,
+		"all-regions",
+		false,
+		fmt.Sprintf("List the %s of all the regions of the environment, adding a REGION column.",
+			what),
+	)
+}
+
+// Selected returns the name of the region given with the '--rh-region' flag, or an empty string if
+// the flag wasn't used.
+func Selected() string {
+	return selected
+}
+
+// List returns the regions of the environment that the given gateway belongs to, sorted by name.
+func List(gatewayURL string) ([]Region, error) {
+	regions, err := discover(gatewayURL)
+	if err != nil {
+		return nil, fmt.Errorf("Can't discover regions: %v", err)
+	}
+	result := make([]Region, 0, len(regions))
+	for name, region := range regions {
+		result = append(result, Region{
+			Name: name,
+			URL:  gatewayOf(region.URL),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// Find returns the region with the given name from the environment that the given gateway belongs
+// to.
+func Find(gatewayURL string, name string) (Region, error) {
+	regions, err := List(gatewayURL)
+	if err != nil {
+		return Region{}, err
+	}
+	names := make([]string, len(regions))
+	for i, region := range regions {
+		if region.Name == name {
+			return region, nil
+		}
+		names[i] = region.Name
+	}
+	return Region{}, fmt.Errorf("Can't find region '%s', valid regions are: %s",
+		name, strings.Join(names, ", "))
+}
+
+// NameOf returns the name of the region whose API gateway is the given URL, or an empty string if
+// it can't be determined. Regions are only discovered for the gateways of openshift.com, so that
+// other servers don't cause requests to it.
+func NameOf(gatewayURL string) string {
+	parsed, err := url.Parse(gatewayURL)
+	if err != nil || !strings.HasSuffix(parsed.Hostname(), "openshift.com") {
+		return ""
+	}
+	regions, err := List(gatewayURL)
+	if err != nil {
+		return ""
+	}
+	for _, region := range regions {
+		if strings.TrimSuffix(region.URL, "/") == strings.TrimSuffix(gatewayURL, "/") {
+			return region.Name
+		}
+	}
+	return ""
+}
+
+// gatewayOf converts the host name returned by the service discovery to a complete URL.
+func gatewayOf(host string) string {
+	if strings.Contains(host, "://") {
+		return host
+	}
+	return "https://" + host
+}
@@ -0,0 +1,66 @@
+package region
+
+import (
+	"fmt"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Regions", func() {
+	var requested []string
+
+	BeforeEach(func() {
+		requested = nil
+		discover = func(gatewayURL string) (map[string]sdk.Region, error) {
+			requested = append(requested, gatewayURL)
+			return map[string]sdk.Region{
+				"us-east-1": {URL: "api.stage.openshift.com"},
+				"singapore": {URL: "api.singapore.stage.openshift.com"},
+			}, nil
+		}
+	})
+
+	AfterEach(func() {
+		discover = sdk.GetRhRegions
+	})
+
+	It("Lists the regions sorted by name with complete URLs", func() {
+		regions, err := List("https://api.stage.openshift.com")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(regions).To(Equal([]Region{
+			{Name: "singapore", URL: "https://api.singapore.stage.openshift.com"},
+			{Name: "us-east-1", URL: "https://api.stage.openshift.com"},
+		}))
+		Expect(requested).To(Equal([]string{"https://api.stage.openshift.com"}))
+	})
+
+	It("Finds a region by name", func() {
+		region, err := Find("https://api.stage.openshift.com", "singapore")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(region.URL).To(Equal("https://api.singapore.stage.openshift.com"))
+	})
+
+	It("Lists the valid regions when the name doesn't exist", func() {
+		_, err := Find("https://api.stage.openshift.com", "mars")
+		Expect(err).To(MatchError(
+			"Can't find region 'mars', valid regions are: singapore, us-east-1",
+		))
+	})
+
+	It("Reports discovery errors", func() {
+		discover = func(gatewayURL string) (map[string]sdk.Region, error) {
+			return nil, fmt.Errorf("no route to host")
+		}
+		_, err := List("https://api.stage.openshift.com")
+		Expect(err).To(MatchError("Can't discover regions: no route to host"))
+	})
+
+	It("Finds the name of the region of a gateway", func() {
+		Expect(NameOf("https://api.singapore.stage.openshift.com/")).To(Equal("singapore"))
+		Expect(NameOf("https://api.example.com")).To(BeEmpty())
+		Expect(requested).To(HaveLen(1))
+	})
+})
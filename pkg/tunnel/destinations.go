@@ -0,0 +1,77 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the types used to decide which destinations can be reached through the
+// tunnel.
+
+package tunnel
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Destinations describes the hosts and networks that can be reached through the tunnel.
+type Destinations struct {
+	// Hosts are host names or IP addresses that can be reached.
+	Hosts []string
+
+	// Domains are DNS domains whose sub-domains can be reached, for example the domain of the
+	// routes of the cluster.
+	Domains []string
+
+	// Networks are the IP networks that can be reached.
+	Networks []*net.IPNet
+}
+
+// AddNetwork adds the network with the given CIDR. Empty values are ignored.
+func (d *Destinations) AddNetwork(cidr string) error {
+	if cidr == "" {
+		return nil
+	}
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return fmt.Errorf("invalid CIDR '%s': %v", cidr, err)
+	}
+	d.Networks = append(d.Networks, network)
+	return nil
+}
+
+// Allowed checks if the given host name or IP address can be reached through the tunnel.
+func (d *Destinations) Allowed(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, item := range d.Hosts {
+		if strings.EqualFold(item, host) {
+			return true
+		}
+	}
+	for _, domain := range d.Domains {
+		if strings.HasSuffix(host, "."+strings.ToLower(domain)) {
+			return true
+		}
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range d.Networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,13 @@
+package tunnel
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTunnel(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tunnel suite")
+}
@@ -0,0 +1,271 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains a proxy that accepts SOCKS5 and HTTP CONNECT requests on the same port and
+// forwards the connections through a dialer, usually an SSH connection to the cluster.
+
+package tunnel
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Dialer opens connections to the destinations of the tunnel. The *ssh.Client type implements
+// this interface.
+type Dialer interface {
+	Dial(network, address string) (net.Conn, error)
+}
+
+// SOCKS5 protocol constants, see RFC 1928:
+const (
+	socksVersion            = 0x05
+	socksNoAuth             = 0x00
+	socksNoAcceptable       = 0xff
+	socksConnect            = 0x01
+	socksIPv4               = 0x01
+	socksDomain             = 0x03
+	socksIPv6               = 0x04
+	socksSucceeded          = 0x00
+	socksNotAllowed         = 0x02
+	socksHostUnreachable    = 0x04
+	socksCommandUnsupported = 0x07
+	socksAddressUnsupported = 0x08
+	socksRequestHeaderSize  = 4
+)
+
+// Proxy forwards SOCKS5 and HTTP CONNECT requests through a dialer. Don't create instances of
+// this type directly; use the NewProxy function instead.
+type Proxy struct {
+	dialer       Dialer
+	destinations *Destinations
+	logger       func(format string, values ...interface{})
+}
+
+// NewProxy creates a proxy that forwards the connections to the allowed destinations using the
+// given dialer.
+func NewProxy(dialer Dialer, destinations *Destinations) *Proxy {
+	return &Proxy{
+		dialer:       dialer,
+		destinations: destinations,
+		logger:       func(string, ...interface{}) {},
+	}
+}
+
+// Logger sets the function used to report connections and errors.
+func (p *Proxy) Logger(value func(format string, values ...interface{})) *Proxy {
+	p.logger = value
+	return p
+}
+
+// Serve accepts connections from the listener till the context is cancelled.
+func (p *Proxy) Serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+	var group sync.WaitGroup
+	defer group.Wait()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			p.handle(ctx, conn)
+		}()
+	}
+}
+
+func (p *Proxy) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+	reader := bufio.NewReader(conn)
+	first, err := reader.Peek(1)
+	if err != nil {
+		return
+	}
+	if first[0] == socksVersion {
+		err = p.handleSOCKS(conn, reader)
+	} else {
+		err = p.handleHTTP(conn, reader)
+	}
+	if err != nil {
+		p.logger("%v", err)
+	}
+}
+
+// handleSOCKS implements the CONNECT command of SOCKS5 without authentication.
+func (p *Proxy) handleSOCKS(conn net.Conn, reader *bufio.Reader) error {
+	header := make([]byte, 2)
+	_, err := io.ReadFull(reader, header)
+	if err != nil {
+		return err
+	}
+	methods := make([]byte, header[1])
+	_, err = io.ReadFull(reader, methods)
+	if err != nil {
+		return err
+	}
+	method := byte(socksNoAcceptable)
+	for _, value := range methods {
+		if value == socksNoAuth {
+			method = socksNoAuth
+		}
+	}
+	_, err = conn.Write([]byte{socksVersion, method})
+	if err != nil || method == socksNoAcceptable {
+		return err
+	}
+
+	request := make([]byte, socksRequestHeaderSize)
+	_, err = io.ReadFull(reader, request)
+	if err != nil {
+		return err
+	}
+	if request[1] != socksConnect {
+		p.socksReply(conn, socksCommandUnsupported)
+		return fmt.Errorf("unsupported SOCKS command %d", request[1])
+	}
+	var host string
+	switch request[3] {
+	case socksIPv4, socksIPv6:
+		size := net.IPv4len
+		if request[3] == socksIPv6 {
+			size = net.IPv6len
+		}
+		ip := make([]byte, size)
+		_, err = io.ReadFull(reader, ip)
+		host = net.IP(ip).String()
+	case socksDomain:
+		var size byte
+		size, err = reader.ReadByte()
+		if err == nil {
+			name := make([]byte, size)
+			_, err = io.ReadFull(reader, name)
+			host = string(name)
+		}
+	default:
+		p.socksReply(conn, socksAddressUnsupported)
+		return fmt.Errorf("unsupported SOCKS address type %d", request[3])
+	}
+	if err != nil {
+		return err
+	}
+	port := make([]byte, 2)
+	_, err = io.ReadFull(reader, port)
+	if err != nil {
+		return err
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+
+	if !p.destinations.Allowed(host) {
+		p.socksReply(conn, socksNotAllowed)
+		return fmt.Errorf("destination '%s' isn't reachable through the tunnel", address)
+	}
+	target, err := p.dialer.Dial("tcp", address)
+	if err != nil {
+		p.socksReply(conn, socksHostUnreachable)
+		return fmt.Errorf("can't connect to '%s': %v", address, err)
+	}
+	defer target.Close()
+	err = p.socksReply(conn, socksSucceeded)
+	if err != nil {
+		return err
+	}
+	p.logger("Connected to '%s'", address)
+	return pipe(conn, reader, target)
+}
+
+func (p *Proxy) socksReply(conn net.Conn, code byte) error {
+	_, err := conn.Write([]byte{socksVersion, code, 0x00, socksIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+// handleHTTP implements the CONNECT method of HTTP proxies.
+func (p *Proxy) handleHTTP(conn net.Conn, reader *bufio.Reader) error {
+	request, err := http.ReadRequest(reader)
+	if err != nil {
+		return err
+	}
+	if request.Method != http.MethodConnect {
+		p.httpReply(conn, http.StatusMethodNotAllowed)
+		return fmt.Errorf("unsupported HTTP method '%s', only CONNECT is supported",
+			request.Method)
+	}
+	address := request.Host
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		p.httpReply(conn, http.StatusBadRequest)
+		return fmt.Errorf("invalid CONNECT address '%s': %v", address, err)
+	}
+	if !p.destinations.Allowed(host) {
+		p.httpReply(conn, http.StatusForbidden)
+		return fmt.Errorf("destination '%s' isn't reachable through the tunnel", address)
+	}
+	target, err := p.dialer.Dial("tcp", address)
+	if err != nil {
+		p.httpReply(conn, http.StatusBadGateway)
+		return fmt.Errorf("can't connect to '%s': %v", address, err)
+	}
+	defer target.Close()
+	err = p.httpReply(conn, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	p.logger("Connected to '%s'", address)
+	return pipe(conn, reader, target)
+}
+
+func (p *Proxy) httpReply(conn net.Conn, code int) error {
+	_, err := fmt.Fprintf(conn, "HTTP/1.1 %d %s\r\n\r\n", code, http.StatusText(code))
+	return err
+}
+
+// pipe copies data in both directions till one of the connections is closed. The reader is
+// used instead of the client connection because it may contain buffered data.
+func pipe(client net.Conn, reader io.Reader, target net.Conn) error {
+	done := make(chan error, 2)
+	go func() {
+		_, err := io.Copy(target, reader)
+		done <- err
+	}()
+	go func() {
+		_, err := io.Copy(client, target)
+		done <- err
+	}()
+	err := <-done
+	if errors.Is(err, net.ErrClosed) || errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,146 @@
+package tunnel
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Destinations", func() {
+	It("allows hosts, sub-domains and networks", func() {
+		destinations := &Destinations{
+			Hosts:   []string{"api.my.example.com"},
+			Domains: []string{"apps.my.example.com"},
+		}
+		Expect(destinations.AddNetwork("10.0.0.0/16")).To(Succeed())
+		Expect(destinations.AddNetwork("")).To(Succeed())
+		Expect(destinations.AddNetwork("10.0.0.0")).ToNot(Succeed())
+
+		Expect(destinations.Allowed("api.my.example.com")).To(BeTrue())
+		Expect(destinations.Allowed("API.my.example.com.")).To(BeTrue())
+		Expect(destinations.Allowed("oauth.apps.my.example.com")).To(BeTrue())
+		Expect(destinations.Allowed("10.0.3.4")).To(BeTrue())
+		Expect(destinations.Allowed("apps.my.example.com")).To(BeFalse())
+		Expect(destinations.Allowed("example.com")).To(BeFalse())
+		Expect(destinations.Allowed("10.1.0.1")).To(BeFalse())
+	})
+})
+
+var _ = Describe("Proxy", func() {
+	var proxyAddress string
+	var echoAddress string
+	var cancel context.CancelFunc
+
+	BeforeEach(func() {
+		// Start a server that echoes what it receives:
+		echo, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(echo.Close)
+		echoAddress = echo.Addr().String()
+		go func() {
+			for {
+				conn, err := echo.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					defer conn.Close()
+					_, _ = io.Copy(conn, conn)
+				}()
+			}
+		}()
+
+		// Start the proxy, using a direct dialer:
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		proxyAddress = listener.Addr().String()
+		destinations := &Destinations{Hosts: []string{"localhost"}}
+		Expect(destinations.AddNetwork("127.0.0.0/8")).To(Succeed())
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		go func() {
+			defer GinkgoRecover()
+			Expect(NewProxy(&net.Dialer{}, destinations).Serve(ctx, listener)).To(Succeed())
+		}()
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	expectEcho := func(conn net.Conn, reader io.Reader) {
+		_, err := conn.Write([]byte("hello"))
+		Expect(err).ToNot(HaveOccurred())
+		data := make([]byte, 5)
+		_, err = io.ReadFull(reader, data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("hello"))
+	}
+
+	socksConnect := func(host string, port int) (net.Conn, byte) {
+		conn, err := net.Dial("tcp", proxyAddress)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = conn.Write([]byte{socksVersion, 1, socksNoAuth})
+		Expect(err).ToNot(HaveOccurred())
+		reply := make([]byte, 2)
+		_, err = io.ReadFull(conn, reply)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reply).To(Equal([]byte{socksVersion, socksNoAuth}))
+
+		request := []byte{socksVersion, socksConnect, 0, socksDomain, byte(len(host))}
+		request = append(request, host...)
+		request = binary.BigEndian.AppendUint16(request, uint16(port))
+		_, err = conn.Write(request)
+		Expect(err).ToNot(HaveOccurred())
+		reply = make([]byte, 10)
+		_, err = io.ReadFull(conn, reply)
+		Expect(err).ToNot(HaveOccurred())
+		return conn, reply[1]
+	}
+
+	It("forwards SOCKS5 connections", func() {
+		_, port, _ := net.SplitHostPort(echoAddress)
+		number, err := strconv.Atoi(port)
+		Expect(err).ToNot(HaveOccurred())
+		conn, code := socksConnect("localhost", number)
+		defer conn.Close()
+		Expect(code).To(Equal(byte(socksSucceeded)))
+		expectEcho(conn, conn)
+	})
+
+	It("rejects SOCKS5 connections to other destinations", func() {
+		conn, code := socksConnect("example.com", 443)
+		defer conn.Close()
+		Expect(code).To(Equal(byte(socksNotAllowed)))
+	})
+
+	It("forwards HTTP CONNECT requests", func() {
+		conn, err := net.Dial("tcp", proxyAddress)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+		fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", echoAddress, echoAddress)
+		reader := bufio.NewReader(conn)
+		response, err := http.ReadResponse(reader, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		expectEcho(conn, reader)
+	})
+
+	It("rejects HTTP CONNECT requests to other destinations", func() {
+		conn, err := net.Dial("tcp", proxyAddress)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+		fmt.Fprintf(conn, "CONNECT example.com:443 HTTP/1.1\r\nHost: example.com:443\r\n\r\n")
+		response, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusForbidden))
+	})
+})
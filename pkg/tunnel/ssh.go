@@ -0,0 +1,207 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to open the SSH connection that carries the tunnel.
+
+package tunnel
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHConfig contains the details needed to connect to the SSH server of the cluster.
+type SSHConfig struct {
+	// User is the SSH user name.
+	User string
+
+	// Address is the host name and port of the SSH server.
+	Address string
+
+	// IdentityFiles are private key files used for authentication, in addition to the keys
+	// of the SSH agent. When empty the default keys in '~/.ssh' are used.
+	IdentityFiles []string
+
+	// KnownHostsFile is the file used to verify the host key of the server. Defaults to
+	// '~/.ssh/known_hosts'.
+	KnownHostsFile string
+
+	// InsecureIgnoreHostKey disables the verification of the host key of the server.
+	InsecureIgnoreHostKey bool
+}
+
+// SSHDialer opens connections through an SSH connection that is established when needed, and
+// established again if it is lost. Don't create instances of this type directly; use the
+// NewSSHDialer function instead.
+type SSHDialer struct {
+	config *ssh.ClientConfig
+	addr   string
+	lock   sync.Mutex
+	client *ssh.Client
+}
+
+// NewSSHDialer prepares the authentication and host key verification for the given
+// configuration. The connection is established by the Connect method or the first dial.
+func NewSSHDialer(cfg SSHConfig) (*SSHDialer, error) {
+	auth, err := authMethods(cfg.IdentityFiles)
+	if err != nil {
+		return nil, err
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("no SSH keys found: start an SSH agent or use --identity")
+	}
+	var hostKeyCallback ssh.HostKeyCallback
+	if cfg.InsecureIgnoreHostKey {
+		// #nosec G106
+		hostKeyCallback = ssh.InsecureIgnoreHostKey()
+	} else {
+		file := cfg.KnownHostsFile
+		if file == "" {
+			home, err := homedir.Dir()
+			if err != nil {
+				return nil, err
+			}
+			file = filepath.Join(home, ".ssh", "known_hosts")
+		}
+		hostKeyCallback, err = knownhosts.New(file)
+		if err != nil {
+			return nil, fmt.Errorf("can't load known hosts file '%s': %v", file, err)
+		}
+	}
+	return &SSHDialer{
+		config: &ssh.ClientConfig{
+			User:            cfg.User,
+			Auth:            auth,
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         30 * time.Second,
+		},
+		addr: cfg.Address,
+	}, nil
+}
+
+// Connect establishes the SSH connection, if it isn't already established.
+func (d *SSHDialer) Connect() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	_, err := d.connect()
+	return err
+}
+
+func (d *SSHDialer) connect() (*ssh.Client, error) {
+	if d.client != nil {
+		return d.client, nil
+	}
+	client, err := ssh.Dial("tcp", d.addr, d.config)
+	if err != nil {
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
+			return nil, fmt.Errorf("host key of '%s' isn't in the known hosts file, connect "+
+				"once with 'ssh' to verify it", d.addr)
+		}
+		return nil, fmt.Errorf("can't connect to '%s': %v", d.addr, err)
+	}
+	d.client = client
+	go func() {
+		_ = client.Wait()
+		d.lock.Lock()
+		if d.client == client {
+			d.client = nil
+		}
+		d.lock.Unlock()
+	}()
+	return client, nil
+}
+
+// Dial implements the Dialer interface.
+func (d *SSHDialer) Dial(network, address string) (net.Conn, error) {
+	d.lock.Lock()
+	client, err := d.connect()
+	d.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return client.Dial(network, address)
+}
+
+// Close closes the SSH connection.
+func (d *SSHDialer) Close() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.client == nil {
+		return nil
+	}
+	err := d.client.Close()
+	d.client = nil
+	return err
+}
+
+// authMethods returns the keys of the SSH agent and of the given identity files, or of the
+// default identity files if none is given.
+func authMethods(identityFiles []string) ([]ssh.AuthMethod, error) {
+	var signers []ssh.Signer
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		conn, err := net.Dial("unix", socket)
+		if err == nil {
+			agentSigners, err := agent.NewClient(conn).Signers()
+			if err == nil {
+				signers = append(signers, agentSigners...)
+			}
+		}
+	}
+	explicit := len(identityFiles) > 0
+	if !explicit {
+		home, err := homedir.Dir()
+		if err == nil {
+			for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+				identityFiles = append(identityFiles, filepath.Join(home, ".ssh", name))
+			}
+		}
+	}
+	for _, file := range identityFiles {
+		// #nosec G304
+		data, err := os.ReadFile(file)
+		if os.IsNotExist(err) && !explicit {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't read SSH key '%s': %v", file, err)
+		}
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			var missing *ssh.PassphraseMissingError
+			if errors.As(err, &missing) && !explicit {
+				// Encrypted default keys are expected to be in the agent:
+				continue
+			}
+			return nil, fmt.Errorf("can't parse SSH key '%s': %v", file, err)
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) == 0 {
+		return nil, nil
+	}
+	return []ssh.AuthMethod{ssh.PublicKeys(signers...)}, nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright (c) 2021 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"                      // nolint
+	. "github.com/onsi/gomega"                         // nolint
+	. "github.com/onsi/gomega/ghttp"                   // nolint
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Auth status", func() {
+	var ctx context.Context
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		apiServer = MakeTCPServer()
+
+		// The refresh token expires soon, but the access token is still valid:
+		accessToken := MakeTokenString("Bearer", 10*time.Minute)
+		refreshToken := MakeTokenString("Refresh", 30*time.Minute)
+		config = EvaluateTemplate(
+			`{
+				"client_id": "cloud-services",
+				"access_token": "{{ .accessToken }}",
+				"refresh_token": "{{ .refreshToken }}",
+				"url": "{{ .url }}",
+				"token_url": "http://my-sso.example.com"
+			}`,
+			"accessToken", accessToken,
+			"refreshToken", refreshToken,
+			"url", apiServer.URL(),
+		)
+	})
+
+	AfterEach(func() {
+		apiServer.Close()
+	})
+
+	It("Reports that the server accepts the credentials", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/current_account"),
+				RespondWithJSON(http.StatusOK, `{"username": "myuser"}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("auth", "status", "--json").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+
+		var status map[string]interface{}
+		err := json.Unmarshal([]byte(result.OutString()), &status)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status["url"]).To(Equal(apiServer.URL()))
+		Expect(status["method"]).To(Equal("refresh-token"))
+		Expect(status["logged_in"]).To(BeTrue())
+		Expect(status["accepted"]).To(BeTrue())
+		Expect(status["account"]).To(Equal("myuser"))
+		Expect(status["refresh_token"]).To(HaveKeyWithValue("type", "Refresh"))
+	})
+
+	It("Reports that the server rejects the credentials", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusUnauthorized, `{
+				"kind": "Error",
+				"reason": "Bearer token is not valid"
+			}`),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("auth", "status").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.OutString()).To(ContainSubstring("doesn't accept the credentials"))
+		Expect(result.ErrString()).To(ContainSubstring("Server didn't accept the credentials"))
+	})
+
+	It("Doesn't contact the server when offline", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("auth", "status", "--offline").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring("Method:             refresh-token"))
+		Expect(result.OutString()).ToNot(ContainSubstring("Server:"))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Warns when the refresh token is about to expire", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{"username": "myuser"}`),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("whoami").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(MatchRegexp(
+			`^Warning: refresh token expires in \S+, run the 'login' command to get a new one\n$`,
+		))
+	})
+})
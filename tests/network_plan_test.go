@@ -0,0 +1,69 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Network plan", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("Suggests the defaults when they are free", func() {
+		result := NewCommand().Args("network", "plan").Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.OutString()).To(MatchRegexp(`machine\s+10\.0\.0\.0/16\s+suggested`))
+		Expect(result.OutString()).To(MatchRegexp(`service\s+172\.30\.0\.0/16\s+suggested`))
+		Expect(result.OutString()).To(MatchRegexp(`pod\s+10\.128\.0\.0/14\s+suggested`))
+		Expect(result.OutString()).To(MatchRegexp(`Max nodes:\s+512`))
+	})
+
+	It("Avoids the ranges in use", func() {
+		result := NewCommand().
+			Args(
+				"network", "plan",
+				"--used", "10.0.0.0/12",
+				"--used", "10.128.0.0/12",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(MatchRegexp(`machine\s+10\.16\.0\.0/16\s+suggested`))
+		Expect(result.OutString()).To(MatchRegexp(`pod\s+10\.144\.0\.0/14\s+suggested`))
+	})
+
+	It("Fails if the given ranges overlap", func() {
+		result := NewCommand().
+			Args(
+				"network", "plan",
+				"--machine-cidr", "10.0.0.0/16",
+				"--pod-cidr", "10.0.128.0/17",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"The machine CIDR 10.0.0.0/16 overlaps the pod CIDR 10.0.128.0/17",
+		))
+	})
+})
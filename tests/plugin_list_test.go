@@ -138,4 +138,26 @@ var _ = Describe("Plugin list", func() {
 			`^\s*ocm-your-plugin\s*$`,
 		))
 	})
+
+	It("Ignores plugins with invalid manifests", func() {
+		// Write a manifest that declares the same flag twice:
+		manifest := filepath.Join(tmp, "ocm-my_plugin.manifest.yaml")
+		err := os.WriteFile(manifest, []byte("name: my_plugin\nflags:\n- name: loud\n- name: loud\n"),
+			0600)
+		Expect(err).ToNot(HaveOccurred())
+		executable := filepath.Join(tmp, "ocm-my_plugin")
+		if runtime.GOOS == "windows" {
+			executable += ".exe"
+		}
+		err = os.WriteFile(executable, nil, 0700) // #nosec G306
+		Expect(err).ToNot(HaveOccurred())
+
+		// Other commands should still work:
+		result := NewCommand().
+			Env("PATH", tmp).
+			Args("version").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("more than one flag named 'loud'"))
+	})
 })
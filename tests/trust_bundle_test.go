@@ -0,0 +1,190 @@
+/*
+Copyright (c) 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Trust bundle", Ordered, func() {
+	var ctx context.Context
+
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	var subscriptionInfo string = `{
+		"items": [
+		  {
+			"kind":"Subscription",
+			"cluster_id":"my-cluster",
+			"id":"subsID"
+		  }]
+	}`
+
+	makeCertificate := func(name string, notAfter time.Time) string {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+			NotAfter:     notAfter,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).ToNot(HaveOccurred())
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	}
+
+	clusterWithBundle := func(bundle string) string {
+		data, err := json.Marshal(map[string]interface{}{
+			"kind":  "ClusterList",
+			"total": 1,
+			"items": []interface{}{
+				map[string]interface{}{
+					"kind":                    "Cluster",
+					"id":                      "my-cluster",
+					"name":                    "my-cluster",
+					"subscription":            map[string]interface{}{"id": "subsID"},
+					"state":                   "ready",
+					"aws":                     map[string]interface{}{"subnet_ids": []string{"subnet-1"}},
+					"additional_trust_bundle": bundle,
+				},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Shows the certificates and warns about the expiring ones", func() {
+		bundle := makeCertificate("Proxy CA", time.Now().Add(90*24*time.Hour)) +
+			makeCertificate("Old CA", time.Now().Add(5*24*time.Hour))
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, subscriptionInfo),
+			RespondWithJSON(http.StatusOK, clusterWithBundle(bundle)),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "trust-bundle", "show", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(MatchRegexp(`CN=Proxy CA\s+\S+\s+valid`))
+		Expect(result.OutString()).To(MatchRegexp(`CN=Old CA\s+\S+\s+expiring`))
+		Expect(result.ErrString()).To(ContainSubstring("Warning: Certificate 'Old CA'"))
+	})
+
+	It("Fails to show a bundle that the server doesn't return", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, subscriptionInfo),
+			RespondWithJSON(http.StatusOK, clusterWithBundle("REDACTED")),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "trust-bundle", "show", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("doesn't return its contents"))
+	})
+
+	It("Adds a certificate to the existing ones", func() {
+		existing := makeCertificate("Proxy CA", time.Now().Add(90*24*time.Hour))
+		added := makeCertificate("New CA", time.Now().Add(90*24*time.Hour))
+		file := filepath.Join(GinkgoT().TempDir(), "new.pem")
+		Expect(os.WriteFile(file, []byte(added+existing), 0600)).To(Succeed())
+
+		var body map[string]interface{}
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, subscriptionInfo),
+			RespondWithJSON(http.StatusOK, clusterWithBundle(existing)),
+			CombineHandlers(
+				VerifyRequest(http.MethodPatch, "/api/clusters_mgmt/v1/clusters/my-cluster"),
+				func(w http.ResponseWriter, r *http.Request) {
+					data, err := io.ReadAll(r.Body)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(json.Unmarshal(data, &body)).To(Succeed())
+				},
+				RespondWithJSON(http.StatusOK, `{"kind": "Cluster", "id": "my-cluster"}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "trust-bundle", "add", "my-cluster", "--file", file).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring("Added certificate 'New CA'"))
+		Expect(result.OutString()).ToNot(ContainSubstring("'Proxy CA'"))
+		Expect(body).To(HaveKeyWithValue("additional_trust_bundle", existing+added))
+	})
+})